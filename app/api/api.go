@@ -1,6 +1,304 @@
 package api
 
+import (
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+)
+
+// NotePost creates or replaces a note. Format is "plain" or "markdown", new
+// notes are plain text and replaced ones keep their format if it is left out.
 type NotePost struct {
 	Title   string `json:"title"`
 	Content string `json:"content"`
+	Format  string `json:"format"`
+}
+
+// NotePatch is the body of a partial update. Fields left out of the request
+// stay nil and are not changed.
+type NotePatch struct {
+	Title   *string `json:"title"`
+	Content *string `json:"content"`
+	Format  *string `json:"format"`
+}
+
+type Note struct {
+	ID        string     `json:"id"`
+	Title     string     `json:"title"`
+	Content   string     `json:"content"`
+	Format    string     `json:"format,omitempty"`
+	UserID    string     `json:"user_id"`
+	Tags      []string   `json:"tags,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	Version   int        `json:"version"`
+}
+
+// FromNote is the representation of n in responses.
+func FromNote(n note.Note) Note {
+	return Note{
+		ID:        n.ID.String(),
+		Title:     n.Title.String(),
+		Content:   n.Content.String(),
+		Format:    string(n.Format),
+		UserID:    n.UserID.String(),
+		Tags:      n.Tags,
+		CreatedAt: n.CreatedAt,
+		UpdatedAt: n.UpdatedAt,
+		DeletedAt: n.DeletedAt,
+		Version:   n.Version,
+	}
+}
+
+// PublicNote is a note opened through a share link. It leaves out the owner
+// and what only matters to them.
+type PublicNote struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	Format    string    `json:"format,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// FromPublicNote is the representation of n opened through a share link.
+func FromPublicNote(n note.Note) PublicNote {
+	return PublicNote{
+		ID:        n.ID.String(),
+		Title:     n.Title.String(),
+		Content:   n.Content.String(),
+		Format:    string(n.Format),
+		CreatedAt: n.CreatedAt,
+		UpdatedAt: n.UpdatedAt,
+	}
+}
+
+// NotesPage is a page of notes. NextCursor is left out on the last page.
+type NotesPage struct {
+	Notes      []Note `json:"notes"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type SearchResult struct {
+	Note    Note    `json:"note"`
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+// ImportResult reports what happened to each note of an import, in the order
+// they were found in the file.
+type ImportResult struct {
+	Created int          `json:"created"`
+	Skipped int          `json:"skipped"`
+	Failed  int          `json:"failed"`
+	Items   []ImportItem `json:"items"`
+}
+
+// ImportItem is the result of a single note. Status is "created", "skipped"
+// if the note has been imported before, with NoteID being that note, or
+// "failed" with the reason in Error.
+type ImportItem struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	NoteID string `json:"note_id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BatchPost is a list of operations run in one transaction. Mode is "atomic"
+// (the default), applying all operations or none, or "best_effort".
+type BatchPost struct {
+	Mode       string           `json:"mode"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchOperation is a "create", "update" or "delete". Update and delete take
+// the ID of the note and optionally the Version last read. Fields left out of
+// an update are not changed.
+type BatchOperation struct {
+	Op      string  `json:"op"`
+	ID      string  `json:"id"`
+	Version int     `json:"version"`
+	Title   *string `json:"title"`
+	Content *string `json:"content"`
+	Format  *string `json:"format"`
+}
+
+type BatchResult struct {
+	Applied int                    `json:"applied"`
+	Failed  int                    `json:"failed"`
+	Results []BatchOperationResult `json:"results"`
+}
+
+// BatchOperationResult is the result of the operation at the same index.
+// Status is "applied", "failed" with the reason in Error, or "aborted" if the
+// operation was not applied because another one of an atomic batch failed.
+type BatchOperationResult struct {
+	Op     string `json:"op"`
+	Status string `json:"status"`
+	Note   *Note  `json:"note,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type Revision struct {
+	NoteID    string    `json:"note_id"`
+	Number    int       `json:"number"`
+	AuthorID  string    `json:"author_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+}
+
+// TagsPost adds tags to a note.
+type TagsPost struct {
+	Tags []string `json:"tags"`
+}
+
+// TagPatch renames a tag. Renaming to an existing tag merges both.
+type TagPatch struct {
+	Name string `json:"name"`
+}
+
+type TagCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// RevisionDiff is the unified diff between two revisions of a note.
+type RevisionDiff struct {
+	From int    `json:"from"`
+	To   int    `json:"to"`
+	Diff string `json:"diff"`
+}
+
+// NotebookPost creates a notebook. ParentID is left out for a top-level
+// notebook.
+type NotebookPost struct {
+	Name     string  `json:"name"`
+	ParentID *string `json:"parent_id"`
+}
+
+type NotebookPatch struct {
+	Name string `json:"name"`
+}
+
+// NotebookMove moves a notebook into another one, or to the top level if
+// ParentID is null.
+type NotebookMove struct {
+	ParentID *string `json:"parent_id"`
+}
+
+// NoteMove moves a note into a notebook, or out of its notebook if NotebookID
+// is null.
+type NoteMove struct {
+	NotebookID *string `json:"notebook_id"`
+}
+
+type Notebook struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	ParentID  *string   `json:"parent_id,omitempty"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NotebookContents is a notebook with the notebooks and notes directly
+// inside it.
+type NotebookContents struct {
+	Notebook
+	Notebooks []Notebook `json:"notebooks"`
+	Notes     []Note     `json:"notes"`
+}
+
+type Attachment struct {
+	ID          string    `json:"id"`
+	NoteID      string    `json:"note_id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AttachmentUsage is the storage used by the attachments of a user in bytes.
+// A Quota of 0 means unlimited.
+type AttachmentUsage struct {
+	Used  int64 `json:"used"`
+	Quota int64 `json:"quota"`
+}
+
+// SharePost grants a user access to a note. Permission is one of "read",
+// "write" or "owner".
+type SharePost struct {
+	UserID     string `json:"user_id"`
+	Permission string `json:"permission"`
+}
+
+type ShareDelete struct {
+	UserID string `json:"user_id"`
+}
+
+type Share struct {
+	NoteID     string `json:"note_id"`
+	UserID     string `json:"user_id"`
+	Permission string `json:"permission"`
+}
+
+// LinkPost creates a public share link. All fields are optional, a MaxViews
+// of 0 means unlimited.
+type LinkPost struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+	MaxViews  int        `json:"max_views"`
+	Password  string     `json:"password"`
+}
+
+// Link is a public share link. Token is only returned when the link is
+// created.
+type Link struct {
+	ID          string     `json:"id"`
+	NoteID      string     `json:"note_id"`
+	Token       string     `json:"token,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	MaxViews    int        `json:"max_views"`
+	Views       int        `json:"views"`
+	HasPassword bool       `json:"has_password"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+type UserPost struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// UserPatch is the body of a partial user update. Fields left out of the
+// request stay nil and are not changed.
+type UserPatch struct {
+	Name     *string `json:"name"`
+	Email    *string `json:"email"`
+	Password *string `json:"password"`
+}
+
+type User struct {
+	ID    string   `json:"id"`
+	Name  string   `json:"name"`
+	Email string   `json:"email"`
+	Roles []string `json:"roles,omitempty"`
+}
+
+type LoginPost struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type RefreshPost struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
 }
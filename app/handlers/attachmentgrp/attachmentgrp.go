@@ -0,0 +1,230 @@
+package attachmentgrp
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/app/api"
+	"github.com/Keisn1/note-taking-app/domain/core/attachment"
+	"github.com/Keisn1/note-taking-app/domain/web/mid"
+	"github.com/google/uuid"
+)
+
+// multipartOverhead is allowed on top of the file for the boundaries and
+// headers of the multipart body.
+const multipartOverhead = 1 << 20
+
+// maxMemory is the part of an upload kept in memory, the rest is buffered in
+// a temporary file.
+const maxMemory = 8 << 20
+
+// downloadWriteTimeout is the time each chunk of a download may take to be
+// written. It replaces the write timeout of the server, which would cut off
+// large files on slow connections.
+const downloadWriteTimeout = 30 * time.Second
+
+type Handlers struct {
+	attachmentSvc attachment.Service
+	maxSize       int64
+}
+
+func NewHandlers(as attachment.Service, maxSize int64) Handlers {
+	return Handlers{attachmentSvc: as, maxSize: maxSize}
+}
+
+// Upload attaches the file of the multipart field "file" to the note set by
+// mid.AuthorizeNote.
+func (hdl *Handlers) Upload(w http.ResponseWriter, r *http.Request) {
+	n := mid.GetNote(r.Context())
+	logMsg := fmt.Sprintf("Upload: noteID %v", n.ID)
+
+	r.Body = http.MaxBytesReader(w, r.Body, hdl.maxSize+multipartOverhead)
+	if err := r.ParseMultipartForm(maxMemory); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			handleError(w, "file too large", http.StatusRequestEntityTooLarge, logMsg, "error", err)
+			return
+		}
+		handleError(w, "", http.StatusBadRequest, logMsg, "error", err)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		handleError(w, "missing file", http.StatusBadRequest, logMsg, "error", err)
+		return
+	}
+	defer file.Close()
+
+	if header.Size > hdl.maxSize {
+		handleError(w, "file too large", http.StatusRequestEntityTooLarge, logMsg, "error", fmt.Errorf("size %d", header.Size))
+		return
+	}
+
+	// generic types are left to the detection of the service
+	contentType := header.Header.Get("Content-Type")
+	if contentType == "application/octet-stream" {
+		contentType = ""
+	}
+
+	a, err := hdl.attachmentSvc.Create(r.Context(), n, attachment.NewAttachment{
+		Filename:    header.Filename,
+		ContentType: contentType,
+		Size:        header.Size,
+		Body:        file,
+	})
+	if err != nil {
+		handleServiceError(w, logMsg, err)
+		return
+	}
+
+	respond(w, http.StatusCreated, toAPIAttachment(a), fmt.Sprintf("Upload: noteID %v attachmentID %v", n.ID, a.ID))
+}
+
+// Query lists the attachments of the note set by mid.AuthorizeNote.
+func (hdl *Handlers) Query(w http.ResponseWriter, r *http.Request) {
+	n := mid.GetNote(r.Context())
+	logMsg := fmt.Sprintf("Query: noteID %v", n.ID)
+
+	attachments, err := hdl.attachmentSvc.QueryByNoteID(r.Context(), n.ID)
+	if err != nil {
+		handleServiceError(w, logMsg, err)
+		return
+	}
+
+	ret := make([]api.Attachment, 0, len(attachments))
+	for _, a := range attachments {
+		ret = append(ret, toAPIAttachment(a))
+	}
+
+	respond(w, http.StatusOK, ret, logMsg)
+}
+
+// Download streams the file of an attachment. Range and conditional requests
+// are handled by http.ServeContent, with the sha256 of the file as ETag.
+func (hdl *Handlers) Download(w http.ResponseWriter, r *http.Request) {
+	n := mid.GetNote(r.Context())
+	logMsg := fmt.Sprintf("Download: noteID %v attachmentID %v", n.ID, r.PathValue("attachment_id"))
+
+	attachmentID, err := uuid.Parse(r.PathValue("attachment_id"))
+	if err != nil {
+		handleError(w, "invalid attachment_id", http.StatusBadRequest, logMsg, "error", err)
+		return
+	}
+
+	a, blob, err := hdl.attachmentSvc.Open(r.Context(), n.ID, attachmentID)
+	if err != nil {
+		handleServiceError(w, logMsg, err)
+		return
+	}
+	defer blob.Close()
+
+	// files are never rendered inline, so an uploaded page can't run scripts
+	// on our origin
+	w.Header().Set("Content-Type", a.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("ETag", a.ETag())
+	http.ServeContent(deadlineWriter{ResponseWriter: w, rc: http.NewResponseController(w)}, r, a.Filename, a.CreatedAt, blob)
+	slog.Info("Success: " + logMsg)
+}
+
+// deadlineWriter extends the write deadline of the response before every
+// write, see downloadWriteTimeout.
+type deadlineWriter struct {
+	http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func (w deadlineWriter) Write(p []byte) (int, error) {
+	// not all ResponseWriters support deadlines, which only matters to large files
+	_ = w.rc.SetWriteDeadline(time.Now().Add(downloadWriteTimeout))
+	return w.ResponseWriter.Write(p)
+}
+
+func (hdl *Handlers) Delete(w http.ResponseWriter, r *http.Request) {
+	n := mid.GetNote(r.Context())
+	logMsg := fmt.Sprintf("Delete: noteID %v attachmentID %v", n.ID, r.PathValue("attachment_id"))
+
+	attachmentID, err := uuid.Parse(r.PathValue("attachment_id"))
+	if err != nil {
+		handleError(w, "invalid attachment_id", http.StatusBadRequest, logMsg, "error", err)
+		return
+	}
+
+	if err := hdl.attachmentSvc.Delete(r.Context(), n.ID, attachmentID); err != nil {
+		handleServiceError(w, logMsg, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	slog.Info("Success: " + logMsg)
+}
+
+// GetUsage returns the storage used by the caller's attachments and their
+// quota.
+func (hdl *Handlers) GetUsage(w http.ResponseWriter, r *http.Request) {
+	userID := mid.GetUserID(r.Context())
+	logMsg := fmt.Sprintf("GetUsage: userID %v", userID)
+
+	usage, err := hdl.attachmentSvc.Usage(r.Context(), userID)
+	if err != nil {
+		handleServiceError(w, logMsg, err)
+		return
+	}
+
+	respond(w, http.StatusOK, api.AttachmentUsage{Used: usage.Used, Quota: usage.Quota}, logMsg)
+}
+
+// handleServiceError maps the errors of attachment.Service to status codes.
+func handleServiceError(w http.ResponseWriter, logMsg string, err error) {
+	switch {
+	case errors.Is(err, attachment.ErrInvalidFilename):
+		handleError(w, "invalid filename", http.StatusBadRequest, logMsg, "error", err)
+	case errors.Is(err, attachment.ErrInvalidSize):
+		handleError(w, "invalid size", http.StatusBadRequest, logMsg, "error", err)
+	case errors.Is(err, attachment.ErrQuotaExceeded):
+		handleError(w, "storage quota exceeded", http.StatusRequestEntityTooLarge, logMsg, "error", err)
+	case errors.Is(err, attachment.ErrAttachmentNotFound):
+		handleError(w, "attachment not found", http.StatusNotFound, logMsg, "error", err)
+	default:
+		handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
+	}
+}
+
+func respond(w http.ResponseWriter, status int, data any, logMsg string) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+	slog.Info("Success: " + logMsg)
+}
+
+func handleError(w http.ResponseWriter, errMsg string, status int, logMsg string, args ...any) {
+	http.Error(w, errMsg, status)
+	slog.Error(logMsg, args...)
+}
+
+func toAPIAttachment(a attachment.Attachment) api.Attachment {
+	return api.Attachment{
+		ID:          a.ID.String(),
+		NoteID:      a.NoteID.String(),
+		Filename:    a.Filename,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		SHA256:      hex.EncodeToString(a.SHA256),
+		CreatedAt:   a.CreatedAt,
+	}
+}
@@ -0,0 +1,402 @@
+package attachmentgrp_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/app/api"
+	"github.com/Keisn1/note-taking-app/app/handlers/attachmentgrp"
+	"github.com/Keisn1/note-taking-app/domain/core/attachment"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/foundation"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustEncode(t *testing.T, a any) string {
+	data, err := json.Marshal(a)
+	assert.NoError(t, err)
+	return string(data)
+}
+
+func withNote(req *http.Request, n note.Note) *http.Request {
+	ctx := context.WithValue(req.Context(), foundation.NoteKey, n)
+	ctx = context.WithValue(ctx, foundation.UserIDKey, n.UserID)
+	return req.WithContext(ctx)
+}
+
+// multipartBody returns a body with the file in the field "file" and its
+// content type.
+func multipartBody(t *testing.T, filename, contentType, content string) (io.Reader, string) {
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, filename))
+	h.Set("Content-Type", contentType)
+	part, err := mw.CreatePart(h)
+	assert.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	assert.NoError(t, err)
+	assert.NoError(t, mw.Close())
+	return body, mw.FormDataContentType()
+}
+
+// blob is a io.ReadSeekCloser over a string.
+type blob struct {
+	*strings.Reader
+}
+
+func (blob) Close() error { return nil }
+
+func Test_Upload(t *testing.T) {
+	mAttachmentSvc := &mockAttachmentSvc{}
+	hdl := attachmentgrp.NewHandlers(mAttachmentSvc, 16)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	n := note.Note{ID: uuid.UUID{1}, UserID: uuid.UUID{2}}
+	a := attachment.Attachment{ID: uuid.UUID{3}, NoteID: n.ID, UserID: n.UserID, Filename: "hello.txt", ContentType: "text/plain", Size: 5, SHA256: []byte{0xab}}
+
+	testCases := []struct {
+		name        string
+		filename    string
+		contentType string
+		content     string
+		mASP        *mockAttachmentSvcParams
+		wantStatus  int
+		wantBody    string
+	}{
+		{
+			name:        "Upload success",
+			filename:    "hello.txt",
+			contentType: "text/plain",
+			content:     "hello",
+			mASP: &mockAttachmentSvcParams{
+				method:          "Create",
+				arguments:       []any{n, attachment.NewAttachment{Filename: "hello.txt", ContentType: "text/plain", Size: 5}, "hello"},
+				returnArguments: []any{a, nil},
+			},
+			wantStatus: http.StatusCreated,
+			wantBody: mustEncode(t, api.Attachment{
+				ID: a.ID.String(), NoteID: n.ID.String(), Filename: "hello.txt", ContentType: "text/plain", Size: 5, SHA256: "ab",
+			}),
+		},
+		{
+			name:        "Generic content types are detected",
+			filename:    "hello.txt",
+			contentType: "application/octet-stream",
+			content:     "hello",
+			mASP: &mockAttachmentSvcParams{
+				method:          "Create",
+				arguments:       []any{n, attachment.NewAttachment{Filename: "hello.txt", Size: 5}, "hello"},
+				returnArguments: []any{a, nil},
+			},
+			wantStatus: http.StatusCreated,
+			wantBody: mustEncode(t, api.Attachment{
+				ID: a.ID.String(), NoteID: n.ID.String(), Filename: "hello.txt", ContentType: "text/plain", Size: 5, SHA256: "ab",
+			}),
+		},
+		{
+			name:        "Upload beyond quota",
+			filename:    "hello.txt",
+			contentType: "text/plain",
+			content:     "hello",
+			mASP: &mockAttachmentSvcParams{
+				method:          "Create",
+				arguments:       []any{n, attachment.NewAttachment{Filename: "hello.txt", ContentType: "text/plain", Size: 5}, "hello"},
+				returnArguments: []any{attachment.Attachment{}, attachment.ErrQuotaExceeded},
+			},
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantBody:   fmt.Sprintln("storage quota exceeded"),
+		},
+		{
+			name:        "Upload with invalid filename",
+			filename:    "..",
+			contentType: "text/plain",
+			content:     "hello",
+			mASP: &mockAttachmentSvcParams{
+				method:          "Create",
+				arguments:       []any{n, attachment.NewAttachment{Filename: "..", ContentType: "text/plain", Size: 5}, "hello"},
+				returnArguments: []any{attachment.Attachment{}, attachment.ErrInvalidFilename},
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   fmt.Sprintln("invalid filename"),
+		},
+		{
+			name:        "Upload too large",
+			filename:    "big.txt",
+			contentType: "text/plain",
+			content:     strings.Repeat("x", 17),
+			wantStatus:  http.StatusRequestEntityTooLarge,
+			wantBody:    fmt.Sprintln("file too large"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mAttachmentSvc.Reset()
+			if tc.mASP != nil {
+				mAttachmentSvc.Setup(*tc.mASP)
+			}
+			body, contentType := multipartBody(t, tc.filename, tc.contentType, tc.content)
+			req := withNote(httptest.NewRequest(http.MethodPost, "/notes/"+n.ID.String()+"/attachments", body), n)
+			req.Header.Set("Content-Type", contentType)
+			rr := httptest.NewRecorder()
+
+			hdl.Upload(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, tc.wantBody, rr.Body.String())
+			if tc.mASP != nil {
+				mAttachmentSvc.AssertCalled(t, tc.mASP.method, tc.mASP.arguments...)
+			} else {
+				mAttachmentSvc.AssertNotCalled(t, "Create")
+			}
+		})
+	}
+
+	t.Run("Upload without file", func(t *testing.T) {
+		mAttachmentSvc.Reset()
+		req := withNote(httptest.NewRequest(http.MethodPost, "/notes/"+n.ID.String()+"/attachments", strings.NewReader("")), n)
+		req.Header.Set("Content-Type", "multipart/form-data; boundary=x")
+		rr := httptest.NewRecorder()
+
+		hdl.Upload(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mAttachmentSvc.AssertNotCalled(t, "Create")
+	})
+}
+
+func Test_Download(t *testing.T) {
+	mAttachmentSvc := &mockAttachmentSvc{}
+	hdl := attachmentgrp.NewHandlers(mAttachmentSvc, 16)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	n := note.Note{ID: uuid.UUID{1}, UserID: uuid.UUID{2}}
+	a := attachment.Attachment{
+		ID: uuid.UUID{3}, NoteID: n.ID, UserID: n.UserID, Filename: "zahlen änderung.txt", ContentType: "text/plain",
+		Size: 10, SHA256: []byte{0xab}, CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	testCases := []struct {
+		name         string
+		attachmentID string
+		header       map[string]string
+		mASP         *mockAttachmentSvcParams
+		wantStatus   int
+		wantBody     string
+		wantHeader   map[string]string
+	}{
+		{
+			name:         "Download success",
+			attachmentID: a.ID.String(),
+			mASP: &mockAttachmentSvcParams{
+				method:          "Open",
+				arguments:       []any{n.ID, a.ID},
+				returnArguments: []any{a, blob{strings.NewReader("0123456789")}, nil},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "0123456789",
+			wantHeader: map[string]string{
+				"Content-Type":           "text/plain",
+				"Content-Length":         "10",
+				"Content-Disposition":    "attachment; filename*=utf-8''zahlen%20%C3%A4nderung.txt",
+				"X-Content-Type-Options": "nosniff",
+				"ETag":                   `"ab"`,
+				"Accept-Ranges":          "bytes",
+			},
+		},
+		{
+			name:         "Download a range",
+			attachmentID: a.ID.String(),
+			header:       map[string]string{"Range": "bytes=2-4"},
+			mASP: &mockAttachmentSvcParams{
+				method:          "Open",
+				arguments:       []any{n.ID, a.ID},
+				returnArguments: []any{a, blob{strings.NewReader("0123456789")}, nil},
+			},
+			wantStatus: http.StatusPartialContent,
+			wantBody:   "234",
+			wantHeader: map[string]string{"Content-Range": "bytes 2-4/10", "Content-Length": "3"},
+		},
+		{
+			name:         "Download unchanged file",
+			attachmentID: a.ID.String(),
+			header:       map[string]string{"If-None-Match": `"ab"`},
+			mASP: &mockAttachmentSvcParams{
+				method:          "Open",
+				arguments:       []any{n.ID, a.ID},
+				returnArguments: []any{a, blob{strings.NewReader("0123456789")}, nil},
+			},
+			wantStatus: http.StatusNotModified,
+			wantBody:   "",
+		},
+		{
+			name:         "Download missing attachment",
+			attachmentID: a.ID.String(),
+			mASP: &mockAttachmentSvcParams{
+				method:          "Open",
+				arguments:       []any{n.ID, a.ID},
+				returnArguments: []any{attachment.Attachment{}, nil, attachment.ErrAttachmentNotFound},
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   fmt.Sprintln("attachment not found"),
+		},
+		{name: "Download with invalid id", attachmentID: "abc", wantStatus: http.StatusBadRequest, wantBody: fmt.Sprintln("invalid attachment_id")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mAttachmentSvc.Reset()
+			if tc.mASP != nil {
+				mAttachmentSvc.Setup(*tc.mASP)
+			}
+			req := withNote(httptest.NewRequest(http.MethodGet, "/notes/"+n.ID.String()+"/attachments/"+tc.attachmentID, nil), n)
+			req.SetPathValue("attachment_id", tc.attachmentID)
+			for k, v := range tc.header {
+				req.Header.Set(k, v)
+			}
+			rr := httptest.NewRecorder()
+
+			hdl.Download(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, tc.wantBody, rr.Body.String())
+			for k, v := range tc.wantHeader {
+				assert.Equal(t, v, rr.Header().Get(k), k)
+			}
+		})
+	}
+}
+
+// slowBlob takes delay for every byte read.
+type slowBlob struct {
+	blob
+	delay time.Duration
+}
+
+func (b slowBlob) Read(p []byte) (int, error) {
+	time.Sleep(b.delay)
+	return b.blob.Read(p[:min(1, len(p))])
+}
+
+func Test_DownloadSlowFile(t *testing.T) {
+	mAttachmentSvc := &mockAttachmentSvc{}
+	hdl := attachmentgrp.NewHandlers(mAttachmentSvc, 16)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	n := note.Note{ID: uuid.UUID{1}, UserID: uuid.UUID{2}}
+	a := attachment.Attachment{ID: uuid.UUID{3}, NoteID: n.ID, UserID: n.UserID, Filename: "large.txt", ContentType: "text/plain", Size: 10}
+	mAttachmentSvc.Setup(mockAttachmentSvcParams{
+		method:          "Open",
+		arguments:       []any{n.ID, a.ID},
+		returnArguments: []any{a, slowBlob{blob: blob{strings.NewReader("0123456789")}, delay: 20 * time.Millisecond}, nil},
+	})
+
+	// the download takes longer than the write timeout of the server
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.SetPathValue("attachment_id", a.ID.String())
+		hdl.Download(w, withNote(r, n))
+	}))
+	srv.Config.WriteTimeout = 100 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	res, err := srv.Client().Get(srv.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "0123456789", string(body))
+}
+
+func Test_Delete(t *testing.T) {
+	mAttachmentSvc := &mockAttachmentSvc{}
+	hdl := attachmentgrp.NewHandlers(mAttachmentSvc, 16)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	n := note.Note{ID: uuid.UUID{1}, UserID: uuid.UUID{2}}
+	attachmentID := uuid.UUID{3}
+
+	testCases := []struct {
+		name       string
+		mASP       mockAttachmentSvcParams
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Delete success",
+			mASP:       mockAttachmentSvcParams{method: "Delete", arguments: []any{n.ID, attachmentID}, returnArguments: []any{nil}},
+			wantStatus: http.StatusNoContent,
+			wantBody:   "",
+		},
+		{
+			name:       "Delete missing attachment",
+			mASP:       mockAttachmentSvcParams{method: "Delete", arguments: []any{n.ID, attachmentID}, returnArguments: []any{attachment.ErrAttachmentNotFound}},
+			wantStatus: http.StatusNotFound,
+			wantBody:   fmt.Sprintln("attachment not found"),
+		},
+		{
+			name:       "Delete fails",
+			mASP:       mockAttachmentSvcParams{method: "Delete", arguments: []any{n.ID, attachmentID}, returnArguments: []any{errors.New("blob store down")}},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   fmt.Sprintln(""),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mAttachmentSvc.Setup(tc.mASP)
+			req := withNote(httptest.NewRequest(http.MethodDelete, "/notes/"+n.ID.String()+"/attachments/"+attachmentID.String(), nil), n)
+			req.SetPathValue("attachment_id", attachmentID.String())
+			rr := httptest.NewRecorder()
+
+			hdl.Delete(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, tc.wantBody, rr.Body.String())
+			mAttachmentSvc.AssertCalled(t, tc.mASP.method, tc.mASP.arguments...)
+		})
+	}
+}
+
+func Test_GetUsage(t *testing.T) {
+	mAttachmentSvc := &mockAttachmentSvc{}
+	hdl := attachmentgrp.NewHandlers(mAttachmentSvc, 16)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	userID := uuid.UUID{1}
+	mAttachmentSvc.Setup(mockAttachmentSvcParams{
+		method:          "Usage",
+		arguments:       []any{userID},
+		returnArguments: []any{attachment.Usage{Used: 10, Quota: 100}, nil},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/attachments/usage", nil)
+	req = req.WithContext(context.WithValue(req.Context(), foundation.UserIDKey, userID))
+	rr := httptest.NewRecorder()
+
+	hdl.GetUsage(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, mustEncode(t, api.AttachmentUsage{Used: 10, Quota: 100}), rr.Body.String())
+}
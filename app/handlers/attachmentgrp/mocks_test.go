@@ -0,0 +1,65 @@
+package attachmentgrp_test
+
+import (
+	"context"
+	"io"
+
+	"github.com/Keisn1/note-taking-app/domain/core/attachment"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockAttachmentSvc struct {
+	mock.Mock
+}
+
+type mockAttachmentSvcParams struct {
+	method          string
+	arguments       []any
+	returnArguments []any
+}
+
+func (mAS *mockAttachmentSvc) Setup(p mockAttachmentSvcParams) {
+	mAS.Reset()
+	mAS.On(p.method, p.arguments...).Return(p.returnArguments...)
+}
+
+func (mAS *mockAttachmentSvc) Reset() {
+	mAS.Calls = []mock.Call{}
+	mAS.ExpectedCalls = []*mock.Call{}
+}
+
+// Create reads the body, so that tests can match on its contents.
+func (mAS *mockAttachmentSvc) Create(ctx context.Context, n note.Note, na attachment.NewAttachment) (attachment.Attachment, error) {
+	body, _ := io.ReadAll(na.Body)
+	na.Body = nil
+	args := mAS.Called(n, na, string(body))
+	return args.Get(0).(attachment.Attachment), args.Error(1)
+}
+
+func (mAS *mockAttachmentSvc) QueryByNoteID(ctx context.Context, noteID uuid.UUID) ([]attachment.Attachment, error) {
+	args := mAS.Called(noteID)
+	return args.Get(0).([]attachment.Attachment), args.Error(1)
+}
+
+func (mAS *mockAttachmentSvc) Open(ctx context.Context, noteID, attachmentID uuid.UUID) (attachment.Attachment, io.ReadSeekCloser, error) {
+	args := mAS.Called(noteID, attachmentID)
+	blob, _ := args.Get(1).(io.ReadSeekCloser)
+	return args.Get(0).(attachment.Attachment), blob, args.Error(2)
+}
+
+func (mAS *mockAttachmentSvc) Delete(ctx context.Context, noteID, attachmentID uuid.UUID) error {
+	args := mAS.Called(noteID, attachmentID)
+	return args.Error(0)
+}
+
+func (mAS *mockAttachmentSvc) Usage(ctx context.Context, userID uuid.UUID) (attachment.Usage, error) {
+	args := mAS.Called(userID)
+	return args.Get(0).(attachment.Usage), args.Error(1)
+}
+
+func (mAS *mockAttachmentSvc) PurgeOrphans(ctx context.Context) (int, error) {
+	args := mAS.Called()
+	return args.Int(0), args.Error(1)
+}
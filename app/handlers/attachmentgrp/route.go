@@ -0,0 +1,34 @@
+package attachmentgrp
+
+import (
+	"net/http"
+
+	"github.com/Keisn1/note-taking-app/domain/core/attachment"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/web/auth"
+	"github.com/Keisn1/note-taking-app/domain/web/mid"
+	"github.com/Keisn1/note-taking-app/foundation/web"
+)
+
+type Config struct {
+	Auth          auth.Auth
+	NoteSvc       note.Service
+	AttachmentSvc attachment.Service
+	// MaxSize limits the size of a single uploaded file in bytes.
+	MaxSize int64
+}
+
+func Routes(app *web.App, cfg Config) {
+	authen := mid.Authenticate(cfg.Auth)
+	canRead := mid.AuthorizeNote(cfg.NoteSvc, note.PermissionRead)
+	canWrite := mid.AuthorizeNote(cfg.NoteSvc, note.PermissionWrite)
+	read := mid.Authorize(auth.RuleNotesRead)
+	write := mid.Authorize(auth.RuleNotesWrite)
+
+	hdl := NewHandlers(cfg.AttachmentSvc, cfg.MaxSize)
+	app.Handle("GET /attachments/usage", authen(read(http.HandlerFunc(hdl.GetUsage))))
+	app.Handle("GET /notes/{note_id}/attachments", authen(read(canRead(http.HandlerFunc(hdl.Query)))))
+	app.Handle("POST /notes/{note_id}/attachments", authen(write(canWrite(http.HandlerFunc(hdl.Upload)))))
+	app.Handle("GET /notes/{note_id}/attachments/{attachment_id}", authen(read(canRead(http.HandlerFunc(hdl.Download)))))
+	app.Handle("DELETE /notes/{note_id}/attachments/{attachment_id}", authen(write(canWrite(http.HandlerFunc(hdl.Delete)))))
+}
@@ -0,0 +1,34 @@
+package authgrp
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Keisn1/note-taking-app/domain/web/auth"
+)
+
+type Handlers struct {
+	ks *auth.KeyStore
+}
+
+func NewHandlers(ks *auth.KeyStore) Handlers {
+	return Handlers{ks: ks}
+}
+
+// JWKS publishes the public keys used to verify access tokens, so that other
+// services can verify tokens without sharing a secret.
+func (hdl *Handlers) JWKS(w http.ResponseWriter, r *http.Request) {
+	body, err := json.Marshal(hdl.ks.JWKS())
+	if err != nil {
+		http.Error(w, "", http.StatusInternalServerError)
+		slog.Error("JWKS", "error", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+	slog.Info("Success: JWKS")
+}
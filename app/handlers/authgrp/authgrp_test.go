@@ -0,0 +1,37 @@
+package authgrp_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Keisn1/note-taking-app/app/handlers/authgrp"
+	"github.com/Keisn1/note-taking-app/domain/web/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_JWKS(t *testing.T) {
+	ks := auth.NewKeyStore()
+	for _, kid := range []string{"k2", "k1"} {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		assert.NoError(t, err)
+		assert.NoError(t, ks.Add(kid, key))
+	}
+	hdl := authgrp.NewHandlers(ks)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	hdl.JWKS(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	var got auth.JWKS
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+	assert.Equal(t, ks.JWKS(), got)
+	assert.Equal(t, "k1", got.Keys[0].KeyID)
+	assert.Equal(t, "k2", got.Keys[1].KeyID)
+}
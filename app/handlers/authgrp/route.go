@@ -0,0 +1,17 @@
+package authgrp
+
+import (
+	"net/http"
+
+	"github.com/Keisn1/note-taking-app/domain/web/auth"
+	"github.com/Keisn1/note-taking-app/foundation/web"
+)
+
+type Config struct {
+	KeyStore *auth.KeyStore
+}
+
+func Routes(app *web.App, cfg Config) {
+	hdl := NewHandlers(cfg.KeyStore)
+	app.Handle("GET /.well-known/jwks.json", http.HandlerFunc(hdl.JWKS))
+}
@@ -0,0 +1,152 @@
+package linkgrp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/Keisn1/note-taking-app/app/api"
+	"github.com/Keisn1/note-taking-app/domain/core/sharelink"
+	"github.com/Keisn1/note-taking-app/domain/web/mid"
+	"github.com/google/uuid"
+)
+
+// PasswordHeader carries the password of protected links.
+const PasswordHeader = "X-Share-Password"
+
+type Handlers struct {
+	linkSvc sharelink.Service
+}
+
+func NewHandlers(ls sharelink.Service) Handlers {
+	return Handlers{linkSvc: ls}
+}
+
+// Create makes a public link to the note set by mid.AuthorizeNote. The
+// response is the only place the token shows up.
+func (hdl *Handlers) Create(w http.ResponseWriter, r *http.Request) {
+	n := mid.GetNote(r.Context())
+	userID := mid.GetUserID(r.Context())
+
+	var lp api.LinkPost
+	err := json.NewDecoder(r.Body).Decode(&lp)
+	if err != nil {
+		handleError(w, "", http.StatusBadRequest, "Create: invalid body", "error", err)
+		return
+	}
+
+	nl := sharelink.NewLink{ExpiresAt: lp.ExpiresAt, MaxViews: lp.MaxViews, Password: lp.Password}
+	l, token, err := hdl.linkSvc.Create(r.Context(), n, userID, nl)
+	if err != nil {
+		handleServiceError(w, fmt.Sprintf("Create: noteID %v", n.ID), err)
+		return
+	}
+
+	ret := toAPILink(l)
+	ret.Token = token
+	respond(w, http.StatusCreated, ret, fmt.Sprintf("Create: noteID %v linkID %v", n.ID, l.ID))
+}
+
+func (hdl *Handlers) QueryByNoteID(w http.ResponseWriter, r *http.Request) {
+	n := mid.GetNote(r.Context())
+
+	links, err := hdl.linkSvc.QueryByNoteID(r.Context(), n.ID)
+	if err != nil {
+		handleServiceError(w, fmt.Sprintf("QueryByNoteID: noteID %v", n.ID), err)
+		return
+	}
+
+	ret := make([]api.Link, 0, len(links))
+	for _, l := range links {
+		ret = append(ret, toAPILink(l))
+	}
+
+	respond(w, http.StatusOK, ret, fmt.Sprintf("QueryByNoteID: noteID %v", n.ID))
+}
+
+func (hdl *Handlers) Revoke(w http.ResponseWriter, r *http.Request) {
+	n := mid.GetNote(r.Context())
+
+	linkID, err := uuid.Parse(r.PathValue("link_id"))
+	if err != nil {
+		handleError(w, "", http.StatusNotFound, "Revoke: invalid link_id", "error", err)
+		return
+	}
+
+	if err := hdl.linkSvc.Revoke(r.Context(), n.ID, linkID); err != nil {
+		handleServiceError(w, fmt.Sprintf("Revoke: noteID %v linkID %v", n.ID, linkID), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	slog.Info(fmt.Sprintf("Success: Revoke: noteID %v linkID %v", n.ID, linkID))
+}
+
+// Open serves the note behind a public link. It runs without authentication.
+func (hdl *Handlers) Open(w http.ResponseWriter, r *http.Request) {
+	token := r.PathValue("token")
+
+	n, err := hdl.linkSvc.Open(r.Context(), token, r.Header.Get(PasswordHeader))
+	if err != nil {
+		handleServiceError(w, "Open", err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	respond(w, http.StatusOK, api.FromPublicNote(n), fmt.Sprintf("Open: noteID %v", n.ID))
+}
+
+// handleServiceError maps the errors of sharelink.Service to status codes.
+// Links that don't work anymore are reported as not found, so that a token
+// doesn't reveal whether it ever existed.
+func handleServiceError(w http.ResponseWriter, logMsg string, err error) {
+	switch {
+	case errors.Is(err, sharelink.ErrInvalidMaxViews):
+		handleError(w, "invalid max_views", http.StatusBadRequest, logMsg, "error", err)
+	case errors.Is(err, sharelink.ErrPasswordRequired):
+		w.Header().Set("WWW-Authenticate", PasswordHeader)
+		handleError(w, "password required", http.StatusUnauthorized, logMsg, "error", err)
+	case errors.Is(err, sharelink.ErrInvalidPassword):
+		w.Header().Set("WWW-Authenticate", PasswordHeader)
+		handleError(w, "invalid password", http.StatusUnauthorized, logMsg, "error", err)
+	case errors.Is(err, sharelink.ErrInvalidLink),
+		errors.Is(err, sharelink.ErrLinkExpired),
+		errors.Is(err, sharelink.ErrViewLimitReached),
+		errors.Is(err, sharelink.ErrLinkNotFound):
+		handleError(w, "", http.StatusNotFound, logMsg, "error", err)
+	default:
+		handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
+	}
+}
+
+func respond(w http.ResponseWriter, status int, data any, logMsg string) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+	slog.Info("Success: " + logMsg)
+}
+
+func handleError(w http.ResponseWriter, errMsg string, status int, logMsg string, args ...any) {
+	http.Error(w, errMsg, status)
+	slog.Error(logMsg, args...)
+}
+
+func toAPILink(l sharelink.Link) api.Link {
+	return api.Link{
+		ID:          l.ID.String(),
+		NoteID:      l.NoteID.String(),
+		ExpiresAt:   l.ExpiresAt,
+		MaxViews:    l.MaxViews,
+		Views:       l.Views,
+		HasPassword: l.HasPassword(),
+		RevokedAt:   l.RevokedAt,
+	}
+}
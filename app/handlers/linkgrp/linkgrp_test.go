@@ -0,0 +1,201 @@
+package linkgrp_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Keisn1/note-taking-app/app/api"
+	"github.com/Keisn1/note-taking-app/app/handlers/linkgrp"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/core/sharelink"
+	"github.com/Keisn1/note-taking-app/foundation"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustEncode(t *testing.T, a any) string {
+	data, err := json.Marshal(a)
+	assert.NoError(t, err)
+	return string(data)
+}
+
+func withNote(req *http.Request, n note.Note) *http.Request {
+	ctx := context.WithValue(req.Context(), foundation.NoteKey, n)
+	ctx = context.WithValue(ctx, foundation.UserIDKey, n.UserID)
+	return req.WithContext(ctx)
+}
+
+func Test_Create(t *testing.T) {
+	mLinkSvc := &mockLinkSvc{}
+	hdl := linkgrp.NewHandlers(mLinkSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	n := note.Note{ID: uuid.UUID{1}, Title: note.NewTitle("title"), Content: note.NewContent("content"), UserID: uuid.UUID{2}}
+	l := sharelink.Link{ID: uuid.UUID{3}, NoteID: n.ID, CreatedBy: n.UserID, PasswordHash: []byte("hash"), MaxViews: 5}
+
+	testCases := []struct {
+		name       string
+		body       string
+		mLSP       mockLinkSvcParams
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "Create success returns the token",
+			body: mustEncode(t, api.LinkPost{MaxViews: 5, Password: "secret"}),
+			mLSP: mockLinkSvcParams{
+				method:          "Create",
+				arguments:       []any{n, n.UserID, sharelink.NewLink{MaxViews: 5, Password: "secret"}},
+				returnArguments: []any{l, "token", nil},
+			},
+			wantStatus: http.StatusCreated,
+			wantBody: mustEncode(t, api.Link{
+				ID: l.ID.String(), NoteID: n.ID.String(), Token: "token", MaxViews: 5, HasPassword: true,
+			}),
+		},
+		{
+			name: "Create invalid view limit",
+			body: mustEncode(t, api.LinkPost{MaxViews: -1}),
+			mLSP: mockLinkSvcParams{
+				method:          "Create",
+				arguments:       []any{n, n.UserID, sharelink.NewLink{MaxViews: -1}},
+				returnArguments: []any{sharelink.Link{}, "", sharelink.ErrInvalidMaxViews},
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   fmt.Sprintln("invalid max_views"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mLinkSvc.Setup(tc.mLSP)
+			req := withNote(httptest.NewRequest(http.MethodPost, "/notes/"+n.ID.String()+"/links", strings.NewReader(tc.body)), n)
+			rr := httptest.NewRecorder()
+
+			hdl.Create(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, tc.wantBody, rr.Body.String())
+			mLinkSvc.AssertCalled(t, tc.mLSP.method, tc.mLSP.arguments...)
+		})
+	}
+}
+
+func Test_Open(t *testing.T) {
+	mLinkSvc := &mockLinkSvc{}
+	hdl := linkgrp.NewHandlers(mLinkSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	n := note.Note{ID: uuid.UUID{1}, Title: note.NewTitle("title"), Content: note.NewContent("content"), Format: note.FormatMarkdown, UserID: uuid.UUID{2}, Tags: []string{"work"}}
+
+	testCases := []struct {
+		name       string
+		password   string
+		mLSP       mockLinkSvcParams
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Open success",
+			mLSP:       mockLinkSvcParams{method: "Open", arguments: []any{"token", ""}, returnArguments: []any{n, nil}},
+			wantStatus: http.StatusOK,
+			wantBody:   mustEncode(t, api.PublicNote{ID: n.ID.String(), Title: "title", Content: "content", Format: "markdown"}),
+		},
+		{
+			name:       "Open passes the password",
+			password:   "secret",
+			mLSP:       mockLinkSvcParams{method: "Open", arguments: []any{"token", "secret"}, returnArguments: []any{n, nil}},
+			wantStatus: http.StatusOK,
+			wantBody:   mustEncode(t, api.PublicNote{ID: n.ID.String(), Title: "title", Content: "content", Format: "markdown"}),
+		},
+		{
+			name:       "Open without password",
+			mLSP:       mockLinkSvcParams{method: "Open", arguments: []any{"token", ""}, returnArguments: []any{note.Note{}, sharelink.ErrPasswordRequired}},
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   fmt.Sprintln("password required"),
+		},
+		{
+			name:       "Open expired link",
+			mLSP:       mockLinkSvcParams{method: "Open", arguments: []any{"token", ""}, returnArguments: []any{note.Note{}, sharelink.ErrLinkExpired}},
+			wantStatus: http.StatusNotFound,
+			wantBody:   fmt.Sprintln(""),
+		},
+		{
+			name:       "Open service error",
+			mLSP:       mockLinkSvcParams{method: "Open", arguments: []any{"token", ""}, returnArguments: []any{note.Note{}, errors.New("error linkSvc.Open")}},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   fmt.Sprintln(""),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mLinkSvc.Setup(tc.mLSP)
+			req := httptest.NewRequest(http.MethodGet, "/s/token", nil)
+			req.SetPathValue("token", "token")
+			if tc.password != "" {
+				req.Header.Set(linkgrp.PasswordHeader, tc.password)
+			}
+			rr := httptest.NewRecorder()
+
+			hdl.Open(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, tc.wantBody, rr.Body.String())
+			// the owner stays anonymous
+			assert.NotContains(t, rr.Body.String(), n.UserID.String())
+			mLinkSvc.AssertCalled(t, tc.mLSP.method, tc.mLSP.arguments...)
+		})
+	}
+}
+
+func Test_Revoke(t *testing.T) {
+	mLinkSvc := &mockLinkSvc{}
+	hdl := linkgrp.NewHandlers(mLinkSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	n := note.Note{ID: uuid.UUID{1}, Title: note.NewTitle("title"), Content: note.NewContent("content"), UserID: uuid.UUID{2}}
+	linkID := uuid.UUID{3}
+
+	testCases := []struct {
+		name       string
+		mLSP       mockLinkSvcParams
+		wantStatus int
+	}{
+		{
+			name:       "Revoke success",
+			mLSP:       mockLinkSvcParams{method: "Revoke", arguments: []any{n.ID, linkID}, returnArguments: []any{nil}},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "Revoke link of another note",
+			mLSP:       mockLinkSvcParams{method: "Revoke", arguments: []any{n.ID, linkID}, returnArguments: []any{sharelink.ErrLinkNotFound}},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mLinkSvc.Setup(tc.mLSP)
+			req := withNote(httptest.NewRequest(http.MethodDelete, "/notes/"+n.ID.String()+"/links/"+linkID.String(), nil), n)
+			req.SetPathValue("link_id", linkID.String())
+			rr := httptest.NewRecorder()
+
+			hdl.Revoke(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			mLinkSvc.AssertCalled(t, tc.mLSP.method, tc.mLSP.arguments...)
+		})
+	}
+}
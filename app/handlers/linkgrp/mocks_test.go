@@ -0,0 +1,50 @@
+package linkgrp_test
+
+import (
+	"context"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/core/sharelink"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockLinkSvc struct {
+	mock.Mock
+}
+
+type mockLinkSvcParams struct {
+	method          string
+	arguments       []any
+	returnArguments []any
+}
+
+func (mLS *mockLinkSvc) Setup(p mockLinkSvcParams) {
+	mLS.Reset()
+	mLS.On(p.method, p.arguments...).Return(p.returnArguments...)
+}
+
+func (mLS *mockLinkSvc) Reset() {
+	mLS.Calls = []mock.Call{}
+	mLS.ExpectedCalls = []*mock.Call{}
+}
+
+func (mLS *mockLinkSvc) Create(ctx context.Context, n note.Note, userID uuid.UUID, nl sharelink.NewLink) (sharelink.Link, string, error) {
+	args := mLS.Called(n, userID, nl)
+	return args.Get(0).(sharelink.Link), args.String(1), args.Error(2)
+}
+
+func (mLS *mockLinkSvc) Open(ctx context.Context, token, password string) (note.Note, error) {
+	args := mLS.Called(token, password)
+	return args.Get(0).(note.Note), args.Error(1)
+}
+
+func (mLS *mockLinkSvc) QueryByNoteID(ctx context.Context, noteID uuid.UUID) ([]sharelink.Link, error) {
+	args := mLS.Called(noteID)
+	return args.Get(0).([]sharelink.Link), args.Error(1)
+}
+
+func (mLS *mockLinkSvc) Revoke(ctx context.Context, noteID, linkID uuid.UUID) error {
+	args := mLS.Called(noteID, linkID)
+	return args.Error(0)
+}
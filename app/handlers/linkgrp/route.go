@@ -0,0 +1,32 @@
+package linkgrp
+
+import (
+	"net/http"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/core/sharelink"
+	"github.com/Keisn1/note-taking-app/domain/web/auth"
+	"github.com/Keisn1/note-taking-app/domain/web/mid"
+	"github.com/Keisn1/note-taking-app/foundation/web"
+)
+
+type Config struct {
+	Auth    auth.Auth
+	NoteSvc note.Service
+	LinkSvc sharelink.Service
+}
+
+func Routes(app *web.App, cfg Config) {
+	authen := mid.Authenticate(cfg.Auth)
+	isOwner := mid.AuthorizeNote(cfg.NoteSvc, note.PermissionOwner)
+	read := mid.Authorize(auth.RuleNotesRead)
+	write := mid.Authorize(auth.RuleNotesWrite)
+
+	hdl := NewHandlers(cfg.LinkSvc)
+	app.Handle("GET /notes/{note_id}/links", authen(read(isOwner(http.HandlerFunc(hdl.QueryByNoteID)))))
+	app.Handle("POST /notes/{note_id}/links", authen(write(isOwner(http.HandlerFunc(hdl.Create)))))
+	app.Handle("DELETE /notes/{note_id}/links/{link_id}", authen(write(isOwner(http.HandlerFunc(hdl.Revoke)))))
+
+	// anyone holding the token may read the note, so there's no authentication
+	app.Handle("GET /s/{token}", http.HandlerFunc(hdl.Open))
+}
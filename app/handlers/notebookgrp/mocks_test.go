@@ -0,0 +1,70 @@
+package notebookgrp_test
+
+import (
+	"context"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/core/notebook"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockNotebookSvc struct {
+	mock.Mock
+}
+
+type mockNotebookSvcParams struct {
+	method          string
+	arguments       []any
+	returnArguments []any
+}
+
+func (mNS *mockNotebookSvc) Setup(p mockNotebookSvcParams) {
+	mNS.Reset()
+	mNS.On(p.method, p.arguments...).Return(p.returnArguments...)
+}
+
+func (mNS *mockNotebookSvc) Reset() {
+	mNS.Calls = []mock.Call{}
+	mNS.ExpectedCalls = []*mock.Call{}
+}
+
+func (mNS *mockNotebookSvc) Create(ctx context.Context, nn notebook.NewNotebook) (notebook.Notebook, error) {
+	args := mNS.Called(nn)
+	return args.Get(0).(notebook.Notebook), args.Error(1)
+}
+
+func (mNS *mockNotebookSvc) QueryByID(ctx context.Context, notebookID uuid.UUID) (notebook.Notebook, error) {
+	args := mNS.Called(notebookID)
+	return args.Get(0).(notebook.Notebook), args.Error(1)
+}
+
+func (mNS *mockNotebookSvc) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]notebook.Notebook, error) {
+	args := mNS.Called(userID)
+	return args.Get(0).([]notebook.Notebook), args.Error(1)
+}
+
+func (mNS *mockNotebookSvc) QueryContents(ctx context.Context, nb notebook.Notebook) (notebook.Contents, error) {
+	args := mNS.Called(nb)
+	return args.Get(0).(notebook.Contents), args.Error(1)
+}
+
+func (mNS *mockNotebookSvc) Rename(ctx context.Context, nb notebook.Notebook, name string) (notebook.Notebook, error) {
+	args := mNS.Called(nb, name)
+	return args.Get(0).(notebook.Notebook), args.Error(1)
+}
+
+func (mNS *mockNotebookSvc) Move(ctx context.Context, nb notebook.Notebook, parentID *uuid.UUID) (notebook.Notebook, error) {
+	args := mNS.Called(nb, parentID)
+	return args.Get(0).(notebook.Notebook), args.Error(1)
+}
+
+func (mNS *mockNotebookSvc) Delete(ctx context.Context, nb notebook.Notebook, mode notebook.DeleteMode) error {
+	args := mNS.Called(nb, mode)
+	return args.Error(0)
+}
+
+func (mNS *mockNotebookSvc) MoveNote(ctx context.Context, n note.Note, notebookID *uuid.UUID) error {
+	args := mNS.Called(n, notebookID)
+	return args.Error(0)
+}
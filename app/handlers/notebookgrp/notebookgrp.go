@@ -0,0 +1,254 @@
+package notebookgrp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/Keisn1/note-taking-app/app/api"
+	"github.com/Keisn1/note-taking-app/domain/core/notebook"
+	"github.com/Keisn1/note-taking-app/domain/web/mid"
+	"github.com/google/uuid"
+)
+
+type Handlers struct {
+	notebookSvc notebook.Service
+}
+
+func NewHandlers(nbs notebook.Service) Handlers {
+	return Handlers{notebookSvc: nbs}
+}
+
+// Query lists all notebooks of the caller. Nested notebooks are listed as
+// well, their place in the hierarchy is given by parent_id.
+func (hdl *Handlers) Query(w http.ResponseWriter, r *http.Request) {
+	userID := mid.GetUserID(r.Context())
+	logMsg := fmt.Sprintf("Query: userID %v", userID)
+
+	notebooks, err := hdl.notebookSvc.QueryByUserID(r.Context(), userID)
+	if err != nil {
+		handleServiceError(w, logMsg, err)
+		return
+	}
+
+	respond(w, http.StatusOK, toAPINotebooks(notebooks), logMsg)
+}
+
+func (hdl *Handlers) Create(w http.ResponseWriter, r *http.Request) {
+	userID := mid.GetUserID(r.Context())
+	logMsg := fmt.Sprintf("Create: userID %v", userID)
+
+	var np api.NotebookPost
+	if err := json.NewDecoder(r.Body).Decode(&np); err != nil {
+		handleError(w, "", http.StatusBadRequest, logMsg, "error", err)
+		return
+	}
+
+	parentID, err := parseOptionalID(np.ParentID)
+	if err != nil {
+		handleError(w, "invalid parent_id", http.StatusBadRequest, logMsg, "error", err)
+		return
+	}
+
+	nb, err := hdl.notebookSvc.Create(r.Context(), notebook.NewNotebook{UserID: userID, ParentID: parentID, Name: np.Name})
+	if err != nil {
+		handleServiceError(w, logMsg, err)
+		return
+	}
+
+	respond(w, http.StatusCreated, toAPINotebook(nb), fmt.Sprintf("Create: userID %v notebookID %v", userID, nb.ID))
+}
+
+// GetContents returns the notebook set by mid.AuthorizeNotebook together with
+// the notebooks and notes directly inside it.
+func (hdl *Handlers) GetContents(w http.ResponseWriter, r *http.Request) {
+	nb := mid.GetNotebook(r.Context())
+	logMsg := fmt.Sprintf("GetContents: notebookID %v", nb.ID)
+
+	contents, err := hdl.notebookSvc.QueryContents(r.Context(), nb)
+	if err != nil {
+		handleServiceError(w, logMsg, err)
+		return
+	}
+
+	ret := api.NotebookContents{
+		Notebook:  toAPINotebook(nb),
+		Notebooks: toAPINotebooks(contents.Notebooks),
+		Notes:     make([]api.Note, 0, len(contents.Notes)),
+	}
+	for _, n := range contents.Notes {
+		ret.Notes = append(ret.Notes, api.FromNote(n))
+	}
+
+	respond(w, http.StatusOK, ret, logMsg)
+}
+
+func (hdl *Handlers) Rename(w http.ResponseWriter, r *http.Request) {
+	nb := mid.GetNotebook(r.Context())
+	logMsg := fmt.Sprintf("Rename: notebookID %v", nb.ID)
+
+	var np api.NotebookPatch
+	if err := json.NewDecoder(r.Body).Decode(&np); err != nil {
+		handleError(w, "", http.StatusBadRequest, logMsg, "error", err)
+		return
+	}
+
+	nb, err := hdl.notebookSvc.Rename(r.Context(), nb, np.Name)
+	if err != nil {
+		handleServiceError(w, logMsg, err)
+		return
+	}
+
+	respond(w, http.StatusOK, toAPINotebook(nb), logMsg)
+}
+
+// Move puts the notebook set by mid.AuthorizeNotebook into another notebook
+// of the caller, or to the top level.
+func (hdl *Handlers) Move(w http.ResponseWriter, r *http.Request) {
+	nb := mid.GetNotebook(r.Context())
+	logMsg := fmt.Sprintf("Move: notebookID %v", nb.ID)
+
+	var nm api.NotebookMove
+	if err := json.NewDecoder(r.Body).Decode(&nm); err != nil {
+		handleError(w, "", http.StatusBadRequest, logMsg, "error", err)
+		return
+	}
+
+	parentID, err := parseOptionalID(nm.ParentID)
+	if err != nil {
+		handleError(w, "invalid parent_id", http.StatusBadRequest, logMsg, "error", err)
+		return
+	}
+
+	nb, err = hdl.notebookSvc.Move(r.Context(), nb, parentID)
+	if err != nil {
+		handleServiceError(w, logMsg, err)
+		return
+	}
+
+	respond(w, http.StatusOK, toAPINotebook(nb), logMsg)
+}
+
+// Delete deletes the notebook set by mid.AuthorizeNotebook. The mode query
+// parameter tells what happens to its contents: restrict (the default) only
+// deletes empty notebooks, cascade deletes nested notebooks and moves the
+// notes to the trash and reparent moves the contents to the parent notebook.
+func (hdl *Handlers) Delete(w http.ResponseWriter, r *http.Request) {
+	nb := mid.GetNotebook(r.Context())
+	logMsg := fmt.Sprintf("Delete: notebookID %v", nb.ID)
+
+	mode := notebook.DeleteRestrict
+	if m := r.URL.Query().Get("mode"); m != "" {
+		var err error
+		if mode, err = notebook.ParseDeleteMode(m); err != nil {
+			handleError(w, "invalid mode", http.StatusBadRequest, logMsg, "error", err)
+			return
+		}
+	}
+
+	if err := hdl.notebookSvc.Delete(r.Context(), nb, mode); err != nil {
+		handleServiceError(w, logMsg, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	slog.Info("Success: " + logMsg)
+}
+
+// MoveNote puts the note set by mid.AuthorizeNote into one of the notebooks
+// of its owner, or takes it out of its notebook.
+func (hdl *Handlers) MoveNote(w http.ResponseWriter, r *http.Request) {
+	n := mid.GetNote(r.Context())
+	logMsg := fmt.Sprintf("MoveNote: noteID %v", n.ID)
+
+	var nm api.NoteMove
+	if err := json.NewDecoder(r.Body).Decode(&nm); err != nil {
+		handleError(w, "", http.StatusBadRequest, logMsg, "error", err)
+		return
+	}
+
+	notebookID, err := parseOptionalID(nm.NotebookID)
+	if err != nil {
+		handleError(w, "invalid notebook_id", http.StatusBadRequest, logMsg, "error", err)
+		return
+	}
+
+	if err := hdl.notebookSvc.MoveNote(r.Context(), n, notebookID); err != nil {
+		handleServiceError(w, logMsg, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	slog.Info("Success: " + logMsg)
+}
+
+// handleServiceError maps the errors of notebook.Service to status codes.
+func handleServiceError(w http.ResponseWriter, logMsg string, err error) {
+	switch {
+	case errors.Is(err, notebook.ErrInvalidName):
+		handleError(w, "invalid name", http.StatusBadRequest, logMsg, "error", err)
+	case errors.Is(err, notebook.ErrNotebookNotFound):
+		handleError(w, "notebook not found", http.StatusNotFound, logMsg, "error", err)
+	case errors.Is(err, notebook.ErrNotebookCycle):
+		handleError(w, "notebook can't be moved into itself", http.StatusConflict, logMsg, "error", err)
+	case errors.Is(err, notebook.ErrNotebookNotEmpty):
+		handleError(w, "notebook is not empty", http.StatusConflict, logMsg, "error", err)
+	default:
+		handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
+	}
+}
+
+func respond(w http.ResponseWriter, status int, data any, logMsg string) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+	slog.Info("Success: " + logMsg)
+}
+
+func handleError(w http.ResponseWriter, errMsg string, status int, logMsg string, args ...any) {
+	http.Error(w, errMsg, status)
+	slog.Error(logMsg, args...)
+}
+
+// parseOptionalID parses id unless it is nil.
+func parseOptionalID(id *string) (*uuid.UUID, error) {
+	if id == nil {
+		return nil, nil
+	}
+	parsed, err := uuid.Parse(*id)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+func toAPINotebook(nb notebook.Notebook) api.Notebook {
+	ret := api.Notebook{
+		ID:        nb.ID.String(),
+		UserID:    nb.UserID.String(),
+		Name:      nb.Name,
+		CreatedAt: nb.CreatedAt,
+		UpdatedAt: nb.UpdatedAt,
+	}
+	if nb.ParentID != nil {
+		parentID := nb.ParentID.String()
+		ret.ParentID = &parentID
+	}
+	return ret
+}
+
+func toAPINotebooks(notebooks []notebook.Notebook) []api.Notebook {
+	ret := make([]api.Notebook, 0, len(notebooks))
+	for _, nb := range notebooks {
+		ret = append(ret, toAPINotebook(nb))
+	}
+	return ret
+}
@@ -0,0 +1,310 @@
+package notebookgrp_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Keisn1/note-taking-app/app/api"
+	"github.com/Keisn1/note-taking-app/app/handlers/notebookgrp"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/core/notebook"
+	"github.com/Keisn1/note-taking-app/foundation"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustEncode(t *testing.T, a any) string {
+	data, err := json.Marshal(a)
+	assert.NoError(t, err)
+	return string(data)
+}
+
+func withNotebook(req *http.Request, nb notebook.Notebook) *http.Request {
+	ctx := context.WithValue(req.Context(), foundation.NotebookKey, nb)
+	ctx = context.WithValue(ctx, foundation.UserIDKey, nb.UserID)
+	return req.WithContext(ctx)
+}
+
+func Test_Create(t *testing.T) {
+	mNotebookSvc := &mockNotebookSvc{}
+	hdl := notebookgrp.NewHandlers(mNotebookSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	userID, parentID := uuid.UUID{1}, uuid.UUID{2}
+	parent := parentID.String()
+	nb := notebook.Notebook{ID: uuid.UUID{3}, UserID: userID, ParentID: &parentID, Name: "projects"}
+
+	testCases := []struct {
+		name       string
+		body       string
+		mNSP       *mockNotebookSvcParams
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "Create success",
+			body: mustEncode(t, api.NotebookPost{Name: "projects", ParentID: &parent}),
+			mNSP: &mockNotebookSvcParams{
+				method:          "Create",
+				arguments:       []any{notebook.NewNotebook{UserID: userID, ParentID: &parentID, Name: "projects"}},
+				returnArguments: []any{nb, nil},
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   mustEncode(t, api.Notebook{ID: nb.ID.String(), UserID: userID.String(), ParentID: &parent, Name: "projects"}),
+		},
+		{
+			name: "Create in a missing parent",
+			body: mustEncode(t, api.NotebookPost{Name: "projects", ParentID: &parent}),
+			mNSP: &mockNotebookSvcParams{
+				method:          "Create",
+				arguments:       []any{notebook.NewNotebook{UserID: userID, ParentID: &parentID, Name: "projects"}},
+				returnArguments: []any{notebook.Notebook{}, notebook.ErrNotebookNotFound},
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   fmt.Sprintln("notebook not found"),
+		},
+		{
+			name: "Create with invalid name",
+			body: mustEncode(t, api.NotebookPost{Name: ""}),
+			mNSP: &mockNotebookSvcParams{
+				method:          "Create",
+				arguments:       []any{notebook.NewNotebook{UserID: userID, Name: ""}},
+				returnArguments: []any{notebook.Notebook{}, notebook.ErrInvalidName},
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   fmt.Sprintln("invalid name"),
+		},
+		{name: "Create with invalid parent_id", body: `{"name": "projects", "parent_id": "abc"}`, wantStatus: http.StatusBadRequest, wantBody: fmt.Sprintln("invalid parent_id")},
+		{name: "Create with invalid body", body: `invalid body`, wantStatus: http.StatusBadRequest, wantBody: fmt.Sprintln("")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mNotebookSvc.Reset()
+			if tc.mNSP != nil {
+				mNotebookSvc.Setup(*tc.mNSP)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/notebooks", strings.NewReader(tc.body))
+			req = req.WithContext(context.WithValue(req.Context(), foundation.UserIDKey, userID))
+			rr := httptest.NewRecorder()
+
+			hdl.Create(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, tc.wantBody, rr.Body.String())
+			if tc.mNSP != nil {
+				mNotebookSvc.AssertCalled(t, tc.mNSP.method, tc.mNSP.arguments...)
+			} else {
+				mNotebookSvc.AssertNotCalled(t, "Create")
+			}
+		})
+	}
+}
+
+func Test_GetContents(t *testing.T) {
+	mNotebookSvc := &mockNotebookSvc{}
+	hdl := notebookgrp.NewHandlers(mNotebookSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	userID := uuid.UUID{1}
+	nb := notebook.Notebook{ID: uuid.UUID{2}, UserID: userID, Name: "work"}
+	child := notebook.Notebook{ID: uuid.UUID{3}, UserID: userID, ParentID: &nb.ID, Name: "projects"}
+	n := note.Note{ID: uuid.UUID{4}, Title: note.NewTitle("title"), Content: note.NewContent("content"), UserID: userID}
+
+	mNotebookSvc.Setup(mockNotebookSvcParams{
+		method:          "QueryContents",
+		arguments:       []any{nb},
+		returnArguments: []any{notebook.Contents{Notebooks: []notebook.Notebook{child}, Notes: []note.Note{n}}, nil},
+	})
+	req := withNotebook(httptest.NewRequest(http.MethodGet, "/notebooks/"+nb.ID.String(), nil), nb)
+	rr := httptest.NewRecorder()
+
+	hdl.GetContents(rr, req)
+
+	parent := nb.ID.String()
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, mustEncode(t, api.NotebookContents{
+		Notebook:  api.Notebook{ID: nb.ID.String(), UserID: userID.String(), Name: "work"},
+		Notebooks: []api.Notebook{{ID: child.ID.String(), UserID: userID.String(), ParentID: &parent, Name: "projects"}},
+		Notes:     []api.Note{{ID: n.ID.String(), Title: "title", Content: "content", UserID: userID.String()}},
+	}), rr.Body.String())
+}
+
+func Test_Move(t *testing.T) {
+	mNotebookSvc := &mockNotebookSvc{}
+	hdl := notebookgrp.NewHandlers(mNotebookSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	nb := notebook.Notebook{ID: uuid.UUID{2}, UserID: uuid.UUID{1}, Name: "work"}
+	parentID := uuid.UUID{3}
+
+	testCases := []struct {
+		name       string
+		body       string
+		mNSP       mockNotebookSvcParams
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Move to the top level",
+			body:       `{"parent_id": null}`,
+			mNSP:       mockNotebookSvcParams{method: "Move", arguments: []any{nb, (*uuid.UUID)(nil)}, returnArguments: []any{nb, nil}},
+			wantStatus: http.StatusOK,
+			wantBody:   mustEncode(t, api.Notebook{ID: nb.ID.String(), UserID: nb.UserID.String(), Name: "work"}),
+		},
+		{
+			name:       "Move into a nested notebook",
+			body:       fmt.Sprintf(`{"parent_id": %q}`, parentID),
+			mNSP:       mockNotebookSvcParams{method: "Move", arguments: []any{nb, &parentID}, returnArguments: []any{notebook.Notebook{}, notebook.ErrNotebookCycle}},
+			wantStatus: http.StatusConflict,
+			wantBody:   fmt.Sprintln("notebook can't be moved into itself"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mNotebookSvc.Setup(tc.mNSP)
+			req := withNotebook(httptest.NewRequest(http.MethodPost, "/notebooks/"+nb.ID.String()+"/move", strings.NewReader(tc.body)), nb)
+			rr := httptest.NewRecorder()
+
+			hdl.Move(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, tc.wantBody, rr.Body.String())
+			mNotebookSvc.AssertCalled(t, tc.mNSP.method, tc.mNSP.arguments...)
+		})
+	}
+}
+
+func Test_Delete(t *testing.T) {
+	mNotebookSvc := &mockNotebookSvc{}
+	hdl := notebookgrp.NewHandlers(mNotebookSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	nb := notebook.Notebook{ID: uuid.UUID{2}, UserID: uuid.UUID{1}, Name: "work"}
+
+	testCases := []struct {
+		name        string
+		query       string
+		mNSP        *mockNotebookSvcParams
+		wantStatus  int
+		wantBody    string
+		wantLogging []string
+	}{
+		{
+			name:        "Delete restricts by default",
+			mNSP:        &mockNotebookSvcParams{method: "Delete", arguments: []any{nb, notebook.DeleteRestrict}, returnArguments: []any{notebook.ErrNotebookNotEmpty}},
+			wantStatus:  http.StatusConflict,
+			wantBody:    fmt.Sprintln("notebook is not empty"),
+			wantLogging: []string{"ERROR", notebook.ErrNotebookNotEmpty.Error()},
+		},
+		{
+			name:        "Delete cascading",
+			query:       "?mode=cascade",
+			mNSP:        &mockNotebookSvcParams{method: "Delete", arguments: []any{nb, notebook.DeleteCascade}, returnArguments: []any{nil}},
+			wantStatus:  http.StatusNoContent,
+			wantLogging: []string{"INFO", fmt.Sprintf("Success: Delete: notebookID %v", nb.ID)},
+		},
+		{
+			name:        "Delete service error",
+			query:       "?mode=reparent",
+			mNSP:        &mockNotebookSvcParams{method: "Delete", arguments: []any{nb, notebook.DeleteReparent}, returnArguments: []any{errors.New("error notebookSvc.Delete")}},
+			wantStatus:  http.StatusInternalServerError,
+			wantBody:    fmt.Sprintln(""),
+			wantLogging: []string{"ERROR", "error notebookSvc.Delete"},
+		},
+		{name: "Delete with invalid mode", query: "?mode=drop", wantStatus: http.StatusBadRequest, wantBody: fmt.Sprintln("invalid mode")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logBuf.Reset()
+			mNotebookSvc.Reset()
+			if tc.mNSP != nil {
+				mNotebookSvc.Setup(*tc.mNSP)
+			}
+			req := withNotebook(httptest.NewRequest(http.MethodDelete, "/notebooks/"+nb.ID.String()+tc.query, nil), nb)
+			rr := httptest.NewRecorder()
+
+			hdl.Delete(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, tc.wantBody, rr.Body.String())
+			if tc.mNSP != nil {
+				mNotebookSvc.AssertCalled(t, tc.mNSP.method, tc.mNSP.arguments...)
+			} else {
+				mNotebookSvc.AssertNotCalled(t, "Delete")
+			}
+			for _, logMsg := range tc.wantLogging {
+				assert.Contains(t, logBuf.String(), logMsg)
+			}
+		})
+	}
+}
+
+func Test_MoveNote(t *testing.T) {
+	mNotebookSvc := &mockNotebookSvc{}
+	hdl := notebookgrp.NewHandlers(mNotebookSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	n := note.Note{ID: uuid.UUID{1}, Title: note.NewTitle("title"), Content: note.NewContent("content"), UserID: uuid.UUID{2}}
+	notebookID := uuid.UUID{3}
+
+	testCases := []struct {
+		name       string
+		body       string
+		mNSP       *mockNotebookSvcParams
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "MoveNote success",
+			body:       fmt.Sprintf(`{"notebook_id": %q}`, notebookID),
+			mNSP:       &mockNotebookSvcParams{method: "MoveNote", arguments: []any{n, &notebookID}, returnArguments: []any{nil}},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "MoveNote into a notebook of someone else",
+			body:       fmt.Sprintf(`{"notebook_id": %q}`, notebookID),
+			mNSP:       &mockNotebookSvcParams{method: "MoveNote", arguments: []any{n, &notebookID}, returnArguments: []any{notebook.ErrNotebookNotFound}},
+			wantStatus: http.StatusNotFound,
+			wantBody:   fmt.Sprintln("notebook not found"),
+		},
+		{name: "MoveNote with invalid notebook_id", body: `{"notebook_id": "abc"}`, wantStatus: http.StatusBadRequest, wantBody: fmt.Sprintln("invalid notebook_id")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mNotebookSvc.Reset()
+			if tc.mNSP != nil {
+				mNotebookSvc.Setup(*tc.mNSP)
+			}
+			req := httptest.NewRequest(http.MethodPut, "/notes/"+n.ID.String()+"/notebook", strings.NewReader(tc.body))
+			req = req.WithContext(context.WithValue(req.Context(), foundation.NoteKey, n))
+			rr := httptest.NewRecorder()
+
+			hdl.MoveNote(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, tc.wantBody, rr.Body.String())
+			if tc.mNSP != nil {
+				mNotebookSvc.AssertCalled(t, tc.mNSP.method, tc.mNSP.arguments...)
+			} else {
+				mNotebookSvc.AssertNotCalled(t, "MoveNote")
+			}
+		})
+	}
+}
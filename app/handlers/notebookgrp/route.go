@@ -0,0 +1,35 @@
+package notebookgrp
+
+import (
+	"net/http"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/core/notebook"
+	"github.com/Keisn1/note-taking-app/domain/web/auth"
+	"github.com/Keisn1/note-taking-app/domain/web/mid"
+	"github.com/Keisn1/note-taking-app/foundation/web"
+)
+
+type Config struct {
+	Auth        auth.Auth
+	NoteSvc     note.Service
+	NotebookSvc notebook.Service
+}
+
+func Routes(app *web.App, cfg Config) {
+	authen := mid.Authenticate(cfg.Auth)
+	isOwner := mid.AuthorizeNotebook(cfg.NotebookSvc)
+	isNoteOwner := mid.AuthorizeNote(cfg.NoteSvc, note.PermissionOwner)
+	read := mid.Authorize(auth.RuleNotesRead)
+	write := mid.Authorize(auth.RuleNotesWrite)
+
+	hdl := NewHandlers(cfg.NotebookSvc)
+	app.Handle("GET /notebooks", authen(read(http.HandlerFunc(hdl.Query))))
+	app.Handle("POST /notebooks", authen(write(http.HandlerFunc(hdl.Create))))
+	app.Handle("GET /notebooks/{notebook_id}", authen(read(isOwner(http.HandlerFunc(hdl.GetContents)))))
+	app.Handle("PATCH /notebooks/{notebook_id}", authen(write(isOwner(http.HandlerFunc(hdl.Rename)))))
+	app.Handle("POST /notebooks/{notebook_id}/move", authen(write(isOwner(http.HandlerFunc(hdl.Move)))))
+	app.Handle("DELETE /notebooks/{notebook_id}", authen(write(isOwner(http.HandlerFunc(hdl.Delete)))))
+
+	app.Handle("PUT /notes/{note_id}/notebook", authen(write(isNoteOwner(http.HandlerFunc(hdl.MoveNote)))))
+}
@@ -0,0 +1,125 @@
+package notesgrp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/Keisn1/note-taking-app/app/api"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/web/mid"
+	"github.com/google/uuid"
+)
+
+// Batch runs the create, update and delete operations of the request body in
+// one transaction. It responds with the result of each operation, with 409 if
+// an atomic batch was rolled back.
+func (hdl *Handlers) Batch(w http.ResponseWriter, r *http.Request) {
+	userID := mid.GetUserID(r.Context())
+	logMsg := fmt.Sprintf("Batch: userID %v", userID)
+
+	var bp api.BatchPost
+	if err := json.NewDecoder(r.Body).Decode(&bp); err != nil {
+		handleError(w, "", http.StatusBadRequest, "Batch: invalid body", "error", err)
+		return
+	}
+	if len(bp.Operations) > note.MaxBatchOps {
+		handleError(w, note.ErrTooManyBatchOps.Error(), http.StatusRequestEntityTooLarge, logMsg, "error", note.ErrTooManyBatchOps)
+		return
+	}
+
+	mode := note.BatchAtomic
+	if bp.Mode != "" {
+		var err error
+		if mode, err = note.ParseBatchMode(bp.Mode); err != nil {
+			handleError(w, "invalid mode", http.StatusBadRequest, logMsg, "error", err)
+			return
+		}
+	}
+
+	ops := make([]note.BatchOp, 0, len(bp.Operations))
+	for i, o := range bp.Operations {
+		op, err := toBatchOp(o)
+		if err != nil {
+			handleError(w, fmt.Sprintf("invalid operation %d", i), http.StatusBadRequest, logMsg, "error", err)
+			return
+		}
+		ops = append(ops, op)
+	}
+
+	results, err := hdl.notesSvc.Batch(r.Context(), userID, ops, mode)
+	if err != nil {
+		handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
+		return
+	}
+
+	ret := api.BatchResult{Results: make([]api.BatchOperationResult, 0, len(results))}
+	status := http.StatusOK
+	for _, res := range results {
+		item := api.BatchOperationResult{Op: string(res.Kind), Status: "applied"}
+		switch {
+		case res.Err == nil:
+			n := api.FromNote(res.Note)
+			item.Note = &n
+			ret.Applied++
+		case errors.Is(res.Err, note.ErrBatchAborted):
+			item.Status = "aborted"
+			status = http.StatusConflict
+		default:
+			item.Status, item.Error = "failed", batchError(res.Err)
+			ret.Failed++
+			slog.Info(logMsg, "op", res.Kind, "error", res.Err)
+		}
+		ret.Results = append(ret.Results, item)
+	}
+
+	respond(w, status, ret, logMsg)
+}
+
+func toBatchOp(o api.BatchOperation) (note.BatchOp, error) {
+	kind, err := note.ParseBatchOpKind(o.Op)
+	if err != nil {
+		return note.BatchOp{}, err
+	}
+	op := note.BatchOp{Kind: kind, Version: o.Version}
+
+	if kind != note.BatchCreate {
+		if op.NoteID, err = uuid.Parse(o.ID); err != nil {
+			return note.BatchOp{}, err
+		}
+	}
+
+	// like Create, a new note gets an empty title and content if left out
+	if o.Title != nil || kind == note.BatchCreate {
+		op.Note.Title = note.NewTitle(deref(o.Title))
+	}
+	if o.Content != nil || kind == note.BatchCreate {
+		op.Note.Content = note.NewContent(deref(o.Content))
+	}
+	if o.Format != nil {
+		if op.Note.Format, err = note.ParseFormat(*o.Format); err != nil {
+			return note.BatchOp{}, err
+		}
+	}
+	return op, nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// batchError tells the client why an operation failed without the internals
+// of the error.
+func batchError(err error) string {
+	for _, known := range []error{note.ErrNoteNotFound, note.ErrVersionConflict} {
+		if errors.Is(err, known) {
+			return known.Error()
+		}
+	}
+	return "the operation failed"
+}
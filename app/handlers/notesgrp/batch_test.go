@@ -0,0 +1,146 @@
+package notesgrp_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Keisn1/note-taking-app/app/api"
+	"github.com/Keisn1/note-taking-app/app/handlers/notesgrp"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Batch(t *testing.T) {
+	mNotesSvc := &mockNotesSvc{}
+	hdl := notesgrp.NewHandlers(mNotesSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	userID := uuid.UUID{1}
+	batch := func(body string) *httptest.ResponseRecorder {
+		req := setupRequest(t, http.MethodPost, "/notes/batch", userID)
+		req.Body = io.NopCloser(strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		hdl.Batch(rr, req)
+		return rr
+	}
+
+	body := `{"operations": [
+		{"op": "create", "title": "new"},
+		{"op": "update", "id": "` + uuid.UUID{2}.String() + `", "version": 3, "content": "changed", "format": "markdown"},
+		{"op": "delete", "id": "` + uuid.UUID{3}.String() + `"}
+	]}`
+	ops := []note.BatchOp{
+		{Kind: note.BatchCreate, Note: note.UpdateNote{Title: note.NewTitle("new"), Content: note.NewContent("")}},
+		{Kind: note.BatchUpdate, NoteID: uuid.UUID{2}, Version: 3, Note: note.UpdateNote{Content: note.NewContent("changed"), Format: note.FormatMarkdown}},
+		{Kind: note.BatchDelete, NoteID: uuid.UUID{3}},
+	}
+	created := note.Note{ID: uuid.UUID{4}, Title: note.NewTitle("new"), Content: note.NewContent(""), Format: note.FormatPlain, UserID: userID, Version: 1}
+	updated := note.Note{ID: uuid.UUID{2}, Title: note.NewTitle("old"), Content: note.NewContent("changed"), Format: note.FormatMarkdown, UserID: userID, Version: 4}
+	apiCreated, apiUpdated := api.FromNote(created), api.FromNote(updated)
+
+	testCases := []struct {
+		name       string
+		mode       note.BatchMode
+		body       string
+		results    []note.BatchResult
+		wantStatus int
+		wantBody   api.BatchResult
+	}{
+		{
+			name: "All operations applied",
+			mode: note.BatchAtomic,
+			body: body,
+			results: []note.BatchResult{
+				{Kind: note.BatchCreate, Note: created},
+				{Kind: note.BatchUpdate, Note: updated},
+				{Kind: note.BatchDelete, Note: note.Note{ID: uuid.UUID{3}}},
+			},
+			wantStatus: http.StatusOK,
+			wantBody: api.BatchResult{Applied: 3, Results: []api.BatchOperationResult{
+				{Op: "create", Status: "applied", Note: &apiCreated},
+				{Op: "update", Status: "applied", Note: &apiUpdated},
+				{Op: "delete", Status: "applied", Note: &api.Note{ID: uuid.UUID{3}.String(), UserID: uuid.Nil.String()}},
+			}},
+		},
+		{
+			name: "Atomic batch rolled back",
+			mode: note.BatchAtomic,
+			body: body,
+			results: []note.BatchResult{
+				{Kind: note.BatchCreate, Err: note.ErrBatchAborted},
+				{Kind: note.BatchUpdate, Err: note.ErrVersionConflict},
+				{Kind: note.BatchDelete, Err: note.ErrBatchAborted},
+			},
+			wantStatus: http.StatusConflict,
+			wantBody: api.BatchResult{Failed: 1, Results: []api.BatchOperationResult{
+				{Op: "create", Status: "aborted"},
+				{Op: "update", Status: "failed", Error: note.ErrVersionConflict.Error()},
+				{Op: "delete", Status: "aborted"},
+			}},
+		},
+		{
+			name: "Best effort batch partly applied",
+			mode: note.BatchBestEffort,
+			body: strings.Replace(body, `{"operations"`, `{"mode": "best_effort", "operations"`, 1),
+			results: []note.BatchResult{
+				{Kind: note.BatchCreate, Note: created},
+				{Kind: note.BatchUpdate, Note: updated},
+				{Kind: note.BatchDelete, Err: errors.New("connection reset")},
+			},
+			wantStatus: http.StatusOK,
+			wantBody: api.BatchResult{Applied: 2, Failed: 1, Results: []api.BatchOperationResult{
+				{Op: "create", Status: "applied", Note: &apiCreated},
+				{Op: "update", Status: "applied", Note: &apiUpdated},
+				{Op: "delete", Status: "failed", Error: "the operation failed"},
+			}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mNotesSvc.Setup(mockNotesStoreParams{method: "Batch", arguments: []any{userID, ops, tc.mode}, returnArguments: []any{tc.results, nil}})
+
+			rr := batch(tc.body)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.JSONEq(t, mustEncode(t, tc.wantBody), rr.Body.String())
+			mNotesSvc.AssertExpectations(t)
+		})
+	}
+
+	t.Run("Invalid requests", func(t *testing.T) {
+		for _, body := range []string{
+			`not json`,
+			`{"mode": "sometimes", "operations": []}`,
+			`{"operations": [{"op": "move"}]}`,
+			`{"operations": [{"op": "update", "id": "1"}]}`,
+			`{"operations": [{"op": "create", "format": "rtf"}]}`,
+		} {
+			mNotesSvc.Reset()
+
+			rr := batch(body)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+			mNotesSvc.AssertNotCalled(t, "Batch")
+		}
+
+		rr := batch(`{"operations": [` + strings.Repeat(`{"op": "create"},`, note.MaxBatchOps) + `{"op": "create"}]}`)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	})
+
+	t.Run("Service error", func(t *testing.T) {
+		mNotesSvc.Setup(mockNotesStoreParams{method: "Batch", arguments: []any{userID, ops, note.BatchAtomic}, returnArguments: []any{[]note.BatchResult(nil), errors.New("error notesSvc")}})
+
+		rr := batch(body)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
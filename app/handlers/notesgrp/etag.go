@@ -0,0 +1,53 @@
+package notesgrp
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+)
+
+// etag is the strong entity tag of n, its quoted version.
+func etag(n note.Note) string {
+	return strconv.Quote(strconv.Itoa(n.Version))
+}
+
+// ifMatch reports whether n satisfies the If-Match header of r. Requests
+// without the header always match. Weak tags never match, as required by the
+// strong comparison of RFC 9110.
+func ifMatch(r *http.Request, n note.Note) bool {
+	values := r.Header.Values("If-Match")
+	if len(values) == 0 {
+		return true
+	}
+
+	want := etag(n)
+	for _, v := range values {
+		for _, tag := range strings.Split(v, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || tag == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ifMatchVersion is the version a write has to find n at to satisfy the
+// If-Match header of r, which ifMatch has checked against n. It is 0 if any
+// version does, without the header or with *.
+func ifMatchVersion(r *http.Request, n note.Note) int {
+	values := r.Header.Values("If-Match")
+	if len(values) == 0 {
+		return 0
+	}
+	for _, v := range values {
+		for _, tag := range strings.Split(v, ",") {
+			if strings.TrimSpace(tag) == "*" {
+				return 0
+			}
+		}
+	}
+	return n.Version
+}
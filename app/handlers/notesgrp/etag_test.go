@@ -0,0 +1,148 @@
+package notesgrp_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Keisn1/note-taking-app/app/api"
+	"github.com/Keisn1/note-taking-app/app/handlers/notesgrp"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/foundation"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ETag(t *testing.T) {
+	mNotesSvc := &mockNotesSvc{}
+	hdl := notesgrp.NewHandlers(mNotesSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	userID := uuid.UUID{2}
+	n := note.Note{ID: uuid.UUID{1}, Title: note.NewTitle("title"), Content: note.NewContent("content"), UserID: userID, Version: 3}
+	updated := note.Note{ID: n.ID, Title: note.NewTitle("new title"), Content: note.NewContent(""), UserID: userID, Version: 4}
+	un := note.UpdateNote{Title: note.NewTitle("new title"), Content: note.NewContent(""), UserID: userID}
+	body := mustEncode(t, api.NotePost{Title: "new title"})
+
+	t.Run("GET returns the version as ETag", func(t *testing.T) {
+		req := withNote(setupRequest(t, http.MethodGet, "/notes/"+n.ID.String(), userID), n)
+		rr := httptest.NewRecorder()
+
+		hdl.GetNoteByID(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `"3"`, rr.Header().Get("ETag"))
+	})
+
+	testCases := []struct {
+		name       string
+		ifMatch    string
+		mNSP       *mockNotesStoreParams
+		wantStatus int
+		wantETag   string
+	}{
+		{
+			name:       "PUT without If-Match",
+			mNSP:       &mockNotesStoreParams{method: "Update", arguments: []any{n, un}, returnArguments: []any{updated, nil}},
+			wantStatus: http.StatusOK,
+			wantETag:   `"4"`,
+		},
+		{
+			name:       "PUT with matching If-Match",
+			ifMatch:    `"2", "3"`,
+			mNSP:       &mockNotesStoreParams{method: "Update", arguments: []any{n, un}, returnArguments: []any{updated, nil}},
+			wantStatus: http.StatusOK,
+			wantETag:   `"4"`,
+		},
+		{
+			name:       "PUT with If-Match *",
+			ifMatch:    "*",
+			mNSP:       &mockNotesStoreParams{method: "Update", arguments: []any{n, un}, returnArguments: []any{updated, nil}},
+			wantStatus: http.StatusOK,
+			wantETag:   `"4"`,
+		},
+		{
+			name:       "PUT with stale If-Match",
+			ifMatch:    `"2"`,
+			wantStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:       "PUT with weak If-Match",
+			ifMatch:    `W/"3"`,
+			wantStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:       "PUT racing another update",
+			ifMatch:    `"3"`,
+			mNSP:       &mockNotesStoreParams{method: "Update", arguments: []any{n, un}, returnArguments: []any{note.Note{}, fmt.Errorf("update: %w", note.ErrVersionConflict)}},
+			wantStatus: http.StatusPreconditionFailed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mNotesSvc.Reset()
+			if tc.mNSP != nil {
+				mNotesSvc.Setup(*tc.mNSP)
+			}
+			req := httptest.NewRequest(http.MethodPut, "/notes/"+n.ID.String(), strings.NewReader(body))
+			req = withNote(req.WithContext(context.WithValue(req.Context(), foundation.UserIDKey, userID)), n)
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
+			rr := httptest.NewRecorder()
+
+			hdl.Put(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, tc.wantETag, rr.Header().Get("ETag"))
+			if tc.mNSP == nil {
+				mNotesSvc.AssertNotCalled(t, "Update")
+			}
+		})
+	}
+
+	t.Run("DELETE with stale If-Match", func(t *testing.T) {
+		mNotesSvc.Reset()
+		req := withNote(setupRequest(t, http.MethodDelete, "/notes/"+n.ID.String(), userID), n)
+		req.Header.Set("If-Match", `"2"`)
+		rr := httptest.NewRecorder()
+
+		hdl.Delete(rr, req)
+
+		assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+		mNotesSvc.AssertNotCalled(t, "Delete")
+	})
+
+	t.Run("DELETE racing an update", func(t *testing.T) {
+		mNotesSvc.Reset()
+		mNotesSvc.Setup(mockNotesStoreParams{method: "Delete", arguments: []any{n.ID, 3}, returnArguments: []any{fmt.Errorf("delete: %w", note.ErrVersionConflict)}})
+		req := withNote(setupRequest(t, http.MethodDelete, "/notes/"+n.ID.String(), userID), n)
+		req.Header.Set("If-Match", `"3"`)
+		rr := httptest.NewRecorder()
+
+		hdl.Delete(rr, req)
+
+		assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+		mNotesSvc.AssertExpectations(t)
+	})
+
+	t.Run("DELETE with If-Match * accepts any version", func(t *testing.T) {
+		mNotesSvc.Reset()
+		mNotesSvc.Setup(mockNotesStoreParams{method: "Delete", arguments: []any{n.ID, 0}, returnArguments: []any{nil}})
+		req := withNote(setupRequest(t, http.MethodDelete, "/notes/"+n.ID.String(), userID), n)
+		req.Header.Set("If-Match", "*")
+		rr := httptest.NewRecorder()
+
+		hdl.Delete(rr, req)
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+		mNotesSvc.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,165 @@
+package notesgrp
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/app/api"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/web/mid"
+)
+
+// exportWriteTimeout is the time each note of an export may take to be
+// written. It replaces the write timeout of the server, which would cut off
+// large exports.
+const exportWriteTimeout = 30 * time.Second
+
+// exporter writes the notes of an export one after the other. Close finishes
+// the archive, it has to be called even if there were no notes.
+type exporter interface {
+	Add(n note.Note) error
+	Close() error
+}
+
+type exportFormat struct {
+	contentType string
+	ext         string
+	newExporter func(w io.Writer) exporter
+}
+
+var exportFormats = map[string]exportFormat{
+	"zip":    {contentType: "application/zip", ext: "zip", newExporter: newZipExporter},
+	"json":   {contentType: "application/json", ext: "json", newExporter: newJSONExporter},
+	"ndjson": {contentType: "application/x-ndjson", ext: "ndjson", newExporter: newNDJSONExporter},
+}
+
+// Export streams all notes of the caller as a zip of Markdown files, a JSON
+// array or newline delimited JSON, as given by the format query parameter
+// (default zip). Once the first note has been written the status can't be
+// changed anymore, so a later failure aborts the connection instead, which
+// tells the client that the export is incomplete.
+func (hdl *Handlers) Export(w http.ResponseWriter, r *http.Request) {
+	userID := mid.GetUserID(r.Context())
+	logMsg := fmt.Sprintf("Export: userID %v", userID)
+
+	name := r.URL.Query().Get("format")
+	if name == "" {
+		name = "zip"
+	}
+	format, ok := exportFormats[name]
+	if !ok {
+		handleError(w, "invalid format", http.StatusBadRequest, logMsg, "error", fmt.Errorf("format %q", name))
+		return
+	}
+
+	ex := format.newExporter(w)
+	rc := http.NewResponseController(w)
+	started := false
+	start := func() {
+		if started {
+			return
+		}
+		started = true
+		w.Header().Set("Content-Type", format.contentType)
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": "notes." + format.ext}))
+		w.WriteHeader(http.StatusOK)
+	}
+
+	err := hdl.notesSvc.StreamNotesByUserID(r.Context(), userID, func(n note.Note) error {
+		start()
+		// not all ResponseWriters support deadlines, which only matters to long exports
+		_ = rc.SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+		return ex.Add(n)
+	})
+	if err == nil {
+		start()
+		err = ex.Close()
+	}
+	if err != nil {
+		if !started {
+			handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
+			return
+		}
+		slog.Error(logMsg, "error", err)
+		panic(http.ErrAbortHandler)
+	}
+
+	slog.Info("Success: " + logMsg)
+}
+
+// zipExporter writes each note as a Markdown file with front matter.
+type zipExporter struct {
+	zw *zip.Writer
+}
+
+func newZipExporter(w io.Writer) exporter {
+	return zipExporter{zw: zip.NewWriter(w)}
+}
+
+func (ex zipExporter) Add(n note.Note) error {
+	f, err := ex.zw.CreateHeader(&zip.FileHeader{Name: n.Filename(), Method: zip.Deflate, Modified: n.UpdatedAt})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(n.MarkdownFile())
+	return err
+}
+
+func (ex zipExporter) Close() error {
+	return ex.zw.Close()
+}
+
+// jsonExporter writes a JSON array of api.Note.
+type jsonExporter struct {
+	w     io.Writer
+	enc   *json.Encoder
+	count int
+}
+
+func newJSONExporter(w io.Writer) exporter {
+	return &jsonExporter{w: w, enc: json.NewEncoder(w)}
+}
+
+func (ex *jsonExporter) Add(n note.Note) error {
+	sep := ","
+	if ex.count == 0 {
+		sep = "["
+	}
+	ex.count++
+	if _, err := io.WriteString(ex.w, sep); err != nil {
+		return err
+	}
+	return ex.enc.Encode(api.FromNote(n))
+}
+
+func (ex *jsonExporter) Close() error {
+	end := "]\n"
+	if ex.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(ex.w, end)
+	return err
+}
+
+// ndjsonExporter writes one api.Note per line.
+type ndjsonExporter struct {
+	enc *json.Encoder
+}
+
+func newNDJSONExporter(w io.Writer) exporter {
+	return ndjsonExporter{enc: json.NewEncoder(w)}
+}
+
+func (ex ndjsonExporter) Add(n note.Note) error {
+	return ex.enc.Encode(api.FromNote(n))
+}
+
+func (ex ndjsonExporter) Close() error {
+	return nil
+}
@@ -0,0 +1,121 @@
+package notesgrp_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/app/api"
+	"github.com/Keisn1/note-taking-app/app/handlers/notesgrp"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Export(t *testing.T) {
+	mNotesSvc := &mockNotesSvc{}
+	hdl := notesgrp.NewHandlers(mNotesSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	userID := uuid.UUID{1}
+	updatedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	notes := []note.Note{
+		{ID: uuid.UUID{1}, Title: note.NewTitle("first"), Content: note.NewContent("content 1"), Format: note.FormatPlain, UserID: userID, UpdatedAt: updatedAt},
+		{ID: uuid.UUID{2}, Title: note.NewTitle("second"), Content: note.NewContent("*content 2*"), Format: note.FormatMarkdown, UserID: userID, UpdatedAt: updatedAt},
+	}
+	apiNotes := []api.Note{
+		{ID: notes[0].ID.String(), Title: "first", Content: "content 1", Format: "plain", UserID: userID.String(), UpdatedAt: updatedAt},
+		{ID: notes[1].ID.String(), Title: "second", Content: "*content 2*", Format: "markdown", UserID: userID.String(), UpdatedAt: updatedAt},
+	}
+
+	export := func(target string) *httptest.ResponseRecorder {
+		req := setupRequest(t, http.MethodGet, target, userID)
+		rr := httptest.NewRecorder()
+		hdl.Export(rr, req)
+		return rr
+	}
+
+	t.Run("Zip of markdown files", func(t *testing.T) {
+		mNotesSvc.Setup(mockNotesStoreParams{method: "StreamNotesByUserID", arguments: []any{userID}, returnArguments: []any{notes, nil}})
+
+		rr := export("/notes/export")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/zip", rr.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename=notes.zip`, rr.Header().Get("Content-Disposition"))
+
+		zr, err := zip.NewReader(bytes.NewReader(rr.Body.Bytes()), int64(rr.Body.Len()))
+		assert.NoError(t, err)
+		assert.Len(t, zr.File, 2)
+		for i, f := range zr.File {
+			assert.Equal(t, notes[i].Filename(), f.Name)
+			rc, err := f.Open()
+			assert.NoError(t, err)
+			got, err := io.ReadAll(rc)
+			assert.NoError(t, err)
+			assert.Equal(t, notes[i].MarkdownFile(), got)
+		}
+	})
+
+	t.Run("JSON array", func(t *testing.T) {
+		mNotesSvc.Setup(mockNotesStoreParams{method: "StreamNotesByUserID", arguments: []any{userID}, returnArguments: []any{notes, nil}})
+
+		rr := export("/notes/export?format=json")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+		assert.JSONEq(t, mustEncode(t, apiNotes), rr.Body.String())
+	})
+
+	t.Run("Empty JSON array", func(t *testing.T) {
+		mNotesSvc.Setup(mockNotesStoreParams{method: "StreamNotesByUserID", arguments: []any{userID}, returnArguments: []any{[]note.Note{}, nil}})
+
+		rr := export("/notes/export?format=json")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "[]\n", rr.Body.String())
+	})
+
+	t.Run("Newline delimited JSON", func(t *testing.T) {
+		mNotesSvc.Setup(mockNotesStoreParams{method: "StreamNotesByUserID", arguments: []any{userID}, returnArguments: []any{notes, nil}})
+
+		rr := export("/notes/export?format=ndjson")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+		assert.Equal(t, mustEncode(t, apiNotes[0])+"\n"+mustEncode(t, apiNotes[1])+"\n", rr.Body.String())
+	})
+
+	t.Run("Invalid format", func(t *testing.T) {
+		mNotesSvc.Reset()
+
+		rr := export("/notes/export?format=pdf")
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "invalid format\n", rr.Body.String())
+		mNotesSvc.AssertNotCalled(t, "StreamNotesByUserID")
+	})
+
+	t.Run("Errors before the first note", func(t *testing.T) {
+		mNotesSvc.Setup(mockNotesStoreParams{method: "StreamNotesByUserID", arguments: []any{userID}, returnArguments: []any{[]note.Note{}, errors.New("error notesSvc")}})
+
+		rr := export("/notes/export")
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+
+	t.Run("Errors after the first note abort the response", func(t *testing.T) {
+		mNotesSvc.Setup(mockNotesStoreParams{method: "StreamNotesByUserID", arguments: []any{userID}, returnArguments: []any{notes[:1], errors.New("error notesSvc")}})
+		logBuf.Reset()
+
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() { export("/notes/export?format=ndjson") })
+		assert.Contains(t, logBuf.String(), "error notesSvc")
+	})
+}
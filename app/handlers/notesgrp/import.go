@@ -0,0 +1,296 @@
+package notesgrp
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/Keisn1/note-taking-app/app/api"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/web/mid"
+	"github.com/Keisn1/note-taking-app/foundation/enex"
+	"github.com/Keisn1/note-taking-app/foundation/frontmatter"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxImportSize limits the uploaded file.
+	maxImportSize = 32 << 20
+	// maxImportNoteSize limits a single note of an import.
+	maxImportNoteSize = 1 << 20
+	// maxImportContent limits the unpacked notes of a zip, which can be far
+	// larger than the zip itself.
+	maxImportContent = 64 << 20
+)
+
+var errImportTooLarge = errors.New("the import is too large")
+
+// importReaders read the notes of the formats of an import.
+var importReaders = map[string]func(r io.Reader) ([]note.ImportItem, error){
+	"zip":    readZipImport,
+	"ndjson": readNDJSONImport,
+	"enex":   readENEXImport,
+}
+
+// importContentTypes give the format of an import without format parameter.
+var importContentTypes = map[string]string{
+	"application/zip":              "zip",
+	"application/x-zip-compressed": "zip",
+	"application/x-ndjson":         "ndjson",
+	"application/enex+xml":         "enex",
+	"application/xml":              "enex",
+	"text/xml":                     "enex",
+}
+
+// Import creates notes from the request body: a zip of Markdown files, like
+// the one written by Export, newline delimited JSON as written by Export or
+// an Evernote export. The format is given by the format query parameter or
+// else by the Content-Type. Notes that can't be read are reported as failed,
+// the others are created all at once or not at all.
+func (hdl *Handlers) Import(w http.ResponseWriter, r *http.Request) {
+	userID := mid.GetUserID(r.Context())
+	logMsg := fmt.Sprintf("Import: userID %v", userID)
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		format = importContentTypes[mediaType]
+	}
+	read, ok := importReaders[format]
+	if !ok {
+		handleError(w, "invalid format", http.StatusBadRequest, logMsg, "error", fmt.Errorf("format %q", format))
+		return
+	}
+
+	items, err := read(http.MaxBytesReader(w, r.Body, maxImportSize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesErr), errors.Is(err, errImportTooLarge):
+			handleError(w, "the import is too large", http.StatusRequestEntityTooLarge, logMsg, "error", err)
+		case errors.Is(err, note.ErrTooManyImportItems):
+			handleError(w, note.ErrTooManyImportItems.Error(), http.StatusRequestEntityTooLarge, logMsg, "error", err)
+		default:
+			handleError(w, "invalid "+format+" file", http.StatusBadRequest, logMsg, "error", err)
+		}
+		return
+	}
+
+	results, err := hdl.notesSvc.Import(r.Context(), userID, items)
+	if err != nil {
+		if errors.Is(err, note.ErrTooManyImportItems) {
+			handleError(w, note.ErrTooManyImportItems.Error(), http.StatusRequestEntityTooLarge, logMsg, "error", err)
+			return
+		}
+		handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
+		return
+	}
+
+	respond(w, http.StatusOK, toAPIImportResult(results), logMsg)
+}
+
+// readZipImport reads the Markdown and text files of a zip. Directories and
+// hidden files, like the ones added by macOS, are left out.
+func readZipImport(r io.Reader) ([]note.ImportItem, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	var items []note.ImportItem
+	var total int64
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || isHidden(f.Name) {
+			continue
+		}
+		if len(items) == note.MaxImportItems {
+			return nil, note.ErrTooManyImportItems
+		}
+
+		switch strings.ToLower(path.Ext(f.Name)) {
+		case ".md", ".markdown", ".txt":
+		default:
+			items = append(items, note.ImportItem{Name: f.Name, Err: errors.New("not a Markdown or text file")})
+			continue
+		}
+
+		content, err := readZipFile(f)
+		if err != nil {
+			items = append(items, note.ImportItem{Name: f.Name, Err: err})
+			continue
+		}
+		if total += int64(len(content)); total > maxImportContent {
+			return nil, errImportTooLarge
+		}
+		items = append(items, readMarkdownFile(f.Name, content))
+	}
+	return items, nil
+}
+
+// readMarkdownFile reads a file written by Export, or any other Markdown file
+// with or without front matter. Notes without a title are named after the
+// file, files ending in .txt are plain text.
+func readMarkdownFile(name string, data []byte) note.ImportItem {
+	it := note.ImportItem{Name: name, Format: note.FormatMarkdown}
+	base := path.Base(name)
+	if strings.EqualFold(path.Ext(base), ".txt") {
+		it.Format = note.FormatPlain
+	}
+
+	f, err := frontmatter.Parse(data)
+	if err != nil {
+		it.Err = err
+		return it
+	}
+
+	title := strings.TrimSuffix(base, path.Ext(base))
+	if f.Title != nil {
+		title = *f.Title
+	}
+	it.Title, it.Content = note.NewTitle(title), note.NewContent(f.Content)
+	it.CreatedAt, it.UpdatedAt = f.Created, f.Updated
+	if f.Format != "" {
+		it.Format, it.Err = note.ParseFormat(f.Format)
+	}
+	if it.Err == nil && len(f.Tags) > 0 {
+		it.Tags, it.Err = note.NewTags(f.Tags...)
+	}
+	return it
+}
+
+// readZipFile doesn't trust the size in the header of f.
+func readZipFile(f *zip.File) ([]byte, error) {
+	errTooLarge := fmt.Errorf("the note is larger than %d bytes", maxImportNoteSize)
+	if f.UncompressedSize64 > maxImportNoteSize {
+		return nil, errTooLarge
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(io.LimitReader(rc, maxImportNoteSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > maxImportNoteSize {
+		return nil, errTooLarge
+	}
+	return content, nil
+}
+
+func isHidden(name string) bool {
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") || part == "__MACOSX" {
+			return true
+		}
+	}
+	return false
+}
+
+// readNDJSONImport reads one api.Note per line. Ids are ignored, the notes
+// are created anew.
+func readNDJSONImport(r io.Reader) ([]note.ImportItem, error) {
+	sc := bufio.NewScanner(r)
+	// escaping may double the size of a note
+	sc.Buffer(make([]byte, 0, 64<<10), 2*maxImportNoteSize)
+
+	var items []note.ImportItem
+	for line := 1; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		if len(items) == note.MaxImportItems {
+			return nil, note.ErrTooManyImportItems
+		}
+
+		it := note.ImportItem{Name: fmt.Sprintf("line %d", line)}
+		var n api.Note
+		if err := json.Unmarshal(sc.Bytes(), &n); err != nil {
+			it.Err = err
+			items = append(items, it)
+			continue
+		}
+
+		it.Title, it.Content = note.NewTitle(n.Title), note.NewContent(n.Content)
+		it.CreatedAt, it.UpdatedAt = n.CreatedAt, n.UpdatedAt
+		if n.Format != "" {
+			it.Format, it.Err = note.ParseFormat(n.Format)
+		}
+		if it.Err == nil && len(n.Tags) > 0 {
+			it.Tags, it.Err = note.NewTags(n.Tags...)
+		}
+		items = append(items, it)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// readENEXImport reads the notes of an Evernote export as Markdown.
+func readENEXImport(r io.Reader) ([]note.ImportItem, error) {
+	notes, err := enex.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(notes) > note.MaxImportItems {
+		return nil, note.ErrTooManyImportItems
+	}
+
+	items := make([]note.ImportItem, 0, len(notes))
+	for i, n := range notes {
+		it := note.ImportItem{
+			Name:      fmt.Sprintf("note %d", i+1),
+			Title:     note.NewTitle(n.Title),
+			Content:   note.NewContent(n.Content),
+			Format:    note.FormatMarkdown,
+			CreatedAt: n.Created,
+			UpdatedAt: n.Updated,
+			Err:       n.Err,
+		}
+		if it.Err == nil && len(n.Tags) > 0 {
+			it.Tags, it.Err = note.NewTags(n.Tags...)
+		}
+		items = append(items, it)
+	}
+	return items, nil
+}
+
+func toAPIImportResult(results []note.ImportResult) api.ImportResult {
+	ret := api.ImportResult{Items: make([]api.ImportItem, 0, len(results))}
+	for _, res := range results {
+		item := api.ImportItem{Name: res.Name, Status: string(res.Status)}
+		if res.NoteID != uuid.Nil {
+			item.NoteID = res.NoteID.String()
+		}
+		if res.Err != nil {
+			item.Error = res.Err.Error()
+		}
+
+		switch res.Status {
+		case note.ImportCreated:
+			ret.Created++
+		case note.ImportSkipped:
+			ret.Skipped++
+		case note.ImportFailed:
+			ret.Failed++
+		}
+		ret.Items = append(ret.Items, item)
+	}
+	return ret
+}
@@ -0,0 +1,206 @@
+package notesgrp_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/app/api"
+	"github.com/Keisn1/note-taking-app/app/handlers/notesgrp"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/foundation/frontmatter"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// mustZip zips the files given as pairs of name and content.
+func mustZip(t *testing.T, files ...[2]string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, file := range files {
+		f, err := zw.Create(file[0])
+		assert.NoError(t, err)
+		_, err = f.Write([]byte(file[1]))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+	return buf.String()
+}
+
+func Test_Import(t *testing.T) {
+	mNotesSvc := &mockNotesSvc{}
+	hdl := notesgrp.NewHandlers(mNotesSvc)
+
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	userID := uuid.UUID{1}
+
+	importRequest := func(target, contentType, body string) *httptest.ResponseRecorder {
+		req := setupRequest(t, http.MethodPost, target, userID)
+		req.Body = io.NopCloser(strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		rr := httptest.NewRecorder()
+		hdl.Import(rr, req)
+		return rr
+	}
+
+	t.Run("Reports the result of each note", func(t *testing.T) {
+		items := []note.ImportItem{{Name: "line 1", Title: note.NewTitle("a"), Content: note.NewContent("b")}}
+		results := []note.ImportResult{
+			{Name: "line 1", Status: note.ImportCreated, NoteID: uuid.UUID{2}},
+			{Name: "line 2", Status: note.ImportSkipped, NoteID: uuid.UUID{3}},
+			{Name: "line 3", Status: note.ImportFailed, Err: errors.New("invalid tag")},
+		}
+		mNotesSvc.Setup(mockNotesStoreParams{method: "Import", arguments: []any{userID, items}, returnArguments: []any{results, nil}})
+
+		rr := importRequest("/notes/import", "application/x-ndjson", `{"title": "a", "content": "b"}`+"\n")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, mustEncode(t, api.ImportResult{Created: 1, Skipped: 1, Failed: 1, Items: []api.ImportItem{
+			{Name: "line 1", Status: "created", NoteID: uuid.UUID{2}.String()},
+			{Name: "line 2", Status: "skipped", NoteID: uuid.UUID{3}.String()},
+			{Name: "line 3", Status: "failed", Error: "invalid tag"},
+		}}), rr.Body.String())
+	})
+
+	createdAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	exported := note.Note{
+		ID:        uuid.UUID{1},
+		Title:     note.NewTitle(`say "hi": it's # me`),
+		Content:   note.NewContent("# hi\n\n---\n"),
+		Format:    note.FormatPlain,
+		Tags:      note.Tags{"go", "to-do"},
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt.Add(time.Hour),
+	}
+
+	testCases := []struct {
+		name        string
+		target      string
+		contentType string
+		body        string
+		wantItems   []note.ImportItem
+	}{
+		{
+			name:        "Zip of markdown files",
+			target:      "/notes/import",
+			contentType: "application/zip",
+			body: mustZip(t,
+				[2]string{"a.md", "---\ntitle: A\ntags: [go]\n---\n*a*"},
+				[2]string{".hidden.md", "hidden"},
+				[2]string{"notes/b.txt", "b"},
+				[2]string{"__MACOSX/a.md", "resource fork"},
+				[2]string{"image.png", "png"},
+			),
+			wantItems: []note.ImportItem{
+				{Name: "a.md", Title: note.NewTitle("A"), Content: note.NewContent("*a*"), Format: note.FormatMarkdown, Tags: note.Tags{"go"}},
+				{Name: "notes/b.txt", Title: note.NewTitle("b"), Content: note.NewContent("b"), Format: note.FormatPlain},
+				{Name: "image.png", Err: errors.New("not a Markdown or text file")},
+			},
+		},
+		{
+			name:        "Zip as written by Export",
+			target:      "/notes/import",
+			contentType: "application/zip",
+			body:        mustZip(t, [2]string{exported.Filename(), string(exported.MarkdownFile())}),
+			wantItems: []note.ImportItem{{
+				Name: exported.Filename(), Title: exported.Title, Content: exported.Content, Format: exported.Format,
+				Tags: exported.Tags, CreatedAt: exported.CreatedAt, UpdatedAt: exported.UpdatedAt,
+			}},
+		},
+		{
+			name:        "Invalid front matter fails the file",
+			target:      "/notes/import",
+			contentType: "application/zip",
+			body: mustZip(t,
+				[2]string{"a.md", "---\ntitle: x\ncontent"},
+				[2]string{"b.md", "---\nformat: rtf\n---\n"},
+				[2]string{"c.md", "---\ntags: [a+b]\n---\n"},
+			),
+			wantItems: []note.ImportItem{
+				{Name: "a.md", Format: note.FormatMarkdown, Err: frontmatter.ErrNoEnd},
+				{Name: "b.md", Title: note.NewTitle("b"), Content: note.NewContent(""), Err: note.ErrInvalidFormat},
+				{Name: "c.md", Title: note.NewTitle("c"), Content: note.NewContent(""), Format: note.FormatMarkdown, Err: errors.New("normalizeTag: [a+b]: invalid tag")},
+			},
+		},
+		{
+			name:        "NDJSON as written by Export",
+			target:      "/notes/import?format=ndjson",
+			contentType: "",
+			body: `{"id": "x", "title": "a", "content": "b", "format": "markdown", "tags": ["Go"]}` + "\n\n" +
+				`{"title": "c", "format": "rtf"}` + "\n" + `not json`,
+			wantItems: []note.ImportItem{
+				{Name: "line 1", Title: note.NewTitle("a"), Content: note.NewContent("b"), Format: note.FormatMarkdown, Tags: note.Tags{"go"}},
+				{Name: "line 3", Title: note.NewTitle("c"), Content: note.NewContent(""), Err: note.ErrInvalidFormat},
+				{Name: "line 4", Err: errors.New("invalid character 'o' in literal null (expecting 'u')")},
+			},
+		},
+		{
+			name:        "Evernote export",
+			target:      "/notes/import",
+			contentType: "application/enex+xml",
+			body: `<en-export><note><title>t</title><tag>Work</tag>` +
+				`<content><![CDATA[<en-note><div>x</div></en-note>]]></content></note></en-export>`,
+			wantItems: []note.ImportItem{
+				{Name: "note 1", Title: note.NewTitle("t"), Content: note.NewContent("x\n"), Format: note.FormatMarkdown, Tags: note.Tags{"work"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mNotesSvc.Setup(mockNotesStoreParams{method: "Import", arguments: []any{userID, mock.Anything}, returnArguments: []any{[]note.ImportResult{}, nil}})
+
+			rr := importRequest(tc.target, tc.contentType, tc.body)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			items := mNotesSvc.Calls[0].Arguments.Get(1).([]note.ImportItem)
+			assert.Len(t, items, len(tc.wantItems))
+			for i, want := range tc.wantItems {
+				got := items[i]
+				if want.Err != nil {
+					assert.EqualError(t, got.Err, want.Err.Error())
+				}
+				got.Err, want.Err = nil, nil
+				assert.Equal(t, want, got)
+			}
+		})
+	}
+
+	t.Run("Invalid requests", func(t *testing.T) {
+		for _, tc := range []struct {
+			target, contentType, body string
+			wantStatus                int
+		}{
+			{target: "/notes/import", contentType: "text/plain", body: "x", wantStatus: http.StatusBadRequest},
+			{target: "/notes/import?format=zip", body: "not a zip", wantStatus: http.StatusBadRequest},
+			{target: "/notes/import?format=enex", body: "<rss/>", wantStatus: http.StatusBadRequest},
+			{target: "/notes/import?format=ndjson", body: strings.Repeat("{}\n", note.MaxImportItems+1), wantStatus: http.StatusRequestEntityTooLarge},
+		} {
+			mNotesSvc.Reset()
+
+			rr := importRequest(tc.target, tc.contentType, tc.body)
+
+			assert.Equal(t, tc.wantStatus, rr.Code, tc.target)
+			mNotesSvc.AssertNotCalled(t, "Import")
+		}
+	})
+
+	t.Run("Service error", func(t *testing.T) {
+		mNotesSvc.Setup(mockNotesStoreParams{method: "Import", arguments: []any{userID, mock.Anything}, returnArguments: []any{[]note.ImportResult(nil), errors.New("error notesSvc")}})
+
+		rr := importRequest("/notes/import?format=ndjson", "", "{}")
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
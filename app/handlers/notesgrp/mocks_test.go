@@ -2,6 +2,7 @@ package notesgrp_test
 
 import (
 	"context"
+	"time"
 
 	"github.com/Keisn1/note-taking-app/domain/core/note"
 	"github.com/google/uuid"
@@ -33,21 +34,138 @@ func (mNS *mockNotesSvc) GetNotesByUserID(userID uuid.UUID) ([]note.Note, error)
 	return args.Get(0).([]note.Note), args.Error(1)
 }
 
+// StreamNotesByUserID calls fn with the notes given as first return argument.
+func (mNS *mockNotesSvc) StreamNotesByUserID(ctx context.Context, userID uuid.UUID, fn func(note.Note) error) error {
+	args := mNS.Called(userID)
+	for _, n := range args.Get(0).([]note.Note) {
+		if err := fn(n); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (mNS *mockNotesSvc) Import(ctx context.Context, userID uuid.UUID, items []note.ImportItem) ([]note.ImportResult, error) {
+	args := mNS.Called(userID, items)
+	return args.Get(0).([]note.ImportResult), args.Error(1)
+}
+
+func (mNS *mockNotesSvc) Batch(ctx context.Context, userID uuid.UUID, ops []note.BatchOp, mode note.BatchMode) ([]note.BatchResult, error) {
+	args := mNS.Called(userID, ops, mode)
+	return args.Get(0).([]note.BatchResult), args.Error(1)
+}
+
+func (mNS *mockNotesSvc) Query(ctx context.Context, userID uuid.UUID, filter note.QueryFilter, orderBy note.OrderBy, page note.Page) (note.NotesPage, error) {
+	args := mNS.Called(userID, filter, orderBy, page)
+	return args.Get(0).(note.NotesPage), args.Error(1)
+}
+
 func (mNS *mockNotesSvc) QueryByID(ctx context.Context, noteID uuid.UUID) (note.Note, error) {
 	args := mNS.Called(noteID)
 	return args.Get(0).(note.Note), args.Error(1)
 }
 
-func (mNS *mockNotesSvc) Create(newN note.UpdateNote) (note.Note, error) {
+func (mNS *mockNotesSvc) Create(ctx context.Context, newN note.UpdateNote) (note.Note, error) {
 	args := mNS.Called(newN)
 	return args.Get(0).(note.Note), args.Error(1)
 }
 
-func (mNS *mockNotesSvc) Update(n note.Note, un note.UpdateNote) (note.Note, error) {
-	return note.Note{}, nil
+func (mNS *mockNotesSvc) Update(ctx context.Context, n note.Note, un note.UpdateNote) (note.Note, error) {
+	args := mNS.Called(n, un)
+	return args.Get(0).(note.Note), args.Error(1)
 }
 
-func (mNS *mockNotesSvc) Delete(noteID uuid.UUID) error {
-	args := mNS.Called(noteID)
+func (mNS *mockNotesSvc) Delete(ctx context.Context, noteID uuid.UUID, version int) error {
+	args := mNS.Called(noteID, version)
+	return args.Error(0)
+}
+
+func (mNS *mockNotesSvc) QueryAll(ctx context.Context) ([]note.Note, error) {
+	args := mNS.Called()
+	return args.Get(0).([]note.Note), args.Error(1)
+}
+
+func (mNS *mockNotesSvc) Search(ctx context.Context, userID uuid.UUID, query string) ([]note.SearchResult, error) {
+	args := mNS.Called(userID, query)
+	return args.Get(0).([]note.SearchResult), args.Error(1)
+}
+
+func (mNS *mockNotesSvc) Permission(ctx context.Context, n note.Note, userID uuid.UUID) (note.Permission, error) {
+	args := mNS.Called(n, userID)
+	return args.Get(0).(note.Permission), args.Error(1)
+}
+
+func (mNS *mockNotesSvc) Share(ctx context.Context, n note.Note, userID uuid.UUID, perm note.Permission) (note.Share, error) {
+	args := mNS.Called(n, userID, perm)
+	return args.Get(0).(note.Share), args.Error(1)
+}
+
+func (mNS *mockNotesSvc) Unshare(ctx context.Context, noteID, userID uuid.UUID) error {
+	args := mNS.Called(noteID, userID)
 	return args.Error(0)
 }
+
+func (mNS *mockNotesSvc) QueryShares(ctx context.Context, noteID uuid.UUID) ([]note.Share, error) {
+	args := mNS.Called(noteID)
+	return args.Get(0).([]note.Share), args.Error(1)
+}
+
+func (mNS *mockNotesSvc) QuerySharedWith(ctx context.Context, userID uuid.UUID) ([]note.Note, error) {
+	args := mNS.Called(userID)
+	return args.Get(0).([]note.Note), args.Error(1)
+}
+
+func (mNS *mockNotesSvc) QueryTrash(ctx context.Context, userID uuid.UUID) ([]note.Note, error) {
+	args := mNS.Called(userID)
+	return args.Get(0).([]note.Note), args.Error(1)
+}
+
+func (mNS *mockNotesSvc) Restore(ctx context.Context, noteID, userID uuid.UUID) (note.Note, error) {
+	args := mNS.Called(noteID, userID)
+	return args.Get(0).(note.Note), args.Error(1)
+}
+
+func (mNS *mockNotesSvc) QueryRevisions(ctx context.Context, noteID uuid.UUID) ([]note.Revision, error) {
+	args := mNS.Called(noteID)
+	return args.Get(0).([]note.Revision), args.Error(1)
+}
+
+func (mNS *mockNotesSvc) QueryRevision(ctx context.Context, noteID uuid.UUID, number int) (note.Revision, error) {
+	args := mNS.Called(noteID, number)
+	return args.Get(0).(note.Revision), args.Error(1)
+}
+
+func (mNS *mockNotesSvc) DiffRevisions(ctx context.Context, noteID uuid.UUID, from, to int) (string, error) {
+	args := mNS.Called(noteID, from, to)
+	return args.String(0), args.Error(1)
+}
+
+func (mNS *mockNotesSvc) RestoreRevision(ctx context.Context, n note.Note, number int, authorID uuid.UUID) (note.Note, error) {
+	args := mNS.Called(n, number, authorID)
+	return args.Get(0).(note.Note), args.Error(1)
+}
+
+func (mNS *mockNotesSvc) PurgeTrash(ctx context.Context, retention time.Duration) (int, error) {
+	args := mNS.Called(retention)
+	return args.Int(0), args.Error(1)
+}
+
+func (mNS *mockNotesSvc) AddTags(ctx context.Context, n note.Note, tags note.Tags) (note.Note, error) {
+	args := mNS.Called(n, tags)
+	return args.Get(0).(note.Note), args.Error(1)
+}
+
+func (mNS *mockNotesSvc) RemoveTags(ctx context.Context, n note.Note, tags note.Tags) (note.Note, error) {
+	args := mNS.Called(n, tags)
+	return args.Get(0).(note.Note), args.Error(1)
+}
+
+func (mNS *mockNotesSvc) RenameTag(ctx context.Context, userID uuid.UUID, from, to string) (int, error) {
+	args := mNS.Called(userID, from, to)
+	return args.Int(0), args.Error(1)
+}
+
+func (mNS *mockNotesSvc) QueryTags(ctx context.Context, userID uuid.UUID) ([]note.TagCount, error) {
+	args := mNS.Called(userID)
+	return args.Get(0).([]note.TagCount), args.Error(1)
+}
@@ -2,30 +2,20 @@ package notesgrp
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
 
 	"github.com/Keisn1/note-taking-app/app/api"
 	"github.com/Keisn1/note-taking-app/domain/core/note"
 	"github.com/Keisn1/note-taking-app/domain/web/mid"
+	"github.com/Keisn1/note-taking-app/foundation/web"
 	"github.com/google/uuid"
 )
 
-// import (
-// 	"encoding/json"
-// 	"fmt"
-// 	"log/slog"
-// 	"net/http"
-// 	"strconv"
-
-// 	"github.com/Keisn1/note-taking-app/app/api"
-// 	"github.com/Keisn1/note-taking-app/domain/core/note"
-// 	"github.com/Keisn1/note-taking-app/foundation"
-// 	"github.com/go-chi/chi/v5"
-// 	"github.com/google/uuid"
-// )
-
 type Handlers struct {
 	notesSvc note.Service
 }
@@ -34,64 +24,95 @@ func NewHandlers(ns note.Service) Handlers {
 	return Handlers{notesSvc: ns}
 }
 
-// func (nc *Handlers) Edit(w http.ResponseWriter, r *http.Request) {
-// 	userID, ok := r.Context().Value(foundation.UserIDKey).(uuid.UUID)
-// 	if !ok {
-// 		logMsg := "Edit: invalid userID"
-// 		handleError(w, "", http.StatusBadRequest, logMsg)
-// 		return
-// 	}
-
-// 	noteID, err := strconv.Atoi(chi.URLParam(r, "noteID"))
-// 	if err != nil || noteID < 0 {
-// 		logMsg := fmt.Sprintf("Edit: invalid noteID %v", chi.URLParam(r, "noteID"))
-// 		handleError(w, "", http.StatusBadRequest, logMsg, "error", err)
-// 		return
-// 	}
-
-// 	var np api.NotePost
-// 	err = json.NewDecoder(r.Body).Decode(&np)
-// 	if err != nil {
-// 		handleError(w, "", http.StatusBadRequest, "Add: invalid body", "error", err)
-// 		return
-// 	}
-
-// 	err = nc.notesSvc.EditNote(userID, noteID, np.Note)
-// 	if err != nil {
-// 		logMsg := fmt.Sprintf("Edit: userID %v noteID %v body %v", userID, noteID, np)
-// 		handleError(w, "", http.StatusConflict, logMsg)
-// 		return
-// 	}
-
-// 	w.WriteHeader(http.StatusAccepted)
-// 	slog.Info(
-// 		fmt.Sprintf("Success: Edit: userID %v noteID %v body %v", userID, noteID, np),
-// 	)
-// }
-
-// func (nc *Handlers) Delete(w http.ResponseWriter, r *http.Request) {
-// 	userID, ok := r.Context().Value(foundation.UserIDKey).(uuid.UUID)
-// 	if !ok {
-// 		handleError(w, "", http.StatusBadRequest, "Delete: invalid userID")
-// 		return
-// 	}
-
-// 	noteID, err := strconv.Atoi(chi.URLParam(r, "noteID"))
-// 	if err != nil || noteID < 0 {
-// 		logMsg := fmt.Sprintf("Delete: invalid noteID %v", chi.URLParam(r, "noteID"))
-// 		handleError(w, "", http.StatusBadRequest, logMsg, "error", err)
-// 		return
-// 	}
-
-// 	err = nc.notesSvc.Delete(userID, noteID)
-// 	if err != nil {
-// 		handleError(w, "", http.StatusInternalServerError, "Delete: DBError")
-// 		return
-// 	}
-
-// 	w.WriteHeader(http.StatusNoContent)
-// 	slog.Info(fmt.Sprintf("Success: Delete: userID %v noteID %v", userID, noteID))
-// }
+// Query lists the caller's notes a page at a time. It takes the query
+// parameters limit, cursor, order (e.g. updated_at.desc), title_contains and
+// tag, which may be repeated and is combined according to tag_mode (all or any).
+func (hdl *Handlers) Query(w http.ResponseWriter, r *http.Request) {
+	userID := mid.GetUserID(r.Context())
+	logMsg := fmt.Sprintf("Query: userID %v query %q", userID, r.URL.RawQuery)
+
+	q := r.URL.Query()
+	filter := note.QueryFilter{TitleContains: q.Get("title_contains")}
+	if len(q["tag"]) > 0 {
+		var err error
+		if filter.Tags, err = note.NewTags(q["tag"]...); err != nil {
+			handleError(w, "invalid tag", http.StatusBadRequest, logMsg, "error", err)
+			return
+		}
+	}
+	if mode := q.Get("tag_mode"); mode != "" {
+		var err error
+		if filter.TagMode, err = note.ParseTagMode(mode); err != nil {
+			handleError(w, "invalid tag mode", http.StatusBadRequest, logMsg, "error", err)
+			return
+		}
+	}
+
+	page := note.Page{Cursor: q.Get("cursor")}
+	if limit := q.Get("limit"); limit != "" {
+		var err error
+		if page.Limit, err = strconv.Atoi(limit); err != nil || page.Limit <= 0 {
+			handleError(w, "invalid limit", http.StatusBadRequest, logMsg, "error", err)
+			return
+		}
+	}
+
+	var orderBy note.OrderBy
+	if order := q.Get("order"); order != "" {
+		var err error
+		if orderBy, err = note.ParseOrderBy(order); err != nil {
+			handleError(w, "invalid order", http.StatusBadRequest, logMsg, "error", err)
+			return
+		}
+	}
+
+	np, err := hdl.notesSvc.Query(r.Context(), userID, filter, orderBy, page)
+	if err != nil {
+		switch {
+		case errors.Is(err, note.ErrInvalidLimit):
+			handleError(w, "invalid limit", http.StatusBadRequest, logMsg, "error", err)
+		case errors.Is(err, note.ErrInvalidCursor):
+			handleError(w, "invalid cursor", http.StatusBadRequest, logMsg, "error", err)
+		default:
+			handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
+		}
+		return
+	}
+
+	ret := api.NotesPage{Notes: make([]api.Note, 0, len(np.Notes)), NextCursor: np.NextCursor}
+	for _, n := range np.Notes {
+		ret.Notes = append(ret.Notes, api.FromNote(n))
+	}
+
+	respond(w, http.StatusOK, ret, logMsg)
+}
+
+// GetNoteByID returns the note set by mid.AuthorizeNote as JSON, or its
+// content as sanitized HTML or as Markdown, whichever the Accept header
+// prefers. The rendered content gets a weak ETag, which doesn't satisfy
+// If-Match on updates.
+func (hdl *Handlers) GetNoteByID(w http.ResponseWriter, r *http.Request) {
+	n := mid.GetNote(r.Context())
+	logMsg := fmt.Sprintf("GetNoteByID: noteID %v", n.ID)
+	w.Header().Set("Vary", "Accept")
+
+	switch web.Negotiate(r.Header.Get("Accept"), "application/json", "text/html", "text/markdown") {
+	case "application/json":
+		w.Header().Set("ETag", etag(n))
+		respond(w, http.StatusOK, api.FromNote(n), logMsg)
+	case "text/html":
+		w.Header().Set("ETag", "W/"+etag(n))
+		// the fragment must not run anything when opened on its own
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; img-src *; sandbox")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		respondText(w, "text/html; charset=utf-8", n.HTML(), logMsg)
+	case "text/markdown":
+		w.Header().Set("ETag", "W/"+etag(n))
+		respondText(w, "text/markdown; charset=utf-8", n.Markdown(), logMsg)
+	default:
+		handleError(w, "", http.StatusNotAcceptable, logMsg, "error", fmt.Errorf("accept %q", r.Header.Get("Accept")))
+	}
+}
 
 func (hdl *Handlers) Create(w http.ResponseWriter, r *http.Request) {
 	userID := mid.GetUserID(r.Context())
@@ -103,114 +124,191 @@ func (hdl *Handlers) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	n, err := hdl.notesSvc.Create(toUpdateNote(np, userID))
+	un, err := toUpdateNote(np, userID)
+	if err != nil {
+		handleError(w, "invalid format", http.StatusBadRequest, "Add: invalid body", "error", err)
+		return
+	}
+
+	n, err := hdl.notesSvc.Create(r.Context(), un)
 	if err != nil {
 		logMsg := fmt.Sprintf("Add: userID %v body %v", userID, np)
 		handleError(w, "", http.StatusConflict, logMsg, "error", err)
 		return
 	}
 
-	data, err := json.Marshal(n)
+	w.Header().Set("ETag", etag(n))
+	respond(w, http.StatusCreated, api.FromNote(n), fmt.Sprintf("Create: userID %v body %v", userID, np))
+}
+
+// Put replaces title and content of the note set by mid.AuthorizeNote.
+func (hdl *Handlers) Put(w http.ResponseWriter, r *http.Request) {
+	n := mid.GetNote(r.Context())
+
+	var np api.NotePost
+	err := json.NewDecoder(r.Body).Decode(&np)
+	if err != nil {
+		handleError(w, "", http.StatusBadRequest, "Put: invalid body", "error", err)
+		return
+	}
+
+	un, err := toUpdateNote(np, mid.GetUserID(r.Context()))
+	if err != nil {
+		handleError(w, "invalid format", http.StatusBadRequest, "Put: invalid body", "error", err)
+		return
+	}
+
+	hdl.update(w, r, n, un, "Put")
+}
+
+// Patch updates only the fields present in the request body.
+func (hdl *Handlers) Patch(w http.ResponseWriter, r *http.Request) {
+	n := mid.GetNote(r.Context())
+
+	var np api.NotePatch
+	err := json.NewDecoder(r.Body).Decode(&np)
+	if err != nil {
+		handleError(w, "", http.StatusBadRequest, "Patch: invalid body", "error", err)
+		return
+	}
+
+	un := note.UpdateNote{UserID: mid.GetUserID(r.Context())}
+	if np.Title != nil {
+		un.Title = note.NewTitle(*np.Title)
+	}
+	if np.Content != nil {
+		un.Content = note.NewContent(*np.Content)
+	}
+	if np.Format != nil {
+		if un.Format, err = note.ParseFormat(*np.Format); err != nil {
+			handleError(w, "invalid format", http.StatusBadRequest, "Patch: invalid body", "error", err)
+			return
+		}
+	}
+
+	hdl.update(w, r, n, un, "Patch")
+}
+
+// update fails with 412 if the note doesn't match the If-Match header or is
+// changed by someone else before the update is stored.
+func (hdl *Handlers) update(w http.ResponseWriter, r *http.Request, n note.Note, un note.UpdateNote, method string) {
+	logMsg := fmt.Sprintf("%s: noteID %v", method, n.ID)
+	if !ifMatch(r, n) {
+		handleError(w, "", http.StatusPreconditionFailed, logMsg, "error", note.ErrVersionConflict)
+		return
+	}
+
+	updated, err := hdl.notesSvc.Update(r.Context(), n, un)
+	if err != nil {
+		if errors.Is(err, note.ErrVersionConflict) {
+			handleError(w, "", http.StatusPreconditionFailed, logMsg, "error", err)
+			return
+		}
+		handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
+		return
+	}
+
+	w.Header().Set("ETag", etag(updated))
+	respond(w, http.StatusOK, api.FromNote(updated), fmt.Sprintf("%s: noteID %v", method, n.ID))
+}
+
+// Delete fails with 412 if the note doesn't match the If-Match header or is
+// changed by someone else before it is moved to the trash.
+func (hdl *Handlers) Delete(w http.ResponseWriter, r *http.Request) {
+	n := mid.GetNote(r.Context())
+
+	if !ifMatch(r, n) {
+		logMsg := fmt.Sprintf("Delete: noteID %v", n.ID)
+		handleError(w, "", http.StatusPreconditionFailed, logMsg, "error", note.ErrVersionConflict)
+		return
+	}
+
+	if err := hdl.notesSvc.Delete(r.Context(), n.ID, ifMatchVersion(r, n)); err != nil {
+		logMsg := fmt.Sprintf("Delete: noteID %v", n.ID)
+		if errors.Is(err, note.ErrVersionConflict) {
+			handleError(w, "", http.StatusPreconditionFailed, logMsg, "error", err)
+			return
+		}
+		handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	slog.Info(fmt.Sprintf("Success: Delete: noteID %v", n.ID))
+}
+
+// Search looks for the words of the q query parameter in the caller's notes.
+func (hdl *Handlers) Search(w http.ResponseWriter, r *http.Request) {
+	userID := mid.GetUserID(r.Context())
+	q := r.URL.Query().Get("q")
+
+	results, err := hdl.notesSvc.Search(r.Context(), userID, q)
 	if err != nil {
-		logMsg := fmt.Sprintf("Create: userID %v body %v", userID, np)
+		logMsg := fmt.Sprintf("Search: userID %v q %q", userID, q)
+		if errors.Is(err, note.ErrEmptyQuery) {
+			handleError(w, "missing query parameter q", http.StatusBadRequest, logMsg, "error", err)
+			return
+		}
 		handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
 		return
 	}
 
-	w.WriteHeader(http.StatusAccepted)
-	w.Write(data)
-	slog.Info(
-		fmt.Sprintf("Success: Create: userID %v body %v", userID, np),
-	)
+	ret := make([]api.SearchResult, 0, len(results))
+	for _, res := range results {
+		ret = append(ret, api.SearchResult{Note: api.FromNote(res.Note), Rank: res.Rank, Snippet: res.Snippet})
+	}
+
+	respond(w, http.StatusOK, ret, fmt.Sprintf("Search: userID %v q %q", userID, q))
 }
 
-// func (nc *Handlers) GetNotesByUserID(w http.ResponseWriter, r *http.Request) {
-// 	userID, ok := r.Context().Value(foundation.UserIDKey).(uuid.UUID)
-// 	if !ok {
-// 		logMsg := "GetNotesByUserID: invalid userID"
-// 		handleError(w, "", http.StatusBadRequest, logMsg)
-// 		return
-// 	}
-
-// 	notes, err := nc.notesSvc.GetNotesByUserID(userID)
-// 	if err != nil {
-// 		logMsg := fmt.Sprintf("GetNotesByUserID: userID %v", userID)
-// 		handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
-// 		return
-// 	}
-
-// 	err = json.NewEncoder(w).Encode(notes)
-// 	if err != nil {
-// 		logMsg := fmt.Sprintf("GetNotesByUserID: userID %v: json encoding error", userID)
-// 		handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
-// 		return
-// 	}
-
-// 	slog.Info(fmt.Sprintf("Success: GetNotesByUserID: userID %v", userID))
-// }
-
-// func (nc *Handlers) GetNoteByUserIDAndNoteID(w http.ResponseWriter, r *http.Request) {
-// 	userID, ok := r.Context().Value(foundation.UserIDKey).(uuid.UUID)
-// 	if !ok {
-// 		logMsg := "GetNoteByUserIDandNoteID: invalid userID"
-// 		handleError(w, "", http.StatusBadRequest, logMsg)
-// 		return
-// 	}
-
-// 	noteID, err := strconv.Atoi(chi.URLParam(r, "noteID"))
-// 	if err != nil || noteID < 0 {
-// 		logMsg := fmt.Sprintf("GetNoteByUserIDandNoteID: invalid noteID %v", chi.URLParam(r, "noteID"))
-// 		handleError(w, "", http.StatusBadRequest, logMsg, "error", err)
-// 		return
-// 	}
-
-// 	notes, err := nc.notesSvc.GetNoteByUserIDAndNoteID(userID, noteID)
-// 	if err != nil {
-// 		logMsg := fmt.Sprintf("GetNoteByUserIDAndNoteID: userID %v noteID %v", userID, noteID)
-// 		handleError(w, "", http.StatusInternalServerError, logMsg)
-// 		return
-// 	}
-
-// 	if len(notes) == 0 {
-// 		logMsg := fmt.Sprintf("GetNoteByUserIDAndNoteID: userID %v noteID %v", userID, noteID)
-// 		handleError(w, "", http.StatusNotFound, logMsg, "error", "Not Found")
-// 		return
-// 	}
-
-// 	err = json.NewEncoder(w).Encode(notes)
-// 	if err != nil {
-// 		logMsg := fmt.Sprintf("GetNoteByUserIDAndNoteID: userID %v noteID %v: json encoding error", userID, noteID)
-// 		handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
-// 		return
-// 	}
-
-// 	slog.Info(fmt.Sprintf(
-// 		"Success: GetNoteByUserIDAndNoteID: userID %v noteID %v", userID, noteID,
-// 	))
-// }
-
-// func (nc *Handlers) GetAllNotes(w http.ResponseWriter, r *http.Request) {
-// 	notes, err := nc.notesSvc.GetAllNotes()
-// 	if err != nil {
-// 		http.Error(w, "", http.StatusInternalServerError)
-// 		slog.Error("GetAllNotes: DBError")
-// 		return
-// 	}
-
-// 	err = json.NewEncoder(w).Encode(notes)
-// 	if err != nil {
-// 		handleError(w, "", http.StatusInternalServerError, "GetAllNotes: json encoding error", "error", err)
-// 		return
-// 	}
-
-// 	slog.Info("Success: GetAllNotes")
-// }
+// GetAllNotes lists the notes of all users. It's meant for admins only.
+func (hdl *Handlers) GetAllNotes(w http.ResponseWriter, r *http.Request) {
+	notes, err := hdl.notesSvc.QueryAll(r.Context())
+	if err != nil {
+		handleError(w, "", http.StatusInternalServerError, "GetAllNotes", "error", err)
+		return
+	}
+
+	ret := make([]api.Note, 0, len(notes))
+	for _, n := range notes {
+		ret = append(ret, api.FromNote(n))
+	}
+
+	respond(w, http.StatusOK, ret, "GetAllNotes")
+}
+
+func respond(w http.ResponseWriter, status int, data any, logMsg string) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+	slog.Info("Success: " + logMsg)
+}
+
+func respondText(w http.ResponseWriter, contentType, body, logMsg string) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, body)
+	slog.Info("Success: " + logMsg)
+}
 
 func handleError(w http.ResponseWriter, errMsg string, status int, logMsg string, args ...any) {
 	http.Error(w, errMsg, status)
 	slog.Error(logMsg, args...)
 }
 
-func toUpdateNote(np api.NotePost, userID uuid.UUID) note.UpdateNote {
-	return note.UpdateNote{Title: note.NewTitle(np.Title), Content: note.NewContent(np.Content), UserID: userID}
+func toUpdateNote(np api.NotePost, userID uuid.UUID) (note.UpdateNote, error) {
+	un := note.UpdateNote{Title: note.NewTitle(np.Title), Content: note.NewContent(np.Content), UserID: userID}
+	if np.Format != "" {
+		var err error
+		if un.Format, err = note.ParseFormat(np.Format); err != nil {
+			return note.UpdateNote{}, err
+		}
+	}
+	return un, nil
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"net/http"
@@ -70,9 +71,9 @@ func Test_Create(t *testing.T) {
 				returnN := note.Note{ID: uuid.UUID{1}, Title: note.NewTitle(body.Title), Content: note.NewContent(body.Content), UserID: userID}
 				return mockNotesStoreParams{method: "Create", arguments: []any{updateN}, returnArguments: []any{returnN, nil}}
 			},
-			wantStatus: http.StatusAccepted,
+			wantStatus: http.StatusCreated,
 			wantBody: func(userID uuid.UUID, body api.NotePost) string {
-				return mustEncode(t, note.Note{ID: uuid.UUID{1}, Title: note.NewTitle(body.Title), Content: note.NewContent(body.Content), UserID: userID})
+				return mustEncode(t, api.Note{ID: uuid.UUID{1}.String(), Title: body.Title, Content: body.Content, UserID: userID.String()})
 			},
 			wantLogging: func(userID uuid.UUID, body api.NotePost) []string {
 				return []string{
@@ -166,561 +167,466 @@ func Test_Create(t *testing.T) {
 	}
 }
 
-// func TestGetAllNotes(t *testing.T) {
-// 	mNotesStore := &mockNotesSvc{}
-// 	hdl := notesgrp.NewHandlers(mNotesStore)
-// 	logBuf := &bytes.Buffer{}
-// 	log.SetOutput(logBuf)
-
-// 	type testCase struct {
-// 		name        string
-// 		mNSP        mockNotesStoreParams
-// 		wantStatus  int
-// 		wantBody    string
-// 		wantLogging []string
-// 		assertions  func(t *testing.T, rr *httptest.ResponseRecorder, wantStatus int, wantBody string, wL []string, mNSP mockNotesStoreParams)
-// 	}
-
-// 	testNotes := domain.Notes{
-// 		{NoteID: 1, UserID: uuid.New(), Note: "Note 1 user 1"},
-// 		{NoteID: 2, UserID: uuid.New(), Note: "Note 2 user 1"},
-// 		{NoteID: 3, UserID: uuid.New(), Note: "Note 1 user 2"},
-// 		{NoteID: 4, UserID: uuid.New(), Note: "Note 2 user 2"},
-// 	}
-// 	testCases := []testCase{
-
-// 		{
-// 			name:        "GetAllNotes Success",
-// 			mNSP:        mockNotesStoreParams{method: "GetAllNotes", returnArguments: []any{testNotes, nil}},
-// 			wantStatus:  http.StatusOK,
-// 			wantBody:    mustEncode(t, testNotes).String(),
-// 			wantLogging: []string{"Success: GetAllNotes"},
-// 			assertions: func(t *testing.T, rr *httptest.ResponseRecorder, wantStatus int, wantBody string, wL []string, callAssertion mockNotesStoreParams) {
-// 				assert.Equal(t, wantStatus, rr.Code)
-// 				assert.Equal(t, rr.Body.String(), wantBody)
-// 				for _, logMsg := range wL {
-// 					assert.Contains(t, logBuf.String(), logMsg)
-// 				}
-// 				mNotesStore.AssertCalled(t, callAssertion.method, callAssertion.arguments...)
-// 			},
-// 		},
-// 		{
-// 			name: "GetAllNotes Error DB",
-// 			mNSP: mockNotesStoreParams{
-// 				method:          "GetAllNotes",
-// 				returnArguments: []any{domain.Notes{}, errors.New("error notesStore.GetAllNotes")},
-// 			},
-// 			wantBody:    fmt.Sprintln(""),
-// 			wantStatus:  http.StatusInternalServerError,
-// 			wantLogging: []string{"ERROR", "GetAllNotes: DBError"},
-// 			assertions: func(t *testing.T, rr *httptest.ResponseRecorder, wantStatus int, wantBody string, wL []string, callAssertion mockNotesStoreParams) {
-// 				assert.Equal(t, wantStatus, rr.Code)
-// 				assert.Equal(t, rr.Body.String(), wantBody)
-// 				mNotesStore.AssertCalled(t, callAssertion.method, callAssertion.arguments...)
-// 				for _, logMsg := range wL {
-// 					assert.Contains(t, logBuf.String(), logMsg)
-// 				}
-// 			},
-// 		},
-// 	}
-
-// 	for _, tc := range testCases {
-// 		logBuf.Reset()
-// 		mNotesStore.Setup(tc.mNSP)
-// 		req := setupRequest(t, "GET", "/notes", nil, "", &bytes.Buffer{})
-// 		rr := httptest.NewRecorder()
-// 		hdl.GetAllNotes(rr, req)
-// 		tc.assertions(t, rr, tc.wantStatus, tc.wantBody, tc.wantLogging, tc.mNSP)
-// 	}
-// }
-
-// func TestGetNotesByUserID(t *testing.T) {
-// 	mNotesStore := &mockNotesSvc{}
-// 	hdl := notesgrp.NewHandlers(mNotesStore)
-// 	logBuf := &bytes.Buffer{}
-// 	log.SetOutput(logBuf)
-
-// 	type testCase struct {
-// 		name        string
-// 		userID      any
-// 		mNSP        func(userID any) mockNotesStoreParams
-// 		wantStatus  int
-// 		wantBody    func(userID any) string
-// 		wantLogging func(userID any) []string
-// 		assertions  func(t *testing.T, rr *httptest.ResponseRecorder, wantStatus int, wantBody string, wL []string, mNSP mockNotesStoreParams)
-// 	}
-
-// 	testCases := []testCase{
-// 		{
-// 			name:   "GetNotesByUserID success",
-// 			userID: uuid.New(),
-// 			mNSP: func(userID any) mockNotesStoreParams {
-// 				uid := userID.(uuid.UUID)
-// 				return mockNotesStoreParams{
-// 					method:    "GetNotesByUserID",
-// 					arguments: []any{userID},
-// 					returnArguments: []any{domain.Notes{
-// 						{NoteID: 1, UserID: uid, Note: "Note 1 user 1"},
-// 						{NoteID: 2, UserID: uid, Note: "Note 2 user 1"},
-// 					}, nil},
-// 				}
-// 			},
-// 			wantStatus: http.StatusOK,
-// 			wantBody: func(userID any) string {
-// 				uid := userID.(uuid.UUID)
-// 				return mustEncode(t, domain.Notes{
-// 					{NoteID: 1, UserID: uid, Note: "Note 1 user 1"},
-// 					{NoteID: 2, UserID: uid, Note: "Note 2 user 1"},
-// 				}).String()
-// 			},
-// 			wantLogging: func(userID any) []string {
-// 				return []string{
-// 					"INFO",
-// 					fmt.Sprintf("Success: GetNotesByUserID: userID %v", userID),
-// 				}
-// 			},
-// 			assertions: func(t *testing.T, rr *httptest.ResponseRecorder, wantStatus int, wantBody string, wL []string, mNSP mockNotesStoreParams) {
-// 				assert.Equal(t, wantStatus, rr.Code)
-// 				assert.Equal(t, wantBody, rr.Body.String())
-// 				mNotesStore.AssertCalled(t, mNSP.method, mNSP.arguments...)
-// 				for _, logMsg := range wL {
-// 					assert.Contains(t, logBuf.String(), logMsg)
-// 				}
-// 			},
-// 		},
-// 		{
-// 			name:       "GetNotesByUserID invalid userID",
-// 			userID:     123,
-// 			mNSP:       func(userID any) mockNotesStoreParams { return mockNotesStoreParams{} },
-// 			wantStatus: http.StatusBadRequest,
-// 			wantBody:   func(userID any) string { return fmt.Sprintln("") },
-// 			wantLogging: func(userID any) []string {
-// 				return []string{
-// 					"ERROR",
-// 					"GetNotesByUserID: invalid userID",
-// 				}
-// 			},
-// 			assertions: func(t *testing.T, rr *httptest.ResponseRecorder, wantStatus int, wantBody string, wL []string, mNSP mockNotesStoreParams) {
-// 				assert.Equal(t, wantStatus, rr.Code)
-// 				assert.Equal(t, wantBody, rr.Body.String())
-// 				mNotesStore.AssertNotCalled(t, "GetNotesByUserID")
-// 				for _, logMsg := range wL {
-// 					assert.Contains(t, logBuf.String(), logMsg)
-// 				}
-// 			},
-// 		},
-// 		{
-// 			name:   "GetNotesByUserID DBError",
-// 			userID: uuid.New(),
-// 			mNSP: func(userID any) mockNotesStoreParams {
-// 				return mockNotesStoreParams{
-// 					method:          "GetNotesByUserID",
-// 					arguments:       []any{userID},
-// 					returnArguments: []any{domain.Notes{}, errors.New("error notesStore.GetNotesByUserID")},
-// 				}
-// 			},
-// 			wantStatus: http.StatusInternalServerError,
-// 			wantBody:   func(userID any) string { return fmt.Sprintln("") },
-// 			wantLogging: func(userID any) []string {
-// 				return []string{
-// 					"ERROR",
-// 					fmt.Sprintf("GetNotesByUserID: userID %v", userID),
-// 					"error notesStore.GetNotesByUserID",
-// 				}
-// 			},
-// 			assertions: func(t *testing.T, rr *httptest.ResponseRecorder, wantStatus int, wantBody string, wL []string, callAssertion mockNotesStoreParams) {
-// 				assert.Equal(t, wantStatus, rr.Code)
-// 				assert.Equal(t, wantBody, rr.Body.String())
-// 				mNotesStore.AssertCalled(t, callAssertion.method, callAssertion.arguments...)
-// 				for _, logMsg := range wL {
-// 					assert.Contains(t, logBuf.String(), logMsg)
-// 				}
-// 			},
-// 		},
-// 	}
-
-// 	for _, tc := range testCases {
-// 		logBuf.Reset()
-// 		mNotesStore.Setup(tc.mNSP(tc.userID))
-// 		req := setupRequest(t, "GET", "/users/notes", tc.userID, "", &bytes.Buffer{})
-// 		rr := httptest.NewRecorder()
-// 		hdl.GetNotesByUserID(rr, req)
-// 		tc.assertions(t, rr, tc.wantStatus, tc.wantBody(tc.userID), tc.wantLogging(tc.userID), tc.mNSP(tc.userID))
-// 	}
-// }
-
-// func TestGetNoteByUserIDandNoteID(t *testing.T) {
-// 	mNotesStore := &mockNotesSvc{}
-// 	hdl := notesgrp.NewHandlers(mNotesStore)
-// 	logBuf := &bytes.Buffer{}
-// 	log.SetOutput(logBuf)
-
-// 	type testCase struct {
-// 		name        string
-// 		userID      any
-// 		noteID      string
-// 		mNSP        func(userID any, noteID string) mockNotesStoreParams
-// 		wantStatus  int
-// 		wantBody    func(userID any, noteID string) string
-// 		wantLogging func(userID any, noteID string) []string
-// 		assertions  func(t *testing.T, rr *httptest.ResponseRecorder, wantStatus int, wantBody string, wL []string, mNSP mockNotesStoreParams)
-// 	}
-
-// 	testCases := []testCase{
-// 		{
-// 			name:   "GetNoteByUserIDandNoteID success",
-// 			userID: uuid.New(),
-// 			noteID: "1",
-// 			mNSP: func(userID any, noteID string) mockNotesStoreParams {
-// 				uid := userID.(uuid.UUID)
-// 				nid := mustAtoi(t, noteID)
-// 				return mockNotesStoreParams{
-// 					method:    "GetNoteByUserIDAndNoteID",
-// 					arguments: []any{uid, nid},
-// 					returnArguments: []any{
-// 						domain.Notes{{UserID: uid, NoteID: nid, Note: "test note"}},
-// 						nil,
-// 					},
-// 				}
-// 			},
-// 			wantStatus: http.StatusOK,
-// 			wantBody: func(userID any, noteID string) string {
-// 				uid := userID.(uuid.UUID)
-// 				nid := mustAtoi(t, noteID)
-// 				return mustEncode(t, domain.Notes{{UserID: uid, NoteID: nid, Note: "test note"}}).String()
-// 			},
-// 			wantLogging: func(userID any, noteID string) []string {
-// 				return []string{fmt.Sprintf("Success: GetNoteByUserIDAndNoteID: userID %v noteID %v", userID, noteID)}
-// 			},
-// 			assertions: func(t *testing.T, rr *httptest.ResponseRecorder, wantStatus int, wantBody string, wL []string, callAssertion mockNotesStoreParams) {
-// 				assert.Equal(t, wantStatus, rr.Code)
-// 				assert.Equal(t, wantBody, rr.Body.String())
-// 				mNotesStore.AssertCalled(t, callAssertion.method, callAssertion.arguments...)
-// 				for _, logMsg := range wL {
-// 					assert.Contains(t, logBuf.String(), logMsg)
-// 				}
-// 			},
-// 		},
-// 		{
-// 			name:       "GetNoteByUserIDandNoteID invalid userID",
-// 			userID:     "invalid userID",
-// 			noteID:     "1",
-// 			mNSP:       func(userID any, noteID string) mockNotesStoreParams { return mockNotesStoreParams{} },
-// 			wantStatus: http.StatusBadRequest,
-// 			wantBody:   func(userID any, noteID string) string { return fmt.Sprintln("") },
-// 			wantLogging: func(userID any, noteID string) []string {
-// 				return []string{
-// 					"ERROR",
-// 					"GetNoteByUserIDandNoteID: invalid userID",
-// 				}
-// 			},
-// 			assertions: func(t *testing.T, rr *httptest.ResponseRecorder, wantStatus int, wantBody string, wL []string, mNSP mockNotesStoreParams) {
-// 				assert.Equal(t, wantStatus, rr.Code)
-// 				assert.Equal(t, wantBody, rr.Body.String())
-// 				mNotesStore.AssertNotCalled(t, "GetNotesByUserID")
-// 				for _, logMsg := range wL {
-// 					assert.Contains(t, logBuf.String(), logMsg)
-// 				}
-// 			},
-// 		},
-// 		{
-// 			name:       "GetNoteByUserIDandNoteID invalid noteID",
-// 			userID:     uuid.New(),
-// 			noteID:     "-1",
-// 			mNSP:       func(userID any, noteID string) mockNotesStoreParams { return mockNotesStoreParams{} },
-// 			wantStatus: http.StatusBadRequest,
-// 			wantBody:   func(userID any, noteID string) string { return fmt.Sprintln("") },
-// 			wantLogging: func(userID any, noteID string) []string {
-// 				return []string{
-// 					"ERROR",
-// 					"GetNoteByUserIDandNoteID: invalid noteID",
-// 				}
-// 			},
-// 			assertions: func(t *testing.T, rr *httptest.ResponseRecorder, wantStatus int, wantBody string, wL []string, mNSP mockNotesStoreParams) {
-// 				assert.Equal(t, wantStatus, rr.Code)
-// 				assert.Equal(t, wantBody, rr.Body.String())
-// 				mNotesStore.AssertNotCalled(t, "GetNotesByUserID")
-// 				for _, logMsg := range wL {
-// 					assert.Contains(t, logBuf.String(), logMsg)
-// 				}
-// 			},
-// 		},
-// 		{
-// 			name:       "GetNoteByUserIDandNoteID invalid noteID",
-// 			userID:     uuid.New(),
-// 			noteID:     "invalid noteID",
-// 			mNSP:       func(userID any, noteID string) mockNotesStoreParams { return mockNotesStoreParams{} },
-// 			wantStatus: http.StatusBadRequest,
-// 			wantBody:   func(userID any, noteID string) string { return fmt.Sprintln("") },
-// 			wantLogging: func(userID any, noteID string) []string {
-// 				return []string{
-// 					"ERROR",
-// 					"GetNoteByUserIDandNoteID: invalid noteID",
-// 				}
-// 			},
-// 			assertions: func(t *testing.T, rr *httptest.ResponseRecorder, wantStatus int, wantBody string, wL []string, mNSP mockNotesStoreParams) {
-// 				assert.Equal(t, wantStatus, rr.Code)
-// 				assert.Equal(t, wantBody, rr.Body.String())
-// 				mNotesStore.AssertNotCalled(t, "GetNotesByUserID")
-// 				for _, logMsg := range wL {
-// 					assert.Contains(t, logBuf.String(), logMsg)
-// 				}
-// 			},
-// 		},
-
-// 		{
-// 			name:   "GetNoteByUserIDandNoteID DBError",
-// 			userID: uuid.New(),
-// 			noteID: "1",
-// 			mNSP: func(userID any, noteID string) mockNotesStoreParams {
-// 				uid := userID.(uuid.UUID)
-// 				nid := mustAtoi(t, noteID)
-// 				return mockNotesStoreParams{
-// 					method:    "GetNoteByUserIDAndNoteID",
-// 					arguments: []any{uid, nid},
-// 					returnArguments: []any{
-// 						domain.Notes{},
-// 						errors.New("error notesStore.GetNoteByUserIDAndNoteID"),
-// 					},
-// 				}
-// 			},
-// 			wantStatus: http.StatusInternalServerError,
-// 			wantBody:   func(userID any, noteID string) string { return fmt.Sprintln("") },
-// 			wantLogging: func(userID any, noteID string) []string {
-// 				return []string{
-// 					"ERROR",
-// 					fmt.Sprintf("GetNoteByUserIDAndNoteID: userID %v noteID %v", userID, noteID),
-// 				}
-// 			},
-// 			assertions: func(t *testing.T, rr *httptest.ResponseRecorder, wantStatus int, wantBody string, wL []string, callAssertion mockNotesStoreParams) {
-// 				assert.Equal(t, wantStatus, rr.Code)
-// 				assert.Equal(t, wantBody, rr.Body.String())
-// 				mNotesStore.AssertCalled(t, callAssertion.method, callAssertion.arguments...)
-// 				for _, logMsg := range wL {
-// 					assert.Contains(t, logBuf.String(), logMsg)
-// 				}
-// 			},
-// 		},
-// 		{
-// 			name:   "GetNoteByUserIDandNoteID Not found if empty return from DB",
-// 			userID: uuid.New(),
-// 			noteID: "1",
-// 			mNSP: func(userID any, noteID string) mockNotesStoreParams {
-// 				uid := userID.(uuid.UUID)
-// 				nid := mustAtoi(t, noteID)
-// 				return mockNotesStoreParams{
-// 					method:          "GetNoteByUserIDAndNoteID",
-// 					arguments:       []any{uid, nid},
-// 					returnArguments: []any{domain.Notes{}, nil},
-// 				}
-// 			},
-// 			wantStatus: http.StatusNotFound,
-// 			wantBody:   func(userID any, noteID string) string { return fmt.Sprintln("") },
-// 			wantLogging: func(userID any, noteID string) []string {
-// 				return []string{
-// 					"ERROR",
-// 					fmt.Sprintf("GetNoteByUserIDAndNoteID: userID %v noteID %v", userID, noteID),
-// 					"Not Found",
-// 				}
-// 			},
-// 			assertions: func(t *testing.T, rr *httptest.ResponseRecorder, wantStatus int, wantBody string, wL []string, callAssertion mockNotesStoreParams) {
-// 				assert.Equal(t, wantStatus, rr.Code)
-// 				assert.Equal(t, wantBody, rr.Body.String())
-// 				mNotesStore.AssertCalled(t, callAssertion.method, callAssertion.arguments...)
-// 				for _, logMsg := range wL {
-// 					assert.Contains(t, logBuf.String(), logMsg)
-// 				}
-// 			},
-// 		},
-// 	}
-
-// 	for _, tc := range testCases {
-// 		logBuf.Reset()
-// 		mNotesStore.Setup(tc.mNSP(tc.userID, tc.noteID))
-
-// 		req := setupRequest(t, "GET", "/users/notes/{noteID}", tc.userID, tc.noteID, &bytes.Buffer{})
-// 		rr := httptest.NewRecorder()
-// 		hdl.GetNoteByUserIDAndNoteID(rr, req)
-
-// 		tc.assertions(t, rr, tc.wantStatus, tc.wantBody(tc.userID, tc.noteID), tc.wantLogging(tc.userID, tc.noteID), tc.mNSP(tc.userID, tc.noteID))
-// 	}
-// }
-
-// func TestDelete(t *testing.T) {
-// 	mNotesStore := &mockNotesSvc{}
-// 	hdl := notesgrp.NewHandlers(mNotesStore)
-// 	logBuf := &bytes.Buffer{}
-// 	log.SetOutput(logBuf)
-
-// 	type testCase struct {
-// 		name        string
-// 		userID      any
-// 		noteID      string
-// 		mNSP        func(userID any, noteID string) mockNotesStoreParams
-// 		wantStatus  int
-// 		wantBody    string
-// 		wantLogging func(userID any, noteID string) []string
-// 		assertions  func(t *testing.T, rr *httptest.ResponseRecorder, wantStatus int, wantBody string, wL []string, mNSP mockNotesStoreParams)
-// 	}
-
-// 	testCases := []testCase{
-// 		{
-// 			name:   "Success Deletion",
-// 			userID: uuid.New(),
-// 			noteID: "1",
-// 			mNSP: func(userID any, noteID string) mockNotesStoreParams {
-// 				uid := userID.(uuid.UUID)
-// 				nid := mustAtoi(t, noteID)
-// 				return mockNotesStoreParams{
-// 					method:          "Delete",
-// 					arguments:       []any{uid, nid},
-// 					returnArguments: []any{nil},
-// 				}
-// 			},
-// 			wantStatus: http.StatusNoContent,
-// 			wantBody:   "",
-// 			wantLogging: func(userID any, noteID string) []string {
-// 				return []string{
-// 					"INFO",
-// 					fmt.Sprintf("Success: Delete: userID %v noteID %v", userID, noteID),
-// 				}
-// 			},
-// 			assertions: func(t *testing.T, rr *httptest.ResponseRecorder, wantStatus int, wantBody string, wL []string, callAssertion mockNotesStoreParams) {
-// 				assert.Equal(t, wantStatus, rr.Code)
-// 				assert.Equal(t, wantBody, rr.Body.String())
-// 				mNotesStore.AssertCalled(t, callAssertion.method, callAssertion.arguments...)
-// 				for _, logMsg := range wL {
-// 					assert.Contains(t, logBuf.String(), logMsg)
-// 				}
-// 			},
-// 		},
-// 		{
-// 			name:       "Delete invalid userID",
-// 			userID:     "invalid userID",
-// 			noteID:     "1",
-// 			mNSP:       func(userID any, noteID string) mockNotesStoreParams { return mockNotesStoreParams{} },
-// 			wantStatus: http.StatusBadRequest,
-// 			wantBody:   fmt.Sprintln(""),
-// 			wantLogging: func(userID any, noteID string) []string {
-// 				return []string{
-// 					"ERROR",
-// 					"Delete: invalid userID",
-// 				}
-// 			},
-// 			assertions: func(t *testing.T, rr *httptest.ResponseRecorder, wantStatus int, wantBody string, wL []string, mNSP mockNotesStoreParams) {
-// 				assert.Equal(t, wantStatus, rr.Code)
-// 				assert.Equal(t, wantBody, rr.Body.String())
-// 				mNotesStore.AssertNotCalled(t, "Delete")
-// 				for _, logMsg := range wL {
-// 					assert.Contains(t, logBuf.String(), logMsg)
-// 				}
-// 			},
-// 		},
-// 		{
-// 			name:       "Delete invalid noteID",
-// 			userID:     uuid.New(),
-// 			noteID:     "-1",
-// 			mNSP:       func(userID any, noteID string) mockNotesStoreParams { return mockNotesStoreParams{} },
-// 			wantStatus: http.StatusBadRequest,
-// 			wantBody:   fmt.Sprintln(""),
-// 			wantLogging: func(userID any, noteID string) []string {
-// 				return []string{
-// 					"ERROR",
-// 					"Delete: invalid noteID",
-// 				}
-// 			},
-// 			assertions: func(t *testing.T, rr *httptest.ResponseRecorder, wantStatus int, wantBody string, wL []string, mNSP mockNotesStoreParams) {
-// 				assert.Equal(t, wantStatus, rr.Code)
-// 				assert.Equal(t, wantBody, rr.Body.String())
-// 				mNotesStore.AssertNotCalled(t, "Delete")
-// 				for _, logMsg := range wL {
-// 					assert.Contains(t, logBuf.String(), logMsg)
-// 				}
-// 			},
-// 		},
-// 		{
-// 			name:       "Delete invalid noteID",
-// 			userID:     uuid.New(),
-// 			noteID:     "invalid noteID",
-// 			mNSP:       func(userID any, noteID string) mockNotesStoreParams { return mockNotesStoreParams{} },
-// 			wantStatus: http.StatusBadRequest,
-// 			wantBody:   fmt.Sprintln(""),
-// 			wantLogging: func(userID any, noteID string) []string {
-// 				return []string{
-// 					"ERROR",
-// 					"Delete: invalid noteID",
-// 				}
-// 			},
-// 			assertions: func(t *testing.T, rr *httptest.ResponseRecorder, wantStatus int, wantBody string, wL []string, mNSP mockNotesStoreParams) {
-// 				assert.Equal(t, wantStatus, rr.Code)
-// 				assert.Equal(t, wantBody, rr.Body.String())
-// 				mNotesStore.AssertNotCalled(t, "Delete")
-// 				for _, logMsg := range wL {
-// 					assert.Contains(t, logBuf.String(), logMsg)
-// 				}
-// 			},
-// 		},
-// 		{
-// 			name:   "Delete DBError",
-// 			userID: uuid.New(),
-// 			noteID: "1",
-// 			mNSP: func(userID any, noteID string) mockNotesStoreParams {
-// 				uid := userID.(uuid.UUID)
-// 				nid := mustAtoi(t, noteID)
-// 				return mockNotesStoreParams{
-// 					method:          "Delete",
-// 					arguments:       []any{uid, nid},
-// 					returnArguments: []any{errors.New("error notesStore.Delete")},
-// 				}
-// 			},
-// 			wantStatus: http.StatusInternalServerError,
-// 			wantBody:   fmt.Sprintln(""),
-// 			wantLogging: func(userID any, noteID string) []string {
-// 				return []string{
-// 					"ERROR",
-// 					"Delete: DBError",
-// 				}
-// 			},
-// 			assertions: func(t *testing.T, rr *httptest.ResponseRecorder, wantStatus int, wantBody string, wL []string, mNSP mockNotesStoreParams) {
-// 				assert.Equal(t, wantStatus, rr.Code)
-// 				assert.Equal(t, wantBody, rr.Body.String())
-// 				mNotesStore.AssertNotCalled(t, "Delete")
-// 				for _, logMsg := range wL {
-// 					assert.Contains(t, logBuf.String(), logMsg)
-// 				}
-// 			},
-// 		},
-// 	}
-
-// 	for _, tc := range testCases {
-// 		logBuf.Reset()
-// 		mNotesStore.Setup(tc.mNSP(tc.userID, tc.noteID))
-
-// 		req := setupRequest(t, "DELETE", "/users/notes/{noteID}", tc.userID, tc.noteID, &bytes.Buffer{})
-// 		rr := httptest.NewRecorder()
-// 		hdl.Delete(rr, req)
-
-// 		tc.assertions(t, rr, tc.wantStatus, tc.wantBody, tc.wantLogging(tc.userID, tc.noteID), tc.mNSP(tc.userID, tc.noteID))
-// 	}
-// }
-
-// // WithUrlParam returns a pointer to a request object with the given URL params
-// // added to a new chi.Context object.
-// type Params map[string]string
-
-// func WithUrlParams(r *http.Request, params Params) *http.Request {
-// 	chiCtx := chi.NewRouteContext()
-// 	req := r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, chiCtx))
-// 	for key, value := range params {
-// 		chiCtx.URLParams.Add(key, value)
-// 	}
-// 	return req
-// }
-
-// func mustAtoi(t *testing.T, s string) int {
-// 	i, err := strconv.Atoi(s)
-// 	if err != nil {
-// 		t.Fatal()
-// 	}
-// 	return i
-// }
+func Test_GetAllNotes(t *testing.T) {
+	mNotesSvc := &mockNotesSvc{}
+	hdl := notesgrp.NewHandlers(mNotesSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	notes := []note.Note{
+		{ID: uuid.UUID{1}, Title: note.NewTitle("title 1"), Content: note.NewContent("content 1"), UserID: uuid.UUID{1}},
+		{ID: uuid.UUID{2}, Title: note.NewTitle("title 2"), Content: note.NewContent("content 2"), UserID: uuid.UUID{2}},
+	}
+
+	testCases := []struct {
+		name        string
+		mNSP        mockNotesStoreParams
+		wantStatus  int
+		wantBody    string
+		wantLogging []string
+	}{
+		{
+			name:       "GetAllNotes success",
+			mNSP:       mockNotesStoreParams{method: "QueryAll", returnArguments: []any{notes, nil}},
+			wantStatus: http.StatusOK,
+			wantBody: mustEncode(t, []api.Note{
+				{ID: uuid.UUID{1}.String(), Title: "title 1", Content: "content 1", UserID: uuid.UUID{1}.String()},
+				{ID: uuid.UUID{2}.String(), Title: "title 2", Content: "content 2", UserID: uuid.UUID{2}.String()},
+			}),
+			wantLogging: []string{"INFO", "Success: GetAllNotes"},
+		},
+		{
+			name:        "GetAllNotes service error",
+			mNSP:        mockNotesStoreParams{method: "QueryAll", returnArguments: []any{[]note.Note{}, errors.New("error notesSvc.QueryAll")}},
+			wantStatus:  http.StatusInternalServerError,
+			wantBody:    fmt.Sprintln(""),
+			wantLogging: []string{"ERROR", "GetAllNotes", "error notesSvc.QueryAll"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logBuf.Reset()
+			mNotesSvc.Setup(tc.mNSP)
+			req := setupRequest(t, http.MethodGet, "/admin/notes", uuid.New())
+			rr := httptest.NewRecorder()
+
+			hdl.GetAllNotes(rr, req)
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, tc.wantBody, rr.Body.String())
+			for _, logMsg := range tc.wantLogging {
+				assert.Contains(t, logBuf.String(), logMsg)
+			}
+		})
+	}
+}
+
+func withNote(req *http.Request, n note.Note) *http.Request {
+	ctx := context.WithValue(req.Context(), foundation.NoteKey, n)
+	return req.WithContext(ctx)
+}
+
+func Test_Query(t *testing.T) {
+	mNotesSvc := &mockNotesSvc{}
+	hdl := notesgrp.NewHandlers(mNotesSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	userID := uuid.New()
+	notes := []note.Note{
+		{ID: uuid.UUID{1}, Title: note.NewTitle("title 1"), Content: note.NewContent("content 1"), UserID: userID},
+		{ID: uuid.UUID{2}, Title: note.NewTitle("title 2"), Content: note.NewContent("content 2"), UserID: userID},
+	}
+
+	testCases := []struct {
+		name       string
+		target     string
+		mNSP       *mockNotesStoreParams
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:   "Query with defaults",
+			target: "/notes",
+			mNSP: &mockNotesStoreParams{
+				method:          "Query",
+				arguments:       []any{userID, note.QueryFilter{}, note.OrderBy{}, note.Page{}},
+				returnArguments: []any{note.NotesPage{Notes: notes}, nil},
+			},
+			wantStatus: http.StatusOK,
+			wantBody: mustEncode(t, api.NotesPage{Notes: []api.Note{
+				{ID: uuid.UUID{1}.String(), Title: "title 1", Content: "content 1", UserID: userID.String()},
+				{ID: uuid.UUID{2}.String(), Title: "title 2", Content: "content 2", UserID: userID.String()},
+			}}),
+		},
+		{
+			name:   "Query passes paging, order and filter",
+			target: "/notes?limit=1&cursor=abc&order=updated_at.desc&title_contains=title",
+			mNSP: &mockNotesStoreParams{
+				method: "Query",
+				arguments: []any{
+					userID,
+					note.QueryFilter{TitleContains: "title"},
+					note.OrderBy{Field: note.OrderByUpdatedAt, Desc: true},
+					note.Page{Limit: 1, Cursor: "abc"},
+				},
+				returnArguments: []any{note.NotesPage{Notes: notes[:1], NextCursor: "next"}, nil},
+			},
+			wantStatus: http.StatusOK,
+			wantBody: mustEncode(t, api.NotesPage{
+				Notes:      []api.Note{{ID: uuid.UUID{1}.String(), Title: "title 1", Content: "content 1", UserID: userID.String()}},
+				NextCursor: "next",
+			}),
+		},
+		{
+			name:   "Query with invalid cursor",
+			target: "/notes?cursor=abc",
+			mNSP: &mockNotesStoreParams{
+				method:          "Query",
+				arguments:       []any{userID, note.QueryFilter{}, note.OrderBy{}, note.Page{Cursor: "abc"}},
+				returnArguments: []any{note.NotesPage{}, note.ErrInvalidCursor},
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   fmt.Sprintln("invalid cursor"),
+		},
+		{
+			name:   "Query service error",
+			target: "/notes",
+			mNSP: &mockNotesStoreParams{
+				method:          "Query",
+				arguments:       []any{userID, note.QueryFilter{}, note.OrderBy{}, note.Page{}},
+				returnArguments: []any{note.NotesPage{}, errors.New("error notesSvc.Query")},
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   fmt.Sprintln(""),
+		},
+		{name: "Query with invalid limit", target: "/notes?limit=abc", wantStatus: http.StatusBadRequest, wantBody: fmt.Sprintln("invalid limit")},
+		{name: "Query with negative limit", target: "/notes?limit=-1", wantStatus: http.StatusBadRequest, wantBody: fmt.Sprintln("invalid limit")},
+		{name: "Query with invalid order", target: "/notes?order=content", wantStatus: http.StatusBadRequest, wantBody: fmt.Sprintln("invalid order")},
+		{
+			name:   "Query passes normalized tags",
+			target: "/notes?tag=Work&tag=to+do&tag=work&tag_mode=any",
+			mNSP: &mockNotesStoreParams{
+				method:          "Query",
+				arguments:       []any{userID, note.QueryFilter{Tags: note.Tags{"to-do", "work"}, TagMode: note.TagMatchAny}, note.OrderBy{}, note.Page{}},
+				returnArguments: []any{note.NotesPage{Notes: notes[:1]}, nil},
+			},
+			wantStatus: http.StatusOK,
+			wantBody: mustEncode(t, api.NotesPage{
+				Notes: []api.Note{{ID: uuid.UUID{1}.String(), Title: "title 1", Content: "content 1", UserID: userID.String()}},
+			}),
+		},
+		{name: "Query with invalid tag", target: "/notes?tag=a%2Bb", wantStatus: http.StatusBadRequest, wantBody: fmt.Sprintln("invalid tag")},
+		{name: "Query with invalid tag mode", target: "/notes?tag=a&tag_mode=none", wantStatus: http.StatusBadRequest, wantBody: fmt.Sprintln("invalid tag mode")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logBuf.Reset()
+			mNotesSvc.Reset()
+			if tc.mNSP != nil {
+				mNotesSvc.Setup(*tc.mNSP)
+			}
+			req := setupRequest(t, http.MethodGet, tc.target, userID)
+			rr := httptest.NewRecorder()
+
+			hdl.Query(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, tc.wantBody, rr.Body.String())
+			if tc.mNSP != nil {
+				mNotesSvc.AssertCalled(t, tc.mNSP.method, tc.mNSP.arguments...)
+			} else {
+				mNotesSvc.AssertNotCalled(t, "Query")
+			}
+		})
+	}
+}
+
+func Test_GetNoteByID(t *testing.T) {
+	hdl := notesgrp.NewHandlers(&mockNotesSvc{})
+	n := note.Note{ID: uuid.UUID{1}, Title: note.NewTitle("title"), Content: note.NewContent("*content*"), Format: note.FormatMarkdown, UserID: uuid.UUID{2}}
+
+	testCases := []struct {
+		name            string
+		accept          string
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "No Accept header gets JSON",
+			accept:          "",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody:        mustEncode(t, api.Note{ID: n.ID.String(), Title: "title", Content: "*content*", Format: "markdown", UserID: n.UserID.String()}),
+		},
+		{
+			name:            "HTML",
+			accept:          "text/html,application/xhtml+xml,*/*;q=0.8",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/html; charset=utf-8",
+			wantBody:        "<p><em>content</em></p>\n",
+		},
+		{
+			name:            "Markdown",
+			accept:          "text/markdown",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/markdown; charset=utf-8",
+			wantBody:        "*content*",
+		},
+		{
+			name:       "Not acceptable",
+			accept:     "image/png",
+			wantStatus: http.StatusNotAcceptable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := withNote(setupRequest(t, http.MethodGet, "/notes/"+n.ID.String(), n.UserID), n)
+			req.Header.Set("Accept", tc.accept)
+			rr := httptest.NewRecorder()
+			hdl.GetNoteByID(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, "Accept", rr.Header().Get("Vary"))
+			if tc.wantStatus == http.StatusOK {
+				assert.Equal(t, tc.wantContentType, rr.Header().Get("Content-Type"))
+				assert.Equal(t, tc.wantBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+func Test_Update(t *testing.T) {
+	mNotesSvc := &mockNotesSvc{}
+	hdl := notesgrp.NewHandlers(mNotesSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	userID := uuid.UUID{2}
+	// a user the note has been shared with, who becomes the author of the revision
+	editorID := uuid.UUID{3}
+	n := note.Note{ID: uuid.UUID{1}, Title: note.NewTitle("title"), Content: note.NewContent("content"), UserID: userID}
+	newTitle := "new title"
+
+	testCases := []struct {
+		name       string
+		method     string
+		handler    http.HandlerFunc
+		body       string
+		mNSP       mockNotesStoreParams
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:    "Put replaces title and content",
+			method:  http.MethodPut,
+			handler: hdl.Put,
+			body:    mustEncode(t, api.NotePost{Title: "new title", Content: ""}),
+			mNSP: mockNotesStoreParams{
+				method:    "Update",
+				arguments: []any{n, note.UpdateNote{Title: note.NewTitle("new title"), Content: note.NewContent(""), UserID: editorID}},
+				returnArguments: []any{
+					note.Note{ID: n.ID, Title: note.NewTitle("new title"), Content: note.NewContent(""), UserID: userID}, nil,
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   mustEncode(t, api.Note{ID: n.ID.String(), Title: "new title", Content: "", UserID: userID.String()}),
+		},
+		{
+			name:    "Patch leaves missing fields untouched",
+			method:  http.MethodPatch,
+			handler: hdl.Patch,
+			body:    mustEncode(t, api.NotePatch{Title: &newTitle}),
+			mNSP: mockNotesStoreParams{
+				method:    "Update",
+				arguments: []any{n, note.UpdateNote{Title: note.NewTitle("new title"), UserID: editorID}},
+				returnArguments: []any{
+					note.Note{ID: n.ID, Title: note.NewTitle("new title"), Content: note.NewContent("content"), UserID: userID}, nil,
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   mustEncode(t, api.Note{ID: n.ID.String(), Title: "new title", Content: "content", UserID: userID.String()}),
+		},
+		{
+			name:    "Patch changes the format",
+			method:  http.MethodPatch,
+			handler: hdl.Patch,
+			body:    `{"format": "markdown"}`,
+			mNSP: mockNotesStoreParams{
+				method:    "Update",
+				arguments: []any{n, note.UpdateNote{Format: note.FormatMarkdown, UserID: editorID}},
+				returnArguments: []any{
+					note.Note{ID: n.ID, Title: n.Title, Content: n.Content, Format: note.FormatMarkdown, UserID: userID}, nil,
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   mustEncode(t, api.Note{ID: n.ID.String(), Title: "title", Content: "content", Format: "markdown", UserID: userID.String()}),
+		},
+		{
+			name:    "Update service error",
+			method:  http.MethodPatch,
+			handler: hdl.Patch,
+			body:    mustEncode(t, api.NotePatch{Title: &newTitle}),
+			mNSP: mockNotesStoreParams{
+				method:          "Update",
+				arguments:       []any{n, note.UpdateNote{Title: note.NewTitle("new title"), UserID: editorID}},
+				returnArguments: []any{note.Note{}, errors.New("error notesSvc.Update")},
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   fmt.Sprintln(""),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mNotesSvc.Setup(tc.mNSP)
+			req := httptest.NewRequest(tc.method, "/notes/"+n.ID.String(), strings.NewReader(tc.body))
+			req = withNote(req.WithContext(context.WithValue(req.Context(), foundation.UserIDKey, editorID)), n)
+			rr := httptest.NewRecorder()
+
+			tc.handler(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, tc.wantBody, rr.Body.String())
+			mNotesSvc.AssertCalled(t, tc.mNSP.method, tc.mNSP.arguments...)
+		})
+	}
+
+	t.Run("Invalid body", func(t *testing.T) {
+		mNotesSvc.Reset()
+		for _, h := range []http.HandlerFunc{hdl.Put, hdl.Patch} {
+			logBuf.Reset()
+			req := withNote(httptest.NewRequest(http.MethodPut, "/notes/"+n.ID.String(), strings.NewReader("invalid body")), n)
+			rr := httptest.NewRecorder()
+
+			h(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Contains(t, logBuf.String(), "invalid body")
+			mNotesSvc.AssertNotCalled(t, "Update")
+		}
+	})
+
+	t.Run("Invalid format", func(t *testing.T) {
+		mNotesSvc.Reset()
+		for _, h := range []http.HandlerFunc{hdl.Put, hdl.Patch} {
+			req := withNote(httptest.NewRequest(http.MethodPut, "/notes/"+n.ID.String(), strings.NewReader(`{"format": "rtf"}`)), n)
+			rr := httptest.NewRecorder()
+
+			h(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, "invalid format\n", rr.Body.String())
+			mNotesSvc.AssertNotCalled(t, "Update")
+		}
+	})
+}
+
+func Test_Delete(t *testing.T) {
+	mNotesSvc := &mockNotesSvc{}
+	hdl := notesgrp.NewHandlers(mNotesSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	n := note.Note{ID: uuid.UUID{1}, Title: note.NewTitle("title"), Content: note.NewContent("content"), UserID: uuid.UUID{2}}
+
+	testCases := []struct {
+		name        string
+		mNSP        mockNotesStoreParams
+		wantStatus  int
+		wantLogging []string
+	}{
+		{
+			name:        "Delete success",
+			mNSP:        mockNotesStoreParams{method: "Delete", arguments: []any{n.ID, 0}, returnArguments: []any{nil}},
+			wantStatus:  http.StatusNoContent,
+			wantLogging: []string{"INFO", fmt.Sprintf("Success: Delete: noteID %v", n.ID)},
+		},
+		{
+			name:        "Delete service error",
+			mNSP:        mockNotesStoreParams{method: "Delete", arguments: []any{n.ID, 0}, returnArguments: []any{errors.New("error notesSvc.Delete")}},
+			wantStatus:  http.StatusInternalServerError,
+			wantLogging: []string{"ERROR", fmt.Sprintf("Delete: noteID %v", n.ID), "error notesSvc.Delete"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logBuf.Reset()
+			mNotesSvc.Setup(tc.mNSP)
+			req := withNote(setupRequest(t, http.MethodDelete, "/notes/"+n.ID.String(), n.UserID), n)
+			rr := httptest.NewRecorder()
+
+			hdl.Delete(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			mNotesSvc.AssertCalled(t, tc.mNSP.method, tc.mNSP.arguments...)
+			for _, logMsg := range tc.wantLogging {
+				assert.Contains(t, logBuf.String(), logMsg)
+			}
+		})
+	}
+}
+
+func Test_Search(t *testing.T) {
+	mNotesSvc := &mockNotesSvc{}
+	hdl := notesgrp.NewHandlers(mNotesSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	userID := uuid.UUID{1}
+	results := []note.SearchResult{
+		{Note: note.Note{ID: uuid.UUID{1}, Title: note.NewTitle("title"), Content: note.NewContent("content"), UserID: userID}, Rank: 0.5, Snippet: "<b>title</b> content"},
+	}
+
+	testCases := []struct {
+		name       string
+		target     string
+		mNSP       mockNotesStoreParams
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Search success",
+			target:     "/notes/search?q=title",
+			mNSP:       mockNotesStoreParams{method: "Search", arguments: []any{userID, "title"}, returnArguments: []any{results, nil}},
+			wantStatus: http.StatusOK,
+			wantBody: mustEncode(t, []api.SearchResult{{
+				Note:    api.Note{ID: uuid.UUID{1}.String(), Title: "title", Content: "content", UserID: userID.String()},
+				Rank:    0.5,
+				Snippet: "<b>title</b> content",
+			}}),
+		},
+		{
+			name:       "Search without query",
+			target:     "/notes/search",
+			mNSP:       mockNotesStoreParams{method: "Search", arguments: []any{userID, ""}, returnArguments: []any{[]note.SearchResult{}, note.ErrEmptyQuery}},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   fmt.Sprintln("missing query parameter q"),
+		},
+		{
+			name:       "Search service error",
+			target:     "/notes/search?q=title",
+			mNSP:       mockNotesStoreParams{method: "Search", arguments: []any{userID, "title"}, returnArguments: []any{[]note.SearchResult{}, errors.New("error notesSvc.Search")}},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   fmt.Sprintln(""),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mNotesSvc.Setup(tc.mNSP)
+			req := setupRequest(t, http.MethodGet, tc.target, userID)
+			rr := httptest.NewRecorder()
+
+			hdl.Search(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, tc.wantBody, rr.Body.String())
+			mNotesSvc.AssertCalled(t, tc.mNSP.method, tc.mNSP.arguments...)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package notesgrp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Keisn1/note-taking-app/app/api"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/web/mid"
+)
+
+var errInvalidRevision = errors.New("invalid revision")
+
+// GetRevisions lists the revisions of the note set by mid.AuthorizeNote,
+// oldest first.
+func (hdl *Handlers) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	n := mid.GetNote(r.Context())
+	logMsg := fmt.Sprintf("GetRevisions: noteID %v", n.ID)
+
+	revisions, err := hdl.notesSvc.QueryRevisions(r.Context(), n.ID)
+	if err != nil {
+		handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
+		return
+	}
+
+	ret := make([]api.Revision, 0, len(revisions))
+	for _, rev := range revisions {
+		ret = append(ret, toAPIRevision(rev))
+	}
+
+	respond(w, http.StatusOK, ret, logMsg)
+}
+
+func (hdl *Handlers) GetRevision(w http.ResponseWriter, r *http.Request) {
+	n := mid.GetNote(r.Context())
+	logMsg := fmt.Sprintf("GetRevision: noteID %v rev %q", n.ID, r.PathValue("rev"))
+
+	number, err := parseRevision(r.PathValue("rev"))
+	if err != nil {
+		handleRevisionError(w, logMsg, err)
+		return
+	}
+
+	rev, err := hdl.notesSvc.QueryRevision(r.Context(), n.ID, number)
+	if err != nil {
+		handleRevisionError(w, logMsg, err)
+		return
+	}
+
+	respond(w, http.StatusOK, toAPIRevision(rev), logMsg)
+}
+
+// DiffRevisions returns the unified diff between the revisions given by the
+// query parameters from and to.
+func (hdl *Handlers) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	n := mid.GetNote(r.Context())
+	logMsg := fmt.Sprintf("DiffRevisions: noteID %v query %q", n.ID, r.URL.RawQuery)
+
+	from, err := parseRevision(r.URL.Query().Get("from"))
+	if err != nil {
+		handleRevisionError(w, logMsg, err)
+		return
+	}
+	to, err := parseRevision(r.URL.Query().Get("to"))
+	if err != nil {
+		handleRevisionError(w, logMsg, err)
+		return
+	}
+
+	diff, err := hdl.notesSvc.DiffRevisions(r.Context(), n.ID, from, to)
+	if err != nil {
+		handleRevisionError(w, logMsg, err)
+		return
+	}
+
+	respond(w, http.StatusOK, api.RevisionDiff{From: from, To: to, Diff: diff}, logMsg)
+}
+
+// RestoreRevision sets the note back to a former revision.
+func (hdl *Handlers) RestoreRevision(w http.ResponseWriter, r *http.Request) {
+	n := mid.GetNote(r.Context())
+	logMsg := fmt.Sprintf("RestoreRevision: noteID %v rev %q", n.ID, r.PathValue("rev"))
+
+	number, err := parseRevision(r.PathValue("rev"))
+	if err != nil {
+		handleRevisionError(w, logMsg, err)
+		return
+	}
+
+	restored, err := hdl.notesSvc.RestoreRevision(r.Context(), n, number, mid.GetUserID(r.Context()))
+	if err != nil {
+		handleRevisionError(w, logMsg, err)
+		return
+	}
+
+	w.Header().Set("ETag", etag(restored))
+	respond(w, http.StatusOK, api.FromNote(restored), logMsg)
+}
+
+func parseRevision(s string) (int, error) {
+	number, err := strconv.Atoi(s)
+	if err != nil || number < 1 {
+		return 0, fmt.Errorf("parseRevision: [%s]: %w", s, errInvalidRevision)
+	}
+	return number, nil
+}
+
+func handleRevisionError(w http.ResponseWriter, logMsg string, err error) {
+	switch {
+	case errors.Is(err, errInvalidRevision):
+		handleError(w, "invalid revision", http.StatusBadRequest, logMsg, "error", err)
+	case errors.Is(err, note.ErrRevisionNotFound):
+		handleError(w, "", http.StatusNotFound, logMsg, "error", err)
+	case errors.Is(err, note.ErrVersionConflict):
+		handleError(w, "", http.StatusPreconditionFailed, logMsg, "error", err)
+	default:
+		handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
+	}
+}
+
+func toAPIRevision(r note.Revision) api.Revision {
+	return api.Revision{
+		NoteID:    r.NoteID.String(),
+		Number:    r.Number,
+		AuthorID:  r.AuthorID.String(),
+		CreatedAt: r.CreatedAt,
+		Title:     r.Title,
+		Content:   r.Content,
+	}
+}
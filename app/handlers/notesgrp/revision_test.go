@@ -0,0 +1,164 @@
+package notesgrp_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/app/api"
+	"github.com/Keisn1/note-taking-app/app/handlers/notesgrp"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/foundation"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetRevisions(t *testing.T) {
+	mNotesSvc := &mockNotesSvc{}
+	hdl := notesgrp.NewHandlers(mNotesSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	n := note.Note{ID: uuid.UUID{1}, Title: note.NewTitle("title"), Content: note.NewContent("content"), UserID: uuid.UUID{2}}
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	revisions := []note.Revision{
+		{NoteID: n.ID, Number: 1, AuthorID: n.UserID, CreatedAt: createdAt, Title: "title", Content: "content"},
+	}
+
+	mNotesSvc.Setup(mockNotesStoreParams{method: "QueryRevisions", arguments: []any{n.ID}, returnArguments: []any{revisions, nil}})
+	req := withNote(httptest.NewRequest(http.MethodGet, "/notes/"+n.ID.String()+"/revisions", nil), n)
+	rr := httptest.NewRecorder()
+
+	hdl.GetRevisions(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, mustEncode(t, []api.Revision{
+		{NoteID: n.ID.String(), Number: 1, AuthorID: n.UserID.String(), CreatedAt: createdAt, Title: "title", Content: "content"},
+	}), rr.Body.String())
+}
+
+func Test_DiffRevisions(t *testing.T) {
+	mNotesSvc := &mockNotesSvc{}
+	hdl := notesgrp.NewHandlers(mNotesSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	n := note.Note{ID: uuid.UUID{1}, Title: note.NewTitle("title"), Content: note.NewContent("content"), UserID: uuid.UUID{2}}
+	diff := "--- revision 1\n+++ revision 2\n@@ -1 +1 @@\n-title\n+new title\n"
+
+	testCases := []struct {
+		name       string
+		query      string
+		mNSP       *mockNotesStoreParams
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Diff success",
+			query:      "from=1&to=2",
+			mNSP:       &mockNotesStoreParams{method: "DiffRevisions", arguments: []any{n.ID, 1, 2}, returnArguments: []any{diff, nil}},
+			wantStatus: http.StatusOK,
+			wantBody:   mustEncode(t, api.RevisionDiff{From: 1, To: 2, Diff: diff}),
+		},
+		{
+			name:       "Missing revision",
+			query:      "from=1&to=3",
+			mNSP:       &mockNotesStoreParams{method: "DiffRevisions", arguments: []any{n.ID, 1, 3}, returnArguments: []any{"", note.ErrRevisionNotFound}},
+			wantStatus: http.StatusNotFound,
+			wantBody:   "\n",
+		},
+		{
+			name:       "Invalid revision",
+			query:      "from=0&to=2",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid revision\n",
+		},
+		{
+			name:       "Missing query parameter",
+			query:      "from=1",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid revision\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mNotesSvc.Reset()
+			if tc.mNSP != nil {
+				mNotesSvc.Setup(*tc.mNSP)
+			}
+			req := withNote(httptest.NewRequest(http.MethodGet, "/notes/"+n.ID.String()+"/revisions/diff?"+tc.query, nil), n)
+			rr := httptest.NewRecorder()
+
+			hdl.DiffRevisions(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, tc.wantBody, rr.Body.String())
+			if tc.mNSP == nil {
+				mNotesSvc.AssertNotCalled(t, "DiffRevisions")
+			}
+		})
+	}
+}
+
+func Test_RestoreRevision(t *testing.T) {
+	mNotesSvc := &mockNotesSvc{}
+	hdl := notesgrp.NewHandlers(mNotesSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	editorID := uuid.UUID{3}
+	n := note.Note{ID: uuid.UUID{1}, Title: note.NewTitle("title"), Content: note.NewContent("content"), UserID: uuid.UUID{2}}
+	restored := note.Note{ID: n.ID, Title: note.NewTitle("old title"), Content: note.NewContent("old content"), UserID: n.UserID}
+
+	testCases := []struct {
+		name        string
+		mNSP        mockNotesStoreParams
+		wantStatus  int
+		wantLogging []string
+	}{
+		{
+			name:        "Restore success",
+			mNSP:        mockNotesStoreParams{method: "RestoreRevision", arguments: []any{n, 1, editorID}, returnArguments: []any{restored, nil}},
+			wantStatus:  http.StatusOK,
+			wantLogging: []string{"INFO", fmt.Sprintf("Success: RestoreRevision: noteID %v rev %q", n.ID, "1")},
+		},
+		{
+			name:        "Missing revision",
+			mNSP:        mockNotesStoreParams{method: "RestoreRevision", arguments: []any{n, 1, editorID}, returnArguments: []any{note.Note{}, note.ErrRevisionNotFound}},
+			wantStatus:  http.StatusNotFound,
+			wantLogging: []string{"ERROR", note.ErrRevisionNotFound.Error()},
+		},
+		{
+			name:        "Service error",
+			mNSP:        mockNotesStoreParams{method: "RestoreRevision", arguments: []any{n, 1, editorID}, returnArguments: []any{note.Note{}, errors.New("error notesSvc.RestoreRevision")}},
+			wantStatus:  http.StatusInternalServerError,
+			wantLogging: []string{"ERROR", "error notesSvc.RestoreRevision"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logBuf.Reset()
+			mNotesSvc.Setup(tc.mNSP)
+			req := httptest.NewRequest(http.MethodPost, "/notes/"+n.ID.String()+"/revisions/1/restore", nil)
+			req = withNote(req.WithContext(context.WithValue(req.Context(), foundation.UserIDKey, editorID)), n)
+			req.SetPathValue("rev", "1")
+			rr := httptest.NewRecorder()
+
+			hdl.RestoreRevision(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			mNotesSvc.AssertCalled(t, tc.mNSP.method, tc.mNSP.arguments...)
+			for _, logMsg := range tc.wantLogging {
+				assert.Contains(t, logBuf.String(), logMsg)
+			}
+		})
+	}
+}
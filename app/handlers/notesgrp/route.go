@@ -0,0 +1,56 @@
+package notesgrp
+
+import (
+	"net/http"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/web/auth"
+	"github.com/Keisn1/note-taking-app/domain/web/mid"
+	"github.com/Keisn1/note-taking-app/foundation/web"
+)
+
+type Config struct {
+	Auth    auth.Auth
+	NoteSvc note.Service
+}
+
+func Routes(app *web.App, cfg Config) {
+	authen := mid.Authenticate(cfg.Auth)
+	canRead := mid.AuthorizeNote(cfg.NoteSvc, note.PermissionRead)
+	canWrite := mid.AuthorizeNote(cfg.NoteSvc, note.PermissionWrite)
+	isOwner := mid.AuthorizeNote(cfg.NoteSvc, note.PermissionOwner)
+	read := mid.Authorize(auth.RuleNotesRead)
+	write := mid.Authorize(auth.RuleNotesWrite)
+
+	hdl := NewHandlers(cfg.NoteSvc)
+	app.Handle("GET /notes", authen(read(http.HandlerFunc(hdl.Query))))
+	app.Handle("POST /notes", authen(write(http.HandlerFunc(hdl.Create))))
+	app.Handle("GET /notes/export", authen(read(http.HandlerFunc(hdl.Export))))
+	app.Handle("POST /notes/import", authen(write(http.HandlerFunc(hdl.Import))))
+	app.Handle("POST /notes/batch", authen(write(http.HandlerFunc(hdl.Batch))))
+	app.Handle("GET /notes/search", authen(read(http.HandlerFunc(hdl.Search))))
+	app.Handle("GET /notes/shared-with-me", authen(read(http.HandlerFunc(hdl.GetSharedWithMe))))
+	app.Handle("GET /notes/trash", authen(read(http.HandlerFunc(hdl.GetTrash))))
+	app.Handle("GET /notes/{note_id}", authen(read(canRead(http.HandlerFunc(hdl.GetNoteByID)))))
+	app.Handle("PUT /notes/{note_id}", authen(write(canWrite(http.HandlerFunc(hdl.Put)))))
+	app.Handle("PATCH /notes/{note_id}", authen(write(canWrite(http.HandlerFunc(hdl.Patch)))))
+	app.Handle("DELETE /notes/{note_id}", authen(write(isOwner(http.HandlerFunc(hdl.Delete)))))
+	app.Handle("POST /notes/{note_id}/restore", authen(write(http.HandlerFunc(hdl.Restore))))
+
+	app.Handle("GET /notes/{note_id}/revisions", authen(read(canRead(http.HandlerFunc(hdl.GetRevisions)))))
+	app.Handle("GET /notes/{note_id}/revisions/diff", authen(read(canRead(http.HandlerFunc(hdl.DiffRevisions)))))
+	app.Handle("GET /notes/{note_id}/revisions/{rev}", authen(read(canRead(http.HandlerFunc(hdl.GetRevision)))))
+	app.Handle("POST /notes/{note_id}/revisions/{rev}/restore", authen(write(canWrite(http.HandlerFunc(hdl.RestoreRevision)))))
+
+	app.Handle("POST /notes/{note_id}/tags", authen(write(canWrite(http.HandlerFunc(hdl.AddTags)))))
+	app.Handle("DELETE /notes/{note_id}/tags/{tag}", authen(write(canWrite(http.HandlerFunc(hdl.RemoveTag)))))
+	app.Handle("GET /tags", authen(read(http.HandlerFunc(hdl.GetTags))))
+	app.Handle("PATCH /tags/{tag}", authen(write(http.HandlerFunc(hdl.RenameTag))))
+
+	app.Handle("GET /notes/{note_id}/shares", authen(read(isOwner(http.HandlerFunc(hdl.GetShares)))))
+	app.Handle("POST /notes/{note_id}/shares", authen(write(isOwner(http.HandlerFunc(hdl.Share)))))
+	app.Handle("DELETE /notes/{note_id}/shares", authen(write(isOwner(http.HandlerFunc(hdl.Unshare)))))
+
+	readAll := mid.Authorize(auth.RuleNotesReadAll)
+	app.Handle("GET /admin/notes", authen(readAll(http.HandlerFunc(hdl.GetAllNotes))))
+}
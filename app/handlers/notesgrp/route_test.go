@@ -0,0 +1,53 @@
+package notesgrp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/app/handlers/notesgrp"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	revocationmem "github.com/Keisn1/note-taking-app/domain/core/revocation/repositories/memory"
+	"github.com/Keisn1/note-taking-app/domain/web/auth"
+	"github.com/Keisn1/note-taking-app/foundation/common"
+	"github.com/Keisn1/note-taking-app/foundation/web"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RouteScopes(t *testing.T) {
+	jwtSvc := auth.MustNewJWTService(common.MustGenerateRandomKey(32))
+	mNotesSvc := &mockNotesSvc{}
+	app := web.NewApp()
+	notesgrp.Routes(app, notesgrp.Config{Auth: auth.NewAuth(jwtSvc, revocationmem.NewRepo()), NoteSvc: mNotesSvc})
+
+	testCases := []struct {
+		name       string
+		roles      []string
+		method     string
+		path       string
+		wantStatus int
+	}{
+		// the scopes of admins don't include the ones of users
+		{name: "Reading notes needs notes:read", roles: []string{auth.RoleAdmin}, method: http.MethodGet, path: "/notes/trash", wantStatus: http.StatusForbidden},
+		{name: "Writing notes needs notes:write", roles: []string{auth.RoleAdmin}, method: http.MethodPatch, path: "/tags/go", wantStatus: http.StatusForbidden},
+		{name: "Reading all notes needs notes:read:all", roles: []string{auth.RoleUser}, method: http.MethodGet, path: "/admin/notes", wantStatus: http.StatusForbidden},
+		{name: "Admins read all notes", roles: []string{auth.RoleUser, auth.RoleAdmin}, method: http.MethodGet, path: "/admin/notes", wantStatus: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mNotesSvc.Setup(mockNotesStoreParams{method: "QueryAll", returnArguments: []any{[]note.Note{}, nil}})
+			token, err := jwtSvc.CreateToken(uuid.New(), tc.roles, time.Minute)
+			assert.NoError(t, err)
+
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+			app.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+		})
+	}
+}
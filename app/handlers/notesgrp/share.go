@@ -0,0 +1,132 @@
+package notesgrp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/Keisn1/note-taking-app/app/api"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/core/user"
+	"github.com/Keisn1/note-taking-app/domain/web/mid"
+	"github.com/google/uuid"
+)
+
+// Share grants another user access to the note set by mid.AuthorizeNote.
+// Sharing again with the same user replaces the permission.
+func (hdl *Handlers) Share(w http.ResponseWriter, r *http.Request) {
+	n := mid.GetNote(r.Context())
+
+	var sp api.SharePost
+	err := json.NewDecoder(r.Body).Decode(&sp)
+	if err != nil {
+		handleError(w, "", http.StatusBadRequest, "Share: invalid body", "error", err)
+		return
+	}
+
+	userID, err := uuid.Parse(sp.UserID)
+	if err != nil {
+		handleError(w, "invalid user_id", http.StatusBadRequest, "Share: invalid user_id", "error", err)
+		return
+	}
+
+	perm, err := note.ParsePermission(sp.Permission)
+	if err != nil {
+		handleError(w, "invalid permission", http.StatusBadRequest, "Share: invalid permission", "error", err)
+		return
+	}
+
+	s, err := hdl.notesSvc.Share(r.Context(), n, userID, perm)
+	if err != nil {
+		handleShareError(w, fmt.Sprintf("Share: noteID %v userID %v", n.ID, userID), err)
+		return
+	}
+
+	respond(w, http.StatusCreated, toAPIShare(s), fmt.Sprintf("Share: noteID %v userID %v", n.ID, userID))
+}
+
+// Unshare revokes the access of the user in the request body.
+func (hdl *Handlers) Unshare(w http.ResponseWriter, r *http.Request) {
+	n := mid.GetNote(r.Context())
+
+	var sd api.ShareDelete
+	err := json.NewDecoder(r.Body).Decode(&sd)
+	if err != nil {
+		handleError(w, "", http.StatusBadRequest, "Unshare: invalid body", "error", err)
+		return
+	}
+
+	userID, err := uuid.Parse(sd.UserID)
+	if err != nil {
+		handleError(w, "invalid user_id", http.StatusBadRequest, "Unshare: invalid user_id", "error", err)
+		return
+	}
+
+	if err := hdl.notesSvc.Unshare(r.Context(), n.ID, userID); err != nil {
+		handleShareError(w, fmt.Sprintf("Unshare: noteID %v userID %v", n.ID, userID), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	slog.Info(fmt.Sprintf("Success: Unshare: noteID %v userID %v", n.ID, userID))
+}
+
+func (hdl *Handlers) GetShares(w http.ResponseWriter, r *http.Request) {
+	n := mid.GetNote(r.Context())
+
+	shares, err := hdl.notesSvc.QueryShares(r.Context(), n.ID)
+	if err != nil {
+		handleError(w, "", http.StatusInternalServerError, fmt.Sprintf("GetShares: noteID %v", n.ID), "error", err)
+		return
+	}
+
+	ret := make([]api.Share, 0, len(shares))
+	for _, s := range shares {
+		ret = append(ret, toAPIShare(s))
+	}
+
+	respond(w, http.StatusOK, ret, fmt.Sprintf("GetShares: noteID %v", n.ID))
+}
+
+// GetSharedWithMe lists the notes other users have shared with the caller.
+func (hdl *Handlers) GetSharedWithMe(w http.ResponseWriter, r *http.Request) {
+	userID := mid.GetUserID(r.Context())
+
+	notes, err := hdl.notesSvc.QuerySharedWith(r.Context(), userID)
+	if err != nil {
+		logMsg := fmt.Sprintf("GetSharedWithMe: userID %v", userID)
+		handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
+		return
+	}
+
+	ret := make([]api.Note, 0, len(notes))
+	for _, n := range notes {
+		ret = append(ret, api.FromNote(n))
+	}
+
+	respond(w, http.StatusOK, ret, fmt.Sprintf("GetSharedWithMe: userID %v", userID))
+}
+
+// handleShareError maps the sharing errors of note.Service to status codes.
+func handleShareError(w http.ResponseWriter, logMsg string, err error) {
+	switch {
+	case errors.Is(err, note.ErrInvalidPermission):
+		handleError(w, "invalid permission", http.StatusBadRequest, logMsg, "error", err)
+	case errors.Is(err, note.ErrShareWithOwner):
+		handleError(w, "cannot share a note with its owner", http.StatusBadRequest, logMsg, "error", err)
+	case errors.Is(err, user.ErrUserNotFound), errors.Is(err, note.ErrShareNotFound):
+		handleError(w, "", http.StatusNotFound, logMsg, "error", err)
+	default:
+		handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
+	}
+}
+
+func toAPIShare(s note.Share) api.Share {
+	return api.Share{
+		NoteID:     s.NoteID.String(),
+		UserID:     s.UserID.String(),
+		Permission: s.Permission.String(),
+	}
+}
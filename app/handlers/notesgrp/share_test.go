@@ -0,0 +1,179 @@
+package notesgrp_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Keisn1/note-taking-app/app/api"
+	"github.com/Keisn1/note-taking-app/app/handlers/notesgrp"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/core/user"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Share(t *testing.T) {
+	mNotesSvc := &mockNotesSvc{}
+	hdl := notesgrp.NewHandlers(mNotesSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	n := note.Note{ID: uuid.UUID{1}, Title: note.NewTitle("title"), Content: note.NewContent("content"), UserID: uuid.UUID{2}}
+	grantee := uuid.UUID{3}
+
+	testCases := []struct {
+		name       string
+		body       string
+		mNSP       mockNotesStoreParams
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "Share success",
+			body: mustEncode(t, api.SharePost{UserID: grantee.String(), Permission: "write"}),
+			mNSP: mockNotesStoreParams{
+				method:          "Share",
+				arguments:       []any{n, grantee, note.PermissionWrite},
+				returnArguments: []any{note.Share{NoteID: n.ID, UserID: grantee, Permission: note.PermissionWrite}, nil},
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   mustEncode(t, api.Share{NoteID: n.ID.String(), UserID: grantee.String(), Permission: "write"}),
+		},
+		{
+			name: "Share with owner",
+			body: mustEncode(t, api.SharePost{UserID: grantee.String(), Permission: "read"}),
+			mNSP: mockNotesStoreParams{
+				method:          "Share",
+				arguments:       []any{n, grantee, note.PermissionRead},
+				returnArguments: []any{note.Share{}, fmt.Errorf("share: %w", note.ErrShareWithOwner)},
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   fmt.Sprintln(note.ErrShareWithOwner.Error()),
+		},
+		{
+			name: "Share with unknown user",
+			body: mustEncode(t, api.SharePost{UserID: grantee.String(), Permission: "read"}),
+			mNSP: mockNotesStoreParams{
+				method:          "Share",
+				arguments:       []any{n, grantee, note.PermissionRead},
+				returnArguments: []any{note.Share{}, fmt.Errorf("share: %w", user.ErrUserNotFound)},
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   fmt.Sprintln(""),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mNotesSvc.Setup(tc.mNSP)
+			req := withNote(httptest.NewRequest(http.MethodPost, "/notes/"+n.ID.String()+"/shares", strings.NewReader(tc.body)), n)
+			rr := httptest.NewRecorder()
+
+			hdl.Share(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, tc.wantBody, rr.Body.String())
+			mNotesSvc.AssertCalled(t, tc.mNSP.method, tc.mNSP.arguments...)
+		})
+	}
+
+	t.Run("Invalid bodies are rejected", func(t *testing.T) {
+		mNotesSvc.Reset()
+		for _, body := range []string{
+			"invalid body",
+			mustEncode(t, api.SharePost{UserID: "invalid", Permission: "read"}),
+			mustEncode(t, api.SharePost{UserID: grantee.String(), Permission: "admin"}),
+		} {
+			req := withNote(httptest.NewRequest(http.MethodPost, "/notes/"+n.ID.String()+"/shares", strings.NewReader(body)), n)
+			rr := httptest.NewRecorder()
+
+			hdl.Share(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			mNotesSvc.AssertNotCalled(t, "Share")
+		}
+	})
+}
+
+func Test_Unshare(t *testing.T) {
+	mNotesSvc := &mockNotesSvc{}
+	hdl := notesgrp.NewHandlers(mNotesSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	n := note.Note{ID: uuid.UUID{1}, Title: note.NewTitle("title"), Content: note.NewContent("content"), UserID: uuid.UUID{2}}
+	grantee := uuid.UUID{3}
+
+	testCases := []struct {
+		name        string
+		mNSP        mockNotesStoreParams
+		wantStatus  int
+		wantLogging []string
+	}{
+		{
+			name:        "Unshare success",
+			mNSP:        mockNotesStoreParams{method: "Unshare", arguments: []any{n.ID, grantee}, returnArguments: []any{nil}},
+			wantStatus:  http.StatusNoContent,
+			wantLogging: []string{"INFO", fmt.Sprintf("Success: Unshare: noteID %v userID %v", n.ID, grantee)},
+		},
+		{
+			name:        "Unshare missing share",
+			mNSP:        mockNotesStoreParams{method: "Unshare", arguments: []any{n.ID, grantee}, returnArguments: []any{note.ErrShareNotFound}},
+			wantStatus:  http.StatusNotFound,
+			wantLogging: []string{"ERROR", fmt.Sprintf("Unshare: noteID %v userID %v", n.ID, grantee)},
+		},
+		{
+			name:        "Unshare service error",
+			mNSP:        mockNotesStoreParams{method: "Unshare", arguments: []any{n.ID, grantee}, returnArguments: []any{errors.New("error notesSvc.Unshare")}},
+			wantStatus:  http.StatusInternalServerError,
+			wantLogging: []string{"ERROR", "error notesSvc.Unshare"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logBuf.Reset()
+			mNotesSvc.Setup(tc.mNSP)
+			body := mustEncode(t, api.ShareDelete{UserID: grantee.String()})
+			req := withNote(httptest.NewRequest(http.MethodDelete, "/notes/"+n.ID.String()+"/shares", strings.NewReader(body)), n)
+			rr := httptest.NewRecorder()
+
+			hdl.Unshare(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			mNotesSvc.AssertCalled(t, tc.mNSP.method, tc.mNSP.arguments...)
+			for _, logMsg := range tc.wantLogging {
+				assert.Contains(t, logBuf.String(), logMsg)
+			}
+		})
+	}
+}
+
+func Test_GetSharedWithMe(t *testing.T) {
+	mNotesSvc := &mockNotesSvc{}
+	hdl := notesgrp.NewHandlers(mNotesSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	userID := uuid.UUID{3}
+	notes := []note.Note{
+		{ID: uuid.UUID{1}, Title: note.NewTitle("title 1"), Content: note.NewContent("content 1"), UserID: uuid.UUID{1}},
+	}
+
+	mNotesSvc.Setup(mockNotesStoreParams{method: "QuerySharedWith", arguments: []any{userID}, returnArguments: []any{notes, nil}})
+	req := setupRequest(t, http.MethodGet, "/notes/shared-with-me", userID)
+	rr := httptest.NewRecorder()
+
+	hdl.GetSharedWithMe(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, mustEncode(t, []api.Note{
+		{ID: uuid.UUID{1}.String(), Title: "title 1", Content: "content 1", UserID: uuid.UUID{1}.String()},
+	}), rr.Body.String())
+}
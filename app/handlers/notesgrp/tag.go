@@ -0,0 +1,118 @@
+package notesgrp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/Keisn1/note-taking-app/app/api"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/web/mid"
+)
+
+// AddTags adds the tags of the request body to the note set by
+// mid.AuthorizeNote.
+func (hdl *Handlers) AddTags(w http.ResponseWriter, r *http.Request) {
+	n := mid.GetNote(r.Context())
+	logMsg := fmt.Sprintf("AddTags: noteID %v", n.ID)
+
+	var tp api.TagsPost
+	if err := json.NewDecoder(r.Body).Decode(&tp); err != nil {
+		handleError(w, "", http.StatusBadRequest, logMsg, "error", err)
+		return
+	}
+
+	tags, err := note.NewTags(tp.Tags...)
+	if err != nil || len(tags) == 0 {
+		handleError(w, "invalid tag", http.StatusBadRequest, logMsg, "error", err)
+		return
+	}
+
+	n, err = hdl.notesSvc.AddTags(r.Context(), n, tags)
+	if err != nil {
+		if errors.Is(err, note.ErrVersionConflict) {
+			handleError(w, "", http.StatusPreconditionFailed, logMsg, "error", err)
+			return
+		}
+		handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
+		return
+	}
+
+	w.Header().Set("ETag", etag(n))
+	respond(w, http.StatusOK, api.FromNote(n), logMsg)
+}
+
+// RemoveTag removes the tag of the path from the note set by
+// mid.AuthorizeNote. Removing a tag the note doesn't have is not an error.
+func (hdl *Handlers) RemoveTag(w http.ResponseWriter, r *http.Request) {
+	n := mid.GetNote(r.Context())
+	logMsg := fmt.Sprintf("RemoveTag: noteID %v tag %q", n.ID, r.PathValue("tag"))
+
+	tags, err := note.NewTags(r.PathValue("tag"))
+	if err != nil {
+		handleError(w, "invalid tag", http.StatusBadRequest, logMsg, "error", err)
+		return
+	}
+
+	n, err = hdl.notesSvc.RemoveTags(r.Context(), n, tags)
+	if err != nil {
+		if errors.Is(err, note.ErrVersionConflict) {
+			handleError(w, "", http.StatusPreconditionFailed, logMsg, "error", err)
+			return
+		}
+		handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
+		return
+	}
+
+	w.Header().Set("ETag", etag(n))
+	respond(w, http.StatusOK, api.FromNote(n), logMsg)
+}
+
+// GetTags lists the caller's tags with the number of notes having them.
+func (hdl *Handlers) GetTags(w http.ResponseWriter, r *http.Request) {
+	userID := mid.GetUserID(r.Context())
+	logMsg := fmt.Sprintf("GetTags: userID %v", userID)
+
+	tags, err := hdl.notesSvc.QueryTags(r.Context(), userID)
+	if err != nil {
+		handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
+		return
+	}
+
+	ret := make([]api.TagCount, 0, len(tags))
+	for _, tc := range tags {
+		ret = append(ret, api.TagCount{Name: tc.Name, Count: tc.Count})
+	}
+
+	respond(w, http.StatusOK, ret, logMsg)
+}
+
+// RenameTag renames one of the caller's tags on all of their notes. Renaming
+// to a tag that is already in use merges both tags.
+func (hdl *Handlers) RenameTag(w http.ResponseWriter, r *http.Request) {
+	userID := mid.GetUserID(r.Context())
+	logMsg := fmt.Sprintf("RenameTag: userID %v tag %q", userID, r.PathValue("tag"))
+
+	var tp api.TagPatch
+	if err := json.NewDecoder(r.Body).Decode(&tp); err != nil {
+		handleError(w, "", http.StatusBadRequest, logMsg, "error", err)
+		return
+	}
+
+	if _, err := hdl.notesSvc.RenameTag(r.Context(), userID, r.PathValue("tag"), tp.Name); err != nil {
+		switch {
+		case errors.Is(err, note.ErrInvalidTag):
+			handleError(w, "invalid tag", http.StatusBadRequest, logMsg, "error", err)
+		case errors.Is(err, note.ErrTagNotFound):
+			handleError(w, "", http.StatusNotFound, logMsg, "error", err)
+		default:
+			handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	slog.Info("Success: " + logMsg)
+}
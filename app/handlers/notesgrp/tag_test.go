@@ -0,0 +1,194 @@
+package notesgrp_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Keisn1/note-taking-app/app/api"
+	"github.com/Keisn1/note-taking-app/app/handlers/notesgrp"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/foundation"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AddTags(t *testing.T) {
+	mNotesSvc := &mockNotesSvc{}
+	hdl := notesgrp.NewHandlers(mNotesSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	n := note.Note{ID: uuid.UUID{1}, Title: note.NewTitle("title"), Content: note.NewContent("content"), UserID: uuid.UUID{2}}
+	tagged := n
+	tagged.Tags = note.Tags{"go", "work"}
+
+	testCases := []struct {
+		name       string
+		body       string
+		mNSP       *mockNotesStoreParams
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "AddTags success",
+			body:       `{"tags": ["Work", "go", "work"]}`,
+			mNSP:       &mockNotesStoreParams{method: "AddTags", arguments: []any{n, note.Tags{"go", "work"}}, returnArguments: []any{tagged, nil}},
+			wantStatus: http.StatusOK,
+			wantBody:   mustEncode(t, api.Note{ID: n.ID.String(), Title: "title", Content: "content", UserID: n.UserID.String(), Tags: []string{"go", "work"}}),
+		},
+		{
+			name:       "AddTags service error",
+			body:       `{"tags": ["go"]}`,
+			mNSP:       &mockNotesStoreParams{method: "AddTags", arguments: []any{n, note.Tags{"go"}}, returnArguments: []any{note.Note{}, errors.New("error notesSvc.AddTags")}},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   fmt.Sprintln(""),
+		},
+		{
+			name:       "AddTags racing an update",
+			body:       `{"tags": ["go"]}`,
+			mNSP:       &mockNotesStoreParams{method: "AddTags", arguments: []any{n, note.Tags{"go"}}, returnArguments: []any{note.Note{}, fmt.Errorf("addTags: %w", note.ErrVersionConflict)}},
+			wantStatus: http.StatusPreconditionFailed,
+			wantBody:   fmt.Sprintln(""),
+		},
+		{name: "AddTags invalid tag", body: `{"tags": ["a+b"]}`, wantStatus: http.StatusBadRequest, wantBody: fmt.Sprintln("invalid tag")},
+		{name: "AddTags no tags", body: `{"tags": []}`, wantStatus: http.StatusBadRequest, wantBody: fmt.Sprintln("invalid tag")},
+		{name: "AddTags invalid body", body: `invalid body`, wantStatus: http.StatusBadRequest, wantBody: fmt.Sprintln("")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logBuf.Reset()
+			mNotesSvc.Reset()
+			if tc.mNSP != nil {
+				mNotesSvc.Setup(*tc.mNSP)
+			}
+			req := withNote(httptest.NewRequest(http.MethodPost, "/notes/"+n.ID.String()+"/tags", strings.NewReader(tc.body)), n)
+			rr := httptest.NewRecorder()
+
+			hdl.AddTags(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, tc.wantBody, rr.Body.String())
+			if tc.mNSP != nil {
+				mNotesSvc.AssertCalled(t, tc.mNSP.method, tc.mNSP.arguments...)
+			} else {
+				mNotesSvc.AssertNotCalled(t, "AddTags")
+			}
+		})
+	}
+}
+
+func Test_RemoveTag(t *testing.T) {
+	mNotesSvc := &mockNotesSvc{}
+	hdl := notesgrp.NewHandlers(mNotesSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	n := note.Note{ID: uuid.UUID{1}, Title: note.NewTitle("title"), Content: note.NewContent("content"), UserID: uuid.UUID{2}, Tags: note.Tags{"go", "work"}}
+	untagged := n
+	untagged.Tags = note.Tags{"go"}
+
+	mNotesSvc.Setup(mockNotesStoreParams{method: "RemoveTags", arguments: []any{n, note.Tags{"work"}}, returnArguments: []any{untagged, nil}})
+	req := withNote(httptest.NewRequest(http.MethodDelete, "/notes/"+n.ID.String()+"/tags/Work", nil), n)
+	req.SetPathValue("tag", "Work")
+	rr := httptest.NewRecorder()
+
+	hdl.RemoveTag(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, mustEncode(t, api.Note{ID: n.ID.String(), Title: "title", Content: "content", UserID: n.UserID.String(), Tags: []string{"go"}}), rr.Body.String())
+	assert.Contains(t, logBuf.String(), fmt.Sprintf("Success: RemoveTag: noteID %v", n.ID))
+}
+
+func Test_GetTags(t *testing.T) {
+	mNotesSvc := &mockNotesSvc{}
+	hdl := notesgrp.NewHandlers(mNotesSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	userID := uuid.UUID{1}
+	tags := []note.TagCount{{Name: "go", Count: 2}, {Name: "work", Count: 1}}
+
+	mNotesSvc.Setup(mockNotesStoreParams{method: "QueryTags", arguments: []any{userID}, returnArguments: []any{tags, nil}})
+	req := setupRequest(t, http.MethodGet, "/tags", userID)
+	rr := httptest.NewRecorder()
+
+	hdl.GetTags(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, mustEncode(t, []api.TagCount{{Name: "go", Count: 2}, {Name: "work", Count: 1}}), rr.Body.String())
+}
+
+func Test_RenameTag(t *testing.T) {
+	mNotesSvc := &mockNotesSvc{}
+	hdl := notesgrp.NewHandlers(mNotesSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	userID := uuid.UUID{1}
+
+	testCases := []struct {
+		name        string
+		returnArgs  []any
+		wantStatus  int
+		wantBody    string
+		wantLogging []string
+	}{
+		{
+			name:        "RenameTag success",
+			returnArgs:  []any{2, nil},
+			wantStatus:  http.StatusNoContent,
+			wantBody:    "",
+			wantLogging: []string{"INFO", fmt.Sprintf("Success: RenameTag: userID %v tag %q", userID, "work")},
+		},
+		{
+			name:        "RenameTag missing tag",
+			returnArgs:  []any{0, note.ErrTagNotFound},
+			wantStatus:  http.StatusNotFound,
+			wantBody:    fmt.Sprintln(""),
+			wantLogging: []string{"ERROR", note.ErrTagNotFound.Error()},
+		},
+		{
+			name:        "RenameTag invalid tag",
+			returnArgs:  []any{0, note.ErrInvalidTag},
+			wantStatus:  http.StatusBadRequest,
+			wantBody:    fmt.Sprintln("invalid tag"),
+			wantLogging: []string{"ERROR", note.ErrInvalidTag.Error()},
+		},
+		{
+			name:        "RenameTag service error",
+			returnArgs:  []any{0, errors.New("error notesSvc.RenameTag")},
+			wantStatus:  http.StatusInternalServerError,
+			wantBody:    fmt.Sprintln(""),
+			wantLogging: []string{"ERROR", "error notesSvc.RenameTag"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logBuf.Reset()
+			mNSP := mockNotesStoreParams{method: "RenameTag", arguments: []any{userID, "work", "job"}, returnArguments: tc.returnArgs}
+			mNotesSvc.Setup(mNSP)
+			req := httptest.NewRequest(http.MethodPatch, "/tags/work", strings.NewReader(`{"name": "job"}`))
+			req = req.WithContext(context.WithValue(req.Context(), foundation.UserIDKey, userID))
+			req.SetPathValue("tag", "work")
+			rr := httptest.NewRecorder()
+
+			hdl.RenameTag(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, tc.wantBody, rr.Body.String())
+			mNotesSvc.AssertCalled(t, mNSP.method, mNSP.arguments...)
+			for _, logMsg := range tc.wantLogging {
+				assert.Contains(t, logBuf.String(), logMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package notesgrp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Keisn1/note-taking-app/app/api"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/web/mid"
+	"github.com/google/uuid"
+)
+
+// GetTrash lists the caller's deleted notes, most recently deleted first.
+func (hdl *Handlers) GetTrash(w http.ResponseWriter, r *http.Request) {
+	userID := mid.GetUserID(r.Context())
+	logMsg := fmt.Sprintf("GetTrash: userID %v", userID)
+
+	notes, err := hdl.notesSvc.QueryTrash(r.Context(), userID)
+	if err != nil {
+		handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
+		return
+	}
+
+	ret := make([]api.Note, 0, len(notes))
+	for _, n := range notes {
+		ret = append(ret, api.FromNote(n))
+	}
+
+	respond(w, http.StatusOK, ret, logMsg)
+}
+
+// Restore takes one of the caller's notes out of the trash. The note can't be
+// loaded by mid.AuthorizeNote, which doesn't see deleted notes, so ownership
+// is checked by note.Service.Restore.
+func (hdl *Handlers) Restore(w http.ResponseWriter, r *http.Request) {
+	userID := mid.GetUserID(r.Context())
+	logMsg := fmt.Sprintf("Restore: userID %v noteID %v", userID, r.PathValue("note_id"))
+
+	noteID, err := uuid.Parse(r.PathValue("note_id"))
+	if err != nil {
+		handleError(w, "", http.StatusNotFound, logMsg, "error", err)
+		return
+	}
+
+	n, err := hdl.notesSvc.Restore(r.Context(), noteID, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, note.ErrNoteNotFound):
+			handleError(w, "", http.StatusNotFound, logMsg, "error", err)
+		case errors.Is(err, note.ErrNoteNotTrashed):
+			handleError(w, "note is not in the trash", http.StatusConflict, logMsg, "error", err)
+		default:
+			handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
+		}
+		return
+	}
+
+	respond(w, http.StatusOK, api.FromNote(n), logMsg)
+}
@@ -0,0 +1,108 @@
+package notesgrp_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/app/api"
+	"github.com/Keisn1/note-taking-app/app/handlers/notesgrp"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetTrash(t *testing.T) {
+	mNotesSvc := &mockNotesSvc{}
+	hdl := notesgrp.NewHandlers(mNotesSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	userID := uuid.UUID{1}
+	deletedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	notes := []note.Note{
+		{ID: uuid.UUID{1}, Title: note.NewTitle("title 1"), Content: note.NewContent("content 1"), UserID: userID, DeletedAt: &deletedAt},
+	}
+
+	mNotesSvc.Setup(mockNotesStoreParams{method: "QueryTrash", arguments: []any{userID}, returnArguments: []any{notes, nil}})
+	req := setupRequest(t, http.MethodGet, "/notes/trash", userID)
+	rr := httptest.NewRecorder()
+
+	hdl.GetTrash(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, mustEncode(t, []api.Note{
+		{ID: uuid.UUID{1}.String(), Title: "title 1", Content: "content 1", UserID: userID.String(), DeletedAt: &deletedAt},
+	}), rr.Body.String())
+}
+
+func Test_Restore(t *testing.T) {
+	mNotesSvc := &mockNotesSvc{}
+	hdl := notesgrp.NewHandlers(mNotesSvc)
+	logBuf := &bytes.Buffer{}
+	log.SetOutput(logBuf)
+
+	userID := uuid.UUID{1}
+	n := note.Note{ID: uuid.UUID{2}, Title: note.NewTitle("title"), Content: note.NewContent("content"), UserID: userID}
+
+	testCases := []struct {
+		name        string
+		mNSP        mockNotesStoreParams
+		wantStatus  int
+		wantBody    string
+		wantLogging []string
+	}{
+		{
+			name:        "Restore success",
+			mNSP:        mockNotesStoreParams{method: "Restore", arguments: []any{n.ID, userID}, returnArguments: []any{n, nil}},
+			wantStatus:  http.StatusOK,
+			wantBody:    mustEncode(t, api.Note{ID: n.ID.String(), Title: "title", Content: "content", UserID: userID.String()}),
+			wantLogging: []string{"INFO", fmt.Sprintf("Success: Restore: userID %v noteID %v", userID, n.ID)},
+		},
+		{
+			name:        "Restore missing note",
+			mNSP:        mockNotesStoreParams{method: "Restore", arguments: []any{n.ID, userID}, returnArguments: []any{note.Note{}, note.ErrNoteNotFound}},
+			wantStatus:  http.StatusNotFound,
+			wantBody:    "\n",
+			wantLogging: []string{"ERROR", fmt.Sprintf("Restore: userID %v noteID %v", userID, n.ID)},
+		},
+		{
+			name:        "Restore note not in the trash",
+			mNSP:        mockNotesStoreParams{method: "Restore", arguments: []any{n.ID, userID}, returnArguments: []any{note.Note{}, note.ErrNoteNotTrashed}},
+			wantStatus:  http.StatusConflict,
+			wantBody:    "note is not in the trash\n",
+			wantLogging: []string{"ERROR", note.ErrNoteNotTrashed.Error()},
+		},
+		{
+			name:        "Restore service error",
+			mNSP:        mockNotesStoreParams{method: "Restore", arguments: []any{n.ID, userID}, returnArguments: []any{note.Note{}, errors.New("error notesSvc.Restore")}},
+			wantStatus:  http.StatusInternalServerError,
+			wantBody:    "\n",
+			wantLogging: []string{"ERROR", "error notesSvc.Restore"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logBuf.Reset()
+			mNotesSvc.Setup(tc.mNSP)
+			req := setupRequest(t, http.MethodPost, "/notes/"+n.ID.String()+"/restore", userID)
+			req.SetPathValue("note_id", n.ID.String())
+			rr := httptest.NewRecorder()
+
+			hdl.Restore(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, tc.wantBody, rr.Body.String())
+			mNotesSvc.AssertCalled(t, tc.mNSP.method, tc.mNSP.arguments...)
+			for _, logMsg := range tc.wantLogging {
+				assert.Contains(t, logBuf.String(), logMsg)
+			}
+		})
+	}
+}
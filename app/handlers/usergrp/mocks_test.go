@@ -0,0 +1,62 @@
+package usergrp_test
+
+import (
+	"context"
+	"net/mail"
+
+	"github.com/Keisn1/note-taking-app/domain/core/user"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockUserSvc struct {
+	mock.Mock
+}
+
+type mockUserSvcParams struct {
+	method          string
+	arguments       []any
+	returnArguments []any
+}
+
+func (mUS *mockUserSvc) Setup(ps ...mockUserSvcParams) {
+	mUS.Reset()
+	for _, p := range ps {
+		mUS.On(p.method, p.arguments...).Return(p.returnArguments...)
+	}
+}
+
+func (mUS *mockUserSvc) Reset() {
+	mUS.Calls = []mock.Call{}
+	mUS.ExpectedCalls = []*mock.Call{}
+}
+
+func (mUS *mockUserSvc) QueryByID(ctx context.Context, userID uuid.UUID) (user.User, error) {
+	args := mUS.Called(userID)
+	return args.Get(0).(user.User), args.Error(1)
+}
+
+func (mUS *mockUserSvc) QueryAll(ctx context.Context) ([]user.User, error) {
+	args := mUS.Called()
+	return args.Get(0).([]user.User), args.Error(1)
+}
+
+func (mUS *mockUserSvc) Authenticate(ctx context.Context, email mail.Address, password string) (user.User, error) {
+	args := mUS.Called(email, password)
+	return args.Get(0).(user.User), args.Error(1)
+}
+
+func (mUS *mockUserSvc) Create(ctx context.Context, uu user.UpdateUser) (user.User, error) {
+	args := mUS.Called(uu)
+	return args.Get(0).(user.User), args.Error(1)
+}
+
+func (mUS *mockUserSvc) Update(ctx context.Context, u user.User, uu user.UpdateUser) (user.User, error) {
+	args := mUS.Called(u, uu)
+	return args.Get(0).(user.User), args.Error(1)
+}
+
+func (mUS *mockUserSvc) Delete(ctx context.Context, userID uuid.UUID) error {
+	args := mUS.Called(userID)
+	return args.Error(0)
+}
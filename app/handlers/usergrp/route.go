@@ -0,0 +1,38 @@
+package usergrp
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/session"
+	"github.com/Keisn1/note-taking-app/domain/core/user"
+	"github.com/Keisn1/note-taking-app/domain/web/auth"
+	"github.com/Keisn1/note-taking-app/domain/web/mid"
+	"github.com/Keisn1/note-taking-app/foundation/web"
+)
+
+type Config struct {
+	Auth       auth.Auth
+	JWTSvc     auth.JWTService
+	UserSvc    user.Service
+	SessionSvc session.Service
+	TokenTTL   time.Duration
+}
+
+func Routes(app *web.App, cfg Config) {
+	authen := mid.Authenticate(cfg.Auth)
+	readSelf := mid.Authorize(auth.RuleUsersReadSelf)
+
+	hdl := NewHandlers(cfg.UserSvc, cfg.SessionSvc, cfg.Auth, cfg.JWTSvc, cfg.TokenTTL)
+	app.Handle("POST /users", http.HandlerFunc(hdl.Create))
+	app.Handle("POST /auth/login", http.HandlerFunc(hdl.Login))
+	app.Handle("POST /auth/refresh", http.HandlerFunc(hdl.Refresh))
+	app.Handle("POST /auth/logout", authen(http.HandlerFunc(hdl.Logout)))
+	app.Handle("POST /auth/logout-all", authen(http.HandlerFunc(hdl.LogoutAll)))
+	app.Handle("GET /users/me", authen(readSelf(http.HandlerFunc(hdl.QueryMe))))
+	app.Handle("PATCH /users/me", authen(http.HandlerFunc(hdl.UpdateMe)))
+	app.Handle("DELETE /users/me", authen(http.HandlerFunc(hdl.DeleteMe)))
+
+	readAll := mid.Authorize(auth.RuleUsersReadAll)
+	app.Handle("GET /admin/users", authen(readAll(http.HandlerFunc(hdl.QueryAll))))
+}
@@ -0,0 +1,329 @@
+package usergrp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/mail"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/app/api"
+	"github.com/Keisn1/note-taking-app/domain/core/session"
+	"github.com/Keisn1/note-taking-app/domain/core/user"
+	"github.com/Keisn1/note-taking-app/domain/web/auth"
+	"github.com/Keisn1/note-taking-app/domain/web/mid"
+)
+
+type Handlers struct {
+	userSvc    user.Service
+	sessionSvc session.Service
+	auth       auth.Auth
+	jwtSvc     auth.JWTService
+	tokenTTL   time.Duration
+}
+
+func NewHandlers(us user.Service, ss session.Service, a auth.Auth, jwtSvc auth.JWTService, tokenTTL time.Duration) Handlers {
+	return Handlers{userSvc: us, sessionSvc: ss, auth: a, jwtSvc: jwtSvc, tokenTTL: tokenTTL}
+}
+
+func (hdl *Handlers) Create(w http.ResponseWriter, r *http.Request) {
+	var up api.UserPost
+	err := json.NewDecoder(r.Body).Decode(&up)
+	if err != nil {
+		handleError(w, "", http.StatusBadRequest, "Create: invalid body", "error", err)
+		return
+	}
+
+	email, err := mail.ParseAddress(up.Email)
+	if err != nil {
+		handleError(w, "invalid email", http.StatusBadRequest, "Create: invalid email", "error", err)
+		return
+	}
+
+	uu := user.UpdateUser{
+		Name:     user.NewName(up.Name),
+		Email:    user.NewEmail(email.Address),
+		Password: user.NewPassword(up.Password),
+	}
+
+	u, err := hdl.userSvc.Create(r.Context(), uu)
+	if err != nil {
+		handleServiceError(w, "Create", err)
+		return
+	}
+
+	respond(w, http.StatusCreated, toAPIUser(u), fmt.Sprintf("Create: userID %v", u.ID))
+}
+
+func (hdl *Handlers) Login(w http.ResponseWriter, r *http.Request) {
+	var lp api.LoginPost
+	err := json.NewDecoder(r.Body).Decode(&lp)
+	if err != nil {
+		handleError(w, "", http.StatusBadRequest, "Login: invalid body", "error", err)
+		return
+	}
+
+	// the address is read like on signup, so that "Rob <rob@example.com>" or
+	// surrounding spaces find the same user
+	email, err := mail.ParseAddress(lp.Email)
+	if err != nil {
+		handleError(w, "invalid email", http.StatusBadRequest, "Login: invalid email", "error", err)
+		return
+	}
+
+	u, err := hdl.userSvc.Authenticate(r.Context(), mail.Address{Address: email.Address}, lp.Password)
+	if err != nil {
+		if errors.Is(err, user.ErrAuthenticationFailure) {
+			handleError(w, "invalid email or password", http.StatusUnauthorized, "Login: authentication failed", "error", err)
+			return
+		}
+		handleError(w, "", http.StatusInternalServerError, "Login", "error", err)
+		return
+	}
+
+	refreshToken, err := hdl.sessionSvc.Issue(r.Context(), u.ID)
+	if err != nil {
+		handleError(w, "", http.StatusInternalServerError, fmt.Sprintf("Login: userID %v: issue refresh token", u.ID), "error", err)
+		return
+	}
+
+	hdl.respondToken(w, u, refreshToken, "Login")
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh
+// token. Each refresh token can be used only once.
+func (hdl *Handlers) Refresh(w http.ResponseWriter, r *http.Request) {
+	var rp api.RefreshPost
+	err := json.NewDecoder(r.Body).Decode(&rp)
+	if err != nil {
+		handleError(w, "", http.StatusBadRequest, "Refresh: invalid body", "error", err)
+		return
+	}
+
+	userID, refreshToken, err := hdl.sessionSvc.Rotate(r.Context(), rp.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, session.ErrInvalidToken),
+			errors.Is(err, session.ErrTokenExpired),
+			errors.Is(err, session.ErrTokenReused):
+			handleError(w, "invalid refresh token", http.StatusUnauthorized, "Refresh: rejected", "error", err)
+		default:
+			handleError(w, "", http.StatusInternalServerError, "Refresh", "error", err)
+		}
+		return
+	}
+
+	// the roles may have changed since the last token was issued
+	u, err := hdl.userSvc.QueryByID(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			handleError(w, "invalid refresh token", http.StatusUnauthorized, fmt.Sprintf("Refresh: userID %v: user gone", userID), "error", err)
+			return
+		}
+		handleError(w, "", http.StatusInternalServerError, fmt.Sprintf("Refresh: userID %v", userID), "error", err)
+		return
+	}
+
+	hdl.respondToken(w, u, refreshToken, "Refresh")
+}
+
+// Logout revokes the access token of the request. If the body carries a
+// refresh token, its session is ended as well.
+func (hdl *Handlers) Logout(w http.ResponseWriter, r *http.Request) {
+	userID := mid.GetUserID(r.Context())
+
+	var rp api.RefreshPost
+	err := json.NewDecoder(r.Body).Decode(&rp)
+	if err != nil && !errors.Is(err, io.EOF) {
+		handleError(w, "", http.StatusBadRequest, "Logout: invalid body", "error", err)
+		return
+	}
+
+	if rp.RefreshToken != "" {
+		err := hdl.sessionSvc.Revoke(r.Context(), userID, rp.RefreshToken)
+		if err != nil && !errors.Is(err, session.ErrInvalidToken) {
+			handleError(w, "", http.StatusInternalServerError, fmt.Sprintf("Logout: userID %v: revoke refresh token", userID), "error", err)
+			return
+		}
+	}
+
+	if err := hdl.auth.Revoke(r.Context(), mid.GetClaims(r.Context())); err != nil {
+		handleError(w, "", http.StatusInternalServerError, fmt.Sprintf("Logout: userID %v: revoke access token", userID), "error", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	slog.Info(fmt.Sprintf("Success: Logout: userID %v", userID))
+}
+
+// LogoutAll ends every session of the user, including the one of the request.
+func (hdl *Handlers) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	userID := mid.GetUserID(r.Context())
+
+	if err := hdl.sessionSvc.RevokeAll(r.Context(), userID); err != nil {
+		handleError(w, "", http.StatusInternalServerError, fmt.Sprintf("LogoutAll: userID %v: revoke refresh tokens", userID), "error", err)
+		return
+	}
+
+	// the access tokens issued within the current second stay valid, see
+	// auth.Auth.RevokeAll
+	if err := hdl.auth.RevokeAll(r.Context(), userID, time.Now()); err != nil {
+		handleError(w, "", http.StatusInternalServerError, fmt.Sprintf("LogoutAll: userID %v: revoke access tokens", userID), "error", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	slog.Info(fmt.Sprintf("Success: LogoutAll: userID %v", userID))
+}
+
+func (hdl *Handlers) respondToken(w http.ResponseWriter, u user.User, refreshToken string, method string) {
+	accessToken, err := hdl.jwtSvc.CreateToken(u.ID, rolesToStrings(u.Roles), hdl.tokenTTL)
+	if err != nil {
+		handleError(w, "", http.StatusInternalServerError, fmt.Sprintf("%s: userID %v: create token", method, u.ID), "error", err)
+		return
+	}
+
+	token := api.Token{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(hdl.tokenTTL.Seconds()),
+		RefreshToken: refreshToken,
+	}
+	respond(w, http.StatusOK, token, fmt.Sprintf("%s: userID %v", method, u.ID))
+}
+
+func (hdl *Handlers) QueryMe(w http.ResponseWriter, r *http.Request) {
+	userID := mid.GetUserID(r.Context())
+
+	u, err := hdl.userSvc.QueryByID(r.Context(), userID)
+	if err != nil {
+		handleServiceError(w, fmt.Sprintf("QueryMe: userID %v", userID), err)
+		return
+	}
+
+	respond(w, http.StatusOK, toAPIUser(u), fmt.Sprintf("QueryMe: userID %v", userID))
+}
+
+func (hdl *Handlers) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	userID := mid.GetUserID(r.Context())
+
+	var up api.UserPatch
+	err := json.NewDecoder(r.Body).Decode(&up)
+	if err != nil {
+		handleError(w, "", http.StatusBadRequest, "UpdateMe: invalid body", "error", err)
+		return
+	}
+
+	var uu user.UpdateUser
+	if up.Name != nil {
+		uu.Name = user.NewName(*up.Name)
+	}
+	if up.Email != nil {
+		email, err := mail.ParseAddress(*up.Email)
+		if err != nil {
+			handleError(w, "invalid email", http.StatusBadRequest, "UpdateMe: invalid email", "error", err)
+			return
+		}
+		uu.Email = user.NewEmail(email.Address)
+	}
+	if up.Password != nil {
+		uu.Password = user.NewPassword(*up.Password)
+	}
+
+	u, err := hdl.userSvc.QueryByID(r.Context(), userID)
+	if err != nil {
+		handleServiceError(w, fmt.Sprintf("UpdateMe: userID %v", userID), err)
+		return
+	}
+
+	u, err = hdl.userSvc.Update(r.Context(), u, uu)
+	if err != nil {
+		handleServiceError(w, fmt.Sprintf("UpdateMe: userID %v", userID), err)
+		return
+	}
+
+	respond(w, http.StatusOK, toAPIUser(u), fmt.Sprintf("UpdateMe: userID %v", userID))
+}
+
+func (hdl *Handlers) DeleteMe(w http.ResponseWriter, r *http.Request) {
+	userID := mid.GetUserID(r.Context())
+
+	if err := hdl.userSvc.Delete(r.Context(), userID); err != nil {
+		handleServiceError(w, fmt.Sprintf("DeleteMe: userID %v", userID), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	slog.Info(fmt.Sprintf("Success: DeleteMe: userID %v", userID))
+}
+
+// QueryAll lists all users. It's meant for admins only.
+func (hdl *Handlers) QueryAll(w http.ResponseWriter, r *http.Request) {
+	users, err := hdl.userSvc.QueryAll(r.Context())
+	if err != nil {
+		handleError(w, "", http.StatusInternalServerError, "QueryAll", "error", err)
+		return
+	}
+
+	ret := make([]api.User, 0, len(users))
+	for _, u := range users {
+		ret = append(ret, toAPIUser(u))
+	}
+
+	respond(w, http.StatusOK, ret, "QueryAll")
+}
+
+// handleServiceError maps the errors of user.Service to status codes.
+func handleServiceError(w http.ResponseWriter, logMsg string, err error) {
+	switch {
+	case errors.Is(err, user.ErrEmailTaken):
+		handleError(w, "email already taken", http.StatusConflict, logMsg, "error", err)
+	case errors.Is(err, user.ErrInvalidPassword):
+		handleError(w, "invalid password", http.StatusBadRequest, logMsg, "error", err)
+	case errors.Is(err, user.ErrUserNotFound):
+		handleError(w, "", http.StatusNotFound, logMsg, "error", err)
+	default:
+		handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
+	}
+}
+
+func respond(w http.ResponseWriter, status int, data any, logMsg string) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		handleError(w, "", http.StatusInternalServerError, logMsg, "error", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+	slog.Info("Success: " + logMsg)
+}
+
+func handleError(w http.ResponseWriter, errMsg string, status int, logMsg string, args ...any) {
+	http.Error(w, errMsg, status)
+	slog.Error(logMsg, args...)
+}
+
+func toAPIUser(u user.User) api.User {
+	return api.User{
+		ID:    u.ID.String(),
+		Name:  u.Name.String(),
+		Email: u.Email.String().Address,
+		Roles: rolesToStrings(u.Roles),
+	}
+}
+
+func rolesToStrings(roles []user.Role) []string {
+	if len(roles) == 0 {
+		return nil
+	}
+	ret := make([]string, 0, len(roles))
+	for _, r := range roles {
+		ret = append(ret, string(r))
+	}
+	return ret
+}
@@ -0,0 +1,501 @@
+package usergrp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/app/api"
+	"github.com/Keisn1/note-taking-app/app/handlers/usergrp"
+	revocationmem "github.com/Keisn1/note-taking-app/domain/core/revocation/repositories/memory"
+	"github.com/Keisn1/note-taking-app/domain/core/session"
+	"github.com/Keisn1/note-taking-app/domain/core/session/repositories/memory"
+	"github.com/Keisn1/note-taking-app/domain/core/user"
+	usermem "github.com/Keisn1/note-taking-app/domain/core/user/repositories/memory"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/Keisn1/note-taking-app/domain/web/auth"
+	"github.com/Keisn1/note-taking-app/foundation"
+	"github.com/Keisn1/note-taking-app/foundation/common"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustEncode(t *testing.T, a any) string {
+	data, err := json.Marshal(a)
+	assert.NoError(t, err)
+	return string(data)
+}
+
+func setupRequest(t *testing.T, method string, userID uuid.UUID, body string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(method, "/notImplemented", strings.NewReader(body))
+	ctx := context.WithValue(req.Context(), foundation.UserIDKey, userID)
+	return req.WithContext(ctx)
+}
+
+func newSessionSvc() session.Service {
+	return session.NewSvc(memory.NewRepo(nil), transaction.NewMemoryManager(), time.Hour)
+}
+
+func newHandlers(us user.Service, ss session.Service, jwtSvc auth.JWTService) usergrp.Handlers {
+	return usergrp.NewHandlers(us, ss, auth.NewAuth(jwtSvc, revocationmem.NewRepo()), jwtSvc, time.Minute)
+}
+
+func Test_Create(t *testing.T) {
+	mUserSvc := &mockUserSvc{}
+	hdl := newHandlers(mUserSvc, newSessionSvc(), auth.MustNewJWTService(common.MustGenerateRandomKey(32)))
+
+	uu := user.UpdateUser{Name: user.NewName("rob"), Email: user.NewEmail("rob@example.com"), Password: user.NewPassword("password")}
+	rob := user.User{ID: uuid.UUID{1}, Name: user.NewName("rob"), Email: user.NewEmail("rob@example.com")}
+
+	testCases := []struct {
+		name       string
+		body       string
+		mUSP       []mockUserSvcParams
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Create success",
+			body:       mustEncode(t, api.UserPost{Name: "rob", Email: "rob@example.com", Password: "password"}),
+			mUSP:       []mockUserSvcParams{{method: "Create", arguments: []any{uu}, returnArguments: []any{rob, nil}}},
+			wantStatus: http.StatusCreated,
+			wantBody:   mustEncode(t, api.User{ID: rob.ID.String(), Name: "rob", Email: "rob@example.com"}),
+		},
+		{
+			name:       "Email taken",
+			body:       mustEncode(t, api.UserPost{Name: "rob", Email: "rob@example.com", Password: "password"}),
+			mUSP:       []mockUserSvcParams{{method: "Create", arguments: []any{uu}, returnArguments: []any{user.User{}, fmt.Errorf("create: %w", user.ErrEmailTaken)}}},
+			wantStatus: http.StatusConflict,
+			wantBody:   fmt.Sprintln("email already taken"),
+		},
+		{
+			name:       "Invalid email",
+			body:       mustEncode(t, api.UserPost{Name: "rob", Email: "not an email", Password: "password"}),
+			wantStatus: http.StatusBadRequest,
+			wantBody:   fmt.Sprintln("invalid email"),
+		},
+		{
+			name:       "Invalid body",
+			body:       "invalid body",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   fmt.Sprintln(""),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mUserSvc.Setup(tc.mUSP...)
+			req := setupRequest(t, http.MethodPost, uuid.UUID{}, tc.body)
+			rr := httptest.NewRecorder()
+
+			hdl.Create(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, tc.wantBody, rr.Body.String())
+			if len(tc.mUSP) == 0 {
+				mUserSvc.AssertNotCalled(t, "Create")
+			}
+		})
+	}
+}
+
+func Test_Login(t *testing.T) {
+	mUserSvc := &mockUserSvc{}
+	jwtSvc := auth.MustNewJWTService(common.MustGenerateRandomKey(32))
+	sessionSvc := newSessionSvc()
+	hdl := newHandlers(mUserSvc, sessionSvc, jwtSvc)
+
+	rob := user.User{ID: uuid.UUID{1}, Name: user.NewName("rob"), Email: user.NewEmail("rob@example.com"), Roles: []user.Role{user.RoleUser}}
+	email := mail.Address{Address: "rob@example.com"}
+
+	t.Run("Login success returns a token for the user", func(t *testing.T) {
+		mUserSvc.Setup(mockUserSvcParams{method: "Authenticate", arguments: []any{email, "password"}, returnArguments: []any{rob, nil}})
+		req := setupRequest(t, http.MethodPost, uuid.UUID{}, mustEncode(t, api.LoginPost{Email: "rob@example.com", Password: "password"}))
+		rr := httptest.NewRecorder()
+
+		hdl.Login(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var token api.Token
+		assert.NoError(t, json.NewDecoder(rr.Body).Decode(&token))
+		assert.Equal(t, "Bearer", token.TokenType)
+		assert.Equal(t, 60, token.ExpiresIn)
+
+		claims, err := jwtSvc.Verify(token.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, rob.ID.String(), claims.Subject)
+		assert.Equal(t, []string{"user"}, claims.Roles)
+		assert.Equal(t, auth.ScopesForRoles([]string{"user"}), claims.Scopes)
+
+		userID, _, err := sessionSvc.Rotate(context.Background(), token.RefreshToken)
+		assert.NoError(t, err)
+		assert.Equal(t, rob.ID, userID)
+	})
+
+	t.Run("Wrong credentials", func(t *testing.T) {
+		mUserSvc.Setup(mockUserSvcParams{
+			method:          "Authenticate",
+			arguments:       []any{email, "wrong"},
+			returnArguments: []any{user.User{}, fmt.Errorf("authenticate: %w", user.ErrAuthenticationFailure)},
+		})
+		req := setupRequest(t, http.MethodPost, uuid.UUID{}, mustEncode(t, api.LoginPost{Email: "rob@example.com", Password: "wrong"}))
+		rr := httptest.NewRecorder()
+
+		hdl.Login(rr, req)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("Service error", func(t *testing.T) {
+		mUserSvc.Setup(mockUserSvcParams{
+			method:          "Authenticate",
+			arguments:       []any{email, "password"},
+			returnArguments: []any{user.User{}, errors.New("DBError")},
+		})
+		req := setupRequest(t, http.MethodPost, uuid.UUID{}, mustEncode(t, api.LoginPost{Email: "rob@example.com", Password: "password"}))
+		rr := httptest.NewRecorder()
+
+		hdl.Login(rr, req)
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+
+	t.Run("The email is read like on signup", func(t *testing.T) {
+		mUserSvc.Setup(mockUserSvcParams{method: "Authenticate", arguments: []any{email, "password"}, returnArguments: []any{rob, nil}})
+		req := setupRequest(t, http.MethodPost, uuid.UUID{}, mustEncode(t, api.LoginPost{Email: " Rob <rob@example.com> ", Password: "password"}))
+		rr := httptest.NewRecorder()
+
+		hdl.Login(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Invalid email", func(t *testing.T) {
+		mUserSvc.Reset()
+		req := setupRequest(t, http.MethodPost, uuid.UUID{}, mustEncode(t, api.LoginPost{Email: "rob", Password: "password"}))
+		rr := httptest.NewRecorder()
+
+		hdl.Login(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mUserSvc.AssertNotCalled(t, "Authenticate")
+	})
+}
+
+func Test_Refresh(t *testing.T) {
+	jwtSvc := auth.MustNewJWTService(common.MustGenerateRandomKey(32))
+	sessionSvc := newSessionSvc()
+	mUserSvc := &mockUserSvc{}
+	hdl := newHandlers(mUserSvc, sessionSvc, jwtSvc)
+	userID := uuid.New()
+	mUserSvc.Setup(mockUserSvcParams{
+		method:          "QueryByID",
+		arguments:       []any{userID},
+		returnArguments: []any{user.User{ID: userID, Roles: []user.Role{user.RoleUser, user.RoleAdmin}}, nil},
+	})
+
+	refresh := func(refreshToken string) *httptest.ResponseRecorder {
+		req := setupRequest(t, http.MethodPost, uuid.UUID{}, mustEncode(t, api.RefreshPost{RefreshToken: refreshToken}))
+		rr := httptest.NewRecorder()
+		hdl.Refresh(rr, req)
+		return rr
+	}
+
+	refreshToken, err := sessionSvc.Issue(context.Background(), userID)
+	assert.NoError(t, err)
+
+	t.Run("Refresh returns new access and refresh token", func(t *testing.T) {
+		rr := refresh(refreshToken)
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var token api.Token
+		assert.NoError(t, json.NewDecoder(rr.Body).Decode(&token))
+		assert.NotEqual(t, refreshToken, token.RefreshToken)
+
+		claims, err := jwtSvc.Verify(token.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, userID.String(), claims.Subject)
+		// roles are looked up again on refresh
+		assert.Equal(t, []string{"user", "admin"}, claims.Roles)
+	})
+
+	t.Run("Replaying a used refresh token is rejected", func(t *testing.T) {
+		rr := refresh(refreshToken)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Equal(t, fmt.Sprintln("invalid refresh token"), rr.Body.String())
+	})
+
+	t.Run("Unknown refresh token", func(t *testing.T) {
+		rr := refresh("unknown")
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("User deleted in the meantime", func(t *testing.T) {
+		goneID := uuid.New()
+		mUserSvc.Setup(mockUserSvcParams{method: "QueryByID", arguments: []any{goneID}, returnArguments: []any{user.User{}, user.ErrUserNotFound}})
+		refreshToken, err := sessionSvc.Issue(context.Background(), goneID)
+		assert.NoError(t, err)
+
+		rr := refresh(refreshToken)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func Test_Me(t *testing.T) {
+	mUserSvc := &mockUserSvc{}
+	hdl := newHandlers(mUserSvc, newSessionSvc(), auth.MustNewJWTService(common.MustGenerateRandomKey(32)))
+
+	rob := user.User{ID: uuid.UUID{1}, Name: user.NewName("rob"), Email: user.NewEmail("rob@example.com")}
+	newName := "robbie"
+
+	testCases := []struct {
+		name       string
+		handler    http.HandlerFunc
+		method     string
+		body       string
+		mUSP       []mockUserSvcParams
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "QueryMe success",
+			handler:    hdl.QueryMe,
+			method:     http.MethodGet,
+			mUSP:       []mockUserSvcParams{{method: "QueryByID", arguments: []any{rob.ID}, returnArguments: []any{rob, nil}}},
+			wantStatus: http.StatusOK,
+			wantBody:   mustEncode(t, api.User{ID: rob.ID.String(), Name: "rob", Email: "rob@example.com"}),
+		},
+		{
+			name:       "QueryMe user gone",
+			handler:    hdl.QueryMe,
+			method:     http.MethodGet,
+			mUSP:       []mockUserSvcParams{{method: "QueryByID", arguments: []any{rob.ID}, returnArguments: []any{user.User{}, user.ErrUserNotFound}}},
+			wantStatus: http.StatusNotFound,
+			wantBody:   fmt.Sprintln(""),
+		},
+		{
+			name:    "UpdateMe changes only the given fields",
+			handler: hdl.UpdateMe,
+			method:  http.MethodPatch,
+			body:    mustEncode(t, api.UserPatch{Name: &newName}),
+			mUSP: []mockUserSvcParams{
+				{method: "QueryByID", arguments: []any{rob.ID}, returnArguments: []any{rob, nil}},
+				{
+					method:          "Update",
+					arguments:       []any{rob, user.UpdateUser{Name: user.NewName("robbie")}},
+					returnArguments: []any{user.User{ID: rob.ID, Name: user.NewName("robbie"), Email: rob.Email}, nil},
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   mustEncode(t, api.User{ID: rob.ID.String(), Name: "robbie", Email: "rob@example.com"}),
+		},
+		{
+			name:       "DeleteMe success",
+			handler:    hdl.DeleteMe,
+			method:     http.MethodDelete,
+			mUSP:       []mockUserSvcParams{{method: "Delete", arguments: []any{rob.ID}, returnArguments: []any{nil}}},
+			wantStatus: http.StatusNoContent,
+			wantBody:   "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mUserSvc.Setup(tc.mUSP...)
+			req := setupRequest(t, tc.method, rob.ID, tc.body)
+			rr := httptest.NewRecorder()
+
+			tc.handler(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, tc.wantBody, rr.Body.String())
+			for _, p := range tc.mUSP {
+				mUserSvc.AssertCalled(t, p.method, p.arguments...)
+			}
+		})
+	}
+}
+
+func Test_UpdateMe(t *testing.T) {
+	rob := user.User{ID: uuid.New(), Name: user.NewName("rob"), Email: user.NewEmail("rob@example.com")}
+	userSvc := user.NewSvc(usermem.NewRepo([]user.User{rob}), transaction.NewMemoryManager())
+	hdl := newHandlers(userSvc, newSessionSvc(), auth.MustNewJWTService(common.MustGenerateRandomKey(32)))
+
+	for _, password := range []string{"", strings.Repeat("72", 37)} {
+		req := setupRequest(t, http.MethodPatch, rob.ID, mustEncode(t, api.UserPatch{Password: &password}))
+		rr := httptest.NewRecorder()
+
+		hdl.UpdateMe(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, password)
+		assert.Equal(t, fmt.Sprintln("invalid password"), rr.Body.String())
+	}
+}
+
+func Test_DeleteMe(t *testing.T) {
+	ctx := context.Background()
+	jwtSvc := auth.MustNewJWTService(common.MustGenerateRandomKey(32))
+	revocations := revocationmem.NewRepo()
+	a := auth.NewAuth(jwtSvc, revocations)
+	rob := user.User{ID: uuid.New(), Name: user.NewName("rob"), Email: user.NewEmail("rob@example.com")}
+	revokeTokens := func(ctx context.Context, userID uuid.UUID) error {
+		return revocations.RevokeUser(ctx, userID, time.Now())
+	}
+	userSvc := user.NewSvc(usermem.NewRepo([]user.User{rob}), transaction.NewMemoryManager(), revokeTokens)
+	hdl := usergrp.NewHandlers(userSvc, newSessionSvc(), a, jwtSvc, time.Minute)
+
+	accessToken, err := jwtSvc.CreateToken(rob.ID, nil, time.Minute)
+	assert.NoError(t, err)
+	bearer := "Bearer " + accessToken
+	_, err = a.Authenticate(ctx, bearer)
+	assert.NoError(t, err)
+
+	req := setupRequest(t, http.MethodDelete, rob.ID, "")
+	rr := httptest.NewRecorder()
+	hdl.DeleteMe(rr, req)
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	// the token of the deleted user is not accepted anymore
+	_, err = a.Authenticate(ctx, bearer)
+	assert.ErrorIs(t, err, auth.ErrTokenRevoked)
+}
+
+func Test_Logout(t *testing.T) {
+	ctx := context.Background()
+	jwtSvc := auth.MustNewJWTService(common.MustGenerateRandomKey(32))
+	a := auth.NewAuth(jwtSvc, revocationmem.NewRepo())
+	sessionSvc := newSessionSvc()
+	hdl := usergrp.NewHandlers(&mockUserSvc{}, sessionSvc, a, jwtSvc, time.Minute)
+	userID := uuid.New()
+
+	login := func(t *testing.T) (string, string) {
+		accessToken, err := jwtSvc.CreateToken(userID, nil, time.Minute)
+		assert.NoError(t, err)
+		refreshToken, err := sessionSvc.Issue(ctx, userID)
+		assert.NoError(t, err)
+		return "Bearer " + accessToken, refreshToken
+	}
+
+	withClaims := func(t *testing.T, req *http.Request, bearer string) *http.Request {
+		claims, err := a.Authenticate(ctx, bearer)
+		assert.NoError(t, err)
+		return req.WithContext(context.WithValue(req.Context(), foundation.ClaimsKey, claims))
+	}
+
+	t.Run("Logout revokes access and refresh token", func(t *testing.T) {
+		bearer, refreshToken := login(t)
+		otherBearer, otherRefreshToken := login(t)
+
+		req := setupRequest(t, http.MethodPost, userID, mustEncode(t, api.RefreshPost{RefreshToken: refreshToken}))
+		req = withClaims(t, req, bearer)
+		rr := httptest.NewRecorder()
+
+		hdl.Logout(rr, req)
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+
+		_, err := a.Authenticate(ctx, bearer)
+		assert.ErrorIs(t, err, auth.ErrTokenRevoked)
+		_, _, err = sessionSvc.Rotate(ctx, refreshToken)
+		assert.ErrorIs(t, err, session.ErrInvalidToken)
+
+		// other sessions stay logged in
+		_, err = a.Authenticate(ctx, otherBearer)
+		assert.NoError(t, err)
+		_, _, err = sessionSvc.Rotate(ctx, otherRefreshToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Logout without body", func(t *testing.T) {
+		bearer, _ := login(t)
+
+		req := setupRequest(t, http.MethodPost, userID, "")
+		req = withClaims(t, req, bearer)
+		rr := httptest.NewRecorder()
+
+		hdl.Logout(rr, req)
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+
+		_, err := a.Authenticate(ctx, bearer)
+		assert.ErrorIs(t, err, auth.ErrTokenRevoked)
+	})
+
+	// waitNextSecond waits until the tokens issued so far are older than iat
+	// of the ones issued next, which has second precision
+	waitNextSecond := func() {
+		time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+	}
+
+	t.Run("LogoutAll revokes every session of the user", func(t *testing.T) {
+		bearer, refreshToken := login(t)
+		otherBearer, otherRefreshToken := login(t)
+		waitNextSecond()
+
+		req := setupRequest(t, http.MethodPost, userID, "")
+		req = withClaims(t, req, bearer)
+		rr := httptest.NewRecorder()
+
+		hdl.LogoutAll(rr, req)
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+
+		for _, b := range []string{bearer, otherBearer} {
+			_, err := a.Authenticate(ctx, b)
+			assert.ErrorIs(t, err, auth.ErrTokenRevoked)
+		}
+		for _, rt := range []string{refreshToken, otherRefreshToken} {
+			_, _, err := sessionSvc.Rotate(ctx, rt)
+			assert.ErrorIs(t, err, session.ErrInvalidToken)
+		}
+	})
+
+	t.Run("Logging in right after LogoutAll works", func(t *testing.T) {
+		bearer, _ := login(t)
+
+		req := setupRequest(t, http.MethodPost, userID, "")
+		req = withClaims(t, req, bearer)
+		rr := httptest.NewRecorder()
+
+		hdl.LogoutAll(rr, req)
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+
+		newBearer, newRefreshToken := login(t)
+		_, err := a.Authenticate(ctx, newBearer)
+		assert.NoError(t, err)
+		_, _, err = sessionSvc.Rotate(ctx, newRefreshToken)
+		assert.NoError(t, err)
+	})
+}
+
+func Test_QueryAll(t *testing.T) {
+	mUserSvc := &mockUserSvc{}
+	hdl := newHandlers(mUserSvc, newSessionSvc(), auth.MustNewJWTService(common.MustGenerateRandomKey(32)))
+
+	users := []user.User{
+		{ID: uuid.UUID{1}, Name: user.NewName("anna"), Email: user.NewEmail("anna@example.com"), Roles: []user.Role{user.RoleUser, user.RoleAdmin}},
+		{ID: uuid.UUID{2}, Name: user.NewName("rob"), Email: user.NewEmail("rob@example.com"), Roles: []user.Role{user.RoleUser}},
+	}
+
+	t.Run("Lists all users", func(t *testing.T) {
+		mUserSvc.Setup(mockUserSvcParams{method: "QueryAll", returnArguments: []any{users, nil}})
+		req := setupRequest(t, http.MethodGet, uuid.UUID{1}, "")
+		rr := httptest.NewRecorder()
+
+		hdl.QueryAll(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, mustEncode(t, []api.User{
+			{ID: uuid.UUID{1}.String(), Name: "anna", Email: "anna@example.com", Roles: []string{"user", "admin"}},
+			{ID: uuid.UUID{2}.String(), Name: "rob", Email: "rob@example.com", Roles: []string{"user"}},
+		}), rr.Body.String())
+	})
+
+	t.Run("Service error", func(t *testing.T) {
+		mUserSvc.Setup(mockUserSvcParams{method: "QueryAll", returnArguments: []any{[]user.User(nil), errors.New("DBError")}})
+		req := setupRequest(t, http.MethodGet, uuid.UUID{1}, "")
+		rr := httptest.NewRecorder()
+
+		hdl.QueryAll(rr, req)
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
@@ -0,0 +1,455 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/app/handlers/attachmentgrp"
+	"github.com/Keisn1/note-taking-app/app/handlers/authgrp"
+	"github.com/Keisn1/note-taking-app/app/handlers/linkgrp"
+	"github.com/Keisn1/note-taking-app/app/handlers/notebookgrp"
+	"github.com/Keisn1/note-taking-app/app/handlers/notesgrp"
+	"github.com/Keisn1/note-taking-app/app/handlers/usergrp"
+	"github.com/Keisn1/note-taking-app/domain/core/attachment"
+	"github.com/Keisn1/note-taking-app/domain/core/attachment/blobstores/localfs"
+	"github.com/Keisn1/note-taking-app/domain/core/attachment/blobstores/s3"
+	"github.com/Keisn1/note-taking-app/domain/core/attachment/repositories/attachmentdb"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/core/note/repositories/notedb"
+	"github.com/Keisn1/note-taking-app/domain/core/notebook"
+	"github.com/Keisn1/note-taking-app/domain/core/notebook/repositories/notebookdb"
+	"github.com/Keisn1/note-taking-app/domain/core/revocation/repositories/revocationdb"
+	"github.com/Keisn1/note-taking-app/domain/core/session"
+	"github.com/Keisn1/note-taking-app/domain/core/session/repositories/sessiondb"
+	"github.com/Keisn1/note-taking-app/domain/core/sharelink"
+	"github.com/Keisn1/note-taking-app/domain/core/sharelink/repositories/sharelinkdb"
+	"github.com/Keisn1/note-taking-app/domain/core/user"
+	"github.com/Keisn1/note-taking-app/domain/core/user/repositories/userdb"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/Keisn1/note-taking-app/domain/web/auth"
+	"github.com/Keisn1/note-taking-app/domain/web/mux"
+	"github.com/Keisn1/note-taking-app/foundation/web"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			slog.Error("migrate", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := run(); err != nil {
+		slog.Error("startup", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loadConfig: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+	// Database
+
+	db, closeDB, err := openDB(cfg.DB)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	// -------------------------------------------------------------------------
+	// Services
+
+	jwtSvc, ks, err := newJWTService(cfg)
+	if err != nil {
+		return err
+	}
+
+	txm := transaction.NewSQLManager(db)
+	noteRepo := notedb.NewNotesRepo(db)
+	shareRepo := notedb.NewShareRepo(db)
+	refreshTokenRepo := sessiondb.NewRefreshTokenRepo(db)
+	linkRepo := sharelinkdb.NewLinkRepo(db)
+	notebookRepo := notebookdb.NewNotebookRepo(db)
+	revocationRepo := revocationdb.NewRevocationRepo(db)
+
+	// deleting a user deletes everything kept of them in the same transaction
+	deleteNotes := func(ctx context.Context, userID uuid.UUID) error {
+		_, err := noteRepo.DeleteByUserID(ctx, userID)
+		return err
+	}
+	// the access tokens of the user stay valid until they expire unless they
+	// are revoked, the cutoff is kept after the user is gone
+	revokeTokens := func(ctx context.Context, userID uuid.UUID) error {
+		return revocationRepo.RevokeUser(ctx, userID, time.Now())
+	}
+	userSvc := user.NewSvc(userdb.NewUserRepo(db), txm,
+		linkRepo.DeleteByCreator,
+		deleteNotes,
+		shareRepo.DeleteByUserID,
+		notebookRepo.DeleteByUserID,
+		refreshTokenRepo.DeleteByUserID,
+		revokeTokens,
+	)
+	noteSvc := note.NewNotesService(noteRepo, shareRepo, notedb.NewRevisionRepo(db), userSvc, txm)
+	sessionSvc := session.NewSvc(refreshTokenRepo, txm, cfg.Auth.RefreshTokenTTL)
+	linkSvc := sharelink.NewSvc(linkRepo, noteSvc)
+	notebookSvc := notebook.NewSvc(notebookRepo, noteSvc, txm)
+
+	blobs, err := newBlobStore(cfg.Attachments)
+	if err != nil {
+		return err
+	}
+	attachmentSvc := attachment.NewSvc(attachmentdb.NewAttachmentRepo(db), blobs, cfg.Attachments.Quota)
+
+	muxCfg := mux.Config{
+		Auth:              auth.NewAuth(jwtSvc, revocationRepo),
+		JWTSvc:            jwtSvc,
+		KeyStore:          ks,
+		TokenTTL:          cfg.Auth.TokenTTL,
+		NoteSvc:           noteSvc,
+		UserSvc:           userSvc,
+		SessionSvc:        sessionSvc,
+		LinkSvc:           linkSvc,
+		NotebookSvc:       notebookSvc,
+		AttachmentSvc:     attachmentSvc,
+		MaxAttachmentSize: cfg.Attachments.MaxSize,
+	}
+
+	// -------------------------------------------------------------------------
+	// Background jobs
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go purgeTrash(jobsCtx, noteSvc, attachmentSvc, revocationRepo, cfg.Notes.TrashRetention, cfg.Notes.PurgeInterval)
+
+	// -------------------------------------------------------------------------
+	// API
+
+	api := http.Server{
+		Addr:         cfg.Web.APIHost,
+		Handler:      mux.NewAPI(routes, muxCfg),
+		ReadTimeout:  cfg.Web.ReadTimeout,
+		WriteTimeout: cfg.Web.WriteTimeout,
+		IdleTimeout:  cfg.Web.IdleTimeout,
+	}
+
+	serverErrors := make(chan error, 1)
+	go func() {
+		slog.Info("startup", "status", "api router started", "host", api.Addr)
+		serverErrors <- api.ListenAndServe()
+	}()
+
+	shutdown := make(chan os.Signal, 1)
+	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-serverErrors:
+		return fmt.Errorf("server error: %w", err)
+
+	case sig := <-shutdown:
+		slog.Info("shutdown", "status", "shutdown started", "signal", sig)
+		defer slog.Info("shutdown", "status", "shutdown complete", "signal", sig)
+
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Web.ShutdownTimeout)
+		defer cancel()
+
+		// Shutdown stops accepting new connections and waits for in-flight
+		// requests to drain until ctx expires.
+		if err := api.Shutdown(ctx); err != nil {
+			api.Close()
+			return fmt.Errorf("could not stop server gracefully: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func routes(app *web.App, cfg mux.Config) {
+	notesgrp.Routes(app, notesgrp.Config{Auth: cfg.Auth, NoteSvc: cfg.NoteSvc})
+	linkgrp.Routes(app, linkgrp.Config{Auth: cfg.Auth, NoteSvc: cfg.NoteSvc, LinkSvc: cfg.LinkSvc})
+	notebookgrp.Routes(app, notebookgrp.Config{Auth: cfg.Auth, NoteSvc: cfg.NoteSvc, NotebookSvc: cfg.NotebookSvc})
+	attachmentgrp.Routes(app, attachmentgrp.Config{
+		Auth:          cfg.Auth,
+		NoteSvc:       cfg.NoteSvc,
+		AttachmentSvc: cfg.AttachmentSvc,
+		MaxSize:       cfg.MaxAttachmentSize,
+	})
+	usergrp.Routes(app, usergrp.Config{
+		Auth:       cfg.Auth,
+		JWTSvc:     cfg.JWTSvc,
+		UserSvc:    cfg.UserSvc,
+		SessionSvc: cfg.SessionSvc,
+		TokenTTL:   cfg.TokenTTL,
+	})
+	if cfg.KeyStore != nil {
+		authgrp.Routes(app, authgrp.Config{KeyStore: cfg.KeyStore})
+	}
+}
+
+// newJWTService signs tokens with the asymmetric keys in JWT_KEYS_DIR when it
+// is set and falls back to the shared JWT_KEY otherwise.
+func newJWTService(cfg config) (auth.JWTService, *auth.KeyStore, error) {
+	opts := []auth.Option{
+		auth.WithIssuer(cfg.Auth.Issuer),
+		auth.WithAudience(cfg.Auth.Audience),
+		auth.WithLeeway(cfg.Auth.Leeway),
+	}
+
+	if cfg.Auth.KeysDir == "" {
+		jwtSvc, err := auth.NewJWTService(cfg.Auth.Key, opts...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("newJWTService: %w", err)
+		}
+		return jwtSvc, nil, nil
+	}
+
+	ks, err := auth.LoadKeyStore(os.DirFS(cfg.Auth.KeysDir), ".", cfg.Auth.ActiveKID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("newJWTService: %w", err)
+	}
+	return auth.NewAsymJWTService(ks, opts...), ks, nil
+}
+
+// newBlobStore keeps attachments on the local disk unless ATTACHMENT_STORE is
+// set to s3.
+func newBlobStore(cfg attachmentConfig) (attachment.BlobStore, error) {
+	switch cfg.Store {
+	case "local":
+		blobs, err := localfs.New(cfg.Dir)
+		if err != nil {
+			return nil, fmt.Errorf("newBlobStore: %w", err)
+		}
+		return blobs, nil
+	case "s3":
+		blobs, err := s3.New(cfg.S3)
+		if err != nil {
+			return nil, fmt.Errorf("newBlobStore: %w", err)
+		}
+		return blobs, nil
+	default:
+		return nil, fmt.Errorf("newBlobStore: unknown ATTACHMENT_STORE %q", cfg.Store)
+	}
+}
+
+// =============================================================================
+
+type config struct {
+	Web struct {
+		APIHost         string
+		ReadTimeout     time.Duration
+		WriteTimeout    time.Duration
+		IdleTimeout     time.Duration
+		ShutdownTimeout time.Duration
+	}
+	DB    dbConfig
+	Notes struct {
+		TrashRetention time.Duration
+		PurgeInterval  time.Duration
+	}
+	Attachments attachmentConfig
+	Auth        struct {
+		Key             []byte
+		KeysDir         string
+		ActiveKID       string
+		Issuer          string
+		Audience        string
+		Leeway          time.Duration
+		TokenTTL        time.Duration
+		RefreshTokenTTL time.Duration
+	}
+}
+
+type attachmentConfig struct {
+	Store   string
+	Dir     string
+	S3      s3.Config
+	Quota   int64
+	MaxSize int64
+}
+
+type dbConfig struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	Name     string
+	SSLMode  string
+}
+
+// openDB opens a pgx pool and exposes it as *sql.DB, which is what the
+// repositories expect.
+func openDB(cfg dbConfig) (*sql.DB, func(), error) {
+	pool, err := pgxpool.New(context.Background(), cfg.dsn())
+	if err != nil {
+		return nil, nil, fmt.Errorf("connecting to db: %w", err)
+	}
+
+	db := stdlib.OpenDBFromPool(pool)
+	closeDB := func() {
+		db.Close()
+		pool.Close()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		closeDB()
+		return nil, nil, fmt.Errorf("ping db: %w", err)
+	}
+
+	return db, closeDB, nil
+}
+
+func (c dbConfig) dsn() string {
+	return fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		c.Host, c.Port, c.User, c.Password, c.Name, c.SSLMode,
+	)
+}
+
+func loadConfig() (config, error) {
+	var cfg config
+	var err error
+
+	cfg.Web.APIHost = getEnv("SERVER_ADDRESS", ":3000")
+	if cfg.Web.ReadTimeout, err = getEnvDuration("READ_TIMEOUT", 5*time.Second); err != nil {
+		return config{}, err
+	}
+	if cfg.Web.WriteTimeout, err = getEnvDuration("WRITE_TIMEOUT", 10*time.Second); err != nil {
+		return config{}, err
+	}
+	if cfg.Web.IdleTimeout, err = getEnvDuration("IDLE_TIMEOUT", 120*time.Second); err != nil {
+		return config{}, err
+	}
+	if cfg.Web.ShutdownTimeout, err = getEnvDuration("SHUTDOWN_TIMEOUT", 20*time.Second); err != nil {
+		return config{}, err
+	}
+
+	cfg.DB = loadDBConfig()
+
+	if cfg.Notes.TrashRetention, err = getEnvDuration("TRASH_RETENTION", 30*24*time.Hour); err != nil {
+		return config{}, err
+	}
+	if cfg.Notes.PurgeInterval, err = getEnvDuration("TRASH_PURGE_INTERVAL", time.Hour); err != nil {
+		return config{}, err
+	}
+
+	if cfg.Attachments, err = loadAttachmentConfig(); err != nil {
+		return config{}, err
+	}
+
+	if cfg.Auth.TokenTTL, err = getEnvDuration("TOKEN_TTL", 15*time.Minute); err != nil {
+		return config{}, err
+	}
+	if cfg.Auth.RefreshTokenTTL, err = getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour); err != nil {
+		return config{}, err
+	}
+
+	cfg.Auth.Issuer = getEnv("JWT_ISSUER", "notes-api")
+	cfg.Auth.Audience = getEnv("JWT_AUDIENCE", "notes-api")
+	if cfg.Auth.Leeway, err = getEnvDuration("JWT_LEEWAY", 30*time.Second); err != nil {
+		return config{}, err
+	}
+
+	cfg.Auth.KeysDir = os.Getenv("JWT_KEYS_DIR")
+	if cfg.Auth.KeysDir != "" {
+		cfg.Auth.ActiveKID = os.Getenv("JWT_ACTIVE_KID")
+		if cfg.Auth.ActiveKID == "" {
+			return config{}, errors.New("JWT_ACTIVE_KID not set")
+		}
+		return cfg, nil
+	}
+
+	jwtKey := os.Getenv("JWT_KEY")
+	if jwtKey == "" {
+		return config{}, errors.New("neither JWT_KEYS_DIR nor JWT_KEY set")
+	}
+	if cfg.Auth.Key, err = base64.StdEncoding.DecodeString(jwtKey); err != nil {
+		return config{}, fmt.Errorf("JWT_KEY: expected base64: %w", err)
+	}
+
+	return cfg, nil
+}
+
+func loadDBConfig() dbConfig {
+	return dbConfig{
+		Host:     getEnv("DB_HOST", "localhost"),
+		Port:     getEnv("DB_PORT", "5432"),
+		User:     getEnv("DB_USER", "postgres"),
+		Password: getEnv("DB_PASSWORD", "password"),
+		Name:     getEnv("DB_NAME", "note_taking_app"),
+		SSLMode:  getEnv("DB_SSLMODE", "disable"),
+	}
+}
+
+func loadAttachmentConfig() (attachmentConfig, error) {
+	cfg := attachmentConfig{
+		Store: getEnv("ATTACHMENT_STORE", "local"),
+		Dir:   getEnv("ATTACHMENT_DIR", "data/attachments"),
+		S3: s3.Config{
+			Endpoint:  getEnv("S3_ENDPOINT", "https://s3.amazonaws.com"),
+			Region:    getEnv("S3_REGION", "us-east-1"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+		},
+	}
+
+	var err error
+	if cfg.Quota, err = getEnvInt64("ATTACHMENT_QUOTA", 100<<20); err != nil {
+		return attachmentConfig{}, err
+	}
+	if cfg.MaxSize, err = getEnvInt64("MAX_ATTACHMENT_SIZE", 10<<20); err != nil {
+		return attachmentConfig{}, err
+	}
+	return cfg, nil
+}
+
+func getEnv(key, fallback string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return fallback
+}
+
+func getEnvDuration(key string, fallback time.Duration) (time.Duration, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", key, err)
+	}
+	return d, nil
+}
+
+func getEnvInt64(key string, fallback int64) (int64, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback, nil
+	}
+	i, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", key, err)
+	}
+	return i, nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/attachment"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/core/revocation"
+)
+
+// purgeTrash deletes the notes that have been in the trash for longer than
+// retention every interval, until ctx is done. The attachments of purged
+// notes are deleted with them. Revoked tokens are dropped from the denylist
+// once they have expired.
+func purgeTrash(ctx context.Context, noteSvc note.Service, attachmentSvc attachment.Service, revoked revocation.Repo, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := noteSvc.PurgeTrash(ctx, retention)
+		if err != nil {
+			slog.Error("purge trash", "error", err)
+		} else if purged > 0 {
+			slog.Info("purge trash", "purged", purged)
+		}
+
+		orphans, err := attachmentSvc.PurgeOrphans(ctx)
+		if err != nil {
+			slog.Error("purge attachments", "error", err)
+		} else if orphans > 0 {
+			slog.Info("purge attachments", "purged", orphans)
+		}
+
+		expired, err := revoked.PurgeExpired(ctx, time.Now())
+		if err != nil {
+			slog.Error("purge revoked tokens", "error", err)
+		} else if expired > 0 {
+			slog.Info("purge revoked tokens", "purged", expired)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package attachment
+
+import (
+	"encoding/hex"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Attachment is a file stored with a note. The file itself lives in a
+// BlobStore under the ID of the attachment. It counts against the storage
+// quota of UserID, the owner of the note.
+type Attachment struct {
+	ID          uuid.UUID
+	NoteID      uuid.UUID
+	UserID      uuid.UUID
+	Filename    string
+	ContentType string
+	Size        int64
+	SHA256      []byte
+	CreatedAt   time.Time
+}
+
+// NewAttachment is a file to be attached. Body has to yield exactly Size
+// bytes. The content type is detected from the file if ContentType is empty.
+type NewAttachment struct {
+	Filename    string
+	ContentType string
+	Size        int64
+	Body        io.Reader
+}
+
+// Usage is the storage used by a user. A Quota of 0 means unlimited.
+type Usage struct {
+	Used  int64
+	Quota int64
+}
+
+func (a Attachment) Key() string { return a.ID.String() }
+
+// ETag is the quoted hex sha256 of the file.
+func (a Attachment) ETag() string { return `"` + hex.EncodeToString(a.SHA256) + `"` }
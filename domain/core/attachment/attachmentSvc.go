@@ -0,0 +1,212 @@
+package attachment
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidFilename = errors.New("invalid filename")
+	ErrInvalidSize     = errors.New("the file doesn't match its size")
+)
+
+const MaxFilenameLength = 255
+
+type Service interface {
+	Create(ctx context.Context, n note.Note, na NewAttachment) (Attachment, error)
+	QueryByNoteID(ctx context.Context, noteID uuid.UUID) ([]Attachment, error)
+	// Open returns an attachment of noteID together with its contents, which
+	// the caller has to close.
+	Open(ctx context.Context, noteID, attachmentID uuid.UUID) (Attachment, io.ReadSeekCloser, error)
+	Delete(ctx context.Context, noteID, attachmentID uuid.UUID) error
+	Usage(ctx context.Context, userID uuid.UUID) (Usage, error)
+	PurgeOrphans(ctx context.Context) (int, error)
+}
+
+type Svc struct {
+	repo  Repo
+	blobs BlobStore
+	quota int64
+}
+
+// NewSvc returns a Service storing files in blobs. quota limits the bytes
+// each user may store, 0 means unlimited.
+func NewSvc(repo Repo, blobs BlobStore, quota int64) Service {
+	return Svc{repo: repo, blobs: blobs, quota: quota}
+}
+
+// Create stores the file of na with n. The quota of the owner of n is checked
+// before the upload and again when the attachment is recorded, the latter
+// catching concurrent uploads in most cases.
+func (s Svc) Create(ctx context.Context, n note.Note, na NewAttachment) (Attachment, error) {
+	filename, err := validateFilename(na.Filename)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("create: [%s]: %w", n.ID, err)
+	}
+	if na.Size < 0 {
+		return Attachment{}, fmt.Errorf("create: [%s]: %w", n.ID, ErrInvalidSize)
+	}
+
+	if s.quota > 0 {
+		used, err := s.repo.Usage(ctx, n.UserID)
+		if err != nil {
+			return Attachment{}, fmt.Errorf("create: [%s]: %w", n.ID, err)
+		}
+		if used+na.Size > s.quota {
+			return Attachment{}, fmt.Errorf("create: [%s]: %w", n.ID, ErrQuotaExceeded)
+		}
+	}
+
+	body := bufio.NewReader(na.Body)
+	contentType := na.ContentType
+	if contentType == "" {
+		head, _ := body.Peek(512)
+		contentType = http.DetectContentType(head)
+	}
+
+	a := Attachment{
+		ID:          uuid.New(),
+		NoteID:      n.ID,
+		UserID:      n.UserID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        na.Size,
+		CreatedAt:   time.Now().UTC().Truncate(time.Microsecond),
+	}
+
+	h := sha256.New()
+	cr := &countingReader{r: io.TeeReader(io.LimitReader(body, na.Size), h)}
+	if err := s.blobs.Put(ctx, a.Key(), cr, na.Size); err != nil {
+		return Attachment{}, fmt.Errorf("create: [%s]: %w", n.ID, err)
+	}
+
+	// the body must end where its size says
+	extra, _ := body.Read(make([]byte, 1))
+	if cr.n != na.Size || extra > 0 {
+		s.deleteBlob(ctx, a.Key())
+		return Attachment{}, fmt.Errorf("create: [%s]: %w", n.ID, ErrInvalidSize)
+	}
+	a.SHA256 = h.Sum(nil)
+
+	if err := s.repo.Create(ctx, a, s.quota); err != nil {
+		s.deleteBlob(ctx, a.Key())
+		return Attachment{}, fmt.Errorf("create: [%s]: %w", n.ID, err)
+	}
+	return a, nil
+}
+
+func (s Svc) QueryByNoteID(ctx context.Context, noteID uuid.UUID) ([]Attachment, error) {
+	attachments, err := s.repo.QueryByNoteID(ctx, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("queryByNoteID: [%s]: %w", noteID, err)
+	}
+	return attachments, nil
+}
+
+func (s Svc) Open(ctx context.Context, noteID, attachmentID uuid.UUID) (Attachment, io.ReadSeekCloser, error) {
+	a, err := s.queryByID(ctx, noteID, attachmentID)
+	if err != nil {
+		return Attachment{}, nil, fmt.Errorf("open: [%s]: %w", attachmentID, err)
+	}
+
+	blob, err := s.blobs.Open(ctx, a.Key())
+	if err != nil {
+		return Attachment{}, nil, fmt.Errorf("open: [%s]: %w", attachmentID, err)
+	}
+	return a, blob, nil
+}
+
+// Delete deletes the attachment before its file, so that a failure leaves an
+// unreferenced file rather than an attachment without contents.
+func (s Svc) Delete(ctx context.Context, noteID, attachmentID uuid.UUID) error {
+	a, err := s.queryByID(ctx, noteID, attachmentID)
+	if err != nil {
+		return fmt.Errorf("delete: [%s]: %w", attachmentID, err)
+	}
+
+	if err := s.repo.Delete(ctx, a.ID); err != nil {
+		return fmt.Errorf("delete: [%s]: %w", attachmentID, err)
+	}
+	if err := s.blobs.Delete(ctx, a.Key()); err != nil {
+		return fmt.Errorf("delete: [%s]: %w", attachmentID, err)
+	}
+	return nil
+}
+
+func (s Svc) Usage(ctx context.Context, userID uuid.UUID) (Usage, error) {
+	used, err := s.repo.Usage(ctx, userID)
+	if err != nil {
+		return Usage{}, fmt.Errorf("usage: [%s]: %w", userID, err)
+	}
+	return Usage{Used: used, Quota: s.quota}, nil
+}
+
+// PurgeOrphans deletes the attachments of notes that have been purged from
+// the trash and returns how many there were.
+func (s Svc) PurgeOrphans(ctx context.Context) (int, error) {
+	orphans, err := s.repo.QueryOrphans(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("purgeOrphans: %w", err)
+	}
+
+	for i, a := range orphans {
+		if err := s.blobs.Delete(ctx, a.Key()); err != nil {
+			return i, fmt.Errorf("purgeOrphans: [%s]: %w", a.ID, err)
+		}
+		if err := s.repo.Delete(ctx, a.ID); err != nil {
+			return i, fmt.Errorf("purgeOrphans: [%s]: %w", a.ID, err)
+		}
+	}
+	return len(orphans), nil
+}
+
+// queryByID returns the attachment if it belongs to noteID.
+func (s Svc) queryByID(ctx context.Context, noteID, attachmentID uuid.UUID) (Attachment, error) {
+	a, err := s.repo.QueryByID(ctx, attachmentID)
+	if err != nil {
+		return Attachment{}, err
+	}
+	if a.NoteID != noteID {
+		return Attachment{}, ErrAttachmentNotFound
+	}
+	return a, nil
+}
+
+// deleteBlob cleans up after a failed upload. Its own failure only leaves an
+// unreferenced file behind.
+func (s Svc) deleteBlob(ctx context.Context, key string) {
+	_ = s.blobs.Delete(ctx, key)
+}
+
+// validateFilename drops any directories from name, which browsers on Windows
+// may send with backslashes.
+func validateFilename(name string) (string, error) {
+	name = strings.ReplaceAll(strings.TrimSpace(name), `\`, "/")
+	name = name[strings.LastIndex(name, "/")+1:]
+	if name == "" || name == "." || name == ".." || utf8.RuneCountInString(name) > MaxFilenameLength {
+		return "", ErrInvalidFilename
+	}
+	return name, nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
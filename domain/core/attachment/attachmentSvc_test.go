@@ -0,0 +1,187 @@
+package attachment_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Keisn1/note-taking-app/domain/core/attachment"
+	"github.com/Keisn1/note-taking-app/domain/core/attachment/blobstores/localfs"
+	"github.com/Keisn1/note-taking-app/domain/core/attachment/repositories/memory"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	noteMemory "github.com/Keisn1/note-taking-app/domain/core/note/repositories/memory"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func fixtureNotes() []note.Note {
+	return []note.Note{
+		{ID: uuid.UUID{1}, Title: note.NewTitle("robs 1st note"), Content: note.NewContent("robs 1st note content"), UserID: uuid.UUID{1}, Version: 1},
+		{ID: uuid.UUID{2}, Title: note.NewTitle("robs 2nd note"), Content: note.NewContent("robs 2nd note content"), UserID: uuid.UUID{1}, Version: 1},
+	}
+}
+
+func setup(t *testing.T, quota int64) (attachment.Service, note.Repo) {
+	t.Helper()
+	notes := noteMemory.MustNewRepo(fixtureNotes())
+	blobs, err := localfs.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return attachment.NewSvc(memory.NewRepo(nil, notes), blobs, quota), notes
+}
+
+func newAttachment(filename, body string) attachment.NewAttachment {
+	return attachment.NewAttachment{Filename: filename, Size: int64(len(body)), Body: strings.NewReader(body)}
+}
+
+func Test_Create(t *testing.T) {
+	ctx := context.Background()
+	n := fixtureNotes()[0]
+
+	t.Run("Files are stored with their metadata", func(t *testing.T) {
+		svc, _ := setup(t, 0)
+		a, err := svc.Create(ctx, n, newAttachment(`C:\Users\rob\hello.txt`, "hello world"))
+		assert.NoError(t, err)
+
+		sum := sha256.Sum256([]byte("hello world"))
+		assert.Equal(t, n.ID, a.NoteID)
+		assert.Equal(t, n.UserID, a.UserID)
+		assert.Equal(t, "hello.txt", a.Filename)
+		assert.Equal(t, "text/plain; charset=utf-8", a.ContentType)
+		assert.Equal(t, int64(11), a.Size)
+		assert.Equal(t, sum[:], a.SHA256)
+
+		got, blob, err := svc.Open(ctx, n.ID, a.ID)
+		assert.NoError(t, err)
+		defer blob.Close()
+		assert.Equal(t, a, got)
+		body, _ := io.ReadAll(blob)
+		assert.Equal(t, "hello world", string(body))
+
+		attachments, err := svc.QueryByNoteID(ctx, n.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []attachment.Attachment{a}, attachments)
+	})
+
+	t.Run("A given content type is kept", func(t *testing.T) {
+		svc, _ := setup(t, 0)
+		na := newAttachment("data", "{}")
+		na.ContentType = "application/json"
+		a, err := svc.Create(ctx, n, na)
+		assert.NoError(t, err)
+		assert.Equal(t, "application/json", a.ContentType)
+	})
+
+	t.Run("The body has to match its size", func(t *testing.T) {
+		svc, _ := setup(t, 0)
+		for _, size := range []int64{-1, 5, 20} {
+			na := newAttachment("hello.txt", "hello world")
+			na.Size = size
+			_, err := svc.Create(ctx, n, na)
+			assert.ErrorIs(t, err, attachment.ErrInvalidSize, size)
+		}
+
+		usage, err := svc.Usage(ctx, n.UserID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), usage.Used)
+	})
+
+	t.Run("Filenames can't be empty or too long", func(t *testing.T) {
+		svc, _ := setup(t, 0)
+		for _, filename := range []string{"", "  ", "dir/", "..", strings.Repeat("a", attachment.MaxFilenameLength+1)} {
+			_, err := svc.Create(ctx, n, newAttachment(filename, "x"))
+			assert.ErrorIs(t, err, attachment.ErrInvalidFilename, filename)
+		}
+	})
+
+	t.Run("Uploads beyond the quota of the owner are rejected", func(t *testing.T) {
+		svc, _ := setup(t, 10)
+		_, err := svc.Create(ctx, n, newAttachment("a.txt", "123456"))
+		assert.NoError(t, err)
+
+		_, err = svc.Create(ctx, fixtureNotes()[1], newAttachment("b.txt", "12345"))
+		assert.ErrorIs(t, err, attachment.ErrQuotaExceeded)
+
+		_, err = svc.Create(ctx, n, newAttachment("b.txt", "1234"))
+		assert.NoError(t, err)
+
+		usage, err := svc.Usage(ctx, n.UserID)
+		assert.NoError(t, err)
+		assert.Equal(t, attachment.Usage{Used: 10, Quota: 10}, usage)
+	})
+}
+
+func Test_Open(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := setup(t, 0)
+	n := fixtureNotes()[0]
+	a, err := svc.Create(ctx, n, newAttachment("numbers.txt", "0123456789"))
+	assert.NoError(t, err)
+
+	t.Run("Blobs can be read from an offset", func(t *testing.T) {
+		_, blob, err := svc.Open(ctx, n.ID, a.ID)
+		assert.NoError(t, err)
+		defer blob.Close()
+
+		_, err = blob.Seek(7, io.SeekStart)
+		assert.NoError(t, err)
+		var buf bytes.Buffer
+		_, err = io.Copy(&buf, blob)
+		assert.NoError(t, err)
+		assert.Equal(t, "789", buf.String())
+	})
+
+	t.Run("Attachments are only found with their note", func(t *testing.T) {
+		_, _, err := svc.Open(ctx, fixtureNotes()[1].ID, a.ID)
+		assert.ErrorIs(t, err, attachment.ErrAttachmentNotFound)
+
+		_, _, err = svc.Open(ctx, n.ID, uuid.New())
+		assert.ErrorIs(t, err, attachment.ErrAttachmentNotFound)
+	})
+}
+
+func Test_Delete(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := setup(t, 0)
+	n := fixtureNotes()[0]
+	a, err := svc.Create(ctx, n, newAttachment("a.txt", "abc"))
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, svc.Delete(ctx, fixtureNotes()[1].ID, a.ID), attachment.ErrAttachmentNotFound)
+	assert.NoError(t, svc.Delete(ctx, n.ID, a.ID))
+	assert.ErrorIs(t, svc.Delete(ctx, n.ID, a.ID), attachment.ErrAttachmentNotFound)
+
+	usage, err := svc.Usage(ctx, n.UserID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), usage.Used)
+}
+
+func Test_PurgeOrphans(t *testing.T) {
+	ctx := context.Background()
+	svc, notes := setup(t, 0)
+	kept, purged := fixtureNotes()[0], fixtureNotes()[1]
+
+	a, err := svc.Create(ctx, kept, newAttachment("a.txt", "abc"))
+	assert.NoError(t, err)
+	orphan, err := svc.Create(ctx, purged, newAttachment("b.txt", "def"))
+	assert.NoError(t, err)
+	assert.NoError(t, notes.Delete(purged.ID))
+
+	count, err := svc.PurgeOrphans(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	_, _, err = svc.Open(ctx, purged.ID, orphan.ID)
+	assert.ErrorIs(t, err, attachment.ErrAttachmentNotFound)
+	_, blob, err := svc.Open(ctx, kept.ID, a.ID)
+	assert.NoError(t, err)
+	blob.Close()
+
+	usage, err := svc.Usage(ctx, kept.UserID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), usage.Used)
+}
@@ -0,0 +1,21 @@
+package attachment
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore keeps the contents of attachments. Keys are attachment ids.
+type BlobStore interface {
+	// Put stores the size bytes read from r under key.
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+	// Open returns the blob stored under key, or ErrBlobNotFound. Seeking
+	// allows serving byte ranges without reading the whole blob.
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	// Delete deletes the blob under key. Deleting a missing blob is not an
+	// error.
+	Delete(ctx context.Context, key string) error
+}
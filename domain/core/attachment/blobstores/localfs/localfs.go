@@ -0,0 +1,101 @@
+// Package localfs stores blobs as files in a directory.
+package localfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Keisn1/note-taking-app/domain/core/attachment"
+)
+
+var ErrInvalidKey = errors.New("invalid blob key")
+
+type Store struct {
+	root string
+}
+
+// New returns a Store keeping its files below root, which is created if
+// needed.
+func New(root string) (Store, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return Store{}, fmt.Errorf("new: [%s]: %w", root, err)
+	}
+	return Store{root: root}, nil
+}
+
+// Put writes to a temporary file first, so that a failed upload never leaves
+// a partial blob under key.
+func (s Store) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	p, err := s.path(key)
+	if err != nil {
+		return fmt.Errorf("put: [%s]: %w", key, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+		return fmt.Errorf("put: [%s]: %w", key, err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("put: [%s]: %w", key, err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return fmt.Errorf("put: [%s]: %w", key, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("put: [%s]: %w", key, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("put: [%s]: %w", key, err)
+	}
+
+	if err := os.Rename(f.Name(), p); err != nil {
+		return fmt.Errorf("put: [%s]: %w", key, err)
+	}
+	return nil
+}
+
+func (s Store) Open(_ context.Context, key string) (io.ReadSeekCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, fmt.Errorf("open: [%s]: %w", key, err)
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("open: [%s]: %w", key, attachment.ErrBlobNotFound)
+		}
+		return nil, fmt.Errorf("open: [%s]: %w", key, err)
+	}
+	return f, nil
+}
+
+func (s Store) Delete(_ context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return fmt.Errorf("delete: [%s]: %w", key, err)
+	}
+
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("delete: [%s]: %w", key, err)
+	}
+	return nil
+}
+
+// path spreads the files over subdirectories named after the first two
+// characters of their key.
+func (s Store) path(key string) (string, error) {
+	if len(key) < 2 || strings.ContainsAny(key, `/\`) || strings.HasPrefix(key, ".") {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.root, key[:2], key), nil
+}
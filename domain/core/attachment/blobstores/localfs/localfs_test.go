@@ -0,0 +1,65 @@
+package localfs
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Keisn1/note-taking-app/domain/core/attachment"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	s, err := New(filepath.Join(t.TempDir(), "blobs"))
+	assert.NoError(t, err)
+
+	key := uuid.New().String()
+	assert.NoError(t, s.Put(ctx, key, strings.NewReader("hello world"), 11))
+
+	f, err := s.Open(ctx, key)
+	assert.NoError(t, err)
+	_, err = f.Seek(6, io.SeekStart)
+	assert.NoError(t, err)
+	got, err := io.ReadAll(f)
+	assert.NoError(t, err)
+	assert.Equal(t, "world", string(got))
+	assert.NoError(t, f.Close())
+
+	// overwriting replaces the blob
+	assert.NoError(t, s.Put(ctx, key, strings.NewReader("bye"), 3))
+	f, err = s.Open(ctx, key)
+	assert.NoError(t, err)
+	got, _ = io.ReadAll(f)
+	f.Close()
+	assert.Equal(t, "bye", string(got))
+
+	assert.NoError(t, s.Delete(ctx, key))
+	_, err = s.Open(ctx, key)
+	assert.ErrorIs(t, err, attachment.ErrBlobNotFound)
+
+	// deleting twice is fine
+	assert.NoError(t, s.Delete(ctx, key))
+
+	// no temporary files are left behind
+	entries, err := os.ReadDir(filepath.Join(s.root, key[:2]))
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestStore_InvalidKeys(t *testing.T) {
+	ctx := context.Background()
+	s, err := New(t.TempDir())
+	assert.NoError(t, err)
+
+	for _, key := range []string{"", "a", "../etc", "ab/cd", `ab\cd`, ".."} {
+		assert.ErrorIs(t, s.Put(ctx, key, strings.NewReader("x"), 1), ErrInvalidKey, key)
+		_, err := s.Open(ctx, key)
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+		assert.ErrorIs(t, s.Delete(ctx, key), ErrInvalidKey, key)
+	}
+}
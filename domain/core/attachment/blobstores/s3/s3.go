@@ -0,0 +1,331 @@
+// Package s3 stores blobs in a bucket of an S3 compatible object storage,
+// such as AWS S3 or MinIO. Requests are signed with AWS Signature Version 4.
+package s3
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/attachment"
+)
+
+// unsignedPayload leaves the body out of the signature, so that uploads can
+// be streamed without hashing them first.
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// emptyPayload is the sha256 of an empty body.
+const emptyPayload = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+type Config struct {
+	// Endpoint is the base URL of the storage, e.g. https://s3.eu-central-1.amazonaws.com
+	// or http://localhost:9000 for MinIO. Buckets are addressed path-style.
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+type Store struct {
+	cfg      Config
+	endpoint *url.URL
+	now      func() time.Time
+}
+
+func New(cfg Config) (Store, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return Store{}, fmt.Errorf("new: [%s]: %w", cfg.Endpoint, err)
+	}
+	if endpoint.Scheme == "" || endpoint.Host == "" || cfg.Bucket == "" || cfg.Region == "" {
+		return Store{}, fmt.Errorf("new: endpoint, region and bucket are required")
+	}
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	return Store{cfg: cfg, endpoint: endpoint, now: time.Now}, nil
+}
+
+func (s Store) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	body := io.NopCloser(r)
+	if size == 0 {
+		body = http.NoBody
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), body)
+	if err != nil {
+		return fmt.Errorf("put: [%s]: %w", key, err)
+	}
+	req.ContentLength = size
+
+	res, err := s.do(req, unsignedPayload)
+	if err != nil {
+		return fmt.Errorf("put: [%s]: %w", key, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("put: [%s]: %w", key, responseError(res))
+	}
+	return nil
+}
+
+// Open asks for the size of the blob. Its contents are requested when they
+// are read, starting at the current offset, so that seeking to a range and
+// reading it only transfers that range.
+func (s Store) Open(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, s.objectURL(key), nil)
+	if err != nil {
+		return nil, fmt.Errorf("open: [%s]: %w", key, err)
+	}
+
+	res, err := s.do(req, emptyPayload)
+	if err != nil {
+		return nil, fmt.Errorf("open: [%s]: %w", key, err)
+	}
+	res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("open: [%s]: %w", key, attachment.ErrBlobNotFound)
+	default:
+		return nil, fmt.Errorf("open: [%s]: %w", key, responseError(res))
+	}
+	if res.ContentLength < 0 {
+		return nil, fmt.Errorf("open: [%s]: missing content length", key)
+	}
+
+	return &object{ctx: ctx, store: s, key: key, size: res.ContentLength}, nil
+}
+
+func (s Store) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return fmt.Errorf("delete: [%s]: %w", key, err)
+	}
+
+	res, err := s.do(req, emptyPayload)
+	if err != nil {
+		return fmt.Errorf("delete: [%s]: %w", key, err)
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return fmt.Errorf("delete: [%s]: %w", key, responseError(res))
+	}
+}
+
+func (s Store) objectURL(key string) string {
+	u := *s.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.cfg.Bucket + "/" + key
+	return u.String()
+}
+
+func (s Store) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	sign(req, s.cfg.Region, s.cfg.AccessKey, s.cfg.SecretKey, payloadHash, s.now().UTC())
+	return s.cfg.Client.Do(req)
+}
+
+// object reads a blob with ranged GET requests. A request is only made on the
+// first read after opening or seeking.
+type object struct {
+	ctx    context.Context
+	store  Store
+	key    string
+	size   int64
+	offset int64
+	body   io.ReadCloser
+}
+
+func (o *object) Read(p []byte) (int, error) {
+	if o.offset >= o.size {
+		return 0, io.EOF
+	}
+	if o.body == nil {
+		if err := o.get(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := o.body.Read(p)
+	o.offset += int64(n)
+	if errors.Is(err, io.EOF) && o.offset < o.size {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (o *object) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += o.offset
+	case io.SeekEnd:
+		offset += o.size
+	default:
+		return 0, fmt.Errorf("seek: invalid whence %d", whence)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("seek: negative offset %d", offset)
+	}
+
+	if offset != o.offset {
+		o.closeBody()
+		o.offset = offset
+	}
+	return offset, nil
+}
+
+func (o *object) Close() error {
+	o.closeBody()
+	return nil
+}
+
+func (o *object) get() error {
+	req, err := http.NewRequestWithContext(o.ctx, http.MethodGet, o.store.objectURL(o.key), nil)
+	if err != nil {
+		return fmt.Errorf("get: [%s]: %w", o.key, err)
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", o.offset))
+
+	res, err := o.store.do(req, emptyPayload)
+	if err != nil {
+		return fmt.Errorf("get: [%s]: %w", o.key, err)
+	}
+
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		// the range was ignored, skip to the offset
+		if _, err := io.CopyN(io.Discard, res.Body, o.offset); err != nil {
+			res.Body.Close()
+			return fmt.Errorf("get: [%s]: %w", o.key, err)
+		}
+	case http.StatusNotFound:
+		res.Body.Close()
+		return fmt.Errorf("get: [%s]: %w", o.key, attachment.ErrBlobNotFound)
+	default:
+		defer res.Body.Close()
+		return fmt.Errorf("get: [%s]: %w", o.key, responseError(res))
+	}
+
+	o.body = res.Body
+	return nil
+}
+
+func (o *object) closeBody() {
+	if o.body != nil {
+		o.body.Close()
+		o.body = nil
+	}
+}
+
+func responseError(res *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	return fmt.Errorf("unexpected status %s: %s", res.Status, strings.TrimSpace(string(msg)))
+}
+
+// sign adds the headers of AWS Signature Version 4 to req. All headers set on
+// req at this point are signed.
+func sign(req *http.Request, region, accessKey, secretKey, payloadHash string, t time.Time) {
+	const service = "s3"
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature,
+	))
+}
+
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		values := q[k]
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, escape(k)+"="+escape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// escape encodes like url.QueryEscape, but with spaces as %20 as required by
+// the signature.
+func escape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
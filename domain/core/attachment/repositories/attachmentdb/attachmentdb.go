@@ -0,0 +1,154 @@
+package attachmentdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/attachment"
+	"github.com/google/uuid"
+)
+
+type DBAttachment struct {
+	ID          uuid.UUID
+	NoteID      uuid.UUID
+	UserID      uuid.UUID
+	Filename    string
+	ContentType string
+	Size        int64
+	SHA256      []byte
+	CreatedAt   time.Time
+}
+
+type database interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+type AttachmentRepo struct {
+	db database
+}
+
+func NewAttachmentRepo(db database) AttachmentRepo {
+	return AttachmentRepo{db: db}
+}
+
+// Create checks the quota in the insert itself. Concurrent uploads of the
+// same user may still both see the usage from before the other one.
+func (r AttachmentRepo) Create(ctx context.Context, a attachment.Attachment, quota int64) error {
+	insertRow := `
+	INSERT INTO attachments (id, note_id, user_id, filename, content_type, size, sha256, created_at)
+	SELECT $1, $2, $3, $4, $5, $6, $7, $8
+	WHERE $9 = 0 OR (SELECT COALESCE(SUM(size), 0) FROM attachments WHERE user_id = $3) + $6 <= $9`
+
+	res, err := r.db.ExecContext(ctx, insertRow, a.ID, a.NoteID, a.UserID, a.Filename, a.ContentType, a.Size, a.SHA256, a.CreatedAt, quota)
+	if err != nil {
+		return fmt.Errorf("create: [%s]: %w", a.ID, err)
+	}
+	if c, _ := res.RowsAffected(); c == 0 {
+		return attachment.ErrQuotaExceeded
+	}
+	return nil
+}
+
+func (r AttachmentRepo) QueryByID(ctx context.Context, attachmentID uuid.UUID) (attachment.Attachment, error) {
+	queryByID := `
+	SELECT id, note_id, user_id, filename, content_type, size, sha256, created_at
+	FROM attachments WHERE id = $1;
+	`
+	row := r.db.QueryRowContext(ctx, queryByID, attachmentID)
+
+	var dbA DBAttachment
+	err := row.Scan(&dbA.ID, &dbA.NoteID, &dbA.UserID, &dbA.Filename, &dbA.ContentType, &dbA.Size, &dbA.SHA256, &dbA.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return attachment.Attachment{}, attachment.ErrAttachmentNotFound
+		}
+		return attachment.Attachment{}, fmt.Errorf("queryByID: [%s]: %w", attachmentID, err)
+	}
+
+	return dbAttachmentToAttachment(dbA), nil
+}
+
+func (r AttachmentRepo) QueryByNoteID(ctx context.Context, noteID uuid.UUID) ([]attachment.Attachment, error) {
+	queryByNoteID := `
+	SELECT id, note_id, user_id, filename, content_type, size, sha256, created_at
+	FROM attachments WHERE note_id = $1 ORDER BY created_at, id;
+	`
+	attachments, err := r.query(ctx, queryByNoteID, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("queryByNoteID: [%s]: %w", noteID, err)
+	}
+	return attachments, nil
+}
+
+func (r AttachmentRepo) Delete(ctx context.Context, attachmentID uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM attachments WHERE id = $1`, attachmentID)
+	if err != nil {
+		return fmt.Errorf("delete: [%s]: %w", attachmentID, err)
+	}
+	if c, _ := res.RowsAffected(); c == 0 {
+		return attachment.ErrAttachmentNotFound
+	}
+	return nil
+}
+
+func (r AttachmentRepo) Usage(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var used int64
+	err := r.db.QueryRowContext(ctx, `SELECT COALESCE(SUM(size), 0) FROM attachments WHERE user_id = $1`, userID).Scan(&used)
+	if err != nil {
+		return 0, fmt.Errorf("usage: [%s]: %w", userID, err)
+	}
+	return used, nil
+}
+
+func (r AttachmentRepo) QueryOrphans(ctx context.Context) ([]attachment.Attachment, error) {
+	queryOrphans := `
+	SELECT id, note_id, user_id, filename, content_type, size, sha256, created_at
+	FROM attachments a WHERE NOT EXISTS (SELECT 1 FROM notes n WHERE n.id = a.note_id)
+	ORDER BY created_at, id;
+	`
+	attachments, err := r.query(ctx, queryOrphans)
+	if err != nil {
+		return nil, fmt.Errorf("queryOrphans: %w", err)
+	}
+	return attachments, nil
+}
+
+func (r AttachmentRepo) query(ctx context.Context, query string, args ...any) ([]attachment.Attachment, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ret := []attachment.Attachment{}
+	for rows.Next() {
+		var dbA DBAttachment
+		err := rows.Scan(&dbA.ID, &dbA.NoteID, &dbA.UserID, &dbA.Filename, &dbA.ContentType, &dbA.Size, &dbA.SHA256, &dbA.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan rows: %w", err)
+		}
+		ret = append(ret, dbAttachmentToAttachment(dbA))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func dbAttachmentToAttachment(dbA DBAttachment) attachment.Attachment {
+	return attachment.Attachment{
+		ID:          dbA.ID,
+		NoteID:      dbA.NoteID,
+		UserID:      dbA.UserID,
+		Filename:    dbA.Filename,
+		ContentType: dbA.ContentType,
+		Size:        dbA.Size,
+		SHA256:      dbA.SHA256,
+		CreatedAt:   dbA.CreatedAt.UTC(),
+	}
+}
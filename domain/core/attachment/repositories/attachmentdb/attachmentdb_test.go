@@ -0,0 +1,76 @@
+package attachmentdb_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/attachment"
+	"github.com/Keisn1/note-taking-app/domain/core/attachment/repositories/attachmentdb"
+	"github.com/google/uuid"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	exitCode := run(m)
+	os.Exit(exitCode)
+}
+
+func TestAttachmentRepo(t *testing.T) {
+	noteIDs := []uuid.UUID{{1}, {2}}
+	testDB, deleteTable := SetupAttachmentsTable(t, noteIDs)
+	defer deleteTable()
+	r := attachmentdb.NewAttachmentRepo(testDB)
+	ctx := context.Background()
+
+	robID, annaID := uuid.UUID{1}, uuid.UUID{2}
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	a1 := attachment.Attachment{ID: uuid.UUID{10}, NoteID: noteIDs[0], UserID: robID, Filename: "a.txt", ContentType: "text/plain", Size: 60, SHA256: []byte{1}, CreatedAt: createdAt}
+	a2 := attachment.Attachment{ID: uuid.UUID{11}, NoteID: noteIDs[0], UserID: robID, Filename: "b.png", ContentType: "image/png", Size: 40, SHA256: []byte{2}, CreatedAt: createdAt.Add(time.Second)}
+	orphan := attachment.Attachment{ID: uuid.UUID{12}, NoteID: uuid.UUID{99}, UserID: annaID, Filename: "c.txt", ContentType: "text/plain", Size: 1, SHA256: []byte{3}, CreatedAt: createdAt}
+
+	t.Run("Attachments are stored within the quota", func(t *testing.T) {
+		assert.NoError(t, r.Create(ctx, a1, 100))
+		assert.NoError(t, r.Create(ctx, a2, 100))
+
+		tooBig := a2
+		tooBig.ID = uuid.New()
+		tooBig.Size = 1
+		assert.ErrorIs(t, r.Create(ctx, tooBig, 100), attachment.ErrQuotaExceeded)
+
+		// other users have their own quota, 0 is unlimited
+		assert.NoError(t, r.Create(ctx, orphan, 0))
+
+		used, err := r.Usage(ctx, robID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(100), used)
+
+		got, err := r.QueryByID(ctx, a1.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, a1, got)
+
+		attachments, err := r.QueryByNoteID(ctx, noteIDs[0])
+		assert.NoError(t, err)
+		assert.Equal(t, []attachment.Attachment{a1, a2}, attachments)
+	})
+
+	t.Run("Attachments of missing notes are orphans", func(t *testing.T) {
+		got, err := r.QueryOrphans(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []attachment.Attachment{orphan}, got)
+	})
+
+	t.Run("Delete attachments", func(t *testing.T) {
+		assert.NoError(t, r.Delete(ctx, a1.ID))
+		assert.ErrorIs(t, r.Delete(ctx, a1.ID), attachment.ErrAttachmentNotFound)
+
+		_, err := r.QueryByID(ctx, a1.ID)
+		assert.ErrorIs(t, err, attachment.ErrAttachmentNotFound)
+
+		used, err := r.Usage(ctx, robID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(40), used)
+	})
+}
@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/Keisn1/note-taking-app/domain/core/attachment"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/google/uuid"
+)
+
+type InMemoryRepo struct {
+	mu          *sync.Mutex
+	attachments map[uuid.UUID]attachment.Attachment
+	notes       note.Repo
+}
+
+// NewRepo returns a repo finding orphans by looking up the notes of the
+// attachments in notes.
+func NewRepo(attachments []attachment.Attachment, notes note.Repo) InMemoryRepo {
+	as := make(map[uuid.UUID]attachment.Attachment)
+	for _, a := range attachments {
+		as[a.ID] = a
+	}
+	return InMemoryRepo{mu: &sync.Mutex{}, attachments: as, notes: notes}
+}
+
+func (r InMemoryRepo) Create(ctx context.Context, a attachment.Attachment, quota int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if quota > 0 && r.usage(a.UserID)+a.Size > quota {
+		return attachment.ErrQuotaExceeded
+	}
+	r.attachments[a.ID] = a
+	return nil
+}
+
+func (r InMemoryRepo) QueryByID(ctx context.Context, attachmentID uuid.UUID) (attachment.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a, ok := r.attachments[attachmentID]
+	if !ok {
+		return attachment.Attachment{}, attachment.ErrAttachmentNotFound
+	}
+	return a, nil
+}
+
+func (r InMemoryRepo) QueryByNoteID(ctx context.Context, noteID uuid.UUID) ([]attachment.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ret := []attachment.Attachment{}
+	for _, a := range r.attachments {
+		if a.NoteID == noteID {
+			ret = append(ret, a)
+		}
+	}
+	sortByCreatedAt(ret)
+	return ret, nil
+}
+
+func (r InMemoryRepo) Delete(ctx context.Context, attachmentID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.attachments[attachmentID]; !ok {
+		return attachment.ErrAttachmentNotFound
+	}
+	delete(r.attachments, attachmentID)
+	return nil
+}
+
+func (r InMemoryRepo) Usage(ctx context.Context, userID uuid.UUID) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.usage(userID), nil
+}
+
+func (r InMemoryRepo) QueryOrphans(ctx context.Context) ([]attachment.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ret := []attachment.Attachment{}
+	for _, a := range r.attachments {
+		_, err := r.notes.QueryByID(ctx, a.NoteID)
+		if errors.Is(err, note.ErrNoteNotFound) {
+			ret = append(ret, a)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	sortByCreatedAt(ret)
+	return ret, nil
+}
+
+func (r InMemoryRepo) usage(userID uuid.UUID) int64 {
+	var used int64
+	for _, a := range r.attachments {
+		if a.UserID == userID {
+			used += a.Size
+		}
+	}
+	return used
+}
+
+func sortByCreatedAt(as []attachment.Attachment) {
+	sort.Slice(as, func(i, j int) bool {
+		if !as[i].CreatedAt.Equal(as[j].CreatedAt) {
+			return as[i].CreatedAt.Before(as[j].CreatedAt)
+		}
+		return as[i].ID.String() < as[j].ID.String()
+	})
+}
@@ -0,0 +1,28 @@
+package attachment
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrQuotaExceeded      = errors.New("storage quota exceeded")
+)
+
+type Repo interface {
+	// Create stores a unless it makes the attachments of a.UserID exceed
+	// quota, in which case it returns ErrQuotaExceeded. A quota of 0 means
+	// unlimited.
+	Create(ctx context.Context, a Attachment, quota int64) error
+	QueryByID(ctx context.Context, attachmentID uuid.UUID) (Attachment, error)
+	QueryByNoteID(ctx context.Context, noteID uuid.UUID) ([]Attachment, error)
+	Delete(ctx context.Context, attachmentID uuid.UUID) error
+	// Usage returns the total size of the attachments of userID.
+	Usage(ctx context.Context, userID uuid.UUID) (int64, error)
+	// QueryOrphans returns the attachments whose note has been deleted for
+	// good.
+	QueryOrphans(ctx context.Context) ([]Attachment, error)
+}
@@ -0,0 +1,207 @@
+package note
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxBatchOps is the number of operations a single batch may hold.
+const MaxBatchOps = 500
+
+var (
+	ErrTooManyBatchOps  = fmt.Errorf("a batch holds at most %d operations", MaxBatchOps)
+	ErrInvalidBatchOp   = errors.New("invalid batch operation")
+	ErrInvalidBatchMode = errors.New("invalid batch mode")
+	// ErrBatchAborted is the error of the operations of an atomic batch that
+	// were not applied because another one failed.
+	ErrBatchAborted = errors.New("the batch was aborted by another operation")
+)
+
+type BatchOpKind string
+
+const (
+	BatchCreate BatchOpKind = "create"
+	BatchUpdate BatchOpKind = "update"
+	// BatchDelete moves the note to the trash like Delete.
+	BatchDelete BatchOpKind = "delete"
+)
+
+func ParseBatchOpKind(s string) (BatchOpKind, error) {
+	switch k := BatchOpKind(s); k {
+	case BatchCreate, BatchUpdate, BatchDelete:
+		return k, nil
+	}
+	return "", fmt.Errorf("parseBatchOpKind: [%s]: %w", s, ErrInvalidBatchOp)
+}
+
+type BatchMode string
+
+const (
+	// BatchAtomic applies all operations or none of them.
+	BatchAtomic BatchMode = "atomic"
+	// BatchBestEffort applies the operations that succeed and reports the
+	// others as failed.
+	BatchBestEffort BatchMode = "best_effort"
+)
+
+func ParseBatchMode(s string) (BatchMode, error) {
+	switch m := BatchMode(s); m {
+	case BatchAtomic, BatchBestEffort:
+		return m, nil
+	}
+	return "", fmt.Errorf("parseBatchMode: [%s]: %w", s, ErrInvalidBatchMode)
+}
+
+// BatchOp is a single operation of a batch. NoteID is the note to update or
+// delete. A Version other than 0 is the version the client last read, the
+// operation fails with ErrVersionConflict if the note has changed since.
+// Note holds the fields of a create or update, its UserID is ignored.
+type BatchOp struct {
+	Kind    BatchOpKind
+	NoteID  uuid.UUID
+	Version int
+	Note    UpdateNote
+}
+
+// BatchResult is the outcome of a BatchOp. Err is nil if it was applied, Note
+// is then the created, updated or trashed note.
+type BatchResult struct {
+	Kind BatchOpKind
+	Note Note
+	Err  error
+}
+
+// BatchWrite is a change made by Repo.Batch. Kind BatchCreate creates Note,
+// BatchUpdate updates it like Repo.Update and BatchDelete trashes it at
+// Note.DeletedAt like Repo.Trash, both only if it is still at Note.Version.
+type BatchWrite struct {
+	Kind BatchOpKind
+	Note Note
+}
+
+// Batch applies the operations of userID in order and in a single
+// transaction, together with the revisions of the created and updated notes.
+// Updates need write permission and deletes ownership, notes userID may not
+// change are reported as not found. Later operations see the changes of
+// earlier ones, so a note may be updated several times.
+func (ns NotesService) Batch(ctx context.Context, userID uuid.UUID, ops []BatchOp, mode BatchMode) ([]BatchResult, error) {
+	if len(ops) > MaxBatchOps {
+		return nil, fmt.Errorf("batch: [%s]: %w", userID, ErrTooManyBatchOps)
+	}
+	if _, err := ns.userSvc.QueryByID(ctx, userID); err != nil {
+		return nil, fmt.Errorf("batch: [%s]: %w", userID, err)
+	}
+
+	results := make([]BatchResult, len(ops))
+	var writes []BatchWrite
+	// the index of the op of each write
+	var indices []int
+	// the notes as changed by the batch so far
+	changed := make(map[uuid.UUID]Note)
+	at := now()
+	for i, op := range ops {
+		results[i].Kind = op.Kind
+		w, err := ns.batchWrite(ctx, userID, op, changed, at)
+		if err != nil {
+			results[i].Err = err
+			if mode == BatchAtomic {
+				return abortBatch(results, i), nil
+			}
+			continue
+		}
+		n := w.Note
+		if w.Kind == BatchUpdate {
+			// the write increments the stored version
+			n.Version++
+		}
+		changed[n.ID] = n
+		writes, indices = append(writes, w), append(indices, i)
+	}
+
+	err := ns.txm.Run(ctx, func(ctx context.Context) error {
+		errs, err := ns.repo.Batch(ctx, writes, mode == BatchAtomic)
+		if err != nil {
+			return fmt.Errorf("batch: [%s]: %w", userID, err)
+		}
+
+		for j, w := range writes {
+			i := indices[j]
+			if results[i].Err = errs[j]; errs[j] != nil {
+				continue
+			}
+			if w.Kind == BatchUpdate {
+				w.Note.Version++
+			}
+			results[i].Note = w.Note
+			if w.Kind == BatchDelete {
+				continue
+			}
+			if _, err := ns.revisionRepo.Append(ctx, newRevision(w.Note, userID)); err != nil {
+				return fmt.Errorf("batch: [%s]: %w", w.Note.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// batchWrite checks op against the note as changed by the batch so far and
+// returns the write applying it.
+func (ns NotesService) batchWrite(ctx context.Context, userID uuid.UUID, op BatchOp, changed map[uuid.UUID]Note, at time.Time) (BatchWrite, error) {
+	if op.Kind == BatchCreate {
+		op.Note.UserID = userID
+		return BatchWrite{Kind: BatchCreate, Note: op.Note.newNote(at)}, nil
+	}
+
+	n, ok := changed[op.NoteID]
+	if !ok {
+		var err error
+		if n, err = ns.repo.QueryByID(ctx, op.NoteID); err != nil {
+			return BatchWrite{}, fmt.Errorf("batch: [%s]: %w", op.NoteID, err)
+		}
+	}
+	if n.IsTrashed() {
+		return BatchWrite{}, fmt.Errorf("batch: [%s]: %w", op.NoteID, ErrNoteNotFound)
+	}
+
+	required := PermissionWrite
+	if op.Kind == BatchDelete {
+		required = PermissionOwner
+	}
+	perm, err := ns.Permission(ctx, n, userID)
+	if err != nil {
+		return BatchWrite{}, fmt.Errorf("batch: [%s]: %w", op.NoteID, err)
+	}
+	if !perm.Allows(required) {
+		return BatchWrite{}, fmt.Errorf("batch: [%s]: %w", op.NoteID, ErrNoteNotFound)
+	}
+	if op.Version != 0 && op.Version != n.Version {
+		return BatchWrite{}, fmt.Errorf("batch: [%s]: %w", op.NoteID, ErrVersionConflict)
+	}
+
+	switch op.Kind {
+	case BatchUpdate:
+		return BatchWrite{Kind: BatchUpdate, Note: op.Note.apply(n, at)}, nil
+	case BatchDelete:
+		n.DeletedAt = &at
+		return BatchWrite{Kind: BatchDelete, Note: n}, nil
+	}
+	return BatchWrite{}, fmt.Errorf("batch: [%s]: %w", op.Kind, ErrInvalidBatchOp)
+}
+
+// abortBatch marks all results but the failed one as aborted.
+func abortBatch(results []BatchResult, failed int) []BatchResult {
+	for i := range results {
+		if i != failed {
+			results[i].Note, results[i].Err = Note{}, ErrBatchAborted
+		}
+	}
+	return results
+}
@@ -0,0 +1,124 @@
+package note_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoteService_Batch(t *testing.T) {
+	userID := uuid.UUID{1}
+	ctx := context.Background()
+	create := func(title string) note.BatchOp {
+		return note.BatchOp{Kind: note.BatchCreate, Note: note.UpdateNote{Title: note.NewTitle(title), Content: note.NewContent("")}}
+	}
+	update := func(noteID uuid.UUID, version int, content string) note.BatchOp {
+		return note.BatchOp{Kind: note.BatchUpdate, NoteID: noteID, Version: version, Note: note.UpdateNote{Content: note.NewContent(content)}}
+	}
+	del := func(noteID uuid.UUID) note.BatchOp {
+		return note.BatchOp{Kind: note.BatchDelete, NoteID: noteID}
+	}
+
+	t.Run("Applies the operations in order", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+
+		results, err := notesS.Batch(ctx, userID, []note.BatchOp{
+			create("new"),
+			update(uuid.UUID{1}, 0, "first"),
+			update(uuid.UUID{1}, 0, "second"),
+			del(uuid.UUID{2}),
+		}, note.BatchAtomic)
+		assert.NoError(t, err)
+		for _, res := range results {
+			assert.NoError(t, res.Err)
+		}
+
+		created, err := notesS.QueryByID(ctx, results[0].Note.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "new", created.Title.String())
+		assert.Equal(t, userID, created.UserID)
+
+		updated, err := notesS.QueryByID(ctx, uuid.UUID{1})
+		assert.NoError(t, err)
+		assert.Equal(t, "second", updated.Content.String())
+		assert.Equal(t, "robs 1st note", updated.Title.String())
+		assert.Equal(t, 2, updated.Version)
+		assert.Equal(t, updated, results[2].Note)
+
+		_, err = notesS.QueryByID(ctx, uuid.UUID{2})
+		assert.ErrorIs(t, err, note.ErrNoteNotFound)
+
+		revisions, err := notesS.QueryRevisions(ctx, uuid.UUID{1})
+		assert.NoError(t, err)
+		assert.Len(t, revisions, 2)
+	})
+
+	t.Run("An atomic batch applies nothing if an operation fails", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+
+		results, err := notesS.Batch(ctx, userID, []note.BatchOp{
+			create("new"),
+			update(uuid.UUID{1}, 0, "changed"),
+			// a note of anna
+			del(uuid.UUID{3}),
+		}, note.BatchAtomic)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, results[0].Err, note.ErrBatchAborted)
+		assert.ErrorIs(t, results[1].Err, note.ErrBatchAborted)
+		assert.ErrorIs(t, results[2].Err, note.ErrNoteNotFound)
+
+		n, err := notesS.QueryByID(ctx, uuid.UUID{1})
+		assert.NoError(t, err)
+		assert.Equal(t, "robs 1st note content", n.Content.String())
+		notes, err := notesS.GetNotesByUserID(userID)
+		assert.NoError(t, err)
+		assert.Len(t, notes, 2)
+	})
+
+	t.Run("A best effort batch applies the operations that succeed", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+
+		results, err := notesS.Batch(ctx, userID, []note.BatchOp{
+			update(uuid.UUID{1}, 1, "stale"),
+			update(uuid.UUID{2}, 0, "changed"),
+			del(uuid.UUID{9}),
+		}, note.BatchBestEffort)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, results[0].Err, note.ErrVersionConflict)
+		assert.NoError(t, results[1].Err)
+		assert.ErrorIs(t, results[2].Err, note.ErrNoteNotFound)
+
+		n, err := notesS.QueryByID(ctx, uuid.UUID{2})
+		assert.NoError(t, err)
+		assert.Equal(t, "changed", n.Content.String())
+	})
+
+	t.Run("Shared notes can be updated but not deleted", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+		anna, err := notesS.QueryByID(ctx, uuid.UUID{3})
+		assert.NoError(t, err)
+		_, err = notesS.Share(ctx, anna, userID, note.PermissionWrite)
+		assert.NoError(t, err)
+
+		results, err := notesS.Batch(ctx, userID, []note.BatchOp{
+			update(uuid.UUID{3}, 0, "changed"),
+			del(uuid.UUID{3}),
+		}, note.BatchBestEffort)
+		assert.NoError(t, err)
+		assert.NoError(t, results[0].Err)
+		assert.ErrorIs(t, results[1].Err, note.ErrNoteNotFound)
+	})
+
+	t.Run("Rejects unknown users and too many operations", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+
+		_, err := notesS.Batch(ctx, uuid.UUID{9}, []note.BatchOp{create("a")}, note.BatchAtomic)
+		assert.Error(t, err)
+
+		_, err = notesS.Batch(ctx, userID, make([]note.BatchOp, note.MaxBatchOps+1), note.BatchAtomic)
+		assert.ErrorIs(t, err, note.ErrTooManyBatchOps)
+	})
+}
@@ -0,0 +1,50 @@
+package note_test
+
+import (
+	"testing"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	testCases := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{name: "Equal texts", a: "a\nb\n", b: "a\nb\n", want: ""},
+		{
+			name: "Changed line",
+			a:    "a\nb\nc",
+			b:    "a\nB\nc",
+			want: "--- a\n+++ b\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name: "Added to empty text",
+			a:    "",
+			b:    "a",
+			want: "--- a\n+++ b\n@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			name: "Distant changes get their own hunks",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12",
+			b:    "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11",
+			want: "--- a\n+++ b\n" +
+				"@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n" +
+				"@@ -9,4 +9,3 @@\n 9\n 10\n 11\n-12\n",
+		},
+		{
+			name: "Close changes share a hunk",
+			a:    "1\n2\n3\n4\n5\n6\n7",
+			b:    "one\n2\n3\n4\n5\n6\nseven",
+			want: "--- a\n+++ b\n@@ -1,7 +1,7 @@\n-1\n+one\n 2\n 3\n 4\n 5\n 6\n-7\n+seven\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, note.UnifiedDiff("a", "b", tc.a, tc.b))
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package note
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// maxFilenameTitle is the number of runes of the title used in file names.
+const maxFilenameTitle = 50
+
+// Filename returns the name of n in an export: the title, reduced to
+// letters, digits and dashes, followed by the start of the id, which keeps
+// notes with the same title apart.
+func (n Note) Filename() string {
+	var b strings.Builder
+	runes, dash := 0, false
+	for _, r := range n.Title.String() {
+		if runes >= maxFilenameTitle {
+			break
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			dash = true
+			continue
+		}
+		if dash && runes > 0 {
+			b.WriteByte('-')
+			runes++
+		}
+		b.WriteRune(r)
+		runes++
+		dash = false
+	}
+
+	name := b.String()
+	if name == "" {
+		name = "untitled"
+	}
+	return name + "-" + n.ID.String()[:8] + ".md"
+}
+
+// MarkdownFile returns the content of n with a YAML front matter holding its
+// id, title, format, tags and timestamps.
+func (n Note) MarkdownFile() []byte {
+	var b bytes.Buffer
+	b.WriteString("---\n")
+	b.WriteString("id: " + n.ID.String() + "\n")
+	b.WriteString("title: " + yamlString(n.Title.String()) + "\n")
+	if n.Format != "" {
+		b.WriteString("format: " + string(n.Format) + "\n")
+	}
+	if len(n.Tags) > 0 {
+		quoted := make([]string, 0, len(n.Tags))
+		for _, t := range n.Tags {
+			quoted = append(quoted, yamlString(t))
+		}
+		b.WriteString("tags: [" + strings.Join(quoted, ", ") + "]\n")
+	}
+	b.WriteString("created_at: " + n.CreatedAt.UTC().Format(time.RFC3339Nano) + "\n")
+	b.WriteString("updated_at: " + n.UpdatedAt.UTC().Format(time.RFC3339Nano) + "\n")
+	b.WriteString("---\n")
+	b.WriteString(n.Content.String())
+	return b.Bytes()
+}
+
+// yamlString quotes s as a double-quoted YAML scalar, which understands the
+// escapes of JSON.
+func yamlString(s string) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,52 @@
+package note_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNote_Filename(t *testing.T) {
+	id := uuid.MustParse("0a1b2c3d-0000-0000-0000-000000000000")
+	testCases := []struct {
+		title string
+		want  string
+	}{
+		{title: "Groceries: Monday / Tuesday!", want: "Groceries-Monday-Tuesday-0a1b2c3d.md"},
+		{title: "Über ../../etc", want: "Über-etc-0a1b2c3d.md"},
+		{title: "   ", want: "untitled-0a1b2c3d.md"},
+		{title: "abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyz", want: "abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwx-0a1b2c3d.md"},
+	}
+
+	for _, tc := range testCases {
+		n := note.Note{ID: id, Title: note.NewTitle(tc.title)}
+		assert.Equal(t, tc.want, n.Filename(), tc.title)
+	}
+}
+
+func TestNote_MarkdownFile(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	n := note.Note{
+		ID:        uuid.UUID{1},
+		Title:     note.NewTitle(`say "hi": <now>`),
+		Content:   note.NewContent("# hi\n"),
+		Format:    note.FormatMarkdown,
+		Tags:      note.Tags{"go", "work"},
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt.Add(90 * time.Minute),
+	}
+
+	want := "---\n" +
+		"id: 01000000-0000-0000-0000-000000000000\n" +
+		`title: "say \"hi\": <now>"` + "\n" +
+		"format: markdown\n" +
+		`tags: ["go", "work"]` + "\n" +
+		"created_at: 2024-01-01T10:00:00Z\n" +
+		"updated_at: 2024-01-01T11:30:00Z\n" +
+		"---\n" +
+		"# hi\n"
+	assert.Equal(t, want, string(n.MarkdownFile()))
+}
@@ -0,0 +1,62 @@
+package note
+
+import (
+	"errors"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/Keisn1/note-taking-app/foundation/markdown"
+	"github.com/Keisn1/note-taking-app/foundation/sanitize"
+)
+
+var ErrInvalidFormat = errors.New("invalid format")
+
+// Format tells how the content of a note is written. Notes without a format
+// are plain text.
+type Format string
+
+const (
+	FormatPlain    Format = "plain"
+	FormatMarkdown Format = "markdown"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatPlain, FormatMarkdown:
+		return f, nil
+	}
+	return "", ErrInvalidFormat
+}
+
+var blankLines = regexp.MustCompile(`\n[ \t]*\n\s*`)
+
+// HTML renders the content of n. Markdown is sanitized, as it may contain raw
+// HTML, so the result can be embedded into a page. Paragraphs of plain text
+// are separated by blank lines.
+func (n Note) HTML() string {
+	content := strings.ReplaceAll(n.Content.String(), "\r\n", "\n")
+	if n.Format == FormatMarkdown {
+		return sanitize.HTML(markdown.Render(content))
+	}
+
+	var b strings.Builder
+	for _, p := range blankLines.Split(strings.TrimSpace(content), -1) {
+		if p == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(html.EscapeString(p), "\n", "<br />\n"))
+		b.WriteString("</p>\n")
+	}
+	return b.String()
+}
+
+// Markdown returns the content of n as Markdown. Plain text is escaped so
+// that it doesn't turn into formatting.
+func (n Note) Markdown() string {
+	if n.Format == FormatMarkdown {
+		return n.Content.String()
+	}
+	return markdown.Escape(n.Content.String())
+}
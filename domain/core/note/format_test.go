@@ -0,0 +1,39 @@
+package note_test
+
+import (
+	"testing"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFormat(t *testing.T) {
+	f, err := note.ParseFormat("markdown")
+	assert.NoError(t, err)
+	assert.Equal(t, note.FormatMarkdown, f)
+
+	for _, s := range []string{"", "Markdown", "html"} {
+		_, err := note.ParseFormat(s)
+		assert.ErrorIs(t, err, note.ErrInvalidFormat, s)
+	}
+}
+
+func TestNote_HTML(t *testing.T) {
+	t.Run("Markdown is rendered and sanitized", func(t *testing.T) {
+		n := note.Note{Content: note.NewContent("# *hi*\r\n\r\n<img src=x onerror=alert(1)>"), Format: note.FormatMarkdown}
+		assert.Equal(t, "<h1><em>hi</em></h1>\n<img src=\"x\" />\n", n.HTML())
+	})
+
+	t.Run("Plain text is escaped", func(t *testing.T) {
+		n := note.Note{Content: note.NewContent("a <b>\nc\n\n\n*d*\n"), Format: note.FormatPlain}
+		assert.Equal(t, "<p>a &lt;b&gt;<br />\nc</p>\n<p>*d*</p>\n", n.HTML())
+	})
+}
+
+func TestNote_Markdown(t *testing.T) {
+	n := note.Note{Content: note.NewContent("*d*"), Format: note.FormatMarkdown}
+	assert.Equal(t, "*d*", n.Markdown())
+
+	n.Format = note.FormatPlain
+	assert.Equal(t, `\*d\*`, n.Markdown())
+}
@@ -0,0 +1,175 @@
+package note
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxImportItems is the number of notes a single import may hold.
+const MaxImportItems = 1000
+
+var ErrTooManyImportItems = fmt.Errorf("an import holds at most %d notes", MaxImportItems)
+
+type ImportStatus string
+
+const (
+	ImportCreated ImportStatus = "created"
+	// ImportSkipped notes have been imported before, by an earlier import or
+	// earlier in the same one.
+	ImportSkipped ImportStatus = "skipped"
+	ImportFailed  ImportStatus = "failed"
+)
+
+// ImportItem is a note read from an import file. Name tells the user which
+// note a result is about, like the file it was read from. Zero timestamps
+// are set to the time of the import.
+type ImportItem struct {
+	Name      string
+	Title     Title
+	Content   Content
+	Format    Format
+	Tags      Tags
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// Err is set if the note couldn't be read. It is reported instead of
+	// importing the note.
+	Err error
+}
+
+// Hash identifies the imported note independent of when it is imported, so
+// that importing the same file twice doesn't duplicate it. Tags and
+// timestamps are left out.
+func (it ImportItem) Hash() string {
+	h := sha256.New()
+	for _, s := range []string{string(it.Format), it.Title.String(), it.Content.String()} {
+		// the length keeps the fields apart
+		fmt.Fprintf(h, "%d:%s", len(s), s)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type ImportResult struct {
+	Name   string
+	Status ImportStatus
+	// NoteID is the created note, or the one imported before for skipped
+	// items.
+	NoteID uuid.UUID
+	Err    error
+}
+
+// ImportedNote is a note to be stored by Repo.Import together with the hash
+// of the item it was imported from.
+type ImportedNote struct {
+	Note Note
+	Hash string
+}
+
+// Import creates the notes of userID read from an import file like Create
+// does. The items that could be read are stored in a single transaction with
+// their first revisions, the others are reported as failed. Items that userID
+// imported before and that are not yet purged from the trash are skipped.
+func (ns NotesService) Import(ctx context.Context, userID uuid.UUID, items []ImportItem) ([]ImportResult, error) {
+	if len(items) > MaxImportItems {
+		return nil, fmt.Errorf("import: [%s]: %w", userID, ErrTooManyImportItems)
+	}
+	results := make([]ImportResult, len(items))
+	hashes := make([]string, len(items))
+	// first index of each hash, a file may hold the same note twice
+	firsts := make(map[string]int)
+	var notes []ImportedNote
+	importedAt := now()
+	for i, it := range items {
+		results[i] = ImportResult{Name: it.Name}
+		if it.Err != nil {
+			results[i].Status, results[i].Err = ImportFailed, it.Err
+			continue
+		}
+
+		hashes[i] = it.Hash()
+		if _, ok := firsts[hashes[i]]; ok {
+			continue
+		}
+		firsts[hashes[i]] = i
+		notes = append(notes, ImportedNote{Note: it.note(userID, importedAt), Hash: hashes[i]})
+	}
+
+	var existing map[string]uuid.UUID
+	created := make(map[string]Note, len(notes))
+	err := ns.txm.Run(ctx, func(ctx context.Context) error {
+		// looked up in the transaction, so userID can't be deleted meanwhile
+		if _, err := ns.userSvc.QueryByID(ctx, userID); err != nil {
+			return fmt.Errorf("import: [%s]: %w", userID, err)
+		}
+
+		var err error
+		if existing, err = ns.repo.Import(ctx, notes); err != nil {
+			return fmt.Errorf("import: [%s]: %w", userID, err)
+		}
+
+		for _, in := range notes {
+			if _, ok := existing[in.Hash]; ok {
+				continue
+			}
+			created[in.Hash] = in.Note
+			if _, err := ns.revisionRepo.Append(ctx, newRevision(in.Note, userID)); err != nil {
+				return fmt.Errorf("import: [%s]: %w", in.Note.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, hash := range hashes {
+		if results[i].Status == ImportFailed {
+			continue
+		}
+		if n, ok := created[hash]; ok && firsts[hash] == i {
+			results[i].Status, results[i].NoteID = ImportCreated, n.ID
+			continue
+		}
+		results[i].Status = ImportSkipped
+		if id, ok := existing[hash]; ok {
+			results[i].NoteID = id
+		} else {
+			results[i].NoteID = created[hash].ID
+		}
+	}
+	return results, nil
+}
+
+func (it ImportItem) note(userID uuid.UUID, importedAt time.Time) Note {
+	n := Note{
+		ID:        uuid.New(),
+		Title:     it.Title,
+		Content:   it.Content,
+		Format:    it.Format,
+		UserID:    userID,
+		Tags:      it.Tags,
+		CreatedAt: it.CreatedAt.UTC().Truncate(time.Microsecond),
+		UpdatedAt: it.UpdatedAt.UTC().Truncate(time.Microsecond),
+		Version:   1,
+	}
+	if n.Format == "" {
+		n.Format = FormatPlain
+	}
+	if n.Title.IsEmpty() {
+		n.Title = NewTitle("")
+	}
+	if n.Content.IsEmpty() {
+		n.Content = NewContent("")
+	}
+	if it.CreatedAt.IsZero() {
+		n.CreatedAt = importedAt
+	}
+	if it.UpdatedAt.IsZero() {
+		n.UpdatedAt = n.CreatedAt
+	}
+	return n
+}
@@ -0,0 +1,78 @@
+package note_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoteService_Import(t *testing.T) {
+	userID := uuid.UUID{1}
+	item := func(name, content string) note.ImportItem {
+		return note.ImportItem{Name: name, Title: note.NewTitle(name), Content: note.NewContent(content), Format: note.FormatMarkdown}
+	}
+
+	t.Run("Creates the notes and skips the ones imported before", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+		ctx := context.Background()
+		createdAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		first := item("a", "content a")
+		first.Tags, first.CreatedAt = note.Tags{"go"}, createdAt
+		items := []note.ImportItem{first, {Name: "broken", Err: fmt.Errorf("invalid")}, item("b", "content b"), first}
+
+		results, err := notesS.Import(ctx, userID, items)
+		assert.NoError(t, err)
+		assert.Len(t, results, 4)
+		assert.Equal(t, []note.ImportStatus{note.ImportCreated, note.ImportFailed, note.ImportCreated, note.ImportSkipped},
+			[]note.ImportStatus{results[0].Status, results[1].Status, results[2].Status, results[3].Status})
+		assert.EqualError(t, results[1].Err, "invalid")
+		assert.Equal(t, results[0].NoteID, results[3].NoteID)
+
+		n, err := notesS.QueryByID(ctx, results[0].NoteID)
+		assert.NoError(t, err)
+		assert.Equal(t, "content a", n.Content.String())
+		assert.Equal(t, note.FormatMarkdown, n.Format)
+		assert.Equal(t, note.Tags{"go"}, n.Tags)
+		assert.Equal(t, createdAt, n.CreatedAt)
+		assert.Equal(t, createdAt, n.UpdatedAt)
+		assert.Equal(t, userID, n.UserID)
+
+		revisions, err := notesS.QueryRevisions(ctx, n.ID)
+		assert.NoError(t, err)
+		assert.Len(t, revisions, 1)
+
+		again, err := notesS.Import(ctx, userID, []note.ImportItem{item("b", "content b"), item("c", "content c")})
+		assert.NoError(t, err)
+		assert.Equal(t, note.ImportResult{Name: "b", Status: note.ImportSkipped, NoteID: results[2].NoteID}, again[0])
+		assert.Equal(t, note.ImportCreated, again[1].Status)
+	})
+
+	t.Run("Other users import the same notes anew", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+		ctx := context.Background()
+
+		robs, err := notesS.Import(ctx, uuid.UUID{1}, []note.ImportItem{item("a", "content a")})
+		assert.NoError(t, err)
+		annas, err := notesS.Import(ctx, uuid.UUID{2}, []note.ImportItem{item("a", "content a")})
+		assert.NoError(t, err)
+
+		assert.Equal(t, note.ImportCreated, annas[0].Status)
+		assert.NotEqual(t, robs[0].NoteID, annas[0].NoteID)
+	})
+
+	t.Run("Rejects unknown users and too many items", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+
+		_, err := notesS.Import(context.Background(), uuid.UUID{9}, []note.ImportItem{item("a", "a")})
+		assert.Error(t, err)
+
+		_, err = notesS.Import(context.Background(), userID, make([]note.ImportItem, note.MaxImportItems+1))
+		assert.ErrorIs(t, err, note.ErrTooManyImportItems)
+	})
+}
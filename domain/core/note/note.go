@@ -1,19 +1,36 @@
 package note
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
 type Note struct {
-	ID      uuid.UUID
-	Title   Title
-	Content Content
-	UserID  uuid.UUID
+	ID        uuid.UUID
+	Title     Title
+	Content   Content
+	Format    Format
+	UserID    uuid.UUID
+	Tags      Tags
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// DeletedAt is set while the note is in the trash.
+	DeletedAt *time.Time
+	// Version starts at 1 and is incremented by every update.
+	Version int
 }
 
+func (n Note) IsTrashed() bool { return n.DeletedAt != nil }
+
+// UpdateNote holds the fields to create or update a note with. UserID is the
+// user making the change, which becomes the owner of a new note and the
+// author of the revision of an update. An empty Format keeps the format of
+// the note, new notes are plain text by default.
 type UpdateNote struct {
 	Title   Title
 	Content Content
+	Format  Format
 	UserID  uuid.UUID
 }
 
@@ -2,59 +2,144 @@ package note
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/Keisn1/note-taking-app/domain/core/user"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
 	"github.com/google/uuid"
 )
 
 type Service interface {
-	Delete(noteID uuid.UUID) error
-	Create(nN UpdateNote) (Note, error)
-	Update(n Note, newN UpdateNote) (Note, error)
+	Delete(ctx context.Context, noteID uuid.UUID, version int) error
+	Create(ctx context.Context, nN UpdateNote) (Note, error)
+	Update(ctx context.Context, n Note, newN UpdateNote) (Note, error)
 	QueryByID(ctx context.Context, noteID uuid.UUID) (Note, error)
 	GetNotesByUserID(userID uuid.UUID) ([]Note, error)
+	StreamNotesByUserID(ctx context.Context, userID uuid.UUID, fn func(Note) error) error
+	Import(ctx context.Context, userID uuid.UUID, items []ImportItem) ([]ImportResult, error)
+	Batch(ctx context.Context, userID uuid.UUID, ops []BatchOp, mode BatchMode) ([]BatchResult, error)
+	Query(ctx context.Context, userID uuid.UUID, filter QueryFilter, orderBy OrderBy, page Page) (NotesPage, error)
+	QueryAll(ctx context.Context) ([]Note, error)
+	Search(ctx context.Context, userID uuid.UUID, query string) ([]SearchResult, error)
+	Permission(ctx context.Context, n Note, userID uuid.UUID) (Permission, error)
+	Share(ctx context.Context, n Note, userID uuid.UUID, perm Permission) (Share, error)
+	Unshare(ctx context.Context, noteID, userID uuid.UUID) error
+	QueryShares(ctx context.Context, noteID uuid.UUID) ([]Share, error)
+	QuerySharedWith(ctx context.Context, userID uuid.UUID) ([]Note, error)
+	QueryTrash(ctx context.Context, userID uuid.UUID) ([]Note, error)
+	Restore(ctx context.Context, noteID, userID uuid.UUID) (Note, error)
+	PurgeTrash(ctx context.Context, retention time.Duration) (int, error)
+	QueryRevisions(ctx context.Context, noteID uuid.UUID) ([]Revision, error)
+	QueryRevision(ctx context.Context, noteID uuid.UUID, number int) (Revision, error)
+	DiffRevisions(ctx context.Context, noteID uuid.UUID, from, to int) (string, error)
+	RestoreRevision(ctx context.Context, n Note, number int, authorID uuid.UUID) (Note, error)
+	AddTags(ctx context.Context, n Note, tags Tags) (Note, error)
+	RemoveTags(ctx context.Context, n Note, tags Tags) (Note, error)
+	RenameTag(ctx context.Context, userID uuid.UUID, from, to string) (int, error)
+	QueryTags(ctx context.Context, userID uuid.UUID) ([]TagCount, error)
 }
 
 type NotesService struct {
-	repo    Repo
-	userSvc user.Service
+	repo         Repo
+	shareRepo    ShareRepo
+	revisionRepo RevisionRepo
+	userSvc      user.Service
+	txm          transaction.Manager
 }
 
-func NewNotesService(nR Repo, us user.Service) NotesService {
-	return NotesService{repo: nR, userSvc: us}
+// NewNotesService takes the transaction manager of the repositories, the
+// repository of us has to be managed by it as well.
+func NewNotesService(nR Repo, sR ShareRepo, rR RevisionRepo, us user.Service, txm transaction.Manager) NotesService {
+	return NotesService{repo: nR, shareRepo: sR, revisionRepo: rR, userSvc: us, txm: txm}
 }
 
-func (ns NotesService) Delete(noteID uuid.UUID) error {
-	err := ns.repo.Delete(noteID)
+// Delete moves the note to the trash, from where it can be restored until it
+// is purged. A version other than 0 is the version the caller last read, it
+// fails with ErrVersionConflict if the note has been changed since.
+func (ns NotesService) Delete(ctx context.Context, noteID uuid.UUID, version int) error {
+	err := ns.repo.Trash(ctx, noteID, version, now())
 	if err != nil {
-		return fmt.Errorf("delete: [%s]", noteID)
+		return fmt.Errorf("delete: [%s]: %w", noteID, err)
 	}
 	return nil
 }
 
+// Create stores the note and its first revision in one transaction with
+// looking up its owner, which can't be deleted in the meantime.
 func (ns NotesService) Create(ctx context.Context, nN UpdateNote) (Note, error) {
-	// MidAuthenticate authenticates user but could still submit
-	// a note with a UserID different from its id
-	if _, err := ns.userSvc.QueryByID(ctx, nN.UserID); err != nil {
+	n := nN.newNote(now())
+	err := ns.txm.Run(ctx, func(ctx context.Context) error {
+		// MidAuthenticate authenticates user but could still submit
+		// a note with a UserID different from its id
+		if _, err := ns.userSvc.QueryByID(ctx, nN.UserID); err != nil {
+			return err
+		}
+
+		if err := ns.repo.Create(ctx, n); err != nil {
+			return err
+		}
+
+		if _, err := ns.revisionRepo.Append(ctx, newRevision(n, nN.UserID)); err != nil {
+			return fmt.Errorf("create: [%s]: %w", n.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
 		return Note{}, err
 	}
+	return n, nil
+}
 
-	n := Note{
-		ID:      uuid.New(),
-		Title:   nN.Title,
-		Content: nN.Content,
-		UserID:  nN.UserID,
+// Update changes the fields of n that are set in newN and records the result
+// as a new revision by newN.UserID, both in one transaction. It fails with
+// ErrVersionConflict if the note has been changed since n was read.
+func (ns NotesService) Update(ctx context.Context, n Note, newN UpdateNote) (Note, error) {
+	n = newN.apply(n, now())
+	authorID := newN.UserID
+	if authorID == uuid.Nil {
+		authorID = n.UserID
 	}
 
-	err := ns.repo.Create(n)
+	err := ns.txm.Run(ctx, func(ctx context.Context) error {
+		if err := ns.repo.Update(ctx, n); err != nil {
+			return fmt.Errorf("update: %w", err)
+		}
+		n.Version++
+
+		if _, err := ns.revisionRepo.Append(ctx, newRevision(n, authorID)); err != nil {
+			return fmt.Errorf("update: [%s]: %w", n.ID, err)
+		}
+		return nil
+	})
 	if err != nil {
 		return Note{}, err
 	}
 	return n, nil
 }
 
-func (ns NotesService) Update(n Note, newN UpdateNote) (Note, error) {
+func (nN UpdateNote) newNote(createdAt time.Time) Note {
+	format := nN.Format
+	if format == "" {
+		format = FormatPlain
+	}
+
+	return Note{
+		ID:        uuid.New(),
+		Title:     nN.Title,
+		Content:   nN.Content,
+		Format:    format,
+		UserID:    nN.UserID,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+		Version:   1,
+	}
+}
+
+// apply sets the fields of n that are set in newN.
+func (newN UpdateNote) apply(n Note, updatedAt time.Time) Note {
 	if !newN.Title.IsEmpty() {
 		n.Title = newN.Title
 	}
@@ -63,11 +148,11 @@ func (ns NotesService) Update(n Note, newN UpdateNote) (Note, error) {
 		n.Content = newN.Content
 	}
 
-	err := ns.repo.Update(n)
-	if err != nil {
-		return Note{}, fmt.Errorf("update: %w", err)
+	if newN.Format != "" {
+		n.Format = newN.Format
 	}
-	return n, nil
+	n.UpdatedAt = updatedAt
+	return n
 }
 
 func (nS NotesService) QueryByID(ctx context.Context, noteID uuid.UUID) (Note, error) {
@@ -75,6 +160,9 @@ func (nS NotesService) QueryByID(ctx context.Context, noteID uuid.UUID) (Note, e
 	if err != nil {
 		return Note{}, fmt.Errorf("getNoteByID: [%s]: %w", noteID, err)
 	}
+	if n.IsTrashed() {
+		return Note{}, fmt.Errorf("getNoteByID: [%s]: %w", noteID, ErrNoteNotFound)
+	}
 	return n, nil
 }
 
@@ -85,3 +173,281 @@ func (nS NotesService) GetNotesByUserID(userID uuid.UUID) ([]Note, error) {
 	}
 	return notes, nil
 }
+
+// StreamNotesByUserID calls fn with each note of userID without loading all
+// of them at once. Unlike GetNotesByUserID it is no error if there are none.
+func (nS NotesService) StreamNotesByUserID(ctx context.Context, userID uuid.UUID, fn func(Note) error) error {
+	if err := nS.repo.StreamByUserID(ctx, userID, fn); err != nil {
+		return fmt.Errorf("streamNotesByUserID: [%s]: %w", userID, err)
+	}
+	return nil
+}
+
+// Query returns a page of the notes of userID. A zero orderBy sorts by
+// DefaultOrderBy, a zero page.Limit returns DefaultLimit notes.
+func (nS NotesService) Query(ctx context.Context, userID uuid.UUID, filter QueryFilter, orderBy OrderBy, page Page) (NotesPage, error) {
+	if orderBy.Field == "" {
+		orderBy = DefaultOrderBy
+	}
+	if page.Limit == 0 {
+		page.Limit = DefaultLimit
+	}
+	if page.Limit < 0 || page.Limit > MaxLimit {
+		return NotesPage{}, fmt.Errorf("query: [%d]: %w", page.Limit, ErrInvalidLimit)
+	}
+	if page.Cursor != "" {
+		if _, err := DecodeCursor(page.Cursor, orderBy); err != nil {
+			return NotesPage{}, fmt.Errorf("query: %w", err)
+		}
+	}
+
+	// one note more than asked for tells whether there is a next page
+	notes, err := nS.repo.Query(ctx, userID, filter, orderBy, Page{Limit: page.Limit + 1, Cursor: page.Cursor})
+	if err != nil {
+		return NotesPage{}, fmt.Errorf("query: [%s]: %w", userID, err)
+	}
+
+	ret := NotesPage{Notes: notes}
+	if len(notes) > page.Limit {
+		ret.Notes = notes[:page.Limit]
+		ret.NextCursor = NewCursor(orderBy, ret.Notes[page.Limit-1]).Encode()
+	}
+	return ret, nil
+}
+
+func (nS NotesService) QueryAll(ctx context.Context) ([]Note, error) {
+	notes, err := nS.repo.QueryAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("queryAll: %w", err)
+	}
+	return notes, nil
+}
+
+// Search looks for query in the title and content of the notes of userID.
+func (nS NotesService) Search(ctx context.Context, userID uuid.UUID, query string) ([]SearchResult, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("search: %w", ErrEmptyQuery)
+	}
+
+	results, err := nS.repo.Search(ctx, userID, query)
+	if err != nil {
+		return nil, fmt.Errorf("search: [%s]: %w", userID, err)
+	}
+	return results, nil
+}
+
+// Permission returns the level of access userID has on n. The owner always
+// has PermissionOwner, everyone else what they have been granted, if anything.
+func (nS NotesService) Permission(ctx context.Context, n Note, userID uuid.UUID) (Permission, error) {
+	if n.UserID == userID {
+		return PermissionOwner, nil
+	}
+
+	perm, err := nS.shareRepo.QueryPermission(ctx, n.ID, userID)
+	if err != nil {
+		if errors.Is(err, ErrShareNotFound) {
+			return PermissionNone, nil
+		}
+		return PermissionNone, fmt.Errorf("permission: [%s]: %w", n.ID, err)
+	}
+	return perm, nil
+}
+
+func (nS NotesService) Share(ctx context.Context, n Note, userID uuid.UUID, perm Permission) (Share, error) {
+	if perm == PermissionNone {
+		return Share{}, fmt.Errorf("share: [%s]: %w", n.ID, ErrInvalidPermission)
+	}
+	if n.UserID == userID {
+		return Share{}, fmt.Errorf("share: [%s]: %w", n.ID, ErrShareWithOwner)
+	}
+	if _, err := nS.userSvc.QueryByID(ctx, userID); err != nil {
+		return Share{}, fmt.Errorf("share: [%s]: %w", n.ID, err)
+	}
+
+	s := Share{NoteID: n.ID, UserID: userID, Permission: perm}
+	if err := nS.shareRepo.Upsert(ctx, s); err != nil {
+		return Share{}, fmt.Errorf("share: [%s]: %w", n.ID, err)
+	}
+	return s, nil
+}
+
+func (nS NotesService) Unshare(ctx context.Context, noteID, userID uuid.UUID) error {
+	if err := nS.shareRepo.Delete(ctx, noteID, userID); err != nil {
+		return fmt.Errorf("unshare: [%s]: %w", noteID, err)
+	}
+	return nil
+}
+
+func (nS NotesService) QueryShares(ctx context.Context, noteID uuid.UUID) ([]Share, error) {
+	shares, err := nS.shareRepo.QueryByNoteID(ctx, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("queryShares: [%s]: %w", noteID, err)
+	}
+	return shares, nil
+}
+
+// QuerySharedWith returns the notes other users have shared with userID.
+func (nS NotesService) QuerySharedWith(ctx context.Context, userID uuid.UUID) ([]Note, error) {
+	shares, err := nS.shareRepo.QueryByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("querySharedWith: [%s]: %w", userID, err)
+	}
+
+	notes := make([]Note, 0, len(shares))
+	for _, s := range shares {
+		n, err := nS.repo.QueryByID(ctx, s.NoteID)
+		if err != nil {
+			if errors.Is(err, ErrNoteNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("querySharedWith: [%s]: %w", userID, err)
+		}
+		if n.IsTrashed() {
+			continue
+		}
+		notes = append(notes, n)
+	}
+	return notes, nil
+}
+
+func (nS NotesService) QueryTrash(ctx context.Context, userID uuid.UUID) ([]Note, error) {
+	notes, err := nS.repo.QueryTrash(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("queryTrash: [%s]: %w", userID, err)
+	}
+	return notes, nil
+}
+
+// Restore takes a note of userID out of the trash. Notes of other users are
+// reported as not found, even if they have been shared with userID.
+func (nS NotesService) Restore(ctx context.Context, noteID, userID uuid.UUID) (Note, error) {
+	n, err := nS.repo.QueryByID(ctx, noteID)
+	if err != nil {
+		return Note{}, fmt.Errorf("restore: [%s]: %w", noteID, err)
+	}
+	if n.UserID != userID {
+		return Note{}, fmt.Errorf("restore: [%s]: %w", noteID, ErrNoteNotFound)
+	}
+	if !n.IsTrashed() {
+		return Note{}, fmt.Errorf("restore: [%s]: %w", noteID, ErrNoteNotTrashed)
+	}
+
+	if err := nS.repo.Restore(ctx, noteID); err != nil {
+		return Note{}, fmt.Errorf("restore: [%s]: %w", noteID, err)
+	}
+	n.DeletedAt = nil
+	return n, nil
+}
+
+// PurgeTrash deletes the notes that have been in the trash for longer than
+// retention and returns how many there were.
+func (nS NotesService) PurgeTrash(ctx context.Context, retention time.Duration) (int, error) {
+	purged, err := nS.repo.Purge(ctx, now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("purgeTrash: %w", err)
+	}
+	return purged, nil
+}
+
+func (nS NotesService) QueryRevisions(ctx context.Context, noteID uuid.UUID) ([]Revision, error) {
+	revisions, err := nS.revisionRepo.QueryByNoteID(ctx, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("queryRevisions: [%s]: %w", noteID, err)
+	}
+	return revisions, nil
+}
+
+func (nS NotesService) QueryRevision(ctx context.Context, noteID uuid.UUID, number int) (Revision, error) {
+	r, err := nS.revisionRepo.QueryByNumber(ctx, noteID, number)
+	if err != nil {
+		return Revision{}, fmt.Errorf("queryRevision: [%s]: [%d]: %w", noteID, number, err)
+	}
+	return r, nil
+}
+
+// DiffRevisions returns the unified diff from revision from to revision to of
+// the note, see Revision.Text.
+func (nS NotesService) DiffRevisions(ctx context.Context, noteID uuid.UUID, from, to int) (string, error) {
+	a, err := nS.revisionRepo.QueryByNumber(ctx, noteID, from)
+	if err != nil {
+		return "", fmt.Errorf("diffRevisions: [%s]: [%d]: %w", noteID, from, err)
+	}
+	b, err := nS.revisionRepo.QueryByNumber(ctx, noteID, to)
+	if err != nil {
+		return "", fmt.Errorf("diffRevisions: [%s]: [%d]: %w", noteID, to, err)
+	}
+
+	return UnifiedDiff(fmt.Sprintf("revision %d", from), fmt.Sprintf("revision %d", to), a.Text(), b.Text()), nil
+}
+
+// RestoreRevision sets title and content of n back to the ones of a former
+// revision. The history is kept, restoring appends a new revision.
+func (nS NotesService) RestoreRevision(ctx context.Context, n Note, number int, authorID uuid.UUID) (Note, error) {
+	r, err := nS.revisionRepo.QueryByNumber(ctx, n.ID, number)
+	if err != nil {
+		return Note{}, fmt.Errorf("restoreRevision: [%s]: [%d]: %w", n.ID, number, err)
+	}
+
+	restored, err := nS.Update(ctx, n, UpdateNote{Title: NewTitle(r.Title), Content: NewContent(r.Content), UserID: authorID})
+	if err != nil {
+		return Note{}, fmt.Errorf("restoreRevision: [%s]: [%d]: %w", n.ID, number, err)
+	}
+	return restored, nil
+}
+
+// AddTags tags n. Tags belong to the owner of the note, also when someone it
+// has been shared with adds them. Changing tags doesn't create a revision,
+// but a new version, and fails with ErrVersionConflict like Update.
+func (nS NotesService) AddTags(ctx context.Context, n Note, tags Tags) (Note, error) {
+	n.Tags = n.Tags.Add(tags)
+	if err := nS.repo.SetTags(ctx, n); err != nil {
+		return Note{}, fmt.Errorf("addTags: [%s]: %w", n.ID, err)
+	}
+	n.Version++
+	return n, nil
+}
+
+func (nS NotesService) RemoveTags(ctx context.Context, n Note, tags Tags) (Note, error) {
+	n.Tags = n.Tags.Remove(tags)
+	if err := nS.repo.SetTags(ctx, n); err != nil {
+		return Note{}, fmt.Errorf("removeTags: [%s]: %w", n.ID, err)
+	}
+	n.Version++
+	return n, nil
+}
+
+// RenameTag renames a tag on all notes of userID and returns how many notes
+// had it.
+func (nS NotesService) RenameTag(ctx context.Context, userID uuid.UUID, from, to string) (int, error) {
+	from, err := NormalizeTag(from)
+	if err != nil {
+		return 0, fmt.Errorf("renameTag: %w", err)
+	}
+	to, err = NormalizeTag(to)
+	if err != nil {
+		return 0, fmt.Errorf("renameTag: %w", err)
+	}
+	if from == to {
+		return 0, nil
+	}
+
+	renamed, err := nS.repo.RenameTag(ctx, userID, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("renameTag: [%s]: %w", from, err)
+	}
+	return renamed, nil
+}
+
+func (nS NotesService) QueryTags(ctx context.Context, userID uuid.UUID) ([]TagCount, error) {
+	tags, err := nS.repo.QueryTags(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("queryTags: [%s]: %w", userID, err)
+	}
+	return tags, nil
+}
+
+// now is truncated to the precision of Postgres timestamps, so that notes
+// compare equal after a round trip through the database.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
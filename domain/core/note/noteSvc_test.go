@@ -6,7 +6,8 @@ import (
 	"testing"
 
 	"github.com/Keisn1/note-taking-app/domain/core/note"
-	"github.com/Keisn1/note-taking-app/domain/core/user"
+	"github.com/Keisn1/note-taking-app/domain/core/note/repositories/memory"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
@@ -16,7 +17,7 @@ func TestNoteService_Delete(t *testing.T) {
 		notesS := Setup(t, fixtureNotes())
 		noteID := uuid.UUID{}
 
-		err := notesS.Delete(noteID)
+		err := notesS.Delete(context.Background(), noteID, 0)
 		assert.ErrorContains(t, err, fmt.Errorf("delete: [%s]", noteID).Error())
 	})
 
@@ -25,7 +26,7 @@ func TestNoteService_Delete(t *testing.T) {
 		robsNote := fixtureNotes()[0]
 		noteID := robsNote.ID
 
-		err := notesS.Delete(noteID)
+		err := notesS.Delete(context.Background(), noteID, 0)
 		assert.NoError(t, err)
 
 		_, err = notesS.QueryByID(context.Background(), noteID)
@@ -44,10 +45,11 @@ func TestNoteService_Create(t *testing.T) {
 	})
 
 	t.Run("Throws error if repo throws error (given repo.Create is called)", func(t *testing.T) {
+		userID := uuid.New()
 		errorRepo := ErrorNoteRepo{}
-		notesS := note.NewNotesService(errorRepo, user.Svc{})
+		userSvc := StubUserService{ids: map[uuid.UUID]struct{}{userID: {}}}
+		notesS := note.NewNotesService(errorRepo, memory.NewShareRepo(nil), memory.NewRevisionRepo(nil), userSvc, transaction.NewMemoryManager())
 
-		userID := uuid.New()
 		newNote := note.UpdateNote{Title: note.NewTitle(""), Content: note.NewContent(""), UserID: userID}
 		_, err := notesS.Create(context.Background(), newNote)
 		assert.Error(t, err)
@@ -66,6 +68,8 @@ func TestNoteService_Create(t *testing.T) {
 		assert.Equal(t, "new note title", got.Title.String())
 		assert.Equal(t, "new note content", got.Content.String())
 		assert.Equal(t, userID, got.UserID)
+		assert.False(t, got.CreatedAt.IsZero())
+		assert.Equal(t, got.CreatedAt, got.UpdatedAt)
 
 		noteID := got.ID
 		want := got
@@ -78,8 +82,6 @@ func TestNoteService_Create(t *testing.T) {
 
 func TestNoteService_Update(t *testing.T) {
 	t.Run("Given a note present in the system and a note containing updates for this note, I can update the present note inside the system", func(t *testing.T) {
-		notesS := Setup(t, fixtureNotes())
-
 		type testCase struct {
 			name       string
 			currNote   note.Note
@@ -132,8 +134,12 @@ func TestNoteService_Update(t *testing.T) {
 
 		for _, tc := range testCases {
 			t.Run(tc.name, func(t *testing.T) {
-				got, err := notesS.Update(tc.currNote, tc.updateNote)
+				notesS := Setup(t, fixtureNotes())
+				got, err := notesS.Update(context.Background(), tc.currNote, tc.updateNote)
 				assert.NoError(t, err)
+				assert.True(t, got.UpdatedAt.After(tc.currNote.UpdatedAt))
+				tc.want.UpdatedAt = got.UpdatedAt
+				tc.want.Version = tc.currNote.Version + 1
 				assert.Equal(t, tc.want, got) // assert that the right note was sent back
 
 				got, err = notesS.QueryByID(context.Background(), tc.currNote.ID)
@@ -144,6 +150,37 @@ func TestNoteService_Update(t *testing.T) {
 	})
 }
 
+func TestNoteService_UpdateConflict(t *testing.T) {
+	notesS := Setup(t, fixtureNotes())
+	n := fixtureNotes()[0]
+
+	updated, err := notesS.Update(context.Background(), n, note.UpdateNote{Title: note.NewTitle("first"), UserID: n.UserID})
+	assert.NoError(t, err)
+	assert.Equal(t, n.Version+1, updated.Version)
+
+	// n is stale now
+	_, err = notesS.Update(context.Background(), n, note.UpdateNote{Title: note.NewTitle("second"), UserID: n.UserID})
+	assert.ErrorIs(t, err, note.ErrVersionConflict)
+
+	got, err := notesS.QueryByID(context.Background(), n.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, updated, got)
+}
+
+func TestNoteService_UpdateRollback(t *testing.T) {
+	n := fixtureNotes()[0]
+	userSvc := StubUserService{ids: map[uuid.UUID]struct{}{n.UserID: {}}}
+	notesS := note.NewNotesService(memory.MustNewRepo(fixtureNotes()), memory.NewShareRepo(nil), ErrorRevisionRepo{}, userSvc, transaction.NewMemoryManager())
+
+	_, err := notesS.Update(context.Background(), n, note.UpdateNote{Title: note.NewTitle("new title"), UserID: n.UserID})
+	assert.ErrorContains(t, err, "error in revisionRepo")
+
+	// the note is not changed without its revision
+	got, err := notesS.QueryByID(context.Background(), n.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, n, got)
+}
+
 func TestNoteService_QueryByID(t *testing.T) {
 	t.Run("GetNoteByID return error on missing note", func(t *testing.T) {
 		notesS := Setup(t, fixtureNotes())
@@ -221,3 +258,51 @@ func TestNoteService_GetNotesByUserID(t *testing.T) {
 		}
 	})
 }
+
+func TestNoteService_StreamNotesByUserID(t *testing.T) {
+	t.Run("Calls fn with each note of the user", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+
+		var got []note.Note
+		err := notesS.StreamNotesByUserID(context.Background(), uuid.UUID{2}, func(n note.Note) error {
+			got = append(got, n)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, fixtureNotes()[2:4], got)
+	})
+
+	t.Run("Users without notes have nothing to stream", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+
+		err := notesS.StreamNotesByUserID(context.Background(), uuid.UUID{9}, func(n note.Note) error {
+			t.Fatal("fn called")
+			return nil
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Forwards repo errors", func(t *testing.T) {
+		notesS := note.NewNotesService(ErrorNoteRepo{}, memory.NewShareRepo(nil), memory.NewRevisionRepo(nil), StubUserService{}, transaction.NewMemoryManager())
+
+		err := notesS.StreamNotesByUserID(context.Background(), uuid.UUID{1}, func(n note.Note) error { return nil })
+		assert.EqualError(t, err, fmt.Sprintf("streamNotesByUserID: [%s]: error in noteRepo", uuid.UUID{1}))
+	})
+}
+
+func TestNoteService_QueryAll(t *testing.T) {
+	t.Run("Returns the notes of all users", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+
+		got, err := notesS.QueryAll(context.Background())
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, fixtureNotes(), got)
+	})
+
+	t.Run("Forwards repo errors", func(t *testing.T) {
+		notesS := note.NewNotesService(ErrorNoteRepo{}, memory.NewShareRepo(nil), memory.NewRevisionRepo(nil), StubUserService{}, transaction.NewMemoryManager())
+
+		_, err := notesS.QueryAll(context.Background())
+		assert.EqualError(t, err, "queryAll: error in noteRepo")
+	})
+}
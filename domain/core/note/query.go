@@ -0,0 +1,147 @@
+package note
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidOrderBy = errors.New("invalid order")
+	ErrInvalidCursor  = errors.New("invalid cursor")
+	ErrInvalidLimit   = errors.New("invalid limit")
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// QueryFilter narrows down the notes of a query. Zero fields don't filter.
+type QueryFilter struct {
+	TitleContains string
+	// Tags keeps the notes having all or, with TagMatchAny, any of the tags.
+	Tags    Tags
+	TagMode TagMode
+}
+
+const (
+	OrderByID        = "id"
+	OrderByTitle     = "title"
+	OrderByCreatedAt = "created_at"
+	OrderByUpdatedAt = "updated_at"
+)
+
+var orderByFields = map[string]struct{}{
+	OrderByID:        {},
+	OrderByTitle:     {},
+	OrderByCreatedAt: {},
+	OrderByUpdatedAt: {},
+}
+
+// OrderBy sorts the notes of a query by Field. Notes with equal Field are
+// sorted by ID in the same direction, which makes the order total.
+type OrderBy struct {
+	Field string
+	Desc  bool
+}
+
+var DefaultOrderBy = OrderBy{Field: OrderByUpdatedAt, Desc: true}
+
+// ParseOrderBy parses orders of the form "<field>.asc" or "<field>.desc".
+// The direction defaults to ascending.
+func ParseOrderBy(s string) (OrderBy, error) {
+	field, dir, _ := strings.Cut(s, ".")
+	if _, ok := orderByFields[field]; !ok {
+		return OrderBy{}, fmt.Errorf("parseOrderBy: [%s]: %w", s, ErrInvalidOrderBy)
+	}
+
+	switch strings.ToLower(dir) {
+	case "", "asc":
+		return OrderBy{Field: field}, nil
+	case "desc":
+		return OrderBy{Field: field, Desc: true}, nil
+	default:
+		return OrderBy{}, fmt.Errorf("parseOrderBy: [%s]: %w", s, ErrInvalidOrderBy)
+	}
+}
+
+func (o OrderBy) String() string {
+	if o.Desc {
+		return o.Field + ".desc"
+	}
+	return o.Field + ".asc"
+}
+
+// Page selects Limit notes following the note Cursor points to. An empty
+// Cursor starts at the first note.
+type Page struct {
+	Limit  int
+	Cursor string
+}
+
+// NotesPage is a page of notes. NextCursor is empty on the last page.
+type NotesPage struct {
+	Notes      []Note
+	NextCursor string
+}
+
+// Cursor is the position of a note in a query ordered by OrderBy. It holds
+// the value of the sort field and the ID of the note, which is all keyset
+// pagination needs.
+type Cursor struct {
+	OrderBy string    `json:"o"`
+	Value   string    `json:"v,omitempty"`
+	ID      uuid.UUID `json:"id"`
+}
+
+func NewCursor(o OrderBy, n Note) Cursor {
+	c := Cursor{OrderBy: o.String(), ID: n.ID}
+	switch o.Field {
+	case OrderByTitle:
+		c.Value = n.Title.String()
+	case OrderByCreatedAt:
+		c.Value = n.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case OrderByUpdatedAt:
+		c.Value = n.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return c
+}
+
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor decodes s and checks that it was issued for a query ordered by o.
+func DecodeCursor(s string, o OrderBy) (Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("decodeCursor: %w", ErrInvalidCursor)
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return Cursor{}, fmt.Errorf("decodeCursor: %w", ErrInvalidCursor)
+	}
+	if c.OrderBy != o.String() {
+		return Cursor{}, fmt.Errorf("decodeCursor: order changed: %w", ErrInvalidCursor)
+	}
+	if _, err := c.Time(); err != nil {
+		return Cursor{}, fmt.Errorf("decodeCursor: %w", ErrInvalidCursor)
+	}
+	return c, nil
+}
+
+// Time returns Value of cursors on a timestamp field.
+func (c Cursor) Time() (time.Time, error) {
+	if !strings.HasPrefix(c.OrderBy, OrderByCreatedAt) && !strings.HasPrefix(c.OrderBy, OrderByUpdatedAt) {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339Nano, c.Value)
+}
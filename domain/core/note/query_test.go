@@ -0,0 +1,113 @@
+package note_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOrderBy(t *testing.T) {
+	testCases := []struct {
+		in      string
+		want    note.OrderBy
+		wantErr bool
+	}{
+		{in: "updated_at.desc", want: note.OrderBy{Field: note.OrderByUpdatedAt, Desc: true}},
+		{in: "title.asc", want: note.OrderBy{Field: note.OrderByTitle}},
+		{in: "created_at", want: note.OrderBy{Field: note.OrderByCreatedAt}},
+		{in: "content.asc", wantErr: true},
+		{in: "id.up", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		got, err := note.ParseOrderBy(tc.in)
+		if tc.wantErr {
+			assert.ErrorIs(t, err, note.ErrInvalidOrderBy, tc.in)
+			continue
+		}
+		assert.NoError(t, err, tc.in)
+		assert.Equal(t, tc.want, got)
+	}
+}
+
+func TestNoteService_Query(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.UUID{1}
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// updated_at ties between the notes 2 and 3 are broken by ID
+	notes := []note.Note{
+		{ID: uuid.UUID{1}, Title: note.NewTitle("Groceries"), Content: note.NewContent(""), UserID: userID, UpdatedAt: base.Add(3 * time.Hour)},
+		{ID: uuid.UUID{2}, Title: note.NewTitle("todo"), Content: note.NewContent(""), UserID: userID, UpdatedAt: base.Add(1 * time.Hour)},
+		{ID: uuid.UUID{3}, Title: note.NewTitle("ideas"), Content: note.NewContent(""), UserID: userID, UpdatedAt: base.Add(1 * time.Hour)},
+		{ID: uuid.UUID{4}, Title: note.NewTitle("grocery list"), Content: note.NewContent(""), UserID: userID, UpdatedAt: base.Add(2 * time.Hour)},
+		{ID: uuid.UUID{5}, Title: note.NewTitle("other users note"), Content: note.NewContent(""), UserID: uuid.UUID{2}, UpdatedAt: base},
+	}
+
+	ids := func(notes []note.Note) []uuid.UUID {
+		var ret []uuid.UUID
+		for _, n := range notes {
+			ret = append(ret, n.ID)
+		}
+		return ret
+	}
+
+	t.Run("Pages through the notes of the user in order", func(t *testing.T) {
+		notesS := Setup(t, notes)
+		orderBy := note.OrderBy{Field: note.OrderByUpdatedAt, Desc: true}
+
+		var got []uuid.UUID
+		page := note.Page{Limit: 3}
+		for {
+			np, err := notesS.Query(ctx, userID, note.QueryFilter{}, orderBy, page)
+			assert.NoError(t, err)
+			assert.LessOrEqual(t, len(np.Notes), 3)
+			got = append(got, ids(np.Notes)...)
+			if np.NextCursor == "" {
+				break
+			}
+			page.Cursor = np.NextCursor
+		}
+		assert.Equal(t, []uuid.UUID{{1}, {4}, {3}, {2}}, got)
+	})
+
+	t.Run("Sorts by title ascending and filters case-insensitively", func(t *testing.T) {
+		notesS := Setup(t, notes)
+
+		np, err := notesS.Query(ctx, userID, note.QueryFilter{TitleContains: "GROC"}, note.OrderBy{Field: note.OrderByTitle}, note.Page{})
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{{1}, {4}}, ids(np.Notes))
+		assert.Empty(t, np.NextCursor)
+	})
+
+	t.Run("Uses the default order", func(t *testing.T) {
+		notesS := Setup(t, notes)
+
+		np, err := notesS.Query(ctx, userID, note.QueryFilter{}, note.OrderBy{}, note.Page{Limit: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{{1}}, ids(np.Notes))
+
+		// the cursor belongs to the default order
+		_, err = notesS.Query(ctx, userID, note.QueryFilter{}, note.DefaultOrderBy, note.Page{Cursor: np.NextCursor})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Rejects invalid pages", func(t *testing.T) {
+		notesS := Setup(t, notes)
+
+		_, err := notesS.Query(ctx, userID, note.QueryFilter{}, note.OrderBy{}, note.Page{Limit: note.MaxLimit + 1})
+		assert.ErrorIs(t, err, note.ErrInvalidLimit)
+
+		_, err = notesS.Query(ctx, userID, note.QueryFilter{}, note.OrderBy{}, note.Page{Cursor: "garbage"})
+		assert.ErrorIs(t, err, note.ErrInvalidCursor)
+
+		np, err := notesS.Query(ctx, userID, note.QueryFilter{}, note.OrderBy{Field: note.OrderByTitle}, note.Page{Limit: 1})
+		assert.NoError(t, err)
+		_, err = notesS.Query(ctx, userID, note.QueryFilter{}, note.OrderBy{Field: note.OrderByID}, note.Page{Cursor: np.NextCursor})
+		assert.ErrorIs(t, err, note.ErrInvalidCursor)
+	})
+}
@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/google/uuid"
+)
+
+// Batch undoes the writes applied so far when an atomic batch fails, like the
+// rollback of the database, and registers them with the transaction of ctx
+// otherwise.
+func (nR Repo) Batch(ctx context.Context, writes []note.BatchWrite, atomic bool) ([]error, error) {
+	errs := make([]error, len(writes))
+	var undo []func()
+	for i, w := range writes {
+		old, existed := nR.notes[w.Note.ID]
+		if errs[i] = nR.write(w); errs[i] == nil {
+			undo = append(undo, func() { nR.restore(w.Note.ID, old, existed) })
+			continue
+		}
+		if !atomic {
+			continue
+		}
+
+		for j := len(undo) - 1; j >= 0; j-- {
+			undo[j]()
+		}
+		for j := range errs {
+			if j != i {
+				errs[j] = note.ErrBatchAborted
+			}
+		}
+		return errs, nil
+	}
+
+	for _, u := range undo {
+		transaction.OnRollback(ctx, u)
+	}
+	return errs, nil
+}
+
+func (nR Repo) write(w note.BatchWrite) error {
+	switch w.Kind {
+	case note.BatchCreate:
+		return nR.Create(context.Background(), w.Note)
+	case note.BatchUpdate:
+		return nR.Update(context.Background(), w.Note)
+	case note.BatchDelete:
+		return nR.Trash(context.Background(), w.Note.ID, w.Note.Version, *w.Note.DeletedAt)
+	}
+	return fmt.Errorf("write: [%s]: %w", w.Kind, note.ErrInvalidBatchOp)
+}
+
+// restore puts back the note as it was before a write.
+func (nR Repo) restore(noteID uuid.UUID, old note.Note, existed bool) {
+	nR.remove(noteID)
+	if existed {
+		nR.put(old)
+	}
+}
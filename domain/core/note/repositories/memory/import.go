@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/google/uuid"
+)
+
+type importKey struct {
+	userID uuid.UUID
+	hash   string
+}
+
+// Import can't fail halfway, which makes it as atomic as the transaction of
+// the database. The hashes of purged notes are forgotten like in the
+// database.
+func (nR Repo) Import(ctx context.Context, notes []note.ImportedNote) (map[string]uuid.UUID, error) {
+	existing := make(map[string]uuid.UUID)
+	for _, in := range notes {
+		key := importKey{userID: in.Note.UserID, hash: in.Hash}
+		if id, ok := nR.imports[key]; ok {
+			if _, ok := nR.notes[id]; ok {
+				existing[in.Hash] = id
+				continue
+			}
+		}
+		nR.imports[key] = in.Note.ID
+		nR.put(in.Note)
+		transaction.OnRollback(ctx, func() {
+			delete(nR.imports, key)
+			nR.remove(in.Note.ID)
+		})
+	}
+	return existing, nil
+}
@@ -2,15 +2,18 @@ package memory
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
 	"github.com/google/uuid"
 )
 
 type Repo struct {
-	notes map[uuid.UUID]note.Note
+	notes   map[uuid.UUID]note.Note
+	index   index
+	imports map[importKey]uuid.UUID
 }
 
 func NewRepo(notes []note.Note) (Repo, error) {
@@ -20,8 +23,11 @@ func NewRepo(notes []note.Note) (Repo, error) {
 	}
 
 	nR.notes = make(map[uuid.UUID]note.Note)
+	nR.index = make(index)
+	nR.imports = make(map[importKey]uuid.UUID)
 	for _, n := range notes {
 		nR.notes[n.ID] = n
+		nR.index.add(n)
 	}
 	return nR, nil
 }
@@ -35,7 +41,8 @@ func MustNewRepo(notes []note.Note) Repo {
 }
 
 func (nR Repo) Delete(noteID uuid.UUID) error {
-	if _, ok := nR.notes[noteID]; ok {
+	if n, ok := nR.notes[noteID]; ok {
+		nR.index.remove(n)
 		delete(nR.notes, noteID)
 		return nil
 	}
@@ -43,20 +50,60 @@ func (nR Repo) Delete(noteID uuid.UUID) error {
 
 }
 
-func (nR Repo) Create(n note.Note) error {
+func (nR Repo) Create(ctx context.Context, n note.Note) error {
 	if _, ok := nR.notes[n.ID]; ok {
 		return fmt.Errorf("create: already present %s", n.ID)
 	}
 	nR.notes[n.ID] = n
+	nR.index.add(n)
+	transaction.OnRollback(ctx, func() { nR.remove(n.ID) })
 	return nil
 }
 
-func (nR Repo) Update(note note.Note) error {
-	if _, ok := nR.notes[note.ID]; ok {
-		nR.notes[note.ID] = note
-		return nil
+func (nR Repo) DeleteByUserID(ctx context.Context, userID uuid.UUID) (int, error) {
+	deleted := 0
+	for id, n := range nR.notes {
+		if n.UserID != userID {
+			continue
+		}
+		nR.remove(id)
+		transaction.OnRollback(ctx, func() { nR.put(n) })
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (nR Repo) put(n note.Note) {
+	nR.notes[n.ID] = n
+	nR.index.add(n)
+}
+
+func (nR Repo) remove(noteID uuid.UUID) {
+	if n, ok := nR.notes[noteID]; ok {
+		nR.index.remove(n)
+		delete(nR.notes, noteID)
+	}
+}
+
+func (nR Repo) Update(ctx context.Context, n note.Note) error {
+	old, ok := nR.notes[n.ID]
+	if !ok {
+		return fmt.Errorf("update: [%s]: %w", n.ID, note.ErrNoteNotFound)
 	}
-	return errors.New("")
+	if old.Version != n.Version {
+		return fmt.Errorf("update: [%s]: %w", n.ID, note.ErrVersionConflict)
+	}
+
+	// tags are stored by SetTags only
+	n.Tags = old.Tags
+	n.Version++
+	nR.remove(n.ID)
+	nR.put(n)
+	transaction.OnRollback(ctx, func() {
+		nR.remove(n.ID)
+		nR.put(old)
+	})
+	return nil
 }
 
 func (nR Repo) QueryByID(ctx context.Context, noteID uuid.UUID) (note.Note, error) {
@@ -65,14 +112,14 @@ func (nR Repo) QueryByID(ctx context.Context, noteID uuid.UUID) (note.Note, erro
 			return n, nil
 		}
 	}
-	return note.Note{}, fmt.Errorf("GetNoteByID: Not found [%s]", noteID)
+	return note.Note{}, fmt.Errorf("queryByID: [%s]: %w", noteID, note.ErrNoteNotFound)
 }
 
 func (nR Repo) QueryByUserID(userID uuid.UUID) ([]note.Note, error) {
 	var ret []note.Note
 	var found bool
 	for _, n := range nR.notes {
-		if n.UserID == userID {
+		if n.UserID == userID && !n.IsTrashed() {
 			found = true
 			ret = append(ret, n)
 		}
@@ -80,6 +127,35 @@ func (nR Repo) QueryByUserID(userID uuid.UUID) ([]note.Note, error) {
 	if !found {
 		return nil, fmt.Errorf("getNotesByUserID: not found [%s]", userID)
 	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].ID.String() < ret[j].ID.String() })
+	return ret, nil
+}
+
+func (nR Repo) StreamByUserID(ctx context.Context, userID uuid.UUID, fn func(note.Note) error) error {
+	var notes []note.Note
+	for _, n := range nR.notes {
+		if n.UserID == userID && !n.IsTrashed() {
+			notes = append(notes, n)
+		}
+	}
+	sort.Slice(notes, func(i, j int) bool { return notes[i].ID.String() < notes[j].ID.String() })
+
+	for _, n := range notes {
+		if err := fn(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (nR Repo) QueryAll(ctx context.Context) ([]note.Note, error) {
+	ret := make([]note.Note, 0, len(nR.notes))
+	for _, n := range nR.notes {
+		if !n.IsTrashed() {
+			ret = append(ret, n)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].ID.String() < ret[j].ID.String() })
 	return ret, nil
 }
 
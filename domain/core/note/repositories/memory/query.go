@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/google/uuid"
+)
+
+func (nR Repo) Query(ctx context.Context, userID uuid.UUID, filter note.QueryFilter, orderBy note.OrderBy, page note.Page) ([]note.Note, error) {
+	var notes []note.Note
+	for _, n := range nR.notes {
+		if n.UserID == userID && !n.IsTrashed() && matchesFilter(n, filter) {
+			notes = append(notes, n)
+		}
+	}
+	sort.Slice(notes, func(i, j int) bool { return less(notes[i], notes[j], orderBy) })
+
+	start := 0
+	if page.Cursor != "" {
+		c, err := note.DecodeCursor(page.Cursor, orderBy)
+		if err != nil {
+			return nil, err
+		}
+		// the note of the cursor may be gone, so search for the first note
+		// sorting after its position
+		start = sort.Search(len(notes), func(i int) bool { return afterCursor(notes[i], c, orderBy) })
+	}
+
+	end := min(len(notes), start+page.Limit)
+	ret := make([]note.Note, 0, end-start)
+	return append(ret, notes[start:end]...), nil
+}
+
+func matchesFilter(n note.Note, filter note.QueryFilter) bool {
+	if filter.TitleContains != "" && !strings.Contains(strings.ToLower(n.Title.String()), strings.ToLower(filter.TitleContains)) {
+		return false
+	}
+	return matchesTags(n, filter)
+}
+
+func less(a, b note.Note, orderBy note.OrderBy) bool {
+	c := compare(a, note.NewCursor(orderBy, b), orderBy)
+	if orderBy.Desc {
+		return c > 0
+	}
+	return c < 0
+}
+
+func afterCursor(n note.Note, c note.Cursor, orderBy note.OrderBy) bool {
+	cmp := compare(n, c, orderBy)
+	if orderBy.Desc {
+		return cmp < 0
+	}
+	return cmp > 0
+}
+
+// compare compares the position of n to the one of c in ascending order.
+func compare(n note.Note, c note.Cursor, orderBy note.OrderBy) int {
+	var cmp int
+	switch orderBy.Field {
+	case note.OrderByTitle:
+		cmp = strings.Compare(n.Title.String(), c.Value)
+	case note.OrderByCreatedAt, note.OrderByUpdatedAt:
+		t, _ := c.Time()
+		nt := n.CreatedAt
+		if orderBy.Field == note.OrderByUpdatedAt {
+			nt = n.UpdatedAt
+		}
+		cmp = nt.Compare(t)
+	}
+	if cmp != 0 {
+		return cmp
+	}
+	return strings.Compare(n.ID.String(), c.ID.String())
+}
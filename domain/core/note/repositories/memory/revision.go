@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/google/uuid"
+)
+
+type RevisionRepo struct {
+	mu        *sync.RWMutex
+	revisions map[uuid.UUID][]note.Revision
+}
+
+// NewRevisionRepo expects the revisions of each note to be numbered 1, 2, ...
+// in order.
+func NewRevisionRepo(revisions []note.Revision) RevisionRepo {
+	rR := RevisionRepo{
+		mu:        &sync.RWMutex{},
+		revisions: make(map[uuid.UUID][]note.Revision),
+	}
+	for _, r := range revisions {
+		rR.revisions[r.NoteID] = append(rR.revisions[r.NoteID], r)
+	}
+	return rR
+}
+
+func (rR RevisionRepo) Append(ctx context.Context, r note.Revision) (note.Revision, error) {
+	rR.mu.Lock()
+	defer rR.mu.Unlock()
+	r.Number = len(rR.revisions[r.NoteID]) + 1
+	rR.revisions[r.NoteID] = append(rR.revisions[r.NoteID], r)
+	transaction.OnRollback(ctx, func() {
+		rR.mu.Lock()
+		defer rR.mu.Unlock()
+		rR.revisions[r.NoteID] = rR.revisions[r.NoteID][:r.Number-1]
+	})
+	return r, nil
+}
+
+func (rR RevisionRepo) QueryByNoteID(ctx context.Context, noteID uuid.UUID) ([]note.Revision, error) {
+	rR.mu.RLock()
+	defer rR.mu.RUnlock()
+	return append([]note.Revision{}, rR.revisions[noteID]...), nil
+}
+
+func (rR RevisionRepo) QueryByNumber(ctx context.Context, noteID uuid.UUID, number int) (note.Revision, error) {
+	rR.mu.RLock()
+	defer rR.mu.RUnlock()
+	revisions := rR.revisions[noteID]
+	if number < 1 || number > len(revisions) {
+		return note.Revision{}, fmt.Errorf("queryByNumber: [%s]: [%d]: %w", noteID, number, note.ErrRevisionNotFound)
+	}
+	return revisions[number-1], nil
+}
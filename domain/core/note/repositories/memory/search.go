@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/google/uuid"
+)
+
+const (
+	titleWeight      = 2
+	snippetWords     = 20
+	snippetLeadWords = 5
+)
+
+// index maps the lowercased words of title and content to the notes they
+// appear in. There is no stemming, so only whole words are found.
+type index map[string]map[uuid.UUID]struct{}
+
+func (idx index) add(n note.Note) {
+	for _, w := range tokenize(n.Title.String() + " " + n.Content.String()) {
+		if idx[w] == nil {
+			idx[w] = make(map[uuid.UUID]struct{})
+		}
+		idx[w][n.ID] = struct{}{}
+	}
+}
+
+func (idx index) remove(n note.Note) {
+	for _, w := range tokenize(n.Title.String() + " " + n.Content.String()) {
+		delete(idx[w], n.ID)
+		if len(idx[w]) == 0 {
+			delete(idx, w)
+		}
+	}
+}
+
+func (nR Repo) Search(ctx context.Context, userID uuid.UUID, query string) ([]note.SearchResult, error) {
+	terms := unique(tokenize(query))
+	ret := []note.SearchResult{}
+	if len(terms) == 0 {
+		return ret, nil
+	}
+
+	for noteID := range nR.index[terms[0]] {
+		n := nR.notes[noteID]
+		if n.UserID != userID || n.IsTrashed() || !nR.matchesAll(noteID, terms[1:]) {
+			continue
+		}
+		ret = append(ret, note.SearchResult{Note: n, Rank: rank(n, terms), Snippet: snippet(n, terms)})
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Rank != ret[j].Rank {
+			return ret[i].Rank > ret[j].Rank
+		}
+		return ret[i].Note.ID.String() < ret[j].Note.ID.String()
+	})
+	return ret, nil
+}
+
+func (nR Repo) matchesAll(noteID uuid.UUID, terms []string) bool {
+	for _, t := range terms {
+		if _, ok := nR.index[t][noteID]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// rank counts the occurrences of terms, words of the title count more.
+func rank(n note.Note, terms []string) float64 {
+	var r float64
+	for _, t := range terms {
+		r += titleWeight * float64(count(tokenize(n.Title.String()), t))
+		r += float64(count(tokenize(n.Content.String()), t))
+	}
+	return r
+}
+
+// snippet returns up to snippetWords words of title and content around the
+// first match with the matching words wrapped in <b></b>.
+func snippet(n note.Note, terms []string) string {
+	words := strings.Fields(n.Title.String() + " " + n.Content.String())
+
+	first := 0
+	for i, w := range words {
+		if matches(w, terms) {
+			first = i
+			break
+		}
+	}
+	start := max(0, first-snippetLeadWords)
+	end := min(len(words), start+snippetWords)
+
+	out := make([]string, 0, end-start)
+	for _, w := range words[start:end] {
+		if matches(w, terms) {
+			w = "<b>" + w + "</b>"
+		}
+		out = append(out, w)
+	}
+	return strings.Join(out, " ")
+}
+
+func matches(word string, terms []string) bool {
+	for _, tok := range tokenize(word) {
+		for _, t := range terms {
+			if tok == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func unique(words []string) []string {
+	seen := make(map[string]struct{}, len(words))
+	ret := words[:0]
+	for _, w := range words {
+		if _, ok := seen[w]; !ok {
+			seen[w] = struct{}{}
+			ret = append(ret, w)
+		}
+	}
+	return ret
+}
+
+func count(words []string, term string) int {
+	c := 0
+	for _, w := range words {
+		if w == term {
+			c++
+		}
+	}
+	return c
+}
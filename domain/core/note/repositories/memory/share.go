@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/google/uuid"
+)
+
+type shareKey struct {
+	noteID uuid.UUID
+	userID uuid.UUID
+}
+
+type ShareRepo struct {
+	mu     *sync.RWMutex
+	shares map[shareKey]note.Share
+}
+
+func NewShareRepo(shares []note.Share) ShareRepo {
+	sR := ShareRepo{
+		mu:     &sync.RWMutex{},
+		shares: make(map[shareKey]note.Share),
+	}
+	for _, s := range shares {
+		sR.shares[shareKey{s.NoteID, s.UserID}] = s
+	}
+	return sR
+}
+
+func (sR ShareRepo) Upsert(ctx context.Context, s note.Share) error {
+	sR.mu.Lock()
+	defer sR.mu.Unlock()
+	sR.shares[shareKey{s.NoteID, s.UserID}] = s
+	return nil
+}
+
+func (sR ShareRepo) Delete(ctx context.Context, noteID, userID uuid.UUID) error {
+	sR.mu.Lock()
+	defer sR.mu.Unlock()
+	key := shareKey{noteID, userID}
+	if _, ok := sR.shares[key]; !ok {
+		return fmt.Errorf("delete: [%s]: %w", noteID, note.ErrShareNotFound)
+	}
+	delete(sR.shares, key)
+	return nil
+}
+
+func (sR ShareRepo) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	sR.mu.Lock()
+	defer sR.mu.Unlock()
+	for key, s := range sR.shares {
+		if s.UserID != userID {
+			continue
+		}
+		delete(sR.shares, key)
+		transaction.OnRollback(ctx, func() {
+			sR.mu.Lock()
+			defer sR.mu.Unlock()
+			sR.shares[key] = s
+		})
+	}
+	return nil
+}
+
+func (sR ShareRepo) QueryPermission(ctx context.Context, noteID, userID uuid.UUID) (note.Permission, error) {
+	sR.mu.RLock()
+	defer sR.mu.RUnlock()
+	s, ok := sR.shares[shareKey{noteID, userID}]
+	if !ok {
+		return note.PermissionNone, fmt.Errorf("queryPermission: [%s]: %w", noteID, note.ErrShareNotFound)
+	}
+	return s.Permission, nil
+}
+
+func (sR ShareRepo) QueryByNoteID(ctx context.Context, noteID uuid.UUID) ([]note.Share, error) {
+	return sR.filter(func(s note.Share) bool { return s.NoteID == noteID }), nil
+}
+
+func (sR ShareRepo) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]note.Share, error) {
+	return sR.filter(func(s note.Share) bool { return s.UserID == userID }), nil
+}
+
+func (sR ShareRepo) filter(keep func(note.Share) bool) []note.Share {
+	sR.mu.RLock()
+	defer sR.mu.RUnlock()
+	ret := []note.Share{}
+	for _, s := range sR.shares {
+		if keep(s) {
+			ret = append(ret, s)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].NoteID != ret[j].NoteID {
+			return ret[i].NoteID.String() < ret[j].NoteID.String()
+		}
+		return ret[i].UserID.String() < ret[j].UserID.String()
+	})
+	return ret
+}
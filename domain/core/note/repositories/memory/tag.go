@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/google/uuid"
+)
+
+func (nR Repo) SetTags(ctx context.Context, n note.Note) error {
+	stored, ok := nR.notes[n.ID]
+	if !ok {
+		return fmt.Errorf("setTags: [%s]: %w", n.ID, note.ErrNoteNotFound)
+	}
+	if stored.Version != n.Version {
+		return fmt.Errorf("setTags: [%s]: %w", n.ID, note.ErrVersionConflict)
+	}
+	stored.Tags = n.Tags
+	stored.Version++
+	nR.notes[n.ID] = stored
+	return nil
+}
+
+func (nR Repo) RenameTag(ctx context.Context, userID uuid.UUID, from, to string) (int, error) {
+	var renamed int
+	for id, n := range nR.notes {
+		if n.UserID != userID || !n.Tags.Contains(from) {
+			continue
+		}
+		n.Tags = n.Tags.Remove(note.Tags{from}).Add(note.Tags{to})
+		n.Version++
+		nR.notes[id] = n
+		renamed++
+	}
+	if renamed == 0 {
+		return 0, fmt.Errorf("renameTag: [%s]: %w", from, note.ErrTagNotFound)
+	}
+	return renamed, nil
+}
+
+func (nR Repo) QueryTags(ctx context.Context, userID uuid.UUID) ([]note.TagCount, error) {
+	counts := make(map[string]int)
+	for _, n := range nR.notes {
+		if n.UserID != userID || n.IsTrashed() {
+			continue
+		}
+		for _, tag := range n.Tags {
+			counts[tag]++
+		}
+	}
+
+	ret := make([]note.TagCount, 0, len(counts))
+	for name, count := range counts {
+		ret = append(ret, note.TagCount{Name: name, Count: count})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return ret, nil
+}
+
+func matchesTags(n note.Note, filter note.QueryFilter) bool {
+	if len(filter.Tags) == 0 {
+		return true
+	}
+	for _, tag := range filter.Tags {
+		has := n.Tags.Contains(tag)
+		if filter.TagMode == note.TagMatchAny && has {
+			return true
+		}
+		if filter.TagMode == note.TagMatchAll && !has {
+			return false
+		}
+	}
+	return filter.TagMode == note.TagMatchAll
+}
@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/google/uuid"
+)
+
+func (nR Repo) Trash(ctx context.Context, noteID uuid.UUID, version int, at time.Time) error {
+	n, ok := nR.notes[noteID]
+	if !ok || n.IsTrashed() {
+		return fmt.Errorf("trash: [%s]: %w", noteID, note.ErrNoteNotFound)
+	}
+	if version != 0 && n.Version != version {
+		return fmt.Errorf("trash: [%s]: %w", noteID, note.ErrVersionConflict)
+	}
+	n.DeletedAt = &at
+	nR.notes[noteID] = n
+	return nil
+}
+
+func (nR Repo) Restore(ctx context.Context, noteID uuid.UUID) error {
+	n, ok := nR.notes[noteID]
+	if !ok || !n.IsTrashed() {
+		return fmt.Errorf("restore: [%s]: %w", noteID, note.ErrNoteNotFound)
+	}
+	n.DeletedAt = nil
+	nR.notes[noteID] = n
+	return nil
+}
+
+func (nR Repo) QueryTrash(ctx context.Context, userID uuid.UUID) ([]note.Note, error) {
+	ret := []note.Note{}
+	for _, n := range nR.notes {
+		if n.UserID == userID && n.IsTrashed() {
+			ret = append(ret, n)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if !ret[i].DeletedAt.Equal(*ret[j].DeletedAt) {
+			return ret[i].DeletedAt.After(*ret[j].DeletedAt)
+		}
+		return ret[i].ID.String() < ret[j].ID.String()
+	})
+	return ret, nil
+}
+
+func (nR Repo) Purge(ctx context.Context, before time.Time) (int, error) {
+	var purged int
+	for id, n := range nR.notes {
+		if n.IsTrashed() && n.DeletedAt.Before(before) {
+			nR.index.remove(n)
+			delete(nR.notes, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
@@ -0,0 +1,91 @@
+package notedb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+)
+
+// Batch runs each write of a best effort batch inside a savepoint, which
+// keeps a failing write from aborting the transaction. An atomic batch rolls
+// back to a savepoint taken before its first write, which leaves the writes
+// of a transaction it joined alone.
+func (nR NoteRepo) Batch(ctx context.Context, writes []note.BatchWrite, atomic bool) ([]error, error) {
+	errs := make([]error, len(writes))
+	err := transaction.NewSQLManager(nR.db).Run(ctx, func(ctx context.Context) error {
+		db := nR.conn(ctx)
+		if atomic {
+			if _, err := db.ExecContext(ctx, `SAVEPOINT batch`); err != nil {
+				return err
+			}
+		}
+
+		for i, w := range writes {
+			if !atomic {
+				if _, err := db.ExecContext(ctx, `SAVEPOINT batch_write`); err != nil {
+					return err
+				}
+			}
+
+			var err error
+			errs[i] = write(ctx, db, w)
+			switch {
+			case errs[i] == nil && !atomic:
+				_, err = db.ExecContext(ctx, `RELEASE SAVEPOINT batch_write`)
+			case errs[i] != nil && !atomic:
+				_, err = db.ExecContext(ctx, `ROLLBACK TO SAVEPOINT batch_write`)
+			case errs[i] != nil:
+				for j := range errs {
+					if j != i {
+						errs[j] = note.ErrBatchAborted
+					}
+				}
+				_, err = db.ExecContext(ctx, `ROLLBACK TO SAVEPOINT batch`)
+				return err
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("batch: %w", err)
+	}
+	return errs, nil
+}
+
+func write(ctx context.Context, db transaction.Querier, w note.BatchWrite) error {
+	n := w.Note
+	switch w.Kind {
+	case note.BatchCreate:
+		_, err := db.ExecContext(ctx, insertNote,
+			n.ID, n.Title.String(), n.Content.String(), n.UserID, n.CreatedAt, n.UpdatedAt, n.Version, string(n.Format))
+		if err != nil {
+			return fmt.Errorf("write: [%s]: %w", n.ID, err)
+		}
+		return nil
+	case note.BatchUpdate:
+		res, err := db.ExecContext(ctx, updateNote, n.Title.String(), n.Content.String(), n.UpdatedAt, n.ID, n.Version, string(n.Format))
+		if err != nil {
+			return fmt.Errorf("write: [%s]: %w", n.ID, err)
+		}
+		if c, _ := res.RowsAffected(); c == 0 {
+			// the note was read before the batch, so it has changed since
+			return fmt.Errorf("write: [%s]: %w", n.ID, note.ErrVersionConflict)
+		}
+		return nil
+	case note.BatchDelete:
+		res, err := db.ExecContext(ctx, trashNote, n.ID, *n.DeletedAt, n.Version)
+		if err != nil {
+			return fmt.Errorf("write: [%s]: %w", n.ID, err)
+		}
+		if c, _ := res.RowsAffected(); c == 0 {
+			return fmt.Errorf("write: [%s]: %w", n.ID, notTrashed(ctx, db, n.ID))
+		}
+		return nil
+	}
+	return fmt.Errorf("write: [%s]: %w", w.Kind, note.ErrInvalidBatchOp)
+}
@@ -0,0 +1,104 @@
+package notedb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/core/note/repositories/notedb"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotesRepo_Batch(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	created := note.Note{ID: uuid.UUID{5}, Title: note.NewTitle("new"), Content: note.NewContent(""), Format: note.FormatPlain, UserID: uuid.UUID{1}, Version: 1}
+
+	writes := func(t *testing.T, nR notedb.NoteRepo) []note.BatchWrite {
+		t.Helper()
+		n1, err := nR.QueryByID(ctx, uuid.UUID{1})
+		assert.NoError(t, err)
+		n2, err := nR.QueryByID(ctx, uuid.UUID{2})
+		assert.NoError(t, err)
+
+		n1.Content = note.NewContent("changed")
+		stale := n2
+		stale.Version--
+		n2.DeletedAt = &at
+		return []note.BatchWrite{
+			{Kind: note.BatchCreate, Note: created},
+			{Kind: note.BatchUpdate, Note: n1},
+			{Kind: note.BatchUpdate, Note: stale},
+			{Kind: note.BatchDelete, Note: n2},
+		}
+	}
+
+	t.Run("An atomic batch applies nothing if a write fails", func(t *testing.T) {
+		testDB, deleteTable := SetupNotesTable(t, fixtureNotes())
+		defer deleteTable()
+		nR := notedb.NewNotesRepo(testDB)
+
+		errs, err := nR.Batch(ctx, writes(t, nR), true)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, errs[0], note.ErrBatchAborted)
+		assert.ErrorIs(t, errs[2], note.ErrVersionConflict)
+		assert.ErrorIs(t, errs[3], note.ErrBatchAborted)
+
+		_, err = nR.QueryByID(ctx, created.ID)
+		assert.ErrorIs(t, err, note.ErrNoteNotFound)
+		n1, err := nR.QueryByID(ctx, uuid.UUID{1})
+		assert.NoError(t, err)
+		assert.Equal(t, "robs 1st note content", n1.Content.String())
+	})
+
+	t.Run("A best effort batch applies the writes that succeed", func(t *testing.T) {
+		testDB, deleteTable := SetupNotesTable(t, fixtureNotes())
+		defer deleteTable()
+		nR := notedb.NewNotesRepo(testDB)
+
+		errs, err := nR.Batch(ctx, writes(t, nR), false)
+		assert.NoError(t, err)
+		assert.NoError(t, errs[0])
+		assert.NoError(t, errs[1])
+		assert.ErrorIs(t, errs[2], note.ErrVersionConflict)
+		assert.NoError(t, errs[3])
+
+		got, err := nR.QueryByID(ctx, created.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, created, got)
+		n1, err := nR.QueryByID(ctx, uuid.UUID{1})
+		assert.NoError(t, err)
+		assert.Equal(t, "changed", n1.Content.String())
+		assert.Equal(t, 1, n1.Version)
+		n2, err := nR.QueryByID(ctx, uuid.UUID{2})
+		assert.NoError(t, err)
+		assert.True(t, n2.IsTrashed())
+	})
+
+	t.Run("A delete of a note changed since it was read is a conflict", func(t *testing.T) {
+		testDB, deleteTable := SetupNotesTable(t, fixtureNotes())
+		defer deleteTable()
+		nR := notedb.NewNotesRepo(testDB)
+		n3, err := nR.QueryByID(ctx, uuid.UUID{3})
+		assert.NoError(t, err)
+		n3.Version += 5
+		n3.DeletedAt = &at
+
+		errs, err := nR.Batch(ctx, []note.BatchWrite{{Kind: note.BatchDelete, Note: n3}}, false)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, errs[0], note.ErrVersionConflict)
+
+		got, err := nR.QueryByID(ctx, uuid.UUID{3})
+		assert.NoError(t, err)
+		assert.False(t, got.IsTrashed())
+	})
+
+	t.Run("Fowards error on database error", func(t *testing.T) {
+		nR := notedb.NewNotesRepo(&stubSQLDB{})
+
+		_, err := nR.Batch(ctx, nil, true)
+		assert.EqualError(t, err, "batch: begin: DBError")
+	})
+}
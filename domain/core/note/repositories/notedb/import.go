@@ -0,0 +1,53 @@
+package notedb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/google/uuid"
+)
+
+// Import claims the hash of each note before inserting it. The foreign key of
+// note_imports is only checked at commit, and a concurrent import of the same
+// hash waits for this transaction and then gets the note stored by it.
+func (nR NoteRepo) Import(ctx context.Context, notes []note.ImportedNote) (map[string]uuid.UUID, error) {
+	// the update makes the existing row visible to RETURNING
+	claimHash := `
+	INSERT INTO note_imports (user_id, hash, note_id) VALUES ($1, $2, $3)
+	ON CONFLICT (user_id, hash) DO UPDATE SET hash = EXCLUDED.hash
+	RETURNING note_id`
+
+	existing := make(map[string]uuid.UUID)
+	err := transaction.NewSQLManager(nR.db).Run(ctx, func(ctx context.Context) error {
+		db := nR.conn(ctx)
+		for _, in := range notes {
+			n := in.Note
+			var noteID uuid.UUID
+			if err := db.QueryRowContext(ctx, claimHash, n.UserID, in.Hash, n.ID).Scan(&noteID); err != nil {
+				return fmt.Errorf("[%s]: %w", n.ID, err)
+			}
+			if noteID != n.ID {
+				existing[in.Hash] = noteID
+				continue
+			}
+
+			_, err := db.ExecContext(ctx, insertNote,
+				n.ID, n.Title.String(), n.Content.String(), n.UserID, n.CreatedAt, n.UpdatedAt, n.Version, string(n.Format))
+			if err != nil {
+				return fmt.Errorf("[%s]: %w", n.ID, err)
+			}
+			if len(n.Tags) > 0 {
+				if _, err := db.ExecContext(ctx, setTags, n.ID, n.UserID, tagNames(n)); err != nil {
+					return fmt.Errorf("[%s]: %w", n.ID, err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("import: %w", err)
+	}
+	return existing, nil
+}
@@ -0,0 +1,75 @@
+package notedb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/core/note/repositories/notedb"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotesRepo_Import(t *testing.T) {
+	testDB, deleteTable := SetupNotesTable(t, fixtureNotes())
+	defer deleteTable()
+	nR := notedb.NewNotesRepo(testDB)
+	ctx := context.Background()
+
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	imported := []note.ImportedNote{
+		{Hash: "a", Note: note.Note{ID: uuid.UUID{5}, Title: note.NewTitle("a"), Content: note.NewContent("a"), Format: note.FormatMarkdown, UserID: uuid.UUID{1}, Tags: note.Tags{"go"}, CreatedAt: createdAt, UpdatedAt: createdAt, Version: 1}},
+		{Hash: "b", Note: note.Note{ID: uuid.UUID{6}, Title: note.NewTitle("b"), Content: note.NewContent("b"), Format: note.FormatPlain, UserID: uuid.UUID{1}, CreatedAt: createdAt, UpdatedAt: createdAt, Version: 1}},
+	}
+
+	t.Run("Stores the notes with their tags", func(t *testing.T) {
+		existing, err := nR.Import(ctx, imported)
+		assert.NoError(t, err)
+		assert.Empty(t, existing)
+
+		for _, in := range imported {
+			got, err := nR.QueryByID(ctx, in.Note.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, in.Note, got)
+		}
+	})
+
+	t.Run("Returns the notes imported before", func(t *testing.T) {
+		again := []note.ImportedNote{
+			{Hash: "a", Note: note.Note{ID: uuid.UUID{7}, Title: note.NewTitle("a"), Content: note.NewContent("a"), Format: note.FormatMarkdown, UserID: uuid.UUID{1}, Version: 1}},
+			// the hashes are per user
+			{Hash: "a", Note: note.Note{ID: uuid.UUID{8}, Title: note.NewTitle("a"), Content: note.NewContent("a"), Format: note.FormatMarkdown, UserID: uuid.UUID{2}, Version: 1}},
+		}
+
+		existing, err := nR.Import(ctx, again)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]uuid.UUID{"a": {5}}, existing)
+
+		_, err = nR.QueryByID(ctx, uuid.UUID{7})
+		assert.ErrorIs(t, err, note.ErrNoteNotFound)
+		_, err = nR.QueryByID(ctx, uuid.UUID{8})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Stores nothing if a note fails", func(t *testing.T) {
+		failing := []note.ImportedNote{
+			{Hash: "c", Note: note.Note{ID: uuid.UUID{9}, Title: note.NewTitle("c"), Content: note.NewContent("c"), Format: note.FormatPlain, UserID: uuid.UUID{1}, Version: 1}},
+			// the id is taken
+			{Hash: "d", Note: note.Note{ID: uuid.UUID{1}, Title: note.NewTitle("d"), Content: note.NewContent("d"), Format: note.FormatPlain, UserID: uuid.UUID{1}, Version: 1}},
+		}
+
+		_, err := nR.Import(ctx, failing)
+		assert.Error(t, err)
+
+		_, err = nR.QueryByID(ctx, uuid.UUID{9})
+		assert.ErrorIs(t, err, note.ErrNoteNotFound)
+	})
+
+	t.Run("Fowards error on database error", func(t *testing.T) {
+		nR := notedb.NewNotesRepo(&stubSQLDB{})
+
+		_, err := nR.Import(ctx, imported)
+		assert.EqualError(t, err, "import: begin: DBError")
+	})
+}
@@ -4,22 +4,52 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type DBNote struct {
-	ID      uuid.UUID
-	Title   string
-	Content string
-	UserID  uuid.UUID
+	ID        uuid.UUID
+	Title     string
+	Content   string
+	Format    string
+	UserID    uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt sql.NullTime
+	Version   int
+	Tags      []string
 }
 
 type database interface {
 	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
 	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
 	Exec(query string, args ...any) (sql.Result, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// noteColumns are the columns read by scanNote. The tags of a note are
+// aggregated into an array, sorted byte-wise like note.Tags.
+const noteColumns = `id, title, content, format, user_id, created_at, updated_at, deleted_at, version,
+	ARRAY(SELECT t.name FROM note_tags nt JOIN tags t ON t.id = nt.tag_id WHERE nt.note_id = notes.id ORDER BY t.name COLLATE "C")`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanNote scans the noteColumns of a row into nDB, followed by the extra
+// columns of the query.
+func scanNote(s scanner, nDB *DBNote, extra ...any) error {
+	tags := pgtype.NewMap().SQLScanner(&nDB.Tags)
+	dest := []any{&nDB.ID, &nDB.Title, &nDB.Content, &nDB.Format, &nDB.UserID, &nDB.CreatedAt, &nDB.UpdatedAt, &nDB.DeletedAt, &nDB.Version, tags}
+	return s.Scan(append(dest, extra...)...)
 }
 
 type NoteRepo struct {
@@ -30,20 +60,42 @@ func NewNotesRepo(db database) NoteRepo {
 	return NoteRepo{db: db}
 }
 
-func (nR NoteRepo) Update(n note.Note) error {
-	updateRow := `
+// conn returns the transaction carried by ctx, so that the methods taking a
+// context are part of a unit of work of a transaction.Manager.
+func (nR NoteRepo) conn(ctx context.Context) transaction.Querier {
+	return transaction.DB(ctx, nR.db)
+}
+
+const updateNote = `
 	UPDATE notes
-	SET title = $1, content = $2 WHERE id=$3 `
+	SET title = $1, content = $2, format = COALESCE(NULLIF($6, ''), format), updated_at = $3, version = version + 1
+	WHERE id = $4 AND version = $5`
 
-	res, err := nR.db.Exec(updateRow, n.Title.String(), n.Content.String(), n.ID)
+// Update keeps the format of the note if n has none.
+func (nR NoteRepo) Update(ctx context.Context, n note.Note) error {
+	res, err := nR.conn(ctx).ExecContext(ctx, updateNote, n.Title.String(), n.Content.String(), n.UpdatedAt, n.ID, n.Version, string(n.Format))
 	if err != nil {
 		return fmt.Errorf("update: [%v]: %w", n, err)
 	}
-	if c, _ := res.RowsAffected(); c == 0 {
-		return note.ErrNoteNotFound
+	if c, _ := res.RowsAffected(); c > 0 {
+		return nil
 	}
 
-	return nil
+	return fmt.Errorf("update: [%s]: %w", n.ID, notUpdated(ctx, nR.conn(ctx), n.ID))
+}
+
+// notUpdated tells why an update checking the version of the note changed
+// nothing. Either the note is gone or it has a newer version.
+func notUpdated(ctx context.Context, db transaction.Querier, noteID uuid.UUID) error {
+	var exists bool
+	err := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM notes WHERE id = $1)`, noteID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return note.ErrNoteNotFound
+	}
+	return note.ErrVersionConflict
 }
 
 func (nR NoteRepo) Delete(noteID uuid.UUID) error {
@@ -57,14 +109,37 @@ func (nR NoteRepo) Delete(noteID uuid.UUID) error {
 	return nil
 }
 
-func (nR NoteRepo) Create(n note.Note) error {
-	insertRow := `INSERT INTO notes (id, title, content, user_id) VALUES ($1, $2, $3, $4)`
-	_, err := nR.db.Exec(
-		insertRow,
+// insertNote stores notes without a format as plain text.
+const insertNote = `
+	INSERT INTO notes (id, title, content, user_id, created_at, updated_at, version, format)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE(NULLIF($8, ''), 'plain'))`
+
+// DeleteByUserID deletes the notes and tags of userID for good, the rows
+// referencing them are deleted by the foreign keys.
+func (nR NoteRepo) DeleteByUserID(ctx context.Context, userID uuid.UUID) (int, error) {
+	deleteRows := `
+	WITH deleted_tags AS (DELETE FROM tags WHERE user_id = $1)
+	DELETE FROM notes WHERE user_id = $1`
+	res, err := nR.conn(ctx).ExecContext(ctx, deleteRows, userID)
+	if err != nil {
+		return 0, fmt.Errorf("deleteByUserID: [%s]: %w", userID, err)
+	}
+	deleted, _ := res.RowsAffected()
+	return int(deleted), nil
+}
+
+func (nR NoteRepo) Create(ctx context.Context, n note.Note) error {
+	_, err := nR.conn(ctx).ExecContext(
+		ctx,
+		insertNote,
 		n.ID,
 		n.Title.String(),
 		n.Content.String(),
 		n.UserID,
+		n.CreatedAt,
+		n.UpdatedAt,
+		n.Version,
+		string(n.Format),
 	)
 	if err != nil {
 		return fmt.Errorf("create: [%s]", n.ID)
@@ -75,11 +150,11 @@ func (nR NoteRepo) Create(n note.Note) error {
 
 func (nR NoteRepo) QueryByID(ctx context.Context, noteID uuid.UUID) (note.Note, error) {
 	queryByIDSqlStmt := `
-	SELECT id, title, content, user_id FROM notes WHERE id=$1;
+	SELECT ` + noteColumns + ` FROM notes WHERE id=$1;
 	`
-	row := nR.db.QueryRowContext(ctx, queryByIDSqlStmt, noteID)
+	row := nR.conn(ctx).QueryRowContext(ctx, queryByIDSqlStmt, noteID)
 	var nDB DBNote
-	err := row.Scan(&nDB.ID, &nDB.Title, &nDB.Content, &nDB.UserID)
+	err := scanNote(row, &nDB)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return note.Note{}, note.ErrNoteNotFound
@@ -90,9 +165,9 @@ func (nR NoteRepo) QueryByID(ctx context.Context, noteID uuid.UUID) (note.Note,
 	return noteDBToNote(nDB), nil
 }
 
-func (nR NoteRepo) GetNotesByUserID(userID uuid.UUID) ([]note.Note, error) {
+func (nR NoteRepo) QueryByUserID(userID uuid.UUID) ([]note.Note, error) {
 	getNotesByUserID := `
-	SELECT id, title, content, user_id FROM notes WHERE user_id=$1;
+	SELECT ` + noteColumns + ` FROM notes WHERE user_id=$1 AND deleted_at IS NULL ORDER BY id;
 	`
 	rows, err := nR.db.Query(getNotesByUserID, userID)
 	if err != nil {
@@ -103,7 +178,7 @@ func (nR NoteRepo) GetNotesByUserID(userID uuid.UUID) ([]note.Note, error) {
 	var notes []DBNote
 	for rows.Next() {
 		var nDB DBNote
-		err := rows.Scan(&nDB.ID, &nDB.Title, &nDB.Content, &nDB.UserID)
+		err := scanNote(rows, &nDB)
 		if err != nil {
 			return nil, fmt.Errorf("getNotesByUserId: [%s]: scan rows: %w", userID, err)
 		}
@@ -122,11 +197,204 @@ func (nR NoteRepo) GetNotesByUserID(userID uuid.UUID) ([]note.Note, error) {
 	return ret, nil
 }
 
+// StreamByUserID scans the notes one row at a time, fn is called while the
+// rows are still open.
+func (nR NoteRepo) StreamByUserID(ctx context.Context, userID uuid.UUID, fn func(note.Note) error) error {
+	streamByUserID := `
+	SELECT ` + noteColumns + ` FROM notes WHERE user_id=$1 AND deleted_at IS NULL ORDER BY id;
+	`
+	rows, err := nR.conn(ctx).QueryContext(ctx, streamByUserID, userID)
+	if err != nil {
+		return fmt.Errorf("streamByUserID: [%s]: %w", userID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var nDB DBNote
+		if err := scanNote(rows, &nDB); err != nil {
+			return fmt.Errorf("streamByUserID: [%s]: scan rows: %w", userID, err)
+		}
+		if err := fn(noteDBToNote(nDB)); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("streamByUserID: [%s]: %w", userID, err)
+	}
+	return nil
+}
+
+func (nR NoteRepo) QueryAll(ctx context.Context) ([]note.Note, error) {
+	queryAll := `
+	SELECT ` + noteColumns + ` FROM notes WHERE deleted_at IS NULL ORDER BY id;
+	`
+	rows, err := nR.conn(ctx).QueryContext(ctx, queryAll)
+	if err != nil {
+		return nil, fmt.Errorf("queryAll: %w", err)
+	}
+	defer rows.Close()
+
+	ret := []note.Note{}
+	for rows.Next() {
+		var nDB DBNote
+		err := scanNote(rows, &nDB)
+		if err != nil {
+			return nil, fmt.Errorf("queryAll: scan rows: %w", err)
+		}
+		ret = append(ret, noteDBToNote(nDB))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("queryAll: %w", err)
+	}
+
+	return ret, nil
+}
+
+// orderByColumns maps the fields of note.OrderBy to columns. Titles are
+// compared byte-wise, independent of the collation of the database.
+var orderByColumns = map[string]string{
+	note.OrderByID:        "id",
+	note.OrderByTitle:     `title COLLATE "C"`,
+	note.OrderByCreatedAt: "created_at",
+	note.OrderByUpdatedAt: "updated_at",
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// Query pages through the notes with keyset pagination: the cursor holds the
+// sort key of the last note of the previous page and the query continues
+// right after it, which stays fast on deep pages.
+func (nR NoteRepo) Query(ctx context.Context, userID uuid.UUID, filter note.QueryFilter, orderBy note.OrderBy, page note.Page) ([]note.Note, error) {
+	column, ok := orderByColumns[orderBy.Field]
+	if !ok {
+		return nil, fmt.Errorf("query: [%s]: %w", orderBy, note.ErrInvalidOrderBy)
+	}
+	dir, cmp := "ASC", ">"
+	if orderBy.Desc {
+		dir, cmp = "DESC", "<"
+	}
+
+	where := []string{"user_id = $1", "deleted_at IS NULL"}
+	args := []any{userID}
+
+	if filter.TitleContains != "" {
+		args = append(args, "%"+likeEscaper.Replace(filter.TitleContains)+"%")
+		where = append(where, fmt.Sprintf("title ILIKE $%d", len(args)))
+	}
+
+	if len(filter.Tags) > 0 {
+		args = append(args, []string(filter.Tags))
+		tagged := fmt.Sprintf(`
+		SELECT nt.note_id FROM note_tags nt JOIN tags t ON t.id = nt.tag_id
+		WHERE t.user_id = $1 AND t.name = ANY($%d)`, len(args))
+		if filter.TagMode == note.TagMatchAny {
+			where = append(where, fmt.Sprintf("id IN (%s)", tagged))
+		} else {
+			args = append(args, len(filter.Tags))
+			where = append(where, fmt.Sprintf("id IN (%s GROUP BY nt.note_id HAVING count(*) = $%d)", tagged, len(args)))
+		}
+	}
+
+	if page.Cursor != "" {
+		c, err := note.DecodeCursor(page.Cursor, orderBy)
+		if err != nil {
+			return nil, fmt.Errorf("query: %w", err)
+		}
+		if orderBy.Field == note.OrderByID {
+			args = append(args, c.ID)
+			where = append(where, fmt.Sprintf("id %s $%d", cmp, len(args)))
+		} else {
+			var value any = c.Value
+			if orderBy.Field != note.OrderByTitle {
+				value, _ = c.Time()
+			}
+			args = append(args, value, c.ID)
+			where = append(where, fmt.Sprintf("(%s, id) %s ($%d, $%d)", column, cmp, len(args)-1, len(args)))
+		}
+	}
+
+	args = append(args, page.Limit)
+	query := fmt.Sprintf(`
+	SELECT `+noteColumns+` FROM notes
+	WHERE %s
+	ORDER BY %s %s, id %s
+	LIMIT $%d;
+	`, strings.Join(where, " AND "), column, dir, dir, len(args))
+
+	rows, err := nR.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query: [%s]: %w", userID, err)
+	}
+	defer rows.Close()
+
+	ret := []note.Note{}
+	for rows.Next() {
+		var nDB DBNote
+		err := scanNote(rows, &nDB)
+		if err != nil {
+			return nil, fmt.Errorf("query: [%s]: scan rows: %w", userID, err)
+		}
+		ret = append(ret, noteDBToNote(nDB))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query: [%s]: %w", userID, err)
+	}
+
+	return ret, nil
+}
+
+func (nR NoteRepo) Search(ctx context.Context, userID uuid.UUID, query string) ([]note.SearchResult, error) {
+	// title words are weighted higher than content words by the generated
+	// search column, see migration 0008
+	search := `
+	SELECT ` + noteColumns + `, ts_rank(search, q) AS rank,
+	       ts_headline('english', concat_ws(' ', title, content), q, 'MaxFragments=2, MinWords=5, MaxWords=20')
+	FROM notes, websearch_to_tsquery('english', $2) q
+	WHERE user_id = $1 AND deleted_at IS NULL AND search @@ q
+	ORDER BY rank DESC, id;
+	`
+	rows, err := nR.conn(ctx).QueryContext(ctx, search, userID, query)
+	if err != nil {
+		return nil, fmt.Errorf("search: [%s]: %w", userID, err)
+	}
+	defer rows.Close()
+
+	ret := []note.SearchResult{}
+	for rows.Next() {
+		var nDB DBNote
+		var res note.SearchResult
+		err := scanNote(rows, &nDB, &res.Rank, &res.Snippet)
+		if err != nil {
+			return nil, fmt.Errorf("search: [%s]: scan rows: %w", userID, err)
+		}
+		res.Note = noteDBToNote(nDB)
+		ret = append(ret, res)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("search: [%s]: %w", userID, err)
+	}
+
+	return ret, nil
+}
+
 func noteDBToNote(nDB DBNote) note.Note {
-	return note.Note{
-		ID:      nDB.ID,
-		Title:   note.NewTitle(nDB.Title),
-		Content: note.NewContent(nDB.Content),
-		UserID:  nDB.UserID,
+	n := note.Note{
+		ID:        nDB.ID,
+		Title:     note.NewTitle(nDB.Title),
+		Content:   note.NewContent(nDB.Content),
+		Format:    note.Format(nDB.Format),
+		UserID:    nDB.UserID,
+		CreatedAt: nDB.CreatedAt.UTC(),
+		UpdatedAt: nDB.UpdatedAt.UTC(),
+		Version:   nDB.Version,
+	}
+	// notes without tags have no tags rather than an empty set
+	if len(nDB.Tags) > 0 {
+		n.Tags = note.Tags(nDB.Tags)
+	}
+	if nDB.DeletedAt.Valid {
+		deletedAt := nDB.DeletedAt.Time.UTC()
+		n.DeletedAt = &deletedAt
 	}
+	return n
 }
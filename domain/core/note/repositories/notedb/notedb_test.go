@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/Keisn1/note-taking-app/domain/core/note"
 	"github.com/Keisn1/note-taking-app/domain/core/note/repositories/notedb"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
 	"github.com/google/uuid"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/stretchr/testify/assert"
@@ -26,29 +28,39 @@ func TestNotesRepo_Update(t *testing.T) {
 
 	t.Run("Given an error received by the DB, the error is forwarded", func(t *testing.T) {
 		nR := notedb.NewNotesRepo(&stubSQLDB{})
-		n := note.Note{ID: uuid.New(), Title: note.NewTitle(""), Content: note.NewContent(""), UserID: uuid.New()}
-		err := nR.Update(n)
+		n := note.Note{ID: uuid.New(), Title: note.NewTitle(""), Content: note.NewContent(""), Format: note.FormatPlain, UserID: uuid.New()}
+		err := nR.Update(context.Background(), n)
 		assert.ErrorContains(t, err, fmt.Sprintf("update: [%v]: DBError", n))
 	})
 
 	t.Run("Given a note NOT present in the system, return ErrNoteNotFound", func(t *testing.T) {
 		nR := notedb.NewNotesRepo(testDB)
-		n := note.Note{ID: uuid.New(), Title: note.NewTitle(""), Content: note.NewContent(""), UserID: uuid.New()}
-		err := nR.Update(n)
+		n := note.Note{ID: uuid.New(), Title: note.NewTitle(""), Content: note.NewContent(""), Format: note.FormatPlain, UserID: uuid.New()}
+		err := nR.Update(context.Background(), n)
 		assert.ErrorContains(t, err, note.ErrNoteNotFound.Error())
 	})
 
 	t.Run("Given a note present in the system, I can update its title and its content", func(t *testing.T) {
 		nR := notedb.NewNotesRepo(testDB)
-		n := note.Note{ID: uuid.UUID{1}, Title: note.NewTitle("new title"), Content: note.NewContent("new content"), UserID: uuid.UUID{1}}
+		n := note.Note{ID: uuid.UUID{1}, Title: note.NewTitle("new title"), Content: note.NewContent("new content"), Format: note.FormatPlain, UserID: uuid.UUID{1}}
 
-		err := nR.Update(n)
+		err := nR.Update(context.Background(), n)
 		assert.NoError(t, err)
 
 		got, err := nR.QueryByID(context.Background(), n.ID)
 		assert.NoError(t, err)
+		n.Version++
 		assert.Equal(t, n, got)
 	})
+
+	t.Run("Given a note that has been changed in the meantime, return ErrVersionConflict", func(t *testing.T) {
+		nR := notedb.NewNotesRepo(testDB)
+		stale := note.Note{ID: uuid.UUID{2}, Title: note.NewTitle("title"), Content: note.NewContent("content"), Format: note.FormatPlain, UserID: uuid.UUID{1}}
+
+		assert.NoError(t, nR.Update(context.Background(), stale))
+		err := nR.Update(context.Background(), stale)
+		assert.ErrorIs(t, err, note.ErrVersionConflict)
+	})
 }
 
 func TestNotesRepo_Delete(t *testing.T) {
@@ -60,9 +72,9 @@ func TestNotesRepo_Delete(t *testing.T) {
 		nR := notedb.NewNotesRepo(testDB)
 		ctx := context.Background()
 		noteID := uuid.New()
-		n := note.Note{ID: noteID, Title: note.NewTitle("new title"), Content: note.NewContent("new content"), UserID: uuid.UUID{1}}
+		n := note.Note{ID: noteID, Title: note.NewTitle("new title"), Content: note.NewContent("new content"), Format: note.FormatPlain, UserID: uuid.UUID{1}}
 
-		nR.Create(n)
+		nR.Create(ctx, n)
 		got, err := nR.QueryByID(ctx, noteID)
 		assert.NoError(t, err)
 		assert.Equal(t, n, got)
@@ -92,9 +104,9 @@ func TestNotesRepo_Create(t *testing.T) {
 		nR := notedb.NewNotesRepo(testDB)
 
 		ctx := context.Background()
-		n := note.Note{ID: uuid.UUID{1}, Title: note.NewTitle("new title"), Content: note.NewContent("new content"), UserID: uuid.UUID{1}}
+		n := note.Note{ID: uuid.UUID{1}, Title: note.NewTitle("new title"), Content: note.NewContent("new content"), Format: note.FormatPlain, UserID: uuid.UUID{1}}
 
-		err := nR.Create(n)
+		err := nR.Create(ctx, n)
 		assert.NoError(t, err)
 
 		got, err := nR.QueryByID(ctx, n.ID)
@@ -108,13 +120,47 @@ func TestNotesRepo_Create(t *testing.T) {
 		defer deleteTable()
 		nR := notedb.NewNotesRepo(testDB)
 
-		n := note.Note{ID: uuid.UUID{1}, Title: note.NewTitle("new title"), Content: note.NewContent("new content"), UserID: uuid.UUID{1}}
-		err := nR.Create(n)
+		n := note.Note{ID: uuid.UUID{1}, Title: note.NewTitle("new title"), Content: note.NewContent("new content"), Format: note.FormatPlain, UserID: uuid.UUID{1}}
+		err := nR.Create(context.Background(), n)
 		assert.Error(t, err)
 		assert.ErrorContains(t, err, fmt.Sprintf("create: [%s]", n.ID))
 	})
 }
 
+func TestNotesRepo_DeleteByUserID(t *testing.T) {
+	testDB, deleteTable := SetupNotesTable(t, fixtureNotes())
+	defer testDB.Close()
+	defer deleteTable()
+	nR := notedb.NewNotesRepo(testDB)
+	ctx := context.Background()
+
+	t.Run("Nothing is deleted if the transaction is rolled back", func(t *testing.T) {
+		err := transaction.NewSQLManager(testDB).Run(ctx, func(ctx context.Context) error {
+			count, err := nR.DeleteByUserID(ctx, uuid.UUID{1})
+			assert.NoError(t, err)
+			assert.Equal(t, 2, count)
+			return errors.New("rollback")
+		})
+		assert.EqualError(t, err, "rollback")
+
+		notes, err := nR.QueryByUserID(uuid.UUID{1})
+		assert.NoError(t, err)
+		assert.Len(t, notes, 2)
+	})
+
+	t.Run("Deletes the notes of the user", func(t *testing.T) {
+		count, err := nR.DeleteByUserID(ctx, uuid.UUID{1})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		_, err = nR.QueryByUserID(uuid.UUID{1})
+		assert.ErrorContains(t, err, "not found")
+		notes, err := nR.QueryByUserID(uuid.UUID{2})
+		assert.NoError(t, err)
+		assert.Len(t, notes, 2)
+	})
+}
+
 func TestNotesRepo_QueryByID(t *testing.T) {
 	testDB, deleteTable := SetupNotesTable(t, fixtureNotes())
 	defer testDB.Close()
@@ -132,13 +178,13 @@ func TestNotesRepo_QueryByID(t *testing.T) {
 			{
 				noteID: uuid.UUID{1},
 				want: note.Note{
-					ID: uuid.UUID{1}, Title: note.NewTitle("robs 1st note"), Content: note.NewContent("robs 1st note content"), UserID: uuid.UUID{1},
+					ID: uuid.UUID{1}, Title: note.NewTitle("robs 1st note"), Content: note.NewContent("robs 1st note content"), Format: note.FormatPlain, UserID: uuid.UUID{1},
 				},
 			},
 			{
 				noteID: uuid.UUID{3},
 				want: note.Note{
-					ID: uuid.UUID{3}, Title: note.NewTitle("annas 1st note"), Content: note.NewContent("annas 1st note content"), UserID: uuid.UUID{2},
+					ID: uuid.UUID{3}, Title: note.NewTitle("annas 1st note"), Content: note.NewContent("annas 1st note content"), Format: note.FormatPlain, UserID: uuid.UUID{2},
 				},
 			},
 		}
@@ -176,21 +222,21 @@ func TestNotesRepo_GetNotesByUserID(t *testing.T) {
 			{
 				userID: uuid.UUID{1},
 				want: []note.Note{
-					{ID: uuid.UUID{1}, Title: note.NewTitle("robs 1st note"), Content: note.NewContent("robs 1st note content"), UserID: uuid.UUID{1}},
-					{ID: uuid.UUID{2}, Title: note.NewTitle("robs 2nd note"), Content: note.NewContent("robs 2nd note content"), UserID: uuid.UUID{1}},
+					{ID: uuid.UUID{1}, Title: note.NewTitle("robs 1st note"), Content: note.NewContent("robs 1st note content"), Format: note.FormatPlain, UserID: uuid.UUID{1}},
+					{ID: uuid.UUID{2}, Title: note.NewTitle("robs 2nd note"), Content: note.NewContent("robs 2nd note content"), Format: note.FormatPlain, UserID: uuid.UUID{1}},
 				},
 			},
 			{
 				userID: uuid.UUID{2},
 				want: []note.Note{
-					{ID: uuid.UUID{3}, Title: note.NewTitle("annas 1st note"), Content: note.NewContent("annas 1st note content"), UserID: uuid.UUID{2}},
-					{ID: uuid.UUID{4}, Title: note.NewTitle("annas 2nd note"), Content: note.NewContent("annas 2nd note content"), UserID: uuid.UUID{2}},
+					{ID: uuid.UUID{3}, Title: note.NewTitle("annas 1st note"), Content: note.NewContent("annas 1st note content"), Format: note.FormatPlain, UserID: uuid.UUID{2}},
+					{ID: uuid.UUID{4}, Title: note.NewTitle("annas 2nd note"), Content: note.NewContent("annas 2nd note content"), Format: note.FormatPlain, UserID: uuid.UUID{2}},
 				},
 			},
 		}
 
 		for _, tc := range testCases {
-			got, err := nR.GetNotesByUserID(tc.userID)
+			got, err := nR.QueryByUserID(tc.userID)
 			assert.NoError(t, err)
 			assert.ElementsMatch(t, tc.want, got)
 		}
@@ -200,7 +246,7 @@ func TestNotesRepo_GetNotesByUserID(t *testing.T) {
 
 		userID := uuid.UUID{}
 		wantErrMsg := fmt.Sprintf("getNotesByUserID: not found [%s]", userID)
-		_, err := nR.GetNotesByUserID(userID)
+		_, err := nR.QueryByUserID(userID)
 		assert.ErrorContains(t, err, wantErrMsg)
 	})
 
@@ -210,8 +256,219 @@ func TestNotesRepo_GetNotesByUserID(t *testing.T) {
 
 		userID := uuid.UUID{}
 		wantErr := fmt.Errorf("getNotesByUserID: [%s]: %w", userID, errors.New("DBError"))
-		_, err := nR.GetNotesByUserID(userID)
+		_, err := nR.QueryByUserID(userID)
 		assert.EqualError(t, err, wantErr.Error())
 	})
 
 }
+
+func TestNotesRepo_StreamByUserID(t *testing.T) {
+	testDB, deleteTable := SetupNotesTable(t, fixtureNotes())
+	defer testDB.Close()
+	defer deleteTable()
+	ctx := context.Background()
+
+	t.Run("Streams the notes of a user ordered by id", func(t *testing.T) {
+		nR := notedb.NewNotesRepo(testDB)
+
+		var got []uuid.UUID
+		err := nR.StreamByUserID(ctx, uuid.UUID{1}, func(n note.Note) error {
+			got = append(got, n.ID)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{{1}, {2}}, got)
+	})
+
+	t.Run("Stops at the first error of fn", func(t *testing.T) {
+		nR := notedb.NewNotesRepo(testDB)
+		wantErr := errors.New("fn error")
+
+		calls := 0
+		err := nR.StreamByUserID(ctx, uuid.UUID{1}, func(n note.Note) error {
+			calls++
+			return wantErr
+		})
+		assert.ErrorIs(t, err, wantErr)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Fowards error on database error", func(t *testing.T) {
+		nR := notedb.NewNotesRepo(&stubSQLDB{})
+
+		userID := uuid.UUID{}
+		wantErr := fmt.Errorf("streamByUserID: [%s]: %w", userID, errors.New("DBError"))
+		err := nR.StreamByUserID(ctx, userID, func(n note.Note) error { return nil })
+		assert.EqualError(t, err, wantErr.Error())
+	})
+}
+
+func TestNotesRepo_QueryAll(t *testing.T) {
+	fixtureNotes := fixtureNotes()
+	testDB, deleteTable := SetupNotesTable(t, fixtureNotes)
+	defer deleteTable()
+	nR := notedb.NewNotesRepo(testDB)
+
+	t.Run("Get all notes of all users", func(t *testing.T) {
+		got, err := nR.QueryAll(context.Background())
+		assert.NoError(t, err)
+
+		var want []note.Note
+		for _, nDB := range fixtureNotes {
+			want = append(want, note.Note{ID: nDB.ID, Title: note.NewTitle(nDB.Title), Content: note.NewContent(nDB.Content), Format: note.FormatPlain, UserID: nDB.UserID})
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("Forwards error on database error", func(t *testing.T) {
+		nR := notedb.NewNotesRepo(&stubSQLDB{})
+		_, err := nR.QueryAll(context.Background())
+		assert.EqualError(t, err, "queryAll: DBError")
+	})
+}
+
+func TestNotesRepo_Search(t *testing.T) {
+	testDB, deleteTable := SetupNotesTable(t, fixtureNotes())
+	defer deleteTable()
+	nR := notedb.NewNotesRepo(testDB)
+	ctx := context.Background()
+
+	t.Run("Finds the notes of the user with ranking and highlighted snippets", func(t *testing.T) {
+		got, err := nR.Search(ctx, uuid.UUID{1}, "2nd")
+		assert.NoError(t, err)
+		assert.Len(t, got, 1)
+		assert.Equal(t, uuid.UUID{2}, got[0].Note.ID)
+		assert.Greater(t, got[0].Rank, 0.0)
+		assert.Contains(t, got[0].Snippet, "<b>2nd</b>")
+	})
+
+	t.Run("Notes of other users are not found", func(t *testing.T) {
+		got, err := nR.Search(ctx, uuid.UUID{1}, "annas")
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("Forwards error on database error", func(t *testing.T) {
+		nR := notedb.NewNotesRepo(&stubSQLDB{})
+		_, err := nR.Search(ctx, uuid.UUID{1}, "note")
+		assert.EqualError(t, err, fmt.Sprintf("search: [%s]: DBError", uuid.UUID{1}))
+	})
+}
+
+func TestNotesRepo_Query(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	notes := fixtureNotes()
+	for i := range notes {
+		notes[i].CreatedAt = base.Add(time.Duration(i) * time.Hour)
+		notes[i].UpdatedAt = base.Add(time.Duration(i) * time.Hour)
+	}
+	testDB, deleteTable := SetupNotesTable(t, notes)
+	defer deleteTable()
+	nR := notedb.NewNotesRepo(testDB)
+	ctx := context.Background()
+
+	ids := func(notes []note.Note) []uuid.UUID {
+		var ret []uuid.UUID
+		for _, n := range notes {
+			ret = append(ret, n.ID)
+		}
+		return ret
+	}
+
+	t.Run("Orders and limits the notes of the user", func(t *testing.T) {
+		orderBy := note.OrderBy{Field: note.OrderByUpdatedAt, Desc: true}
+		got, err := nR.Query(ctx, uuid.UUID{1}, note.QueryFilter{}, orderBy, note.Page{Limit: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{{2}}, ids(got))
+		assert.Equal(t, base.Add(time.Hour), got[0].UpdatedAt)
+
+		cursor := note.NewCursor(orderBy, got[0]).Encode()
+		got, err = nR.Query(ctx, uuid.UUID{1}, note.QueryFilter{}, orderBy, note.Page{Limit: 2, Cursor: cursor})
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{{1}}, ids(got))
+	})
+
+	t.Run("Filters by title", func(t *testing.T) {
+		got, err := nR.Query(ctx, uuid.UUID{2}, note.QueryFilter{TitleContains: "2ND"}, note.OrderBy{Field: note.OrderByTitle}, note.Page{Limit: 10})
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{{4}}, ids(got))
+	})
+
+	t.Run("Forwards error on database error", func(t *testing.T) {
+		nR := notedb.NewNotesRepo(&stubSQLDB{})
+		_, err := nR.Query(ctx, uuid.UUID{1}, note.QueryFilter{}, note.DefaultOrderBy, note.Page{Limit: 10})
+		assert.EqualError(t, err, fmt.Sprintf("query: [%s]: DBError", uuid.UUID{1}))
+	})
+}
+
+func TestNotesRepo_Trash(t *testing.T) {
+	testDB, deleteTable := SetupNotesTable(t, fixtureNotes())
+	defer deleteTable()
+	nR := notedb.NewNotesRepo(testDB)
+	ctx := context.Background()
+	deletedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Trashed notes are only found by id and in the trash", func(t *testing.T) {
+		err := nR.Trash(ctx, uuid.UUID{1}, 0, deletedAt)
+		assert.NoError(t, err)
+
+		got, err := nR.QueryByID(ctx, uuid.UUID{1})
+		assert.NoError(t, err)
+		assert.Equal(t, &deletedAt, got.DeletedAt)
+
+		notes, err := nR.QueryByUserID(uuid.UUID{1})
+		assert.NoError(t, err)
+		assert.Len(t, notes, 1)
+
+		trash, err := nR.QueryTrash(ctx, uuid.UUID{1})
+		assert.NoError(t, err)
+		assert.Len(t, trash, 1)
+		assert.Equal(t, uuid.UUID{1}, trash[0].ID)
+
+		err = nR.Trash(ctx, uuid.UUID{1}, 0, deletedAt)
+		assert.ErrorIs(t, err, note.ErrNoteNotFound)
+	})
+
+	t.Run("Restored notes are back", func(t *testing.T) {
+		err := nR.Restore(ctx, uuid.UUID{1})
+		assert.NoError(t, err)
+
+		got, err := nR.QueryByID(ctx, uuid.UUID{1})
+		assert.NoError(t, err)
+		assert.Nil(t, got.DeletedAt)
+
+		err = nR.Restore(ctx, uuid.UUID{1})
+		assert.ErrorIs(t, err, note.ErrNoteNotFound)
+	})
+
+	t.Run("Purges notes trashed before the given time", func(t *testing.T) {
+		assert.NoError(t, nR.Trash(ctx, uuid.UUID{2}, 0, deletedAt))
+		assert.NoError(t, nR.Trash(ctx, uuid.UUID{3}, 0, deletedAt.Add(time.Hour)))
+
+		purged, err := nR.Purge(ctx, deletedAt.Add(time.Minute))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, purged)
+
+		_, err = nR.QueryByID(ctx, uuid.UUID{2})
+		assert.ErrorIs(t, err, note.ErrNoteNotFound)
+		_, err = nR.QueryByID(ctx, uuid.UUID{3})
+		assert.NoError(t, err)
+	})
+
+	t.Run("A note changed since it was read is not trashed", func(t *testing.T) {
+		err := nR.Trash(ctx, uuid.UUID{4}, 7, deletedAt)
+		assert.ErrorIs(t, err, note.ErrVersionConflict)
+
+		got, err := nR.QueryByID(ctx, uuid.UUID{4})
+		assert.NoError(t, err)
+		assert.Nil(t, got.DeletedAt)
+
+		assert.NoError(t, nR.Trash(ctx, uuid.UUID{4}, got.Version, deletedAt))
+	})
+
+	t.Run("Forwards error on database error", func(t *testing.T) {
+		nR := notedb.NewNotesRepo(&stubSQLDB{})
+		err := nR.Trash(ctx, uuid.UUID{1}, 0, deletedAt)
+		assert.EqualError(t, err, fmt.Sprintf("trash: [%s]: DBError", uuid.UUID{1}))
+	})
+}
@@ -0,0 +1,107 @@
+package notedb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/google/uuid"
+)
+
+type DBRevision struct {
+	NoteID    uuid.UUID
+	Number    int
+	AuthorID  uuid.UUID
+	CreatedAt time.Time
+	Title     string
+	Content   string
+}
+
+type RevisionRepo struct {
+	db shareDatabase
+}
+
+func NewRevisionRepo(db shareDatabase) RevisionRepo {
+	return RevisionRepo{db: db}
+}
+
+// conn returns the transaction carried by ctx like NoteRepo.conn, revisions
+// are recorded in the unit of work changing the note.
+func (rR RevisionRepo) conn(ctx context.Context) transaction.Querier {
+	return transaction.DB(ctx, rR.db)
+}
+
+// Append numbers the revision in the same statement it inserts it. Two
+// concurrent appends to the same note may pick the same number, the primary
+// key then rejects the second one.
+func (rR RevisionRepo) Append(ctx context.Context, r note.Revision) (note.Revision, error) {
+	appendRow := `
+	INSERT INTO note_revisions (note_id, number, author_id, created_at, title, content)
+	SELECT $1, COALESCE(MAX(number), 0) + 1, $2, $3, $4, $5
+	FROM note_revisions WHERE note_id = $1
+	RETURNING number`
+
+	row := rR.conn(ctx).QueryRowContext(ctx, appendRow, r.NoteID, r.AuthorID, r.CreatedAt, r.Title, r.Content)
+	if err := row.Scan(&r.Number); err != nil {
+		return note.Revision{}, fmt.Errorf("append: [%s]: %w", r.NoteID, err)
+	}
+	return r, nil
+}
+
+func (rR RevisionRepo) QueryByNoteID(ctx context.Context, noteID uuid.UUID) ([]note.Revision, error) {
+	queryByNoteID := `
+	SELECT note_id, number, author_id, created_at, title, content FROM note_revisions
+	WHERE note_id = $1 ORDER BY number`
+
+	rows, err := rR.conn(ctx).QueryContext(ctx, queryByNoteID, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("queryByNoteID: [%s]: %w", noteID, err)
+	}
+	defer rows.Close()
+
+	ret := []note.Revision{}
+	for rows.Next() {
+		var dbR DBRevision
+		err := rows.Scan(&dbR.NoteID, &dbR.Number, &dbR.AuthorID, &dbR.CreatedAt, &dbR.Title, &dbR.Content)
+		if err != nil {
+			return nil, fmt.Errorf("queryByNoteID: [%s]: scan rows: %w", noteID, err)
+		}
+		ret = append(ret, revisionDBToRevision(dbR))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("queryByNoteID: [%s]: %w", noteID, err)
+	}
+	return ret, nil
+}
+
+func (rR RevisionRepo) QueryByNumber(ctx context.Context, noteID uuid.UUID, number int) (note.Revision, error) {
+	queryByNumber := `
+	SELECT note_id, number, author_id, created_at, title, content FROM note_revisions
+	WHERE note_id = $1 AND number = $2`
+
+	var dbR DBRevision
+	row := rR.conn(ctx).QueryRowContext(ctx, queryByNumber, noteID, number)
+	err := row.Scan(&dbR.NoteID, &dbR.Number, &dbR.AuthorID, &dbR.CreatedAt, &dbR.Title, &dbR.Content)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return note.Revision{}, fmt.Errorf("queryByNumber: [%s]: [%d]: %w", noteID, number, note.ErrRevisionNotFound)
+		}
+		return note.Revision{}, fmt.Errorf("queryByNumber: [%s]: [%d]: %w", noteID, number, err)
+	}
+	return revisionDBToRevision(dbR), nil
+}
+
+func revisionDBToRevision(dbR DBRevision) note.Revision {
+	return note.Revision{
+		NoteID:    dbR.NoteID,
+		Number:    dbR.Number,
+		AuthorID:  dbR.AuthorID,
+		CreatedAt: dbR.CreatedAt.UTC(),
+		Title:     dbR.Title,
+		Content:   dbR.Content,
+	}
+}
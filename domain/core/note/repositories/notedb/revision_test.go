@@ -0,0 +1,49 @@
+package notedb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/core/note/repositories/notedb"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevisionRepo(t *testing.T) {
+	testDB, deleteTable := SetupNotesTable(t, fixtureNotes())
+	defer testDB.Close()
+	defer deleteTable()
+
+	ctx := context.Background()
+	rR := notedb.NewRevisionRepo(testDB)
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Revisions are numbered per note", func(t *testing.T) {
+		first, err := rR.Append(ctx, note.Revision{NoteID: uuid.UUID{1}, AuthorID: uuid.UUID{1}, CreatedAt: createdAt, Title: "title", Content: "content"})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, first.Number)
+
+		second, err := rR.Append(ctx, note.Revision{NoteID: uuid.UUID{1}, AuthorID: uuid.UUID{2}, CreatedAt: createdAt.Add(time.Hour), Title: "title", Content: "new content"})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, second.Number)
+
+		other, err := rR.Append(ctx, note.Revision{NoteID: uuid.UUID{2}, AuthorID: uuid.UUID{1}, CreatedAt: createdAt, Title: "title", Content: "content"})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, other.Number)
+
+		got, err := rR.QueryByNoteID(ctx, uuid.UUID{1})
+		assert.NoError(t, err)
+		assert.Equal(t, []note.Revision{first, second}, got)
+
+		rev, err := rR.QueryByNumber(ctx, uuid.UUID{1}, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, second, rev)
+	})
+
+	t.Run("Querying a missing revision returns ErrRevisionNotFound", func(t *testing.T) {
+		_, err := rR.QueryByNumber(ctx, uuid.UUID{1}, 3)
+		assert.ErrorIs(t, err, note.ErrRevisionNotFound)
+	})
+}
@@ -1,11 +1,13 @@
 package notedb_test
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"testing"
 
 	"github.com/Keisn1/note-taking-app/domain/core/note/repositories/notedb"
+	"github.com/Keisn1/note-taking-app/domain/data/schema"
 	"github.com/google/uuid"
 )
 
@@ -49,27 +51,23 @@ func run(m *testing.M) int {
 }
 
 func SetupNotesTable(t *testing.T, notes []notedb.DBNote) (*sql.DB, func()) {
-	var (
-		createNoteTable = `CREATE TABLE notes(
-							id UUID PRIMARY KEY,
-							title TEXT,
-							content TEXT,
-							user_id UUID NOT NULL)`
-		dropNotesTable = `DROP TABLE notes`
-	)
-
 	dsn := fmt.Sprintf("host=localhost port=5432 user=%s password=%s sslmode=disable dbname=%s ", testUser, testPassword, testDBName)
 	testDB, err := sql.Open("pgx", dsn)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	_, err = testDB.Exec(createNoteTable)
+	migrator, err := schema.NewMigrator(testDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = migrator.Up(context.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	insertRow := `INSERT INTO notes (id, title, content, user_id) VALUES ($1, $2, $3, $4)`
+	insertRow := `INSERT INTO notes (id, title, content, user_id, created_at, updated_at, version) VALUES ($1, $2, $3, $4, $5, $6, $7)`
 	for _, n := range notes {
 		_, err = testDB.Exec(
 			insertRow,
@@ -77,6 +75,9 @@ func SetupNotesTable(t *testing.T, notes []notedb.DBNote) (*sql.DB, func()) {
 			n.Title,
 			n.Content,
 			n.UserID,
+			n.CreatedAt,
+			n.UpdatedAt,
+			n.Version,
 		)
 		if err != nil {
 			t.Fatal(err)
@@ -84,7 +85,7 @@ func SetupNotesTable(t *testing.T, notes []notedb.DBNote) (*sql.DB, func()) {
 	}
 
 	deleteTable := func() {
-		_, err := testDB.Exec(dropNotesTable)
+		err := migrator.Reset(context.Background())
 		if err != nil {
 			t.Fatal(err)
 		}
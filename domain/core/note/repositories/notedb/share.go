@@ -0,0 +1,138 @@
+package notedb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/google/uuid"
+)
+
+type DBShare struct {
+	NoteID     uuid.UUID
+	UserID     uuid.UUID
+	Permission string
+}
+
+type shareDatabase interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+type ShareRepo struct {
+	db shareDatabase
+}
+
+func NewShareRepo(db shareDatabase) ShareRepo {
+	return ShareRepo{db: db}
+}
+
+// conn returns the transaction carried by ctx, see transaction.Manager.
+func (sR ShareRepo) conn(ctx context.Context) transaction.Querier {
+	return transaction.DB(ctx, sR.db)
+}
+
+func (sR ShareRepo) Upsert(ctx context.Context, s note.Share) error {
+	upsertRow := `
+	INSERT INTO note_shares (note_id, user_id, permission) VALUES ($1, $2, $3)
+	ON CONFLICT (note_id, user_id) DO UPDATE SET permission = EXCLUDED.permission`
+
+	_, err := sR.db.ExecContext(ctx, upsertRow, s.NoteID, s.UserID, s.Permission.String())
+	if err != nil {
+		return fmt.Errorf("upsert: [%s]: %w", s.NoteID, err)
+	}
+	return nil
+}
+
+func (sR ShareRepo) Delete(ctx context.Context, noteID, userID uuid.UUID) error {
+	deleteRow := `DELETE FROM note_shares WHERE note_id = $1 AND user_id = $2`
+
+	res, err := sR.db.ExecContext(ctx, deleteRow, noteID, userID)
+	if err != nil {
+		return fmt.Errorf("delete: [%s]: %w", noteID, err)
+	}
+	if c, _ := res.RowsAffected(); c == 0 {
+		return fmt.Errorf("delete: [%s]: %w", noteID, note.ErrShareNotFound)
+	}
+	return nil
+}
+
+// DeleteByUserID runs in the transaction carried by ctx.
+func (sR ShareRepo) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	deleteRows := `DELETE FROM note_shares WHERE user_id = $1`
+
+	if _, err := sR.conn(ctx).ExecContext(ctx, deleteRows, userID); err != nil {
+		return fmt.Errorf("deleteByUserID: [%s]: %w", userID, err)
+	}
+	return nil
+}
+
+func (sR ShareRepo) QueryPermission(ctx context.Context, noteID, userID uuid.UUID) (note.Permission, error) {
+	queryPermission := `SELECT permission FROM note_shares WHERE note_id = $1 AND user_id = $2`
+
+	var perm string
+	err := sR.db.QueryRowContext(ctx, queryPermission, noteID, userID).Scan(&perm)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return note.PermissionNone, fmt.Errorf("queryPermission: [%s]: %w", noteID, note.ErrShareNotFound)
+		}
+		return note.PermissionNone, fmt.Errorf("queryPermission: [%s]: %w", noteID, err)
+	}
+
+	p, err := note.ParsePermission(perm)
+	if err != nil {
+		return note.PermissionNone, fmt.Errorf("queryPermission: [%s]: %w", noteID, err)
+	}
+	return p, nil
+}
+
+func (sR ShareRepo) QueryByNoteID(ctx context.Context, noteID uuid.UUID) ([]note.Share, error) {
+	queryByNoteID := `
+	SELECT note_id, user_id, permission FROM note_shares WHERE note_id = $1 ORDER BY user_id`
+
+	shares, err := sR.query(ctx, queryByNoteID, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("queryByNoteID: [%s]: %w", noteID, err)
+	}
+	return shares, nil
+}
+
+func (sR ShareRepo) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]note.Share, error) {
+	queryByUserID := `
+	SELECT note_id, user_id, permission FROM note_shares WHERE user_id = $1 ORDER BY note_id`
+
+	shares, err := sR.query(ctx, queryByUserID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("queryByUserID: [%s]: %w", userID, err)
+	}
+	return shares, nil
+}
+
+func (sR ShareRepo) query(ctx context.Context, query string, args ...any) ([]note.Share, error) {
+	rows, err := sR.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ret := []note.Share{}
+	for rows.Next() {
+		var sDB DBShare
+		if err := rows.Scan(&sDB.NoteID, &sDB.UserID, &sDB.Permission); err != nil {
+			return nil, fmt.Errorf("scan rows: %w", err)
+		}
+		p, err := note.ParsePermission(sDB.Permission)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, note.Share{NoteID: sDB.NoteID, UserID: sDB.UserID, Permission: p})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
@@ -0,0 +1,76 @@
+package notedb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/core/note/repositories/notedb"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShareRepo(t *testing.T) {
+	testDB, deleteTable := SetupNotesTable(t, fixtureNotes())
+	defer testDB.Close()
+	defer deleteTable()
+
+	ctx := context.Background()
+	sR := notedb.NewShareRepo(testDB)
+
+	t.Run("Querying a missing share returns ErrShareNotFound", func(t *testing.T) {
+		_, err := sR.QueryPermission(ctx, uuid.UUID{1}, uuid.UUID{2})
+		assert.ErrorIs(t, err, note.ErrShareNotFound)
+
+		err = sR.Delete(ctx, uuid.UUID{1}, uuid.UUID{2})
+		assert.ErrorIs(t, err, note.ErrShareNotFound)
+	})
+
+	t.Run("I can share, re-share and unshare a note", func(t *testing.T) {
+		s := note.Share{NoteID: uuid.UUID{1}, UserID: uuid.UUID{2}, Permission: note.PermissionRead}
+		assert.NoError(t, sR.Upsert(ctx, s))
+
+		perm, err := sR.QueryPermission(ctx, s.NoteID, s.UserID)
+		assert.NoError(t, err)
+		assert.Equal(t, note.PermissionRead, perm)
+
+		s.Permission = note.PermissionWrite
+		assert.NoError(t, sR.Upsert(ctx, s))
+
+		byNote, err := sR.QueryByNoteID(ctx, s.NoteID)
+		assert.NoError(t, err)
+		assert.Equal(t, []note.Share{s}, byNote)
+
+		byUser, err := sR.QueryByUserID(ctx, s.UserID)
+		assert.NoError(t, err)
+		assert.Equal(t, []note.Share{s}, byUser)
+
+		assert.NoError(t, sR.Delete(ctx, s.NoteID, s.UserID))
+		_, err = sR.QueryPermission(ctx, s.NoteID, s.UserID)
+		assert.ErrorIs(t, err, note.ErrShareNotFound)
+	})
+
+	t.Run("Deleting a note removes its shares", func(t *testing.T) {
+		s := note.Share{NoteID: uuid.UUID{3}, UserID: uuid.UUID{1}, Permission: note.PermissionRead}
+		assert.NoError(t, sR.Upsert(ctx, s))
+
+		assert.NoError(t, notedb.NewNotesRepo(testDB).Delete(s.NoteID))
+
+		shares, err := sR.QueryByUserID(ctx, s.UserID)
+		assert.NoError(t, err)
+		assert.Empty(t, shares)
+	})
+
+	t.Run("DeleteByUserID removes the shares granted to the user", func(t *testing.T) {
+		granted := note.Share{NoteID: uuid.UUID{1}, UserID: uuid.UUID{5}, Permission: note.PermissionRead}
+		other := note.Share{NoteID: uuid.UUID{1}, UserID: uuid.UUID{6}, Permission: note.PermissionRead}
+		assert.NoError(t, sR.Upsert(ctx, granted))
+		assert.NoError(t, sR.Upsert(ctx, other))
+
+		assert.NoError(t, sR.DeleteByUserID(ctx, granted.UserID))
+
+		shares, err := sR.QueryByNoteID(ctx, granted.NoteID)
+		assert.NoError(t, err)
+		assert.Equal(t, []note.Share{other}, shares)
+	})
+}
@@ -12,6 +12,10 @@ func (s *stubSQLDB) Query(query string, args ...interface{}) (*sql.Rows, error)
 	return nil, errors.New("DBError")
 }
 
+func (s *stubSQLDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return nil, errors.New("DBError")
+}
+
 func (s *stubSQLDB) QueryRowContext(ctx context.Context, query string, args ...any) (row *sql.Row) {
 	return
 }
@@ -19,3 +23,11 @@ func (s *stubSQLDB) QueryRowContext(ctx context.Context, query string, args ...a
 func (s *stubSQLDB) Exec(query string, args ...any) (res sql.Result, err error) {
 	return nil, errors.New("DBError")
 }
+
+func (s *stubSQLDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return nil, errors.New("DBError")
+}
+
+func (s *stubSQLDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return nil, errors.New("DBError")
+}
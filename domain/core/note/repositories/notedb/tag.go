@@ -0,0 +1,116 @@
+package notedb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/google/uuid"
+)
+
+// SetTags creates the tags of n that its owner doesn't use yet and links
+// exactly those to n, together with incrementing its version.
+func (nR NoteRepo) SetTags(ctx context.Context, n note.Note) error {
+	err := transaction.NewSQLManager(nR.db).Run(ctx, func(ctx context.Context) error {
+		db := nR.conn(ctx)
+		res, err := db.ExecContext(ctx, `UPDATE notes SET version = version + 1 WHERE id = $1 AND version = $2`, n.ID, n.Version)
+		if err != nil {
+			return err
+		}
+		if c, _ := res.RowsAffected(); c == 0 {
+			return notUpdated(ctx, db, n.ID)
+		}
+		_, err = db.ExecContext(ctx, setTags, n.ID, n.UserID, tagNames(n))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("setTags: [%s]: %w", n.ID, err)
+	}
+	return nil
+}
+
+const setTags = `
+	WITH wanted AS (
+		INSERT INTO tags (user_id, name) SELECT $2, unnest($3::text[])
+		ON CONFLICT (user_id, name) DO UPDATE SET name = EXCLUDED.name
+		RETURNING id
+	), removed AS (
+		DELETE FROM note_tags WHERE note_id = $1 AND tag_id NOT IN (SELECT id FROM wanted)
+	)
+	INSERT INTO note_tags (note_id, tag_id) SELECT $1, id FROM wanted
+	ON CONFLICT DO NOTHING`
+
+// tagNames returns the tags of n as array parameter, which must not be nil.
+func tagNames(n note.Note) []string {
+	if n.Tags == nil {
+		return []string{}
+	}
+	return []string(n.Tags)
+}
+
+// RenameTag moves the notes of the tag from to the tag to, which is created
+// if needed, and deletes from. It runs as a single statement, so all notes
+// are renamed or none.
+func (nR NoteRepo) RenameTag(ctx context.Context, userID uuid.UUID, from, to string) (int, error) {
+	renameTag := `
+	WITH src AS (
+		SELECT id FROM tags WHERE user_id = $1 AND name = $2
+	), affected AS (
+		SELECT count(*) AS notes FROM note_tags WHERE tag_id IN (SELECT id FROM src)
+	), dst AS (
+		INSERT INTO tags (user_id, name) SELECT $1, $3 WHERE EXISTS (SELECT 1 FROM src)
+		ON CONFLICT (user_id, name) DO UPDATE SET name = EXCLUDED.name
+		RETURNING id
+	), moved AS (
+		INSERT INTO note_tags (note_id, tag_id)
+		SELECT nt.note_id, dst.id FROM note_tags nt, dst WHERE nt.tag_id IN (SELECT id FROM src)
+		ON CONFLICT DO NOTHING
+	), bumped AS (
+		UPDATE notes SET version = version + 1
+		WHERE id IN (SELECT note_id FROM note_tags WHERE tag_id IN (SELECT id FROM src))
+	)
+	DELETE FROM tags WHERE id IN (SELECT id FROM src)
+	RETURNING (SELECT notes FROM affected)`
+
+	var renamed int
+	err := nR.conn(ctx).QueryRowContext(ctx, renameTag, userID, from, to).Scan(&renamed)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("renameTag: [%s]: %w", from, note.ErrTagNotFound)
+		}
+		return 0, fmt.Errorf("renameTag: [%s]: %w", from, err)
+	}
+	return renamed, nil
+}
+
+func (nR NoteRepo) QueryTags(ctx context.Context, userID uuid.UUID) ([]note.TagCount, error) {
+	queryTags := `
+	SELECT t.name, count(*) FROM tags t
+	JOIN note_tags nt ON nt.tag_id = t.id
+	JOIN notes n ON n.id = nt.note_id AND n.deleted_at IS NULL
+	WHERE t.user_id = $1
+	GROUP BY t.name
+	ORDER BY t.name COLLATE "C"`
+
+	rows, err := nR.conn(ctx).QueryContext(ctx, queryTags, userID)
+	if err != nil {
+		return nil, fmt.Errorf("queryTags: [%s]: %w", userID, err)
+	}
+	defer rows.Close()
+
+	ret := []note.TagCount{}
+	for rows.Next() {
+		var tc note.TagCount
+		if err := rows.Scan(&tc.Name, &tc.Count); err != nil {
+			return nil, fmt.Errorf("queryTags: [%s]: scan rows: %w", userID, err)
+		}
+		ret = append(ret, tc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("queryTags: [%s]: %w", userID, err)
+	}
+	return ret, nil
+}
@@ -0,0 +1,78 @@
+package notedb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/core/note/repositories/notedb"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotesRepo_Tags(t *testing.T) {
+	testDB, deleteTable := SetupNotesTable(t, fixtureNotes())
+	defer deleteTable()
+	nR := notedb.NewNotesRepo(testDB)
+	ctx := context.Background()
+
+	setTags := func(t *testing.T, noteID uuid.UUID, tags note.Tags) {
+		t.Helper()
+		n, err := nR.QueryByID(ctx, noteID)
+		assert.NoError(t, err)
+		n.Tags = tags
+		assert.NoError(t, nR.SetTags(ctx, n))
+	}
+
+	t.Run("Tags are stored with the note", func(t *testing.T) {
+		setTags(t, uuid.UUID{1}, note.Tags{"go", "work"})
+		setTags(t, uuid.UUID{1}, note.Tags{"go", "home"})
+		setTags(t, uuid.UUID{2}, note.Tags{"go"})
+		setTags(t, uuid.UUID{3}, note.Tags{"go"})
+
+		got, err := nR.QueryByID(ctx, uuid.UUID{1})
+		assert.NoError(t, err)
+		assert.Equal(t, note.Tags{"go", "home"}, got.Tags)
+		assert.Equal(t, 2, got.Version)
+
+		stale := got
+		stale.Version--
+		err = nR.SetTags(ctx, stale)
+		assert.ErrorIs(t, err, note.ErrVersionConflict)
+
+		tags, err := nR.QueryTags(ctx, uuid.UUID{1})
+		assert.NoError(t, err)
+		assert.Equal(t, []note.TagCount{{Name: "go", Count: 2}, {Name: "home", Count: 1}}, tags)
+	})
+
+	t.Run("Notes can be filtered by tags", func(t *testing.T) {
+		notes, err := nR.Query(ctx, uuid.UUID{1}, note.QueryFilter{Tags: note.Tags{"go", "home"}}, note.OrderBy{}, note.Page{Limit: 10})
+		assert.NoError(t, err)
+		assert.Len(t, notes, 1)
+		assert.Equal(t, uuid.UUID{1}, notes[0].ID)
+
+		notes, err = nR.Query(ctx, uuid.UUID{1}, note.QueryFilter{Tags: note.Tags{"go", "home"}, TagMode: note.TagMatchAny}, note.OrderBy{}, note.Page{Limit: 10})
+		assert.NoError(t, err)
+		assert.Len(t, notes, 2)
+	})
+
+	t.Run("Renaming to an existing tag merges both", func(t *testing.T) {
+		renamed, err := nR.RenameTag(ctx, uuid.UUID{1}, "home", "go")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, renamed)
+		got, err := nR.QueryByID(ctx, uuid.UUID{1})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, got.Version)
+
+		tags, err := nR.QueryTags(ctx, uuid.UUID{1})
+		assert.NoError(t, err)
+		assert.Equal(t, []note.TagCount{{Name: "go", Count: 2}}, tags)
+
+		_, err = nR.RenameTag(ctx, uuid.UUID{1}, "home", "go")
+		assert.ErrorIs(t, err, note.ErrTagNotFound)
+
+		tags, err = nR.QueryTags(ctx, uuid.UUID{2})
+		assert.NoError(t, err)
+		assert.Equal(t, []note.TagCount{{Name: "go", Count: 1}}, tags)
+	})
+}
@@ -0,0 +1,100 @@
+package notedb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/google/uuid"
+)
+
+// trashNote leaves the note alone if it is no longer at version $3, unless
+// $3 is 0.
+const trashNote = `
+	UPDATE notes SET deleted_at = $2
+	WHERE id = $1 AND deleted_at IS NULL AND ($3 = 0 OR version = $3)`
+
+func (nR NoteRepo) Trash(ctx context.Context, noteID uuid.UUID, version int, at time.Time) error {
+	res, err := nR.conn(ctx).ExecContext(ctx, trashNote, noteID, at, version)
+	if err != nil {
+		return fmt.Errorf("trash: [%s]: %w", noteID, err)
+	}
+	if c, _ := res.RowsAffected(); c == 0 {
+		return fmt.Errorf("trash: [%s]: %w", noteID, notTrashed(ctx, nR.conn(ctx), noteID))
+	}
+	return nil
+}
+
+// notTrashed tells why trashNote left the note alone. It is ErrNoteNotFound
+// if the note is gone or already in the trash and ErrVersionConflict if it
+// has a newer version.
+func notTrashed(ctx context.Context, db transaction.Querier, noteID uuid.UUID) error {
+	var trashed bool
+	err := db.QueryRowContext(ctx, `SELECT deleted_at IS NOT NULL FROM notes WHERE id = $1`, noteID).Scan(&trashed)
+	if errors.Is(err, sql.ErrNoRows) || trashed {
+		return note.ErrNoteNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return note.ErrVersionConflict
+}
+
+func (nR NoteRepo) Restore(ctx context.Context, noteID uuid.UUID) error {
+	restore := `UPDATE notes SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`
+	res, err := nR.conn(ctx).ExecContext(ctx, restore, noteID)
+	if err != nil {
+		return fmt.Errorf("restore: [%s]: %w", noteID, err)
+	}
+	if c, _ := res.RowsAffected(); c == 0 {
+		return fmt.Errorf("restore: [%s]: %w", noteID, note.ErrNoteNotFound)
+	}
+	return nil
+}
+
+func (nR NoteRepo) QueryTrash(ctx context.Context, userID uuid.UUID) ([]note.Note, error) {
+	queryTrash := `
+	SELECT ` + noteColumns + ` FROM notes
+	WHERE user_id = $1 AND deleted_at IS NOT NULL
+	ORDER BY deleted_at DESC, id;
+	`
+	rows, err := nR.conn(ctx).QueryContext(ctx, queryTrash, userID)
+	if err != nil {
+		return nil, fmt.Errorf("queryTrash: [%s]: %w", userID, err)
+	}
+	defer rows.Close()
+
+	ret := []note.Note{}
+	for rows.Next() {
+		var nDB DBNote
+		err := scanNote(rows, &nDB)
+		if err != nil {
+			return nil, fmt.Errorf("queryTrash: [%s]: scan rows: %w", userID, err)
+		}
+		ret = append(ret, noteDBToNote(nDB))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("queryTrash: [%s]: %w", userID, err)
+	}
+
+	return ret, nil
+}
+
+// Purge deletes the notes trashed before the given time. Their shares and
+// links go with them, see the foreign keys of note_shares and share_links.
+func (nR NoteRepo) Purge(ctx context.Context, before time.Time) (int, error) {
+	purge := `DELETE FROM notes WHERE deleted_at < $1`
+	res, err := nR.conn(ctx).ExecContext(ctx, purge, before)
+	if err != nil {
+		return 0, fmt.Errorf("purge: %w", err)
+	}
+	c, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("purge: %w", err)
+	}
+	return int(c), nil
+}
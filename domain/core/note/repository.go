@@ -3,18 +3,90 @@ package note
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 var (
-	ErrNoteNotFound = errors.New("the note was not found")
+	ErrNoteNotFound   = errors.New("the note was not found")
+	ErrNoteNotTrashed = errors.New("the note is not in the trash")
+	// ErrVersionConflict is returned when a note has been changed since it
+	// was read.
+	ErrVersionConflict = errors.New("the note has been changed in the meantime")
 )
 
+// Repo stores notes. QueryByID also returns notes in the trash, all other
+// queries leave them out. The methods taking a context run in the
+// transaction it carries, see transaction.Manager.
 type Repo interface {
+	// Delete removes the note for good, Trash only moves it to the trash.
 	Delete(noteID uuid.UUID) error
-	Create(n Note) error
-	Update(note Note) error
+	// DeleteByUserID deletes all notes and tags of userID for good, also the
+	// notes in the trash, and returns how many notes there were.
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) (int, error)
+	// Trash moves the note to the trash if it is still at version, any
+	// version if 0. Otherwise it returns ErrVersionConflict.
+	Trash(ctx context.Context, noteID uuid.UUID, version int, at time.Time) error
+	Restore(ctx context.Context, noteID uuid.UUID) error
+	// QueryTrash returns the trashed notes of userID, most recently trashed
+	// first.
+	QueryTrash(ctx context.Context, userID uuid.UUID) ([]Note, error)
+	// Purge deletes the notes trashed before the given time and returns how
+	// many there were.
+	Purge(ctx context.Context, before time.Time) (int, error)
+	Create(ctx context.Context, n Note) error
+	// Update stores note if the stored note is still at note.Version and
+	// increments the stored version. Otherwise it returns ErrVersionConflict.
+	Update(ctx context.Context, note Note) error
 	QueryByID(ctx context.Context, noteID uuid.UUID) (Note, error)
 	QueryByUserID(userID uuid.UUID) ([]Note, error)
+	// StreamByUserID calls fn with each note of userID, ordered by id, and
+	// stops at the first error of fn, which it returns.
+	StreamByUserID(ctx context.Context, userID uuid.UUID, fn func(Note) error) error
+	QueryAll(ctx context.Context) ([]Note, error)
+	// Query returns up to page.Limit notes of userID matching filter, sorted
+	// by orderBy and starting after page.Cursor.
+	Query(ctx context.Context, userID uuid.UUID, filter QueryFilter, orderBy OrderBy, page Page) ([]Note, error)
+	// Search returns the notes of userID matching all words of query, best
+	// matches first.
+	Search(ctx context.Context, userID uuid.UUID, query string) ([]SearchResult, error)
+	// Import stores the notes with their tags in a single transaction. A note
+	// whose hash its owner already imported is not stored, the returned map
+	// holds the id of the note imported before by hash instead.
+	Import(ctx context.Context, notes []ImportedNote) (map[string]uuid.UUID, error)
+	// Batch applies the writes in order in a single transaction and returns
+	// the error of each, nil if it was applied. If atomic, it stops at the
+	// first failing write and applies none of them, the other writes then
+	// fail with ErrBatchAborted. Otherwise failed writes are left out.
+	Batch(ctx context.Context, writes []BatchWrite, atomic bool) ([]error, error)
+	// SetTags stores n.Tags as the tags of n like Update, if the stored
+	// note is still at n.Version, and increments the stored version.
+	SetTags(ctx context.Context, n Note) error
+	// RenameTag renames the tag from of userID to to on all of their notes,
+	// merging it into to if they already use it, and increments their
+	// versions. It returns the number of notes that had the tag.
+	RenameTag(ctx context.Context, userID uuid.UUID, from, to string) (int, error)
+	// QueryTags returns the tags of userID with the number of their notes
+	// outside the trash having them, ordered by name.
+	QueryTags(ctx context.Context, userID uuid.UUID) ([]TagCount, error)
+}
+
+type ShareRepo interface {
+	Upsert(ctx context.Context, s Share) error
+	Delete(ctx context.Context, noteID, userID uuid.UUID) error
+	QueryPermission(ctx context.Context, noteID, userID uuid.UUID) (Permission, error)
+	QueryByNoteID(ctx context.Context, noteID uuid.UUID) ([]Share, error)
+	QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Share, error)
+	// DeleteByUserID deletes the shares granted to userID.
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
+}
+
+type RevisionRepo interface {
+	// Append stores r as the next revision of its note and returns it with
+	// its number set.
+	Append(ctx context.Context, r Revision) (Revision, error)
+	// QueryByNoteID returns the revisions of the note, oldest first.
+	QueryByNoteID(ctx context.Context, noteID uuid.UUID) ([]Revision, error)
+	QueryByNumber(ctx context.Context, noteID uuid.UUID, number int) (Revision, error)
 }
@@ -0,0 +1,39 @@
+package note
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRevisionNotFound = errors.New("the revision was not found")
+)
+
+// Revision is an immutable snapshot of a note. Every note starts with
+// revision 1 and each update appends the next one.
+type Revision struct {
+	NoteID    uuid.UUID
+	Number    int
+	AuthorID  uuid.UUID
+	CreatedAt time.Time
+	Title     string
+	Content   string
+}
+
+// Text is the revision as a document, the title on the first line followed
+// by an empty line and the content. Diffs are computed on it.
+func (r Revision) Text() string {
+	return r.Title + "\n\n" + r.Content
+}
+
+func newRevision(n Note, authorID uuid.UUID) Revision {
+	return Revision{
+		NoteID:    n.ID,
+		AuthorID:  authorID,
+		CreatedAt: n.UpdatedAt,
+		Title:     n.Title.String(),
+		Content:   n.Content.String(),
+	}
+}
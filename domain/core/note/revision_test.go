@@ -0,0 +1,74 @@
+package note_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoteService_Revisions(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.UUID{1}
+	editorID := uuid.UUID{2}
+
+	setup := func(t *testing.T) (note.NotesService, note.Note) {
+		t.Helper()
+		// the notes only register the users with the stub user service
+		notesS := Setup(t, []note.Note{{ID: uuid.UUID{8}, UserID: ownerID}, {ID: uuid.UUID{9}, UserID: editorID}})
+		n, err := notesS.Create(ctx, note.UpdateNote{Title: note.NewTitle("title"), Content: note.NewContent("line 1\nline 2"), UserID: ownerID})
+		assert.NoError(t, err)
+		return notesS, n
+	}
+
+	t.Run("Every update appends a revision by its author", func(t *testing.T) {
+		notesS, n := setup(t)
+
+		n, err := notesS.Update(context.Background(), n, note.UpdateNote{Content: note.NewContent("line 1\nline two"), UserID: editorID})
+		assert.NoError(t, err)
+
+		got, err := notesS.QueryRevisions(ctx, n.ID)
+		assert.NoError(t, err)
+		assert.Len(t, got, 2)
+		assert.Equal(t, note.Revision{
+			NoteID: n.ID, Number: 1, AuthorID: ownerID, CreatedAt: n.CreatedAt, Title: "title", Content: "line 1\nline 2",
+		}, got[0])
+		assert.Equal(t, note.Revision{
+			NoteID: n.ID, Number: 2, AuthorID: editorID, CreatedAt: n.UpdatedAt, Title: "title", Content: "line 1\nline two",
+		}, got[1])
+	})
+
+	t.Run("Diffs two revisions", func(t *testing.T) {
+		notesS, n := setup(t)
+		_, err := notesS.Update(context.Background(), n, note.UpdateNote{Title: note.NewTitle("new title"), UserID: ownerID})
+		assert.NoError(t, err)
+
+		got, err := notesS.DiffRevisions(ctx, n.ID, 1, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, "--- revision 1\n+++ revision 2\n@@ -1,4 +1,4 @@\n-title\n+new title\n \n line 1\n line 2\n", got)
+
+		_, err = notesS.DiffRevisions(ctx, n.ID, 1, 3)
+		assert.ErrorIs(t, err, note.ErrRevisionNotFound)
+	})
+
+	t.Run("Restoring a revision appends it as a new one", func(t *testing.T) {
+		notesS, n := setup(t)
+		n, err := notesS.Update(context.Background(), n, note.UpdateNote{Title: note.NewTitle("new title"), Content: note.NewContent(""), UserID: ownerID})
+		assert.NoError(t, err)
+
+		restored, err := notesS.RestoreRevision(ctx, n, 1, editorID)
+		assert.NoError(t, err)
+		assert.Equal(t, "title", restored.Title.String())
+		assert.Equal(t, "line 1\nline 2", restored.Content.String())
+
+		got, err := notesS.QueryRevision(ctx, n.ID, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, editorID, got.AuthorID)
+		assert.Equal(t, "title", got.Title)
+
+		_, err = notesS.RestoreRevision(ctx, n, 4, editorID)
+		assert.ErrorIs(t, err, note.ErrRevisionNotFound)
+	})
+}
@@ -0,0 +1,14 @@
+package note
+
+import "errors"
+
+var ErrEmptyQuery = errors.New("empty search query")
+
+// SearchResult is a note matching a search query. Results with a higher Rank
+// match better. Snippet is an excerpt of the note with the matching words
+// wrapped in <b></b>.
+type SearchResult struct {
+	Note    Note
+	Rank    float64
+	Snippet string
+}
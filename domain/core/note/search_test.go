@@ -0,0 +1,79 @@
+package note_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/core/note/repositories/memory"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoteService_Search(t *testing.T) {
+	ctx := context.Background()
+	rob := uuid.UUID{1}
+
+	t.Run("Finds the notes of the user containing all words", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+
+		got, err := notesS.Search(ctx, rob, "2nd CONTENT")
+		assert.NoError(t, err)
+		assert.Len(t, got, 1)
+		assert.Equal(t, fixtureNotes()[1], got[0].Note)
+		assert.Equal(t, "robs <b>2nd</b> note robs <b>2nd</b> note <b>content</b>", got[0].Snippet)
+
+		got, err = notesS.Search(ctx, rob, "annas")
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("Matches in the title rank higher", func(t *testing.T) {
+		notes := []note.Note{
+			{ID: uuid.UUID{1}, Title: note.NewTitle("shopping"), Content: note.NewContent("milk, eggs"), UserID: rob},
+			{ID: uuid.UUID{2}, Title: note.NewTitle("todo"), Content: note.NewContent("go shopping"), UserID: rob},
+		}
+		notesS := Setup(t, notes)
+
+		got, err := notesS.Search(ctx, rob, "shopping")
+		assert.NoError(t, err)
+		assert.Len(t, got, 2)
+		assert.Equal(t, uuid.UUID{1}, got[0].Note.ID)
+		assert.Greater(t, got[0].Rank, got[1].Rank)
+	})
+
+	t.Run("The index follows updates and deletes", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+		robsNote := fixtureNotes()[0]
+
+		_, err := notesS.Update(context.Background(), robsNote, note.UpdateNote{Title: note.NewTitle("groceries")})
+		assert.NoError(t, err)
+
+		got, err := notesS.Search(ctx, rob, "groceries")
+		assert.NoError(t, err)
+		assert.Len(t, got, 1)
+
+		got, err = notesS.Search(ctx, rob, "1st")
+		assert.NoError(t, err)
+		assert.Len(t, got, 1, "content is still indexed")
+
+		assert.NoError(t, notesS.Delete(context.Background(), robsNote.ID, 0))
+		got, err = notesS.Search(ctx, rob, "groceries")
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("Empty queries are rejected", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+		_, err := notesS.Search(ctx, rob, "  ")
+		assert.ErrorIs(t, err, note.ErrEmptyQuery)
+	})
+
+	t.Run("Forwards repo errors", func(t *testing.T) {
+		notesS := note.NewNotesService(ErrorNoteRepo{}, memory.NewShareRepo(nil), memory.NewRevisionRepo(nil), StubUserService{}, transaction.NewMemoryManager())
+		_, err := notesS.Search(ctx, rob, "note")
+		assert.EqualError(t, err, fmt.Sprintf("search: [%s]: error in noteRepo", rob))
+	})
+}
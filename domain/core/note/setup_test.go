@@ -5,6 +5,7 @@ import (
 
 	"github.com/Keisn1/note-taking-app/domain/core/note"
 	"github.com/Keisn1/note-taking-app/domain/core/note/repositories/memory"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
@@ -28,5 +29,5 @@ func Setup(t *testing.T, notes []note.Note) note.NotesService {
 		userSvc.ids[n.UserID] = struct{}{}
 	}
 
-	return note.NewNotesService(repo, userSvc)
+	return note.NewNotesService(repo, memory.NewShareRepo(nil), memory.NewRevisionRepo(nil), userSvc, transaction.NewMemoryManager())
 }
@@ -0,0 +1,59 @@
+package note
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrShareNotFound     = errors.New("the share was not found")
+	ErrInvalidPermission = errors.New("invalid permission")
+	ErrShareWithOwner    = errors.New("cannot share a note with its owner")
+)
+
+// Permission is the level of access a user has on a note. Levels are
+// ordered so that a higher level implies every lower one.
+type Permission int
+
+const (
+	PermissionNone Permission = iota
+	PermissionRead
+	PermissionWrite
+	PermissionOwner
+)
+
+var permissionNames = map[Permission]string{
+	PermissionRead:  "read",
+	PermissionWrite: "write",
+	PermissionOwner: "owner",
+}
+
+func ParsePermission(s string) (Permission, error) {
+	for p, name := range permissionNames {
+		if name == s {
+			return p, nil
+		}
+	}
+	return PermissionNone, fmt.Errorf("parsePermission: [%s]: %w", s, ErrInvalidPermission)
+}
+
+func (p Permission) String() string {
+	if name, ok := permissionNames[p]; ok {
+		return name
+	}
+	return "none"
+}
+
+// Allows reports whether p grants at least the required level.
+func (p Permission) Allows(required Permission) bool {
+	return p != PermissionNone && p >= required
+}
+
+// Share grants UserID access to NoteID at the given Permission.
+type Share struct {
+	NoteID     uuid.UUID
+	UserID     uuid.UUID
+	Permission Permission
+}
@@ -0,0 +1,113 @@
+package note_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermission(t *testing.T) {
+	t.Run("Higher permissions imply lower ones", func(t *testing.T) {
+		assert.True(t, note.PermissionOwner.Allows(note.PermissionWrite))
+		assert.True(t, note.PermissionWrite.Allows(note.PermissionRead))
+		assert.True(t, note.PermissionRead.Allows(note.PermissionRead))
+		assert.False(t, note.PermissionRead.Allows(note.PermissionWrite))
+		assert.False(t, note.PermissionNone.Allows(note.PermissionNone))
+	})
+
+	t.Run("I can parse a permission from its name", func(t *testing.T) {
+		for _, p := range []note.Permission{note.PermissionRead, note.PermissionWrite, note.PermissionOwner} {
+			got, err := note.ParsePermission(p.String())
+			assert.NoError(t, err)
+			assert.Equal(t, p, got)
+		}
+
+		_, err := note.ParsePermission("none")
+		assert.ErrorIs(t, err, note.ErrInvalidPermission)
+	})
+}
+
+func TestNoteService_Share(t *testing.T) {
+	ctx := context.Background()
+	robsNote := fixtureNotes()[0]
+	anna := uuid.UUID{2}
+
+	t.Run("The owner of a note has owner permission, others have none", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+
+		perm, err := notesS.Permission(ctx, robsNote, robsNote.UserID)
+		assert.NoError(t, err)
+		assert.Equal(t, note.PermissionOwner, perm)
+
+		perm, err = notesS.Permission(ctx, robsNote, anna)
+		assert.NoError(t, err)
+		assert.Equal(t, note.PermissionNone, perm)
+	})
+
+	t.Run("I can share a note and change the permission later on", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+
+		s, err := notesS.Share(ctx, robsNote, anna, note.PermissionRead)
+		assert.NoError(t, err)
+		assert.Equal(t, note.Share{NoteID: robsNote.ID, UserID: anna, Permission: note.PermissionRead}, s)
+
+		perm, err := notesS.Permission(ctx, robsNote, anna)
+		assert.NoError(t, err)
+		assert.Equal(t, note.PermissionRead, perm)
+
+		_, err = notesS.Share(ctx, robsNote, anna, note.PermissionWrite)
+		assert.NoError(t, err)
+
+		shares, err := notesS.QueryShares(ctx, robsNote.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []note.Share{{NoteID: robsNote.ID, UserID: anna, Permission: note.PermissionWrite}}, shares)
+	})
+
+	t.Run("Sharing is rejected for invalid input", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+
+		_, err := notesS.Share(ctx, robsNote, anna, note.PermissionNone)
+		assert.ErrorIs(t, err, note.ErrInvalidPermission)
+
+		_, err = notesS.Share(ctx, robsNote, robsNote.UserID, note.PermissionRead)
+		assert.ErrorIs(t, err, note.ErrShareWithOwner)
+
+		_, err = notesS.Share(ctx, robsNote, uuid.New(), note.PermissionRead)
+		assert.Error(t, err)
+	})
+
+	t.Run("I can unshare a note", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+
+		_, err := notesS.Share(ctx, robsNote, anna, note.PermissionRead)
+		assert.NoError(t, err)
+
+		err = notesS.Unshare(ctx, robsNote.ID, anna)
+		assert.NoError(t, err)
+
+		perm, err := notesS.Permission(ctx, robsNote, anna)
+		assert.NoError(t, err)
+		assert.Equal(t, note.PermissionNone, perm)
+
+		err = notesS.Unshare(ctx, robsNote.ID, anna)
+		assert.ErrorIs(t, err, note.ErrShareNotFound)
+	})
+
+	t.Run("I can list the notes shared with me", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+
+		_, err := notesS.Share(ctx, robsNote, anna, note.PermissionRead)
+		assert.NoError(t, err)
+
+		got, err := notesS.QuerySharedWith(ctx, anna)
+		assert.NoError(t, err)
+		assert.Equal(t, []note.Note{robsNote}, got)
+
+		got, err = notesS.QuerySharedWith(ctx, robsNote.UserID)
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+	})
+}
@@ -3,6 +3,8 @@ package note_test
 import (
 	"context"
 	"errors"
+	"net/mail"
+	"time"
 
 	"github.com/Keisn1/note-taking-app/domain/core/note"
 	"github.com/Keisn1/note-taking-app/domain/core/user"
@@ -13,13 +15,71 @@ type ErrorNoteRepo struct {
 	notes map[uuid.UUID]note.Note
 }
 
-func (nR ErrorNoteRepo) Create(n note.Note) error      { return errors.New("error in noteRepo") }
+func (nR ErrorNoteRepo) Create(ctx context.Context, n note.Note) error {
+	return errors.New("error in noteRepo")
+}
 func (nR ErrorNoteRepo) Delete(noteID uuid.UUID) error { return nil }
-func (nR ErrorNoteRepo) Update(note note.Note) error   { return nil }
+func (nR ErrorNoteRepo) DeleteByUserID(ctx context.Context, userID uuid.UUID) (int, error) {
+	return 0, errors.New("error in noteRepo")
+}
+func (nR ErrorNoteRepo) Update(ctx context.Context, note note.Note) error { return nil }
 func (nR ErrorNoteRepo) QueryByID(ctx context.Context, noteID uuid.UUID) (note.Note, error) {
 	return note.Note{}, nil
 }
 func (nR ErrorNoteRepo) QueryByUserID(userID uuid.UUID) ([]note.Note, error) { return nil, nil }
+func (nR ErrorNoteRepo) StreamByUserID(ctx context.Context, userID uuid.UUID, fn func(note.Note) error) error {
+	return errors.New("error in noteRepo")
+}
+func (nR ErrorNoteRepo) Import(ctx context.Context, notes []note.ImportedNote) (map[string]uuid.UUID, error) {
+	return nil, errors.New("error in noteRepo")
+}
+func (nR ErrorNoteRepo) Batch(ctx context.Context, writes []note.BatchWrite, atomic bool) ([]error, error) {
+	return nil, errors.New("error in noteRepo")
+}
+func (nR ErrorNoteRepo) QueryAll(ctx context.Context) ([]note.Note, error) {
+	return nil, errors.New("error in noteRepo")
+}
+func (nR ErrorNoteRepo) Query(ctx context.Context, userID uuid.UUID, filter note.QueryFilter, orderBy note.OrderBy, page note.Page) ([]note.Note, error) {
+	return nil, errors.New("error in noteRepo")
+}
+func (nR ErrorNoteRepo) Search(ctx context.Context, userID uuid.UUID, query string) ([]note.SearchResult, error) {
+	return nil, errors.New("error in noteRepo")
+}
+
+func (nR ErrorNoteRepo) Trash(ctx context.Context, noteID uuid.UUID, version int, at time.Time) error {
+	return errors.New("error in noteRepo")
+}
+func (nR ErrorNoteRepo) Restore(ctx context.Context, noteID uuid.UUID) error {
+	return errors.New("error in noteRepo")
+}
+func (nR ErrorNoteRepo) QueryTrash(ctx context.Context, userID uuid.UUID) ([]note.Note, error) {
+	return nil, errors.New("error in noteRepo")
+}
+func (nR ErrorNoteRepo) Purge(ctx context.Context, before time.Time) (int, error) {
+	return 0, errors.New("error in noteRepo")
+}
+
+func (nR ErrorNoteRepo) SetTags(ctx context.Context, n note.Note) error {
+	return errors.New("error in noteRepo")
+}
+func (nR ErrorNoteRepo) RenameTag(ctx context.Context, userID uuid.UUID, from, to string) (int, error) {
+	return 0, errors.New("error in noteRepo")
+}
+func (nR ErrorNoteRepo) QueryTags(ctx context.Context, userID uuid.UUID) ([]note.TagCount, error) {
+	return nil, errors.New("error in noteRepo")
+}
+
+type ErrorRevisionRepo struct{}
+
+func (rR ErrorRevisionRepo) Append(ctx context.Context, r note.Revision) (note.Revision, error) {
+	return note.Revision{}, errors.New("error in revisionRepo")
+}
+func (rR ErrorRevisionRepo) QueryByNoteID(ctx context.Context, noteID uuid.UUID) ([]note.Revision, error) {
+	return nil, errors.New("error in revisionRepo")
+}
+func (rR ErrorRevisionRepo) QueryByNumber(ctx context.Context, noteID uuid.UUID, number int) (note.Revision, error) {
+	return note.Revision{}, errors.New("error in revisionRepo")
+}
 
 type StubUserService struct {
 	ids map[uuid.UUID]struct{}
@@ -32,6 +92,10 @@ func (sus StubUserService) QueryByID(ctx context.Context, userID uuid.UUID) (use
 	return user.User{ID: userID}, nil
 }
 
+func (sus StubUserService) QueryAll(ctx context.Context) ([]user.User, error) {
+	return nil, nil
+}
+
 func (sus StubUserService) Create(ctx context.Context, uu user.UpdateUser) (user.User, error) {
 	return user.User{}, nil
 }
@@ -39,4 +103,8 @@ func (sus StubUserService) Update(ctx context.Context, u user.User, uu user.Upda
 	return user.User{}, nil
 }
 
-func (sus StubUserService) Delete(ctx context.Context, userID uuid.UUID) error
+func (sus StubUserService) Delete(ctx context.Context, userID uuid.UUID) error { return nil }
+
+func (sus StubUserService) Authenticate(ctx context.Context, email mail.Address, password string) (user.User, error) {
+	return user.User{}, nil
+}
@@ -0,0 +1,99 @@
+package note
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidTag     = errors.New("invalid tag")
+	ErrTagNotFound    = errors.New("the tag was not found")
+	ErrInvalidTagMode = errors.New("invalid tag mode")
+)
+
+const MaxTagLength = 32
+
+// Tags is a sorted set of normalized tag names.
+type Tags []string
+
+// NewTags normalizes names, see NormalizeTag, and drops duplicates.
+func NewTags(names ...string) (Tags, error) {
+	tags := make(Tags, 0, len(names))
+	for _, name := range names {
+		tag, err := NormalizeTag(name)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+	return slices.Compact(tags), nil
+}
+
+// NormalizeTag lower-cases name and replaces runs of whitespace with a
+// single dash. Tags consist of letters, digits, '-', '_' and '/' and are at
+// most MaxTagLength characters long.
+func NormalizeTag(name string) (string, error) {
+	tag := strings.ToLower(strings.Join(strings.Fields(name), "-"))
+	if tag == "" || utf8.RuneCountInString(tag) > MaxTagLength {
+		return "", fmt.Errorf("normalizeTag: [%s]: %w", name, ErrInvalidTag)
+	}
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_/", r) {
+			return "", fmt.Errorf("normalizeTag: [%s]: %w", name, ErrInvalidTag)
+		}
+	}
+	return tag, nil
+}
+
+func (t Tags) Contains(tag string) bool {
+	_, found := slices.BinarySearch(t, tag)
+	return found
+}
+
+// Add returns the union of t and other.
+func (t Tags) Add(other Tags) Tags {
+	ret := append(slices.Clone(t), other...)
+	slices.Sort(ret)
+	return slices.Compact(ret)
+}
+
+// Remove returns the tags of t that are not in other.
+func (t Tags) Remove(other Tags) Tags {
+	var ret Tags
+	for _, tag := range t {
+		if !slices.Contains(other, tag) {
+			ret = append(ret, tag)
+		}
+	}
+	return ret
+}
+
+// TagCount is a tag with the number of notes having it.
+type TagCount struct {
+	Name  string
+	Count int
+}
+
+// TagMode tells whether a note has to have all or any of the tags of a
+// QueryFilter.
+type TagMode int
+
+const (
+	TagMatchAll TagMode = iota
+	TagMatchAny
+)
+
+func ParseTagMode(s string) (TagMode, error) {
+	switch s {
+	case "all":
+		return TagMatchAll, nil
+	case "any":
+		return TagMatchAny, nil
+	}
+	return TagMatchAll, fmt.Errorf("parseTagMode: [%s]: %w", s, ErrInvalidTagMode)
+}
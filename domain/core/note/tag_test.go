@@ -0,0 +1,157 @@
+package note_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTags(t *testing.T) {
+	t.Run("Tags are normalized, sorted and unique", func(t *testing.T) {
+		got, err := note.NewTags("Work", "to  do", "work", "projects/Go", "ÄRGER")
+		assert.NoError(t, err)
+		assert.Equal(t, note.Tags{"projects/go", "to-do", "work", "ärger"}, got)
+	})
+
+	t.Run("Invalid tags are rejected", func(t *testing.T) {
+		for _, name := range []string{"", "   ", "a+b", "#tag", "abcdefghijklmnopqrstuvwxyz0123456"} {
+			_, err := note.NewTags(name)
+			assert.ErrorIs(t, err, note.ErrInvalidTag, name)
+		}
+	})
+}
+
+func TestParseTagMode(t *testing.T) {
+	mode, err := note.ParseTagMode("any")
+	assert.NoError(t, err)
+	assert.Equal(t, note.TagMatchAny, mode)
+
+	mode, err = note.ParseTagMode("all")
+	assert.NoError(t, err)
+	assert.Equal(t, note.TagMatchAll, mode)
+
+	_, err = note.ParseTagMode("none")
+	assert.ErrorIs(t, err, note.ErrInvalidTagMode)
+}
+
+func TestNoteService_Tags(t *testing.T) {
+	ctx := context.Background()
+	robsNotes := fixtureNotes()[:2]
+	robID := robsNotes[0].UserID
+
+	t.Run("Tags can be added and removed with a new version", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+
+		got, err := notesS.AddTags(ctx, robsNotes[0], note.Tags{"work", "go"})
+		assert.NoError(t, err)
+		assert.Equal(t, note.Tags{"go", "work"}, got.Tags)
+		assert.Equal(t, robsNotes[0].Version+1, got.Version)
+
+		// robsNotes[0] is stale now
+		_, err = notesS.AddTags(ctx, robsNotes[0], note.Tags{"other"})
+		assert.ErrorIs(t, err, note.ErrVersionConflict)
+		_, err = notesS.Update(ctx, robsNotes[0], note.UpdateNote{Title: note.NewTitle("new title"), UserID: robID})
+		assert.ErrorIs(t, err, note.ErrVersionConflict)
+
+		got, err = notesS.RemoveTags(ctx, got, note.Tags{"work", "missing"})
+		assert.NoError(t, err)
+		assert.Equal(t, note.Tags{"go"}, got.Tags)
+
+		stored, err := notesS.QueryByID(ctx, robsNotes[0].ID)
+		assert.NoError(t, err)
+		assert.Equal(t, got, stored)
+	})
+
+	t.Run("Updates keep the tags", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+
+		tagged, err := notesS.AddTags(ctx, robsNotes[0], note.Tags{"go"})
+		assert.NoError(t, err)
+
+		_, err = notesS.Update(context.Background(), tagged, note.UpdateNote{Title: note.NewTitle("new title"), UserID: robID})
+		assert.NoError(t, err)
+
+		stored, err := notesS.QueryByID(ctx, robsNotes[0].ID)
+		assert.NoError(t, err)
+		assert.Equal(t, tagged.Tags, stored.Tags)
+	})
+
+	t.Run("Tags are counted per user", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+
+		_, err := notesS.AddTags(ctx, robsNotes[0], note.Tags{"go", "work"})
+		assert.NoError(t, err)
+		_, err = notesS.AddTags(ctx, robsNotes[1], note.Tags{"go"})
+		assert.NoError(t, err)
+		_, err = notesS.AddTags(ctx, fixtureNotes()[2], note.Tags{"go"})
+		assert.NoError(t, err)
+
+		got, err := notesS.QueryTags(ctx, robID)
+		assert.NoError(t, err)
+		assert.Equal(t, []note.TagCount{{Name: "go", Count: 2}, {Name: "work", Count: 1}}, got)
+
+		err = notesS.Delete(context.Background(), robsNotes[1].ID, 0)
+		assert.NoError(t, err)
+
+		got, err = notesS.QueryTags(ctx, robID)
+		assert.NoError(t, err)
+		assert.Equal(t, []note.TagCount{{Name: "go", Count: 1}, {Name: "work", Count: 1}}, got)
+	})
+
+	t.Run("Renaming to an existing tag merges both", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+
+		_, err := notesS.AddTags(ctx, robsNotes[0], note.Tags{"golang", "go"})
+		assert.NoError(t, err)
+		_, err = notesS.AddTags(ctx, robsNotes[1], note.Tags{"golang"})
+		assert.NoError(t, err)
+		annasNote, err := notesS.AddTags(ctx, fixtureNotes()[2], note.Tags{"golang"})
+		assert.NoError(t, err)
+
+		renamed, err := notesS.RenameTag(ctx, robID, "GoLang", "Go")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, renamed)
+
+		got, err := notesS.QueryTags(ctx, robID)
+		assert.NoError(t, err)
+		assert.Equal(t, []note.TagCount{{Name: "go", Count: 2}}, got)
+
+		stored, err := notesS.QueryByID(ctx, annasNote.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, note.Tags{"golang"}, stored.Tags)
+	})
+
+	t.Run("Renaming fails for missing and invalid tags", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+
+		_, err := notesS.RenameTag(ctx, robID, "missing", "other")
+		assert.ErrorIs(t, err, note.ErrTagNotFound)
+
+		_, err = notesS.RenameTag(ctx, robID, "go", "a+b")
+		assert.ErrorIs(t, err, note.ErrInvalidTag)
+	})
+
+	t.Run("Notes can be filtered by all or any of the tags", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+
+		_, err := notesS.AddTags(ctx, robsNotes[0], note.Tags{"go", "work"})
+		assert.NoError(t, err)
+		_, err = notesS.AddTags(ctx, robsNotes[1], note.Tags{"go"})
+		assert.NoError(t, err)
+
+		np, err := notesS.Query(ctx, robID, note.QueryFilter{Tags: note.Tags{"go", "work"}}, note.OrderBy{}, note.Page{})
+		assert.NoError(t, err)
+		assert.Len(t, np.Notes, 1)
+		assert.Equal(t, robsNotes[0].ID, np.Notes[0].ID)
+
+		np, err = notesS.Query(ctx, robID, note.QueryFilter{Tags: note.Tags{"go", "work"}, TagMode: note.TagMatchAny}, note.OrderBy{}, note.Page{})
+		assert.NoError(t, err)
+		assert.Len(t, np.Notes, 2)
+
+		np, err = notesS.Query(ctx, robID, note.QueryFilter{Tags: note.Tags{"missing"}, TagMode: note.TagMatchAny}, note.OrderBy{}, note.Page{})
+		assert.NoError(t, err)
+		assert.Empty(t, np.Notes)
+	})
+}
@@ -0,0 +1,100 @@
+package note_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoteService_Trash(t *testing.T) {
+	ctx := context.Background()
+	robsNote := fixtureNotes()[0]
+
+	t.Run("Deleted notes are only listed in the trash", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+
+		err := notesS.Delete(context.Background(), robsNote.ID, 0)
+		assert.NoError(t, err)
+
+		_, err = notesS.QueryByID(ctx, robsNote.ID)
+		assert.ErrorIs(t, err, note.ErrNoteNotFound)
+
+		np, err := notesS.Query(ctx, robsNote.UserID, note.QueryFilter{}, note.OrderBy{}, note.Page{})
+		assert.NoError(t, err)
+		assert.Len(t, np.Notes, 1)
+
+		trash, err := notesS.QueryTrash(ctx, robsNote.UserID)
+		assert.NoError(t, err)
+		assert.Len(t, trash, 1)
+		assert.Equal(t, robsNote.ID, trash[0].ID)
+		assert.NotNil(t, trash[0].DeletedAt)
+
+		err = notesS.Delete(context.Background(), robsNote.ID, 0)
+		assert.ErrorIs(t, err, note.ErrNoteNotFound)
+	})
+
+	t.Run("A note changed since it was read is not deleted", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+		updated, err := notesS.Update(ctx, robsNote, note.UpdateNote{Title: note.NewTitle("new title")})
+		assert.NoError(t, err)
+
+		err = notesS.Delete(ctx, robsNote.ID, robsNote.Version+3)
+		assert.ErrorIs(t, err, note.ErrVersionConflict)
+		_, err = notesS.QueryByID(ctx, robsNote.ID)
+		assert.NoError(t, err)
+
+		assert.NoError(t, notesS.Delete(ctx, robsNote.ID, updated.Version))
+	})
+
+	t.Run("The owner can restore a deleted note", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+		assert.NoError(t, notesS.Delete(context.Background(), robsNote.ID, 0))
+
+		got, err := notesS.Restore(ctx, robsNote.ID, robsNote.UserID)
+		assert.NoError(t, err)
+		assert.Nil(t, got.DeletedAt)
+
+		got, err = notesS.QueryByID(ctx, robsNote.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, robsNote.Title, got.Title)
+
+		trash, err := notesS.QueryTrash(ctx, robsNote.UserID)
+		assert.NoError(t, err)
+		assert.Empty(t, trash)
+	})
+
+	t.Run("Restore fails for notes of other users or not in the trash", func(t *testing.T) {
+		notesS := Setup(t, fixtureNotes())
+
+		_, err := notesS.Restore(ctx, robsNote.ID, robsNote.UserID)
+		assert.ErrorIs(t, err, note.ErrNoteNotTrashed)
+
+		assert.NoError(t, notesS.Delete(context.Background(), robsNote.ID, 0))
+		_, err = notesS.Restore(ctx, robsNote.ID, uuid.UUID{2})
+		assert.ErrorIs(t, err, note.ErrNoteNotFound)
+
+		_, err = notesS.Restore(ctx, uuid.New(), robsNote.UserID)
+		assert.ErrorIs(t, err, note.ErrNoteNotFound)
+	})
+
+	t.Run("Purges notes trashed for longer than the retention", func(t *testing.T) {
+		notes := fixtureNotes()
+		longAgo := time.Now().Add(-48 * time.Hour)
+		notes[0].DeletedAt = &longAgo
+		notesS := Setup(t, notes)
+		assert.NoError(t, notesS.Delete(context.Background(), notes[1].ID, 0))
+
+		purged, err := notesS.PurgeTrash(ctx, 24*time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, purged)
+
+		trash, err := notesS.QueryTrash(ctx, notes[0].UserID)
+		assert.NoError(t, err)
+		assert.Len(t, trash, 1)
+		assert.Equal(t, notes[1].ID, trash[0].ID)
+	})
+}
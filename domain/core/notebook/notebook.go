@@ -0,0 +1,61 @@
+package notebook
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/google/uuid"
+)
+
+const MaxNameLength = 100
+
+// Notebook groups notes of a user. Notebooks can be nested, ParentID is nil
+// for top-level notebooks. A note is in at most one notebook.
+type Notebook struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	ParentID  *uuid.UUID
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewNotebook is a notebook to be created. ParentID is left nil for a
+// top-level notebook.
+type NewNotebook struct {
+	UserID   uuid.UUID
+	ParentID *uuid.UUID
+	Name     string
+}
+
+// Contents are the notebooks and notes directly inside a notebook.
+type Contents struct {
+	Notebooks []Notebook
+	Notes     []note.Note
+}
+
+// DeleteMode tells what happens to the contents of a deleted notebook.
+type DeleteMode int
+
+const (
+	// DeleteRestrict only deletes empty notebooks.
+	DeleteRestrict DeleteMode = iota
+	// DeleteCascade deletes the nested notebooks as well and moves all notes
+	// inside to the trash.
+	DeleteCascade
+	// DeleteReparent moves the contents to the parent of the notebook.
+	DeleteReparent
+)
+
+func ParseDeleteMode(s string) (DeleteMode, error) {
+	switch s {
+	case "restrict":
+		return DeleteRestrict, nil
+	case "cascade":
+		return DeleteCascade, nil
+	case "reparent":
+		return DeleteReparent, nil
+	}
+	return DeleteRestrict, fmt.Errorf("parseDeleteMode: [%s]: %w", s, ErrInvalidDeleteMode)
+}
@@ -0,0 +1,266 @@
+package notebook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidName       = errors.New("invalid notebook name")
+	ErrNotebookNotEmpty  = errors.New("the notebook is not empty")
+	ErrNotebookCycle     = errors.New("a notebook can't be moved into itself")
+	ErrInvalidDeleteMode = errors.New("invalid delete mode")
+)
+
+type Service interface {
+	Create(ctx context.Context, nn NewNotebook) (Notebook, error)
+	QueryByID(ctx context.Context, notebookID uuid.UUID) (Notebook, error)
+	QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Notebook, error)
+	QueryContents(ctx context.Context, nb Notebook) (Contents, error)
+	Rename(ctx context.Context, nb Notebook, name string) (Notebook, error)
+	Move(ctx context.Context, nb Notebook, parentID *uuid.UUID) (Notebook, error)
+	Delete(ctx context.Context, nb Notebook, mode DeleteMode) error
+	MoveNote(ctx context.Context, n note.Note, notebookID *uuid.UUID) error
+}
+
+type Svc struct {
+	repo    Repo
+	noteSvc note.Service
+	txm     transaction.Manager
+}
+
+func NewSvc(repo Repo, ns note.Service, txm transaction.Manager) Service {
+	return Svc{repo: repo, noteSvc: ns, txm: txm}
+}
+
+func (s Svc) Create(ctx context.Context, nn NewNotebook) (Notebook, error) {
+	name, err := validateName(nn.Name)
+	if err != nil {
+		return Notebook{}, fmt.Errorf("create: %w", err)
+	}
+
+	if nn.ParentID != nil {
+		if _, err := s.queryOwned(ctx, *nn.ParentID, nn.UserID); err != nil {
+			return Notebook{}, fmt.Errorf("create: parent: %w", err)
+		}
+	}
+
+	createdAt := now()
+	nb := Notebook{
+		ID:        uuid.New(),
+		UserID:    nn.UserID,
+		ParentID:  nn.ParentID,
+		Name:      name,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+	if err := s.repo.Create(ctx, nb); err != nil {
+		return Notebook{}, fmt.Errorf("create: %w", err)
+	}
+	return nb, nil
+}
+
+func (s Svc) QueryByID(ctx context.Context, notebookID uuid.UUID) (Notebook, error) {
+	nb, err := s.repo.QueryByID(ctx, notebookID)
+	if err != nil {
+		return Notebook{}, fmt.Errorf("queryByID: [%s]: %w", notebookID, err)
+	}
+	return nb, nil
+}
+
+// QueryByUserID returns all notebooks of userID, nested ones included. The
+// hierarchy is given by their ParentID.
+func (s Svc) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Notebook, error) {
+	notebooks, err := s.repo.QueryByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("queryByUserID: [%s]: %w", userID, err)
+	}
+	return notebooks, nil
+}
+
+// QueryContents returns the notebooks and notes directly inside nb. Notes in
+// the trash are left out.
+func (s Svc) QueryContents(ctx context.Context, nb Notebook) (Contents, error) {
+	children, err := s.repo.QueryChildren(ctx, nb.ID)
+	if err != nil {
+		return Contents{}, fmt.Errorf("queryContents: [%s]: %w", nb.ID, err)
+	}
+
+	noteIDs, err := s.repo.QueryNoteIDs(ctx, nb.ID)
+	if err != nil {
+		return Contents{}, fmt.Errorf("queryContents: [%s]: %w", nb.ID, err)
+	}
+
+	notes := make([]note.Note, 0, len(noteIDs))
+	for _, noteID := range noteIDs {
+		n, err := s.noteSvc.QueryByID(ctx, noteID)
+		if err != nil {
+			if errors.Is(err, note.ErrNoteNotFound) {
+				continue
+			}
+			return Contents{}, fmt.Errorf("queryContents: [%s]: %w", nb.ID, err)
+		}
+		notes = append(notes, n)
+	}
+
+	return Contents{Notebooks: children, Notes: notes}, nil
+}
+
+func (s Svc) Rename(ctx context.Context, nb Notebook, name string) (Notebook, error) {
+	name, err := validateName(name)
+	if err != nil {
+		return Notebook{}, fmt.Errorf("rename: [%s]: %w", nb.ID, err)
+	}
+
+	nb.Name = name
+	nb.UpdatedAt = now()
+	if err := s.repo.Update(ctx, nb); err != nil {
+		return Notebook{}, fmt.Errorf("rename: [%s]: %w", nb.ID, err)
+	}
+	return nb, nil
+}
+
+// Move puts nb inside the notebook parentID, or to the top level if parentID
+// is nil. Both notebooks have to belong to the same user and nb can't be
+// moved into one of its own nested notebooks. The notebooks from the new
+// parent up to the top stay locked until nb is moved, so that concurrent
+// moves can't create a cycle.
+func (s Svc) Move(ctx context.Context, nb Notebook, parentID *uuid.UUID) (Notebook, error) {
+	nb.ParentID = parentID
+	nb.UpdatedAt = now()
+	err := s.txm.Run(ctx, func(ctx context.Context) error {
+		if parentID != nil {
+			if _, err := s.queryOwned(ctx, *parentID, nb.UserID); err != nil {
+				return fmt.Errorf("parent: %w", err)
+			}
+
+			// walk up from the new parent, nb must not be on the way to the top
+			for id := parentID; id != nil; {
+				if *id == nb.ID {
+					return ErrNotebookCycle
+				}
+				ancestor, err := s.repo.QueryByID(ctx, *id)
+				if err != nil {
+					return err
+				}
+				id = ancestor.ParentID
+			}
+		}
+		return s.repo.Update(ctx, nb)
+	})
+	if err != nil {
+		return Notebook{}, fmt.Errorf("move: [%s]: %w", nb.ID, err)
+	}
+	return nb, nil
+}
+
+// Delete deletes nb. What happens to the notebooks and notes inside depends
+// on mode, see DeleteMode. Everything is deleted or moved in one transaction.
+func (s Svc) Delete(ctx context.Context, nb Notebook, mode DeleteMode) error {
+	return s.txm.Run(ctx, func(ctx context.Context) error {
+		return s.delete(ctx, nb, mode)
+	})
+}
+
+func (s Svc) delete(ctx context.Context, nb Notebook, mode DeleteMode) error {
+	// locks nb and reads the parent it has now, a concurrent Move waits for
+	// the delete
+	locked, err := s.repo.QueryByID(ctx, nb.ID)
+	if err != nil {
+		return fmt.Errorf("delete: [%s]: %w", nb.ID, err)
+	}
+	nb = locked
+
+	contents, err := s.QueryContents(ctx, nb)
+	if err != nil {
+		return fmt.Errorf("delete: [%s]: %w", nb.ID, err)
+	}
+
+	switch mode {
+	case DeleteRestrict:
+		if len(contents.Notebooks) > 0 || len(contents.Notes) > 0 {
+			return fmt.Errorf("delete: [%s]: %w", nb.ID, ErrNotebookNotEmpty)
+		}
+	case DeleteCascade:
+		for _, child := range contents.Notebooks {
+			if err := s.delete(ctx, child, DeleteCascade); err != nil {
+				return fmt.Errorf("delete: [%s]: %w", nb.ID, err)
+			}
+		}
+		for _, n := range contents.Notes {
+			if err := s.noteSvc.Delete(ctx, n.ID, 0); err != nil && !errors.Is(err, note.ErrNoteNotFound) {
+				return fmt.Errorf("delete: [%s]: %w", nb.ID, err)
+			}
+		}
+	case DeleteReparent:
+		for _, child := range contents.Notebooks {
+			child.ParentID = nb.ParentID
+			child.UpdatedAt = now()
+			if err := s.repo.Update(ctx, child); err != nil {
+				return fmt.Errorf("delete: [%s]: %w", nb.ID, err)
+			}
+		}
+		for _, n := range contents.Notes {
+			if err := s.repo.SetNotebook(ctx, n.ID, nb.ParentID); err != nil {
+				return fmt.Errorf("delete: [%s]: %w", nb.ID, err)
+			}
+		}
+	default:
+		return fmt.Errorf("delete: [%s]: %w", nb.ID, ErrInvalidDeleteMode)
+	}
+
+	if err := s.repo.Delete(ctx, nb.ID); err != nil {
+		return fmt.Errorf("delete: [%s]: %w", nb.ID, err)
+	}
+	return nil
+}
+
+// MoveNote puts n into the notebook notebookID, which has to belong to the
+// owner of n. A nil notebookID takes n out of its notebook.
+func (s Svc) MoveNote(ctx context.Context, n note.Note, notebookID *uuid.UUID) error {
+	if notebookID != nil {
+		if _, err := s.queryOwned(ctx, *notebookID, n.UserID); err != nil {
+			return fmt.Errorf("moveNote: [%s]: %w", n.ID, err)
+		}
+	}
+
+	if err := s.repo.SetNotebook(ctx, n.ID, notebookID); err != nil {
+		return fmt.Errorf("moveNote: [%s]: %w", n.ID, err)
+	}
+	return nil
+}
+
+// queryOwned returns the notebook if it belongs to userID. Notebooks of other
+// users are reported as not found.
+func (s Svc) queryOwned(ctx context.Context, notebookID, userID uuid.UUID) (Notebook, error) {
+	nb, err := s.repo.QueryByID(ctx, notebookID)
+	if err != nil {
+		return Notebook{}, err
+	}
+	if nb.UserID != userID {
+		return Notebook{}, ErrNotebookNotFound
+	}
+	return nb, nil
+}
+
+func validateName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxNameLength {
+		return "", ErrInvalidName
+	}
+	return name, nil
+}
+
+// now is truncated to the precision of Postgres timestamps, so that notebooks
+// compare equal after a round trip through the database.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
@@ -0,0 +1,228 @@
+package notebook_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	noteMemory "github.com/Keisn1/note-taking-app/domain/core/note/repositories/memory"
+	"github.com/Keisn1/note-taking-app/domain/core/notebook"
+	"github.com/Keisn1/note-taking-app/domain/core/notebook/repositories/memory"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func fixtureNotes() []note.Note {
+	return []note.Note{
+		{ID: uuid.UUID{1}, Title: note.NewTitle("robs 1st note"), Content: note.NewContent("robs 1st note content"), UserID: uuid.UUID{1}, Version: 1},
+		{ID: uuid.UUID{2}, Title: note.NewTitle("robs 2nd note"), Content: note.NewContent("robs 2nd note content"), UserID: uuid.UUID{1}, Version: 1},
+		{ID: uuid.UUID{3}, Title: note.NewTitle("annas 1st note"), Content: note.NewContent("annas 1st note content"), UserID: uuid.UUID{2}, Version: 1},
+	}
+}
+
+func setup() (notebook.Service, note.Service) {
+	txm := transaction.NewMemoryManager()
+	noteSvc := note.NewNotesService(noteMemory.MustNewRepo(fixtureNotes()), noteMemory.NewShareRepo(nil), noteMemory.NewRevisionRepo(nil), nil, txm)
+	return notebook.NewSvc(memory.NewRepo(nil), noteSvc, txm), noteSvc
+}
+
+func Test_Create(t *testing.T) {
+	ctx := context.Background()
+	robID, annaID := uuid.UUID{1}, uuid.UUID{2}
+
+	t.Run("Notebooks can be nested", func(t *testing.T) {
+		svc, _ := setup()
+		work, err := svc.Create(ctx, notebook.NewNotebook{UserID: robID, Name: " work "})
+		assert.NoError(t, err)
+		assert.Equal(t, "work", work.Name)
+		assert.Nil(t, work.ParentID)
+
+		projects, err := svc.Create(ctx, notebook.NewNotebook{UserID: robID, ParentID: &work.ID, Name: "projects"})
+		assert.NoError(t, err)
+		assert.Equal(t, &work.ID, projects.ParentID)
+
+		got, err := svc.QueryByUserID(ctx, robID)
+		assert.NoError(t, err)
+		assert.Equal(t, []notebook.Notebook{projects, work}, got)
+	})
+
+	t.Run("Notebooks can't be created inside notebooks of other users", func(t *testing.T) {
+		svc, _ := setup()
+		annas, err := svc.Create(ctx, notebook.NewNotebook{UserID: annaID, Name: "private"})
+		assert.NoError(t, err)
+
+		_, err = svc.Create(ctx, notebook.NewNotebook{UserID: robID, ParentID: &annas.ID, Name: "intruder"})
+		assert.ErrorIs(t, err, notebook.ErrNotebookNotFound)
+	})
+
+	t.Run("Names can't be empty", func(t *testing.T) {
+		svc, _ := setup()
+		_, err := svc.Create(ctx, notebook.NewNotebook{UserID: robID, Name: "  "})
+		assert.ErrorIs(t, err, notebook.ErrInvalidName)
+	})
+}
+
+func Test_Move(t *testing.T) {
+	ctx := context.Background()
+	robID := uuid.UUID{1}
+
+	svc, _ := setup()
+	work, err := svc.Create(ctx, notebook.NewNotebook{UserID: robID, Name: "work"})
+	assert.NoError(t, err)
+	projects, err := svc.Create(ctx, notebook.NewNotebook{UserID: robID, ParentID: &work.ID, Name: "projects"})
+	assert.NoError(t, err)
+	home, err := svc.Create(ctx, notebook.NewNotebook{UserID: robID, Name: "home"})
+	assert.NoError(t, err)
+
+	t.Run("A notebook can't be moved into itself or a nested notebook", func(t *testing.T) {
+		_, err := svc.Move(ctx, work, &work.ID)
+		assert.ErrorIs(t, err, notebook.ErrNotebookCycle)
+
+		_, err = svc.Move(ctx, work, &projects.ID)
+		assert.ErrorIs(t, err, notebook.ErrNotebookCycle)
+	})
+
+	t.Run("Notebooks can be moved to another parent and to the top level", func(t *testing.T) {
+		moved, err := svc.Move(ctx, projects, &home.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, &home.ID, moved.ParentID)
+
+		contents, err := svc.QueryContents(ctx, home)
+		assert.NoError(t, err)
+		assert.Equal(t, []notebook.Notebook{moved}, contents.Notebooks)
+
+		moved, err = svc.Move(ctx, moved, nil)
+		assert.NoError(t, err)
+		assert.Nil(t, moved.ParentID)
+	})
+}
+
+func Test_MoveNote(t *testing.T) {
+	ctx := context.Background()
+	robID := uuid.UUID{1}
+	notes := fixtureNotes()
+
+	svc, _ := setup()
+	work, err := svc.Create(ctx, notebook.NewNotebook{UserID: robID, Name: "work"})
+	assert.NoError(t, err)
+	home, err := svc.Create(ctx, notebook.NewNotebook{UserID: robID, Name: "home"})
+	assert.NoError(t, err)
+
+	t.Run("Notes are in one notebook at a time", func(t *testing.T) {
+		assert.NoError(t, svc.MoveNote(ctx, notes[0], &work.ID))
+		assert.NoError(t, svc.MoveNote(ctx, notes[1], &work.ID))
+		assert.NoError(t, svc.MoveNote(ctx, notes[1], &home.ID))
+
+		contents, err := svc.QueryContents(ctx, work)
+		assert.NoError(t, err)
+		assert.Equal(t, notes[:1], contents.Notes)
+
+		contents, err = svc.QueryContents(ctx, home)
+		assert.NoError(t, err)
+		assert.Equal(t, notes[1:2], contents.Notes)
+
+		assert.NoError(t, svc.MoveNote(ctx, notes[1], nil))
+		contents, err = svc.QueryContents(ctx, home)
+		assert.NoError(t, err)
+		assert.Empty(t, contents.Notes)
+	})
+
+	t.Run("Notes can only be moved into notebooks of their owner", func(t *testing.T) {
+		err := svc.MoveNote(ctx, notes[2], &work.ID)
+		assert.ErrorIs(t, err, notebook.ErrNotebookNotFound)
+	})
+}
+
+func Test_Delete(t *testing.T) {
+	ctx := context.Background()
+	robID := uuid.UUID{1}
+	notes := fixtureNotes()
+
+	// setupTree creates work with the nested notebook projects, each holding
+	// one note
+	setupTree := func(t *testing.T) (notebook.Service, note.Service, notebook.Notebook, notebook.Notebook) {
+		t.Helper()
+		svc, noteSvc := setup()
+		work, err := svc.Create(ctx, notebook.NewNotebook{UserID: robID, Name: "work"})
+		assert.NoError(t, err)
+		projects, err := svc.Create(ctx, notebook.NewNotebook{UserID: robID, ParentID: &work.ID, Name: "projects"})
+		assert.NoError(t, err)
+		assert.NoError(t, svc.MoveNote(ctx, notes[0], &work.ID))
+		assert.NoError(t, svc.MoveNote(ctx, notes[1], &projects.ID))
+		return svc, noteSvc, work, projects
+	}
+
+	t.Run("Restrict only deletes empty notebooks", func(t *testing.T) {
+		svc, _, work, projects := setupTree(t)
+
+		err := svc.Delete(ctx, work, notebook.DeleteRestrict)
+		assert.ErrorIs(t, err, notebook.ErrNotebookNotEmpty)
+
+		assert.NoError(t, svc.MoveNote(ctx, notes[1], nil))
+		assert.NoError(t, svc.Delete(ctx, projects, notebook.DeleteRestrict))
+
+		_, err = svc.QueryByID(ctx, projects.ID)
+		assert.ErrorIs(t, err, notebook.ErrNotebookNotFound)
+	})
+
+	t.Run("Cascade deletes nested notebooks and trashes the notes", func(t *testing.T) {
+		svc, noteSvc, work, _ := setupTree(t)
+
+		assert.NoError(t, svc.Delete(ctx, work, notebook.DeleteCascade))
+
+		got, err := svc.QueryByUserID(ctx, robID)
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+
+		trash, err := noteSvc.QueryTrash(ctx, robID)
+		assert.NoError(t, err)
+		assert.Len(t, trash, 2)
+	})
+
+	t.Run("Reparent moves the contents to the parent", func(t *testing.T) {
+		svc, noteSvc, work, projects := setupTree(t)
+		inbox, err := svc.Create(ctx, notebook.NewNotebook{UserID: robID, Name: "inbox"})
+		assert.NoError(t, err)
+		work, err = svc.Move(ctx, work, &inbox.ID)
+		assert.NoError(t, err)
+
+		assert.NoError(t, svc.Delete(ctx, work, notebook.DeleteReparent))
+
+		contents, err := svc.QueryContents(ctx, inbox)
+		assert.NoError(t, err)
+		assert.Len(t, contents.Notebooks, 1)
+		assert.Equal(t, projects.ID, contents.Notebooks[0].ID)
+		assert.Equal(t, notes[:1], contents.Notes)
+
+		trash, err := noteSvc.QueryTrash(ctx, robID)
+		assert.NoError(t, err)
+		assert.Empty(t, trash)
+	})
+
+	t.Run("Reparent moves the contents to the parent the notebook has now", func(t *testing.T) {
+		svc, _, work, projects := setupTree(t)
+		inbox, err := svc.Create(ctx, notebook.NewNotebook{UserID: robID, Name: "inbox"})
+		assert.NoError(t, err)
+		_, err = svc.Move(ctx, work, &inbox.ID)
+		assert.NoError(t, err)
+
+		// work was read before it was moved
+		assert.NoError(t, svc.Delete(ctx, work, notebook.DeleteReparent))
+
+		contents, err := svc.QueryContents(ctx, inbox)
+		assert.NoError(t, err)
+		assert.Len(t, contents.Notebooks, 1)
+		assert.Equal(t, projects.ID, contents.Notebooks[0].ID)
+		assert.Equal(t, notes[:1], contents.Notes)
+	})
+}
+
+func TestParseDeleteMode(t *testing.T) {
+	mode, err := notebook.ParseDeleteMode("cascade")
+	assert.NoError(t, err)
+	assert.Equal(t, notebook.DeleteCascade, mode)
+
+	_, err = notebook.ParseDeleteMode("drop")
+	assert.ErrorIs(t, err, notebook.ErrInvalidDeleteMode)
+}
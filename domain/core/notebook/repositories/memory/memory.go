@@ -0,0 +1,184 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/Keisn1/note-taking-app/domain/core/notebook"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/google/uuid"
+)
+
+type InMemoryRepo struct {
+	mu        *sync.Mutex
+	notebooks map[uuid.UUID]notebook.Notebook
+	// notes maps the id of a note to the notebook it is in
+	notes map[uuid.UUID]uuid.UUID
+}
+
+func NewRepo(notebooks []notebook.Notebook) InMemoryRepo {
+	nbs := make(map[uuid.UUID]notebook.Notebook)
+	for _, nb := range notebooks {
+		nbs[nb.ID] = nb
+	}
+	return InMemoryRepo{mu: &sync.Mutex{}, notebooks: nbs, notes: make(map[uuid.UUID]uuid.UUID)}
+}
+
+func (r InMemoryRepo) Create(ctx context.Context, nb notebook.Notebook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notebooks[nb.ID] = nb
+	transaction.OnRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.notebooks, nb.ID)
+	})
+	return nil
+}
+
+func (r InMemoryRepo) Update(ctx context.Context, nb notebook.Notebook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old, ok := r.notebooks[nb.ID]
+	if !ok {
+		return notebook.ErrNotebookNotFound
+	}
+	r.notebooks[nb.ID] = nb
+	transaction.OnRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.notebooks[nb.ID] = old
+	})
+	return nil
+}
+
+func (r InMemoryRepo) Delete(ctx context.Context, notebookID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	nb, ok := r.notebooks[notebookID]
+	if !ok {
+		return notebook.ErrNotebookNotFound
+	}
+	delete(r.notebooks, notebookID)
+	var noteIDs []uuid.UUID
+	for noteID, nbID := range r.notes {
+		if nbID == notebookID {
+			delete(r.notes, noteID)
+			noteIDs = append(noteIDs, noteID)
+		}
+	}
+	transaction.OnRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.notebooks[notebookID] = nb
+		for _, noteID := range noteIDs {
+			r.notes[noteID] = notebookID
+		}
+	})
+	return nil
+}
+
+func (r InMemoryRepo) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, nb := range r.notebooks {
+		if nb.UserID != userID {
+			continue
+		}
+		delete(r.notebooks, nb.ID)
+		var noteIDs []uuid.UUID
+		for noteID, nbID := range r.notes {
+			if nbID == nb.ID {
+				delete(r.notes, noteID)
+				noteIDs = append(noteIDs, noteID)
+			}
+		}
+		transaction.OnRollback(ctx, func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.notebooks[nb.ID] = nb
+			for _, noteID := range noteIDs {
+				r.notes[noteID] = nb.ID
+			}
+		})
+	}
+	return nil
+}
+
+func (r InMemoryRepo) QueryByID(ctx context.Context, notebookID uuid.UUID) (notebook.Notebook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	nb, ok := r.notebooks[notebookID]
+	if !ok {
+		return notebook.Notebook{}, notebook.ErrNotebookNotFound
+	}
+	return nb, nil
+}
+
+func (r InMemoryRepo) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]notebook.Notebook, error) {
+	return r.query(func(nb notebook.Notebook) bool { return nb.UserID == userID }), nil
+}
+
+func (r InMemoryRepo) QueryChildren(ctx context.Context, notebookID uuid.UUID) ([]notebook.Notebook, error) {
+	return r.query(func(nb notebook.Notebook) bool { return nb.ParentID != nil && *nb.ParentID == notebookID }), nil
+}
+
+func (r InMemoryRepo) SetNotebook(ctx context.Context, noteID uuid.UUID, notebookID *uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if notebookID != nil {
+		if _, ok := r.notebooks[*notebookID]; !ok {
+			return notebook.ErrNotebookNotFound
+		}
+	}
+
+	old, ok := r.notes[noteID]
+	if notebookID == nil {
+		delete(r.notes, noteID)
+	} else {
+		r.notes[noteID] = *notebookID
+	}
+	transaction.OnRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if ok {
+			r.notes[noteID] = old
+		} else {
+			delete(r.notes, noteID)
+		}
+	})
+	return nil
+}
+
+func (r InMemoryRepo) QueryNoteIDs(ctx context.Context, notebookID uuid.UUID) ([]uuid.UUID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ret := []uuid.UUID{}
+	for noteID, nbID := range r.notes {
+		if nbID == notebookID {
+			ret = append(ret, noteID)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].String() < ret[j].String() })
+	return ret, nil
+}
+
+// query returns the notebooks keep is true for, sorted by name.
+func (r InMemoryRepo) query(keep func(notebook.Notebook) bool) []notebook.Notebook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ret := []notebook.Notebook{}
+	for _, nb := range r.notebooks {
+		if keep(nb) {
+			ret = append(ret, nb)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Name != ret[j].Name {
+			return ret[i].Name < ret[j].Name
+		}
+		return ret[i].ID.String() < ret[j].ID.String()
+	})
+	return ret
+}
@@ -0,0 +1,226 @@
+package notebookdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/notebook"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/google/uuid"
+)
+
+type DBNotebook struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	ParentID  uuid.NullUUID
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type database interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+type NotebookRepo struct {
+	db database
+}
+
+func NewNotebookRepo(db database) NotebookRepo {
+	return NotebookRepo{db: db}
+}
+
+// conn returns the transaction carried by ctx, see transaction.Manager.
+func (r NotebookRepo) conn(ctx context.Context) transaction.Querier {
+	return transaction.DB(ctx, r.db)
+}
+
+func (r NotebookRepo) Create(ctx context.Context, nb notebook.Notebook) error {
+	insertRow := `
+	INSERT INTO notebooks (id, user_id, parent_id, name, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err := r.conn(ctx).ExecContext(ctx, insertRow, nb.ID, nb.UserID, nb.ParentID, nb.Name, nb.CreatedAt, nb.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("create: [%s]: %w", nb.ID, err)
+	}
+	return nil
+}
+
+func (r NotebookRepo) Update(ctx context.Context, nb notebook.Notebook) error {
+	updateRow := `UPDATE notebooks SET parent_id = $1, name = $2, updated_at = $3 WHERE id = $4`
+
+	res, err := r.conn(ctx).ExecContext(ctx, updateRow, nb.ParentID, nb.Name, nb.UpdatedAt, nb.ID)
+	if err != nil {
+		return fmt.Errorf("update: [%s]: %w", nb.ID, err)
+	}
+	if c, _ := res.RowsAffected(); c == 0 {
+		return notebook.ErrNotebookNotFound
+	}
+	return nil
+}
+
+// Delete relies on the foreign key of notebook_notes to take the notes out of
+// the notebook. The one of parent_id keeps notebooks with nested notebooks
+// from being deleted.
+func (r NotebookRepo) Delete(ctx context.Context, notebookID uuid.UUID) error {
+	res, err := r.conn(ctx).ExecContext(ctx, `DELETE FROM notebooks WHERE id = $1`, notebookID)
+	if err != nil {
+		return fmt.Errorf("delete: [%s]: %w", notebookID, err)
+	}
+	if c, _ := res.RowsAffected(); c == 0 {
+		return notebook.ErrNotebookNotFound
+	}
+	return nil
+}
+
+// DeleteByUserID relies on the foreign key of notebook_notes like Delete. The
+// one of parent_id is only checked at the end of the statement, which deletes
+// the nested notebooks as well.
+func (r NotebookRepo) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.conn(ctx).ExecContext(ctx, `DELETE FROM notebooks WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("deleteByUserID: [%s]: %w", userID, err)
+	}
+	return nil
+}
+
+// QueryByID locks the notebook inside a transaction, so that a concurrent
+// Move or Delete of the notebook service waits for it.
+func (r NotebookRepo) QueryByID(ctx context.Context, notebookID uuid.UUID) (notebook.Notebook, error) {
+	queryByID := `
+	SELECT id, user_id, parent_id, name, created_at, updated_at FROM notebooks WHERE id = $1`
+	if _, ok := transaction.FromContext(ctx); ok {
+		queryByID += ` FOR UPDATE`
+	}
+	row := r.conn(ctx).QueryRowContext(ctx, queryByID, notebookID)
+
+	var dbNB DBNotebook
+	err := row.Scan(&dbNB.ID, &dbNB.UserID, &dbNB.ParentID, &dbNB.Name, &dbNB.CreatedAt, &dbNB.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return notebook.Notebook{}, notebook.ErrNotebookNotFound
+		}
+		return notebook.Notebook{}, fmt.Errorf("queryByID: [%s]: %w", notebookID, err)
+	}
+
+	return dbNotebookToNotebook(dbNB), nil
+}
+
+func (r NotebookRepo) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]notebook.Notebook, error) {
+	queryByUserID := `
+	SELECT id, user_id, parent_id, name, created_at, updated_at FROM notebooks
+	WHERE user_id = $1 ORDER BY name COLLATE "C", id;
+	`
+	notebooks, err := r.query(ctx, queryByUserID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("queryByUserID: [%s]: %w", userID, err)
+	}
+	return notebooks, nil
+}
+
+func (r NotebookRepo) QueryChildren(ctx context.Context, notebookID uuid.UUID) ([]notebook.Notebook, error) {
+	queryChildren := `
+	SELECT id, user_id, parent_id, name, created_at, updated_at FROM notebooks
+	WHERE parent_id = $1 ORDER BY name COLLATE "C", id;
+	`
+	notebooks, err := r.query(ctx, queryChildren, notebookID)
+	if err != nil {
+		return nil, fmt.Errorf("queryChildren: [%s]: %w", notebookID, err)
+	}
+	return notebooks, nil
+}
+
+// SetNotebook increments the version of the note it moves, so that its ETag
+// changes with it.
+func (r NotebookRepo) SetNotebook(ctx context.Context, noteID uuid.UUID, notebookID *uuid.UUID) error {
+	if notebookID == nil {
+		remove := `
+		WITH removed AS (
+			DELETE FROM notebook_notes WHERE note_id = $1 RETURNING note_id
+		)
+		UPDATE notes SET version = version + 1 WHERE id IN (SELECT note_id FROM removed)`
+
+		if _, err := r.conn(ctx).ExecContext(ctx, remove, noteID); err != nil {
+			return fmt.Errorf("setNotebook: [%s]: %w", noteID, err)
+		}
+		return nil
+	}
+
+	upsert := `
+	WITH moved AS (
+		INSERT INTO notebook_notes (note_id, notebook_id) VALUES ($1, $2)
+		ON CONFLICT (note_id) DO UPDATE SET notebook_id = EXCLUDED.notebook_id
+		RETURNING note_id
+	)
+	UPDATE notes SET version = version + 1 WHERE id IN (SELECT note_id FROM moved)`
+
+	if _, err := r.conn(ctx).ExecContext(ctx, upsert, noteID, *notebookID); err != nil {
+		return fmt.Errorf("setNotebook: [%s]: %w", noteID, err)
+	}
+	return nil
+}
+
+func (r NotebookRepo) QueryNoteIDs(ctx context.Context, notebookID uuid.UUID) ([]uuid.UUID, error) {
+	queryNoteIDs := `SELECT note_id FROM notebook_notes WHERE notebook_id = $1 ORDER BY note_id`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, queryNoteIDs, notebookID)
+	if err != nil {
+		return nil, fmt.Errorf("queryNoteIDs: [%s]: %w", notebookID, err)
+	}
+	defer rows.Close()
+
+	ret := []uuid.UUID{}
+	for rows.Next() {
+		var noteID uuid.UUID
+		if err := rows.Scan(&noteID); err != nil {
+			return nil, fmt.Errorf("queryNoteIDs: [%s]: scan rows: %w", notebookID, err)
+		}
+		ret = append(ret, noteID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("queryNoteIDs: [%s]: %w", notebookID, err)
+	}
+	return ret, nil
+}
+
+func (r NotebookRepo) query(ctx context.Context, query string, args ...any) ([]notebook.Notebook, error) {
+	rows, err := r.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ret := []notebook.Notebook{}
+	for rows.Next() {
+		var dbNB DBNotebook
+		err := rows.Scan(&dbNB.ID, &dbNB.UserID, &dbNB.ParentID, &dbNB.Name, &dbNB.CreatedAt, &dbNB.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan rows: %w", err)
+		}
+		ret = append(ret, dbNotebookToNotebook(dbNB))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func dbNotebookToNotebook(dbNB DBNotebook) notebook.Notebook {
+	nb := notebook.Notebook{
+		ID:        dbNB.ID,
+		UserID:    dbNB.UserID,
+		Name:      dbNB.Name,
+		CreatedAt: dbNB.CreatedAt.UTC(),
+		UpdatedAt: dbNB.UpdatedAt.UTC(),
+	}
+	if dbNB.ParentID.Valid {
+		nb.ParentID = &dbNB.ParentID.UUID
+	}
+	return nb
+}
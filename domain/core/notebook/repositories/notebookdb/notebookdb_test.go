@@ -0,0 +1,142 @@
+package notebookdb_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/notebook"
+	"github.com/Keisn1/note-taking-app/domain/core/notebook/repositories/notebookdb"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/google/uuid"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	exitCode := run(m)
+	os.Exit(exitCode)
+}
+
+func TestNotebookRepo(t *testing.T) {
+	noteIDs := []uuid.UUID{{1}, {2}}
+	testDB, deleteTable := SetupNotebooksTable(t, noteIDs)
+	defer deleteTable()
+	r := notebookdb.NewNotebookRepo(testDB)
+	ctx := context.Background()
+
+	userID := uuid.UUID{1}
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	work := notebook.Notebook{ID: uuid.UUID{10}, UserID: userID, Name: "work", CreatedAt: createdAt, UpdatedAt: createdAt}
+	projects := notebook.Notebook{ID: uuid.UUID{11}, UserID: userID, ParentID: &work.ID, Name: "projects", CreatedAt: createdAt, UpdatedAt: createdAt}
+
+	t.Run("Notebooks are stored with their parent", func(t *testing.T) {
+		assert.NoError(t, r.Create(ctx, work))
+		assert.NoError(t, r.Create(ctx, projects))
+
+		got, err := r.QueryByID(ctx, projects.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, projects, got)
+
+		notebooks, err := r.QueryByUserID(ctx, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, []notebook.Notebook{projects, work}, notebooks)
+
+		children, err := r.QueryChildren(ctx, work.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []notebook.Notebook{projects}, children)
+	})
+
+	version := func(t *testing.T, noteID uuid.UUID) int {
+		t.Helper()
+		var v int
+		assert.NoError(t, testDB.QueryRowContext(ctx, `SELECT version FROM notes WHERE id = $1`, noteID).Scan(&v))
+		return v
+	}
+
+	t.Run("Notes are in one notebook at a time", func(t *testing.T) {
+		before := version(t, noteIDs[1])
+		assert.NoError(t, r.SetNotebook(ctx, noteIDs[0], &work.ID))
+		assert.NoError(t, r.SetNotebook(ctx, noteIDs[1], &work.ID))
+		assert.NoError(t, r.SetNotebook(ctx, noteIDs[1], &projects.ID))
+
+		got, err := r.QueryNoteIDs(ctx, work.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, noteIDs[:1], got)
+
+		assert.NoError(t, r.SetNotebook(ctx, noteIDs[1], nil))
+		got, err = r.QueryNoteIDs(ctx, projects.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+
+		// moving a note changes its version
+		assert.Equal(t, before+3, version(t, noteIDs[1]))
+	})
+
+	t.Run("Notebooks with nested notebooks can't be deleted", func(t *testing.T) {
+		assert.Error(t, r.Delete(ctx, work.ID))
+
+		projects.ParentID = nil
+		projects.UpdatedAt = createdAt.Add(time.Hour)
+		assert.NoError(t, r.Update(ctx, projects))
+
+		assert.NoError(t, r.Delete(ctx, work.ID))
+		_, err := r.QueryByID(ctx, work.ID)
+		assert.ErrorIs(t, err, notebook.ErrNotebookNotFound)
+
+		got, err := r.QueryNoteIDs(ctx, work.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+
+		err = r.Delete(ctx, work.ID)
+		assert.ErrorIs(t, err, notebook.ErrNotebookNotFound)
+	})
+
+	t.Run("DeleteByUserID deletes nested notebooks as well", func(t *testing.T) {
+		parent := notebook.Notebook{ID: uuid.UUID{12}, UserID: userID, Name: "parent", CreatedAt: createdAt, UpdatedAt: createdAt}
+		child := notebook.Notebook{ID: uuid.UUID{13}, UserID: userID, ParentID: &parent.ID, Name: "child", CreatedAt: createdAt, UpdatedAt: createdAt}
+		other := notebook.Notebook{ID: uuid.UUID{14}, UserID: uuid.UUID{2}, Name: "other", CreatedAt: createdAt, UpdatedAt: createdAt}
+		for _, nb := range []notebook.Notebook{parent, child, other} {
+			assert.NoError(t, r.Create(ctx, nb))
+		}
+		assert.NoError(t, r.SetNotebook(ctx, noteIDs[0], &child.ID))
+
+		assert.NoError(t, r.DeleteByUserID(ctx, userID))
+
+		notebooks, err := r.QueryByUserID(ctx, userID)
+		assert.NoError(t, err)
+		assert.Empty(t, notebooks)
+		got, err := r.QueryNoteIDs(ctx, child.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+
+		notebooks, err = r.QueryByUserID(ctx, other.UserID)
+		assert.NoError(t, err)
+		assert.Equal(t, []notebook.Notebook{other}, notebooks)
+	})
+
+	t.Run("Changes are undone with the transaction of the context", func(t *testing.T) {
+		inbox := notebook.Notebook{ID: uuid.UUID{15}, UserID: userID, Name: "inbox", CreatedAt: createdAt, UpdatedAt: createdAt}
+		assert.NoError(t, r.Create(ctx, inbox))
+
+		errRollback := errors.New("rollback")
+		err := transaction.NewSQLManager(testDB).Run(ctx, func(ctx context.Context) error {
+			got, err := r.QueryByID(ctx, inbox.ID)
+			assert.NoError(t, err)
+			got.Name = "renamed"
+			assert.NoError(t, r.Update(ctx, got))
+			assert.NoError(t, r.SetNotebook(ctx, noteIDs[1], &inbox.ID))
+			return errRollback
+		})
+		assert.ErrorIs(t, err, errRollback)
+
+		got, err := r.QueryByID(ctx, inbox.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, inbox, got)
+		inInbox, err := r.QueryNoteIDs(ctx, inbox.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, inInbox)
+	})
+}
@@ -0,0 +1,88 @@
+package notebookdb_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/Keisn1/note-taking-app/domain/data/schema"
+	"github.com/google/uuid"
+)
+
+const (
+	testDBName   = "test_note_taking_app_notebooks"
+	testUser     = "postgres"
+	testPassword = "password"
+)
+
+func run(m *testing.M) int {
+	var (
+		dropDB   = fmt.Sprintf(`DROP DATABASE IF EXISTS %s;`, testDBName)
+		createDB = fmt.Sprintf(`CREATE DATABASE %s;`, testDBName)
+	)
+
+	dsn := fmt.Sprintf("host=localhost port=5432 user=%s password=%s sslmode=disable", testUser, testPassword)
+	postgresDB, err := sql.Open("pgx", dsn)
+	if err != nil {
+		panic(err)
+	}
+	defer postgresDB.Close()
+
+	_, err = postgresDB.Exec(dropDB)
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = postgresDB.Exec(createDB)
+	if err != nil {
+		panic(err)
+	}
+
+	defer func() {
+		_, err = postgresDB.Exec(dropDB)
+		if err != nil {
+			panic(fmt.Errorf("postgresDB.Exec() err = %s", err))
+		}
+	}()
+
+	return m.Run()
+}
+
+// SetupNotebooksTable migrates the test database and inserts the notes the
+// tests put into notebooks.
+func SetupNotebooksTable(t *testing.T, noteIDs []uuid.UUID) (*sql.DB, func()) {
+	dsn := fmt.Sprintf("host=localhost port=5432 user=%s password=%s sslmode=disable dbname=%s ", testUser, testPassword, testDBName)
+	testDB, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrator, err := schema.NewMigrator(testDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = migrator.Up(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	insertRow := `INSERT INTO notes (id, title, content, user_id) VALUES ($1, '', '', $2)`
+	for _, noteID := range noteIDs {
+		_, err = testDB.Exec(insertRow, noteID, uuid.New())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deleteTable := func() {
+		err := migrator.Reset(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		testDB.Close()
+	}
+
+	return testDB, deleteTable
+}
@@ -0,0 +1,31 @@
+package notebook
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var ErrNotebookNotFound = errors.New("notebook not found")
+
+type Repo interface {
+	Create(ctx context.Context, nb Notebook) error
+	// Update stores name, parent and UpdatedAt of nb.
+	Update(ctx context.Context, nb Notebook) error
+	// Delete deletes the notebook and takes the notes out of it. Nested
+	// notebooks have to be deleted or moved beforehand.
+	Delete(ctx context.Context, notebookID uuid.UUID) error
+	QueryByID(ctx context.Context, notebookID uuid.UUID) (Notebook, error)
+	QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Notebook, error)
+	// DeleteByUserID deletes all notebooks of userID and takes the notes out
+	// of them. It runs in the transaction carried by ctx.
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
+	// QueryChildren returns the notebooks directly inside notebookID.
+	QueryChildren(ctx context.Context, notebookID uuid.UUID) ([]Notebook, error)
+	// SetNotebook moves noteID into notebookID and increments the version of
+	// the note, if the repo stores it as well. A nil notebookID takes the
+	// note out of its notebook.
+	SetNotebook(ctx context.Context, noteID uuid.UUID, notebookID *uuid.UUID) error
+	QueryNoteIDs(ctx context.Context, notebookID uuid.UUID) ([]uuid.UUID, error)
+}
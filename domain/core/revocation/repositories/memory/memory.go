@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/google/uuid"
+)
+
+type InMemoryRepo struct {
+	mu     *sync.RWMutex
+	tokens map[string]time.Time
+	users  map[uuid.UUID]time.Time
+}
+
+func NewRepo() InMemoryRepo {
+	return InMemoryRepo{
+		mu:     &sync.RWMutex{},
+		tokens: make(map[string]time.Time),
+		users:  make(map[uuid.UUID]time.Time),
+	}
+}
+
+func (r InMemoryRepo) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens[jti] = expiresAt
+	return nil
+}
+
+func (r InMemoryRepo) RevokeUser(ctx context.Context, userID uuid.UUID, before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old, ok := r.users[userID]
+	if !before.After(old) {
+		return nil
+	}
+	r.users[userID] = before
+	transaction.OnRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if ok {
+			r.users[userID] = old
+		} else {
+			delete(r.users, userID)
+		}
+	})
+	return nil
+}
+
+func (r InMemoryRepo) IsRevoked(ctx context.Context, jti string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.tokens[jti]; ok {
+		return true, nil
+	}
+	before, ok := r.users[userID]
+	return ok && issuedAt.Before(before), nil
+}
+
+func (r InMemoryRepo) PurgeExpired(ctx context.Context, before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int
+	for jti, expiresAt := range r.tokens {
+		if expiresAt.Before(before) {
+			delete(r.tokens, jti)
+			purged++
+		}
+	}
+	return purged, nil
+}
@@ -0,0 +1,83 @@
+package revocationdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/google/uuid"
+)
+
+type database interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+type RevocationRepo struct {
+	db database
+}
+
+func NewRevocationRepo(db database) RevocationRepo {
+	return RevocationRepo{db: db}
+}
+
+// conn returns the transaction carried by ctx, see transaction.Manager.
+func (r RevocationRepo) conn(ctx context.Context) database {
+	if tx, ok := transaction.FromContext(ctx); ok {
+		return tx
+	}
+	return r.db
+}
+
+func (r RevocationRepo) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	insertRow := `
+	INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1, $2)
+	ON CONFLICT (jti) DO NOTHING`
+
+	_, err := r.db.ExecContext(ctx, insertRow, jti, expiresAt)
+	if err != nil {
+		return fmt.Errorf("revokeToken: [%s]: %w", jti, err)
+	}
+	return nil
+}
+
+func (r RevocationRepo) RevokeUser(ctx context.Context, userID uuid.UUID, before time.Time) error {
+	upsertRow := `
+	INSERT INTO user_revocations (user_id, revoked_before) VALUES ($1, $2)
+	ON CONFLICT (user_id)
+	DO UPDATE SET revoked_before = GREATEST(user_revocations.revoked_before, EXCLUDED.revoked_before)`
+
+	_, err := r.conn(ctx).ExecContext(ctx, upsertRow, userID, before)
+	if err != nil {
+		return fmt.Errorf("revokeUser: [%s]: %w", userID, err)
+	}
+	return nil
+}
+
+func (r RevocationRepo) IsRevoked(ctx context.Context, jti string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	isRevoked := `
+	SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)
+	    OR EXISTS (SELECT 1 FROM user_revocations WHERE user_id = $2 AND revoked_before > $3)`
+
+	var revoked bool
+	err := r.db.QueryRowContext(ctx, isRevoked, jti, userID, issuedAt).Scan(&revoked)
+	if err != nil {
+		return false, fmt.Errorf("isRevoked: [%s]: %w", jti, err)
+	}
+	return revoked, nil
+}
+
+func (r RevocationRepo) PurgeExpired(ctx context.Context, before time.Time) (int, error) {
+	purge := `DELETE FROM revoked_tokens WHERE expires_at < $1`
+	res, err := r.db.ExecContext(ctx, purge, before)
+	if err != nil {
+		return 0, fmt.Errorf("purgeExpired: %w", err)
+	}
+	c, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("purgeExpired: %w", err)
+	}
+	return int(c), nil
+}
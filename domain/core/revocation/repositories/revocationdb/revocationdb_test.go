@@ -0,0 +1,119 @@
+package revocationdb_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/revocation/repositories/revocationdb"
+	"github.com/google/uuid"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	exitCode := run(m)
+	os.Exit(exitCode)
+}
+
+func TestRevocationRepo_RevokeToken(t *testing.T) {
+	testDB, deleteTables := SetupRevocationTables(t)
+	defer deleteTables()
+	r := revocationdb.NewRevocationRepo(testDB)
+	ctx := context.Background()
+	userID := uuid.New()
+	expiresAt := time.Now().Add(time.Hour)
+
+	err := r.RevokeToken(ctx, "jti 1", expiresAt)
+	assert.NoError(t, err)
+
+	// revoking twice is fine
+	err = r.RevokeToken(ctx, "jti 1", expiresAt)
+	assert.NoError(t, err)
+
+	revoked, err := r.IsRevoked(ctx, "jti 1", userID, time.Now())
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	revoked, err = r.IsRevoked(ctx, "jti 2", userID, time.Now())
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	t.Run("Forwards error on database error", func(t *testing.T) {
+		r := revocationdb.NewRevocationRepo(&stubSQLDB{})
+		err := r.RevokeToken(ctx, "jti 1", expiresAt)
+		assert.EqualError(t, err, "revokeToken: [jti 1]: DBError")
+	})
+}
+
+func TestRevocationRepo_PurgeExpired(t *testing.T) {
+	testDB, deleteTables := SetupRevocationTables(t)
+	defer deleteTables()
+	r := revocationdb.NewRevocationRepo(testDB)
+	ctx := context.Background()
+	now := time.Now()
+
+	assert.NoError(t, r.RevokeToken(ctx, "expired", now.Add(-time.Hour)))
+	assert.NoError(t, r.RevokeToken(ctx, "valid", now.Add(time.Hour)))
+
+	purged, err := r.PurgeExpired(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	revoked, err := r.IsRevoked(ctx, "valid", uuid.New(), now)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	t.Run("Forwards error on database error", func(t *testing.T) {
+		r := revocationdb.NewRevocationRepo(&stubSQLDB{})
+		_, err := r.PurgeExpired(ctx, now)
+		assert.EqualError(t, err, "purgeExpired: DBError")
+	})
+}
+
+func TestRevocationRepo_RevokeUser(t *testing.T) {
+	testDB, deleteTables := SetupRevocationTables(t)
+	defer deleteTables()
+	r := revocationdb.NewRevocationRepo(testDB)
+	ctx := context.Background()
+	userID := uuid.New()
+	cutoff := time.Now().Truncate(time.Second)
+
+	err := r.RevokeUser(ctx, userID, cutoff)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		userID   uuid.UUID
+		issuedAt time.Time
+		want     bool
+	}{
+		{name: "issued before cutoff", userID: userID, issuedAt: cutoff.Add(-time.Second), want: true},
+		{name: "issued after cutoff", userID: userID, issuedAt: cutoff.Add(time.Second), want: false},
+		{name: "other user", userID: uuid.New(), issuedAt: cutoff.Add(-time.Second), want: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			revoked, err := r.IsRevoked(ctx, "jti", tc.userID, tc.issuedAt)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, revoked)
+		})
+	}
+
+	t.Run("Cutoff never moves backwards", func(t *testing.T) {
+		err := r.RevokeUser(ctx, userID, cutoff.Add(-time.Hour))
+		assert.NoError(t, err)
+
+		revoked, err := r.IsRevoked(ctx, "jti", userID, cutoff.Add(-time.Second))
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("Forwards error on database error", func(t *testing.T) {
+		r := revocationdb.NewRevocationRepo(&stubSQLDB{})
+		err := r.RevokeUser(ctx, userID, cutoff)
+		assert.EqualError(t, err, fmt.Sprintf("revokeUser: [%s]: DBError", userID))
+	})
+}
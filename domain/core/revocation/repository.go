@@ -0,0 +1,24 @@
+// Package revocation keeps track of access tokens that must no longer be
+// accepted although they haven't expired yet.
+package revocation
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Repo interface {
+	// RevokeToken denylists the token with jti. The entry is only needed until
+	// expiresAt, after that the token is rejected anyway.
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	// RevokeUser revokes every token of userID issued before before. Later
+	// calls can only move the cutoff forward. It runs in the transaction
+	// carried by ctx, see transaction.Manager.
+	RevokeUser(ctx context.Context, userID uuid.UUID, before time.Time) error
+	IsRevoked(ctx context.Context, jti string, userID uuid.UUID, issuedAt time.Time) (bool, error)
+	// PurgeExpired deletes the denylisted tokens that expired before before
+	// and returns how many there were.
+	PurgeExpired(ctx context.Context, before time.Time) (int, error)
+}
@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/session"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/google/uuid"
+)
+
+type InMemoryRepo struct {
+	tokens map[uuid.UUID]session.RefreshToken
+}
+
+func NewRepo(tokens []session.RefreshToken) InMemoryRepo {
+	ts := make(map[uuid.UUID]session.RefreshToken)
+	for _, rt := range tokens {
+		ts[rt.ID] = rt
+	}
+	return InMemoryRepo{tokens: ts}
+}
+
+func (r InMemoryRepo) Create(ctx context.Context, rt session.RefreshToken) error {
+	r.tokens[rt.ID] = rt
+	transaction.OnRollback(ctx, func() { delete(r.tokens, rt.ID) })
+	return nil
+}
+
+func (r InMemoryRepo) QueryByHash(ctx context.Context, tokenHash []byte) (session.RefreshToken, error) {
+	for _, rt := range r.tokens {
+		if bytes.Equal(rt.TokenHash, tokenHash) {
+			return rt, nil
+		}
+	}
+	return session.RefreshToken{}, session.ErrTokenNotFound
+}
+
+func (r InMemoryRepo) MarkUsed(ctx context.Context, tokenID uuid.UUID, at time.Time) error {
+	rt, ok := r.tokens[tokenID]
+	if !ok {
+		return session.ErrTokenNotFound
+	}
+	if rt.UsedAt != nil {
+		return session.ErrTokenAlreadyUsed
+	}
+	rt.UsedAt = &at
+	r.tokens[tokenID] = rt
+	transaction.OnRollback(ctx, func() {
+		rt.UsedAt = nil
+		r.tokens[tokenID] = rt
+	})
+	return nil
+}
+
+func (r InMemoryRepo) RevokeFamily(ctx context.Context, familyID uuid.UUID, at time.Time) error {
+	for id, rt := range r.tokens {
+		if rt.FamilyID == familyID && rt.RevokedAt == nil {
+			rt.RevokedAt = &at
+			r.tokens[id] = rt
+		}
+	}
+	return nil
+}
+
+func (r InMemoryRepo) RevokeByUserID(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	for id, rt := range r.tokens {
+		if rt.UserID == userID && rt.RevokedAt == nil {
+			rt.RevokedAt = &at
+			r.tokens[id] = rt
+		}
+	}
+	return nil
+}
+
+func (r InMemoryRepo) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	for id, rt := range r.tokens {
+		if rt.UserID == userID {
+			delete(r.tokens, id)
+			transaction.OnRollback(ctx, func() { r.tokens[id] = rt })
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package sessiondb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/session"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/google/uuid"
+)
+
+type DBRefreshToken struct {
+	ID        uuid.UUID
+	FamilyID  uuid.UUID
+	UserID    uuid.UUID
+	TokenHash []byte
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
+	RevokedAt sql.NullTime
+}
+
+type database interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+type RefreshTokenRepo struct {
+	db database
+}
+
+func NewRefreshTokenRepo(db database) RefreshTokenRepo {
+	return RefreshTokenRepo{db: db}
+}
+
+// conn returns the transaction carried by ctx, see transaction.Manager.
+func (r RefreshTokenRepo) conn(ctx context.Context) transaction.Querier {
+	return transaction.DB(ctx, r.db)
+}
+
+func (r RefreshTokenRepo) Create(ctx context.Context, rt session.RefreshToken) error {
+	insertRow := `
+	INSERT INTO refresh_tokens (id, family_id, user_id, token_hash, expires_at)
+	VALUES ($1, $2, $3, $4, $5)`
+
+	_, err := r.conn(ctx).ExecContext(ctx, insertRow, rt.ID, rt.FamilyID, rt.UserID, rt.TokenHash, rt.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("create: [%s]: %w", rt.ID, err)
+	}
+	return nil
+}
+
+// QueryByHash locks the token inside a transaction, so that a concurrent
+// rotation or RevokeFamily waits for it.
+func (r RefreshTokenRepo) QueryByHash(ctx context.Context, tokenHash []byte) (session.RefreshToken, error) {
+	queryByHash := `
+	SELECT id, family_id, user_id, token_hash, expires_at, used_at, revoked_at
+	FROM refresh_tokens WHERE token_hash=$1`
+	if _, ok := transaction.FromContext(ctx); ok {
+		queryByHash += ` FOR UPDATE`
+	}
+	row := r.conn(ctx).QueryRowContext(ctx, queryByHash, tokenHash)
+
+	var dbRT DBRefreshToken
+	err := row.Scan(&dbRT.ID, &dbRT.FamilyID, &dbRT.UserID, &dbRT.TokenHash, &dbRT.ExpiresAt, &dbRT.UsedAt, &dbRT.RevokedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return session.RefreshToken{}, session.ErrTokenNotFound
+		}
+		return session.RefreshToken{}, fmt.Errorf("queryByHash: %w", err)
+	}
+
+	return dbRefreshTokenToRefreshToken(dbRT), nil
+}
+
+func (r RefreshTokenRepo) MarkUsed(ctx context.Context, tokenID uuid.UUID, at time.Time) error {
+	// the used_at IS NULL condition makes concurrent rotations of the same
+	// token fail for all but one of them
+	markUsed := `UPDATE refresh_tokens SET used_at = $1 WHERE id = $2 AND used_at IS NULL`
+
+	res, err := r.conn(ctx).ExecContext(ctx, markUsed, at, tokenID)
+	if err != nil {
+		return fmt.Errorf("markUsed: [%s]: %w", tokenID, err)
+	}
+	if c, _ := res.RowsAffected(); c == 0 {
+		return session.ErrTokenAlreadyUsed
+	}
+	return nil
+}
+
+// RevokeFamily first locks the tokens of the family, which waits for the
+// rotations holding one of them. The update then sees the tokens they
+// created, which a single statement started before their commit would miss.
+func (r RefreshTokenRepo) RevokeFamily(ctx context.Context, familyID uuid.UUID, at time.Time) error {
+	err := transaction.NewSQLManager(r.db).Run(ctx, func(ctx context.Context) error {
+		lock := `SELECT id FROM refresh_tokens WHERE family_id = $1 FOR UPDATE`
+		rows, err := r.conn(ctx).QueryContext(ctx, lock, familyID)
+		if err != nil {
+			return err
+		}
+		if err := rows.Close(); err != nil {
+			return err
+		}
+
+		revoke := `UPDATE refresh_tokens SET revoked_at = $1 WHERE family_id = $2 AND revoked_at IS NULL`
+		_, err = r.conn(ctx).ExecContext(ctx, revoke, at, familyID)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("revokeFamily: [%s]: %w", familyID, err)
+	}
+	return nil
+}
+
+func (r RefreshTokenRepo) RevokeByUserID(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	revoke := `UPDATE refresh_tokens SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL`
+
+	_, err := r.conn(ctx).ExecContext(ctx, revoke, at, userID)
+	if err != nil {
+		return fmt.Errorf("revokeByUserID: [%s]: %w", userID, err)
+	}
+	return nil
+}
+
+func dbRefreshTokenToRefreshToken(dbRT DBRefreshToken) session.RefreshToken {
+	rt := session.RefreshToken{
+		ID:        dbRT.ID,
+		FamilyID:  dbRT.FamilyID,
+		UserID:    dbRT.UserID,
+		TokenHash: dbRT.TokenHash,
+		ExpiresAt: dbRT.ExpiresAt,
+	}
+	if dbRT.UsedAt.Valid {
+		rt.UsedAt = &dbRT.UsedAt.Time
+	}
+	if dbRT.RevokedAt.Valid {
+		rt.RevokedAt = &dbRT.RevokedAt.Time
+	}
+	return rt
+}
+
+func (r RefreshTokenRepo) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	deleteRows := `DELETE FROM refresh_tokens WHERE user_id = $1`
+
+	_, err := r.conn(ctx).ExecContext(ctx, deleteRows, userID)
+	if err != nil {
+		return fmt.Errorf("deleteByUserID: [%s]: %w", userID, err)
+	}
+	return nil
+}
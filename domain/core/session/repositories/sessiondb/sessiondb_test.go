@@ -0,0 +1,157 @@
+package sessiondb_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/session"
+	"github.com/Keisn1/note-taking-app/domain/core/session/repositories/sessiondb"
+	"github.com/google/uuid"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	exitCode := run(m)
+	os.Exit(exitCode)
+}
+
+func TestRefreshTokenRepo_CreateAndQuery(t *testing.T) {
+	testDB, deleteTable := SetupRefreshTokensTable(t, fixtureRefreshTokens())
+	defer deleteTable()
+	r := sessiondb.NewRefreshTokenRepo(testDB)
+	ctx := context.Background()
+
+	t.Run("Create and query by hash", func(t *testing.T) {
+		rt := session.RefreshToken{
+			ID:        uuid.New(),
+			FamilyID:  uuid.New(),
+			UserID:    uuid.New(),
+			TokenHash: []byte("new hash"),
+			ExpiresAt: time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+
+		err := r.Create(ctx, rt)
+		assert.NoError(t, err)
+
+		got, err := r.QueryByHash(ctx, rt.TokenHash)
+		assert.NoError(t, err)
+		assert.Equal(t, rt.ID, got.ID)
+		assert.Equal(t, rt.FamilyID, got.FamilyID)
+		assert.Equal(t, rt.UserID, got.UserID)
+		assert.True(t, rt.ExpiresAt.Equal(got.ExpiresAt))
+		assert.Nil(t, got.UsedAt)
+		assert.Nil(t, got.RevokedAt)
+	})
+
+	t.Run("Token not found", func(t *testing.T) {
+		_, err := r.QueryByHash(ctx, []byte("unknown"))
+		assert.ErrorIs(t, err, session.ErrTokenNotFound)
+	})
+
+	t.Run("Forwards error on database error", func(t *testing.T) {
+		r := sessiondb.NewRefreshTokenRepo(&stubSQLDB{})
+		rt := session.RefreshToken{ID: uuid.New()}
+
+		err := r.Create(ctx, rt)
+		assert.EqualError(t, err, fmt.Sprintf("create: [%s]: DBError", rt.ID))
+	})
+}
+
+func TestRefreshTokenRepo_MarkUsed(t *testing.T) {
+	testDB, deleteTable := SetupRefreshTokensTable(t, fixtureRefreshTokens())
+	defer deleteTable()
+	r := sessiondb.NewRefreshTokenRepo(testDB)
+	ctx := context.Background()
+
+	err := r.MarkUsed(ctx, uuid.UUID{1}, time.Now())
+	assert.NoError(t, err)
+
+	got, err := r.QueryByHash(ctx, []byte("hash 1"))
+	assert.NoError(t, err)
+	assert.NotNil(t, got.UsedAt)
+
+	err = r.MarkUsed(ctx, uuid.UUID{1}, time.Now())
+	assert.ErrorIs(t, err, session.ErrTokenAlreadyUsed)
+}
+
+func TestRefreshTokenRepo_RevokeFamily(t *testing.T) {
+	testDB, deleteTable := SetupRefreshTokensTable(t, fixtureRefreshTokens())
+	defer deleteTable()
+	r := sessiondb.NewRefreshTokenRepo(testDB)
+	ctx := context.Background()
+
+	err := r.RevokeFamily(ctx, uuid.UUID{10}, time.Now())
+	assert.NoError(t, err)
+
+	for _, hash := range []string{"hash 1", "hash 2"} {
+		got, err := r.QueryByHash(ctx, []byte(hash))
+		assert.NoError(t, err)
+		assert.NotNil(t, got.RevokedAt)
+	}
+
+	got, err := r.QueryByHash(ctx, []byte("hash 3"))
+	assert.NoError(t, err)
+	assert.Nil(t, got.RevokedAt)
+}
+
+func TestRefreshTokenRepo_RevokeByUserID(t *testing.T) {
+	tokens := append(fixtureRefreshTokens(), sessiondb.DBRefreshToken{
+		ID: uuid.UUID{4}, FamilyID: uuid.UUID{30}, UserID: uuid.UUID{2}, TokenHash: []byte("hash 4"),
+		ExpiresAt: time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	testDB, deleteTable := SetupRefreshTokensTable(t, tokens)
+	defer deleteTable()
+	r := sessiondb.NewRefreshTokenRepo(testDB)
+	ctx := context.Background()
+
+	err := r.RevokeByUserID(ctx, uuid.UUID{1}, time.Now())
+	assert.NoError(t, err)
+
+	for _, hash := range []string{"hash 1", "hash 2", "hash 3"} {
+		got, err := r.QueryByHash(ctx, []byte(hash))
+		assert.NoError(t, err)
+		assert.NotNil(t, got.RevokedAt)
+	}
+
+	got, err := r.QueryByHash(ctx, []byte("hash 4"))
+	assert.NoError(t, err)
+	assert.Nil(t, got.RevokedAt)
+
+	t.Run("Forwards error on database error", func(t *testing.T) {
+		r := sessiondb.NewRefreshTokenRepo(&stubSQLDB{})
+		err := r.RevokeByUserID(ctx, uuid.UUID{1}, time.Now())
+		assert.EqualError(t, err, fmt.Sprintf("revokeByUserID: [%s]: DBError", uuid.UUID{1}))
+	})
+}
+
+func TestRefreshTokenRepo_DeleteByUserID(t *testing.T) {
+	tokens := append(fixtureRefreshTokens(), sessiondb.DBRefreshToken{
+		ID: uuid.UUID{4}, FamilyID: uuid.UUID{30}, UserID: uuid.UUID{2}, TokenHash: []byte("hash 4"),
+		ExpiresAt: time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	testDB, deleteTable := SetupRefreshTokensTable(t, tokens)
+	defer deleteTable()
+	r := sessiondb.NewRefreshTokenRepo(testDB)
+	ctx := context.Background()
+
+	err := r.DeleteByUserID(ctx, uuid.UUID{1})
+	assert.NoError(t, err)
+
+	for _, hash := range []string{"hash 1", "hash 2", "hash 3"} {
+		_, err := r.QueryByHash(ctx, []byte(hash))
+		assert.ErrorIs(t, err, session.ErrTokenNotFound)
+	}
+
+	_, err = r.QueryByHash(ctx, []byte("hash 4"))
+	assert.NoError(t, err)
+
+	t.Run("Forwards error on database error", func(t *testing.T) {
+		r := sessiondb.NewRefreshTokenRepo(&stubSQLDB{})
+		err := r.DeleteByUserID(ctx, uuid.UUID{1})
+		assert.EqualError(t, err, fmt.Sprintf("deleteByUserID: [%s]: DBError", uuid.UUID{1}))
+	})
+}
@@ -0,0 +1,99 @@
+package sessiondb_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/session/repositories/sessiondb"
+	"github.com/Keisn1/note-taking-app/domain/data/schema"
+	"github.com/google/uuid"
+)
+
+const (
+	testDBName   = "test_note_taking_app_sessions"
+	testUser     = "postgres"
+	testPassword = "password"
+)
+
+func run(m *testing.M) int {
+	var (
+		dropDB   = fmt.Sprintf(`DROP DATABASE IF EXISTS %s;`, testDBName)
+		createDB = fmt.Sprintf(`CREATE DATABASE %s;`, testDBName)
+	)
+
+	dsn := fmt.Sprintf("host=localhost port=5432 user=%s password=%s sslmode=disable", testUser, testPassword)
+	postgresDB, err := sql.Open("pgx", dsn)
+	if err != nil {
+		panic(err)
+	}
+	defer postgresDB.Close()
+
+	_, err = postgresDB.Exec(dropDB)
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = postgresDB.Exec(createDB)
+	if err != nil {
+		panic(err)
+	}
+
+	defer func() {
+		_, err = postgresDB.Exec(dropDB)
+		if err != nil {
+			panic(fmt.Errorf("postgresDB.Exec() err = %s", err))
+		}
+	}()
+
+	return m.Run()
+}
+
+func SetupRefreshTokensTable(t *testing.T, tokens []sessiondb.DBRefreshToken) (*sql.DB, func()) {
+	dsn := fmt.Sprintf("host=localhost port=5432 user=%s password=%s sslmode=disable dbname=%s ", testUser, testPassword, testDBName)
+	testDB, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrator, err := schema.NewMigrator(testDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = migrator.Up(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	insertRow := `
+	INSERT INTO refresh_tokens (id, family_id, user_id, token_hash, expires_at, used_at, revoked_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	for _, rt := range tokens {
+		_, err = testDB.Exec(insertRow, rt.ID, rt.FamilyID, rt.UserID, rt.TokenHash, rt.ExpiresAt, rt.UsedAt, rt.RevokedAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deleteTable := func() {
+		err := migrator.Reset(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		testDB.Close()
+	}
+
+	return testDB, deleteTable
+}
+
+func fixtureRefreshTokens() []sessiondb.DBRefreshToken {
+	expiresAt := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []sessiondb.DBRefreshToken{
+		{ID: uuid.UUID{1}, FamilyID: uuid.UUID{10}, UserID: uuid.UUID{1}, TokenHash: []byte("hash 1"), ExpiresAt: expiresAt},
+		{ID: uuid.UUID{2}, FamilyID: uuid.UUID{10}, UserID: uuid.UUID{1}, TokenHash: []byte("hash 2"), ExpiresAt: expiresAt},
+		{ID: uuid.UUID{3}, FamilyID: uuid.UUID{20}, UserID: uuid.UUID{1}, TokenHash: []byte("hash 3"), ExpiresAt: expiresAt},
+	}
+}
@@ -0,0 +1,31 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTokenNotFound    = errors.New("refresh token not found")
+	ErrTokenAlreadyUsed = errors.New("refresh token already used")
+)
+
+// Repo stores refresh tokens. It runs in the transaction carried by the
+// context, see transaction.Manager.
+type Repo interface {
+	Create(ctx context.Context, rt RefreshToken) error
+	// QueryByHash locks the token inside a transaction until the transaction
+	// ends.
+	QueryByHash(ctx context.Context, tokenHash []byte) (RefreshToken, error)
+	// MarkUsed sets UsedAt if the token has not been used yet. Otherwise it
+	// returns ErrTokenAlreadyUsed.
+	MarkUsed(ctx context.Context, tokenID uuid.UUID, at time.Time) error
+	// RevokeFamily waits for the transactions holding a token of the family
+	// and then revokes all of its tokens, also the ones they created.
+	RevokeFamily(ctx context.Context, familyID uuid.UUID, at time.Time) error
+	RevokeByUserID(ctx context.Context, userID uuid.UUID, at time.Time) error
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
+}
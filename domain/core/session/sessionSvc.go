@@ -0,0 +1,150 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/Keisn1/note-taking-app/foundation/common"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid refresh token")
+	ErrTokenExpired = errors.New("refresh token expired")
+	// ErrTokenReused is returned when an already rotated token is presented
+	// again. The whole token family is revoked in that case.
+	ErrTokenReused = errors.New("refresh token reused")
+)
+
+type Service interface {
+	Issue(ctx context.Context, userID uuid.UUID) (string, error)
+	Rotate(ctx context.Context, token string) (uuid.UUID, string, error)
+	Revoke(ctx context.Context, userID uuid.UUID, token string) error
+	RevokeAll(ctx context.Context, userID uuid.UUID) error
+}
+
+type Svc struct {
+	repo Repo
+	txm  transaction.Manager
+	ttl  time.Duration
+}
+
+func NewSvc(repo Repo, txm transaction.Manager, ttl time.Duration) Service {
+	return Svc{repo: repo, txm: txm, ttl: ttl}
+}
+
+// Issue starts a new token family for userID and returns its first token.
+func (s Svc) Issue(ctx context.Context, userID uuid.UUID) (string, error) {
+	token, err := s.create(ctx, userID, uuid.New())
+	if err != nil {
+		return "", fmt.Errorf("issue: %w", err)
+	}
+	return token, nil
+}
+
+// Rotate exchanges token for a new token of the same family and returns the
+// user the token belongs to. Looking up, using up and replacing the token
+// happen in one transaction holding the token, so that a concurrent reuse
+// revokes the new token as well.
+func (s Svc) Rotate(ctx context.Context, token string) (uuid.UUID, string, error) {
+	var rt RefreshToken
+	var newToken string
+	// reused is set if the family is revoked, which has to be committed
+	var reused bool
+	err := s.txm.Run(ctx, func(ctx context.Context) error {
+		var err error
+		if rt, err = s.repo.QueryByHash(ctx, common.HashToken(token)); err != nil {
+			if errors.Is(err, ErrTokenNotFound) {
+				return ErrInvalidToken
+			}
+			return err
+		}
+
+		if rt.RevokedAt != nil {
+			return ErrInvalidToken
+		}
+
+		now := time.Now()
+		if rt.UsedAt != nil {
+			reused = true
+			return s.repo.RevokeFamily(ctx, rt.FamilyID, now)
+		}
+
+		if now.After(rt.ExpiresAt) {
+			return ErrTokenExpired
+		}
+
+		if err := s.repo.MarkUsed(ctx, rt.ID, now); err != nil {
+			// a concurrent request rotated the same token first
+			if errors.Is(err, ErrTokenAlreadyUsed) {
+				reused = true
+				return s.repo.RevokeFamily(ctx, rt.FamilyID, now)
+			}
+			return err
+		}
+
+		newToken, err = s.create(ctx, rt.UserID, rt.FamilyID)
+		return err
+	})
+	switch {
+	case reused && err != nil:
+		return uuid.UUID{}, "", fmt.Errorf("rotate: %w: %w", ErrTokenReused, err)
+	case reused:
+		return uuid.UUID{}, "", fmt.Errorf("rotate: %w", ErrTokenReused)
+	case err != nil:
+		return uuid.UUID{}, "", fmt.Errorf("rotate: %w", err)
+	}
+
+	return rt.UserID, newToken, nil
+}
+
+// Revoke ends the session token belongs to by revoking its whole family.
+func (s Svc) Revoke(ctx context.Context, userID uuid.UUID, token string) error {
+	rt, err := s.repo.QueryByHash(ctx, common.HashToken(token))
+	if err != nil {
+		if errors.Is(err, ErrTokenNotFound) {
+			return fmt.Errorf("revoke: %w", ErrInvalidToken)
+		}
+		return fmt.Errorf("revoke: %w", err)
+	}
+
+	if rt.UserID != userID {
+		return fmt.Errorf("revoke: %w", ErrInvalidToken)
+	}
+
+	if err := s.repo.RevokeFamily(ctx, rt.FamilyID, time.Now()); err != nil {
+		return fmt.Errorf("revoke: %w", err)
+	}
+	return nil
+}
+
+// RevokeAll ends every session of userID.
+func (s Svc) RevokeAll(ctx context.Context, userID uuid.UUID) error {
+	if err := s.repo.RevokeByUserID(ctx, userID, time.Now()); err != nil {
+		return fmt.Errorf("revokeAll: %w", err)
+	}
+	return nil
+}
+
+func (s Svc) create(ctx context.Context, userID, familyID uuid.UUID) (string, error) {
+	token, err := common.NewToken()
+	if err != nil {
+		return "", err
+	}
+
+	rt := RefreshToken{
+		ID:        uuid.New(),
+		FamilyID:  familyID,
+		UserID:    userID,
+		TokenHash: common.HashToken(token),
+		ExpiresAt: time.Now().Add(s.ttl),
+	}
+	if err := s.repo.Create(ctx, rt); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
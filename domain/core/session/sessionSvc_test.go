@@ -0,0 +1,161 @@
+package session_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/session"
+	"github.com/Keisn1/note-taking-app/domain/core/session/repositories/memory"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Rotate(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("Rotating returns the user and a new token", func(t *testing.T) {
+		svc := session.NewSvc(memory.NewRepo(nil), transaction.NewMemoryManager(), time.Hour)
+		token, err := svc.Issue(ctx, userID)
+		assert.NoError(t, err)
+
+		gotUserID, newToken, err := svc.Rotate(ctx, token)
+		assert.NoError(t, err)
+		assert.Equal(t, userID, gotUserID)
+		assert.NotEqual(t, token, newToken)
+
+		gotUserID, _, err = svc.Rotate(ctx, newToken)
+		assert.NoError(t, err)
+		assert.Equal(t, userID, gotUserID)
+	})
+
+	t.Run("Reusing a rotated token revokes the family", func(t *testing.T) {
+		svc := session.NewSvc(memory.NewRepo(nil), transaction.NewMemoryManager(), time.Hour)
+		token, err := svc.Issue(ctx, userID)
+		assert.NoError(t, err)
+
+		_, newToken, err := svc.Rotate(ctx, token)
+		assert.NoError(t, err)
+
+		_, _, err = svc.Rotate(ctx, token)
+		assert.ErrorIs(t, err, session.ErrTokenReused)
+
+		// the legitimately rotated token is gone as well
+		_, _, err = svc.Rotate(ctx, newToken)
+		assert.ErrorIs(t, err, session.ErrInvalidToken)
+	})
+
+	t.Run("Other families stay valid on reuse", func(t *testing.T) {
+		svc := session.NewSvc(memory.NewRepo(nil), transaction.NewMemoryManager(), time.Hour)
+		token, err := svc.Issue(ctx, userID)
+		assert.NoError(t, err)
+		otherToken, err := svc.Issue(ctx, userID)
+		assert.NoError(t, err)
+
+		_, _, err = svc.Rotate(ctx, token)
+		assert.NoError(t, err)
+		_, _, err = svc.Rotate(ctx, token)
+		assert.ErrorIs(t, err, session.ErrTokenReused)
+
+		_, _, err = svc.Rotate(ctx, otherToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Expired token", func(t *testing.T) {
+		svc := session.NewSvc(memory.NewRepo(nil), transaction.NewMemoryManager(), -time.Minute)
+		token, err := svc.Issue(ctx, userID)
+		assert.NoError(t, err)
+
+		_, _, err = svc.Rotate(ctx, token)
+		assert.ErrorIs(t, err, session.ErrTokenExpired)
+	})
+
+	t.Run("Unknown token", func(t *testing.T) {
+		svc := session.NewSvc(memory.NewRepo(nil), transaction.NewMemoryManager(), time.Hour)
+
+		_, _, err := svc.Rotate(ctx, "unknown")
+		assert.ErrorIs(t, err, session.ErrInvalidToken)
+		assert.ErrorContains(t, err, "rotate")
+	})
+}
+
+func Test_Revoke(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("Revoke ends the session of the token", func(t *testing.T) {
+		svc := session.NewSvc(memory.NewRepo(nil), transaction.NewMemoryManager(), time.Hour)
+		token, err := svc.Issue(ctx, userID)
+		assert.NoError(t, err)
+		otherToken, err := svc.Issue(ctx, userID)
+		assert.NoError(t, err)
+
+		assert.NoError(t, svc.Revoke(ctx, userID, token))
+
+		_, _, err = svc.Rotate(ctx, token)
+		assert.ErrorIs(t, err, session.ErrInvalidToken)
+		_, _, err = svc.Rotate(ctx, otherToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Tokens of other users can't be revoked", func(t *testing.T) {
+		svc := session.NewSvc(memory.NewRepo(nil), transaction.NewMemoryManager(), time.Hour)
+		token, err := svc.Issue(ctx, userID)
+		assert.NoError(t, err)
+
+		err = svc.Revoke(ctx, uuid.New(), token)
+		assert.ErrorIs(t, err, session.ErrInvalidToken)
+
+		_, _, err = svc.Rotate(ctx, token)
+		assert.NoError(t, err)
+	})
+
+	t.Run("RevokeAll ends every session of the user", func(t *testing.T) {
+		svc := session.NewSvc(memory.NewRepo(nil), transaction.NewMemoryManager(), time.Hour)
+		token, err := svc.Issue(ctx, userID)
+		assert.NoError(t, err)
+		otherToken, err := svc.Issue(ctx, userID)
+		assert.NoError(t, err)
+		otherUserToken, err := svc.Issue(ctx, uuid.New())
+		assert.NoError(t, err)
+
+		assert.NoError(t, svc.RevokeAll(ctx, userID))
+
+		for _, tok := range []string{token, otherToken} {
+			_, _, err = svc.Rotate(ctx, tok)
+			assert.ErrorIs(t, err, session.ErrInvalidToken)
+		}
+		_, _, err = svc.Rotate(ctx, otherUserToken)
+		assert.NoError(t, err)
+	})
+	t.Run("A failed rotation keeps the token usable", func(t *testing.T) {
+		repo := &failingCreateRepo{InMemoryRepo: memory.NewRepo(nil)}
+		svc := session.NewSvc(repo, transaction.NewMemoryManager(), time.Hour)
+		token, err := svc.Issue(ctx, userID)
+		assert.NoError(t, err)
+
+		repo.fail = true
+		_, _, err = svc.Rotate(ctx, token)
+		assert.ErrorContains(t, err, "create failed")
+
+		repo.fail = false
+		gotUserID, _, err := svc.Rotate(ctx, token)
+		assert.NoError(t, err)
+		assert.Equal(t, userID, gotUserID)
+	})
+}
+
+type failingCreateRepo struct {
+	memory.InMemoryRepo
+	fail bool
+}
+
+func (r *failingCreateRepo) Create(ctx context.Context, rt session.RefreshToken) error {
+	if r.fail {
+		return errors.New("create failed")
+	}
+	return r.InMemoryRepo.Create(ctx, rt)
+}
@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/sharelink"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/google/uuid"
+)
+
+type InMemoryRepo struct {
+	mu    *sync.Mutex
+	links map[uuid.UUID]sharelink.Link
+}
+
+func NewRepo(links []sharelink.Link) InMemoryRepo {
+	ls := make(map[uuid.UUID]sharelink.Link)
+	for _, l := range links {
+		ls[l.ID] = l
+	}
+	return InMemoryRepo{mu: &sync.Mutex{}, links: ls}
+}
+
+func (r InMemoryRepo) Create(ctx context.Context, l sharelink.Link) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.links[l.ID] = l
+	return nil
+}
+
+func (r InMemoryRepo) QueryByHash(ctx context.Context, tokenHash []byte) (sharelink.Link, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, l := range r.links {
+		if bytes.Equal(l.TokenHash, tokenHash) {
+			return l, nil
+		}
+	}
+	return sharelink.Link{}, sharelink.ErrLinkNotFound
+}
+
+func (r InMemoryRepo) QueryByNoteID(ctx context.Context, noteID uuid.UUID) ([]sharelink.Link, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ret := []sharelink.Link{}
+	for _, l := range r.links {
+		if l.NoteID == noteID {
+			ret = append(ret, l)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].ID.String() < ret[j].ID.String() })
+	return ret, nil
+}
+
+func (r InMemoryRepo) AddView(ctx context.Context, linkID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l, ok := r.links[linkID]
+	if !ok {
+		return sharelink.ErrLinkNotFound
+	}
+	if l.MaxViews > 0 && l.Views >= l.MaxViews {
+		return sharelink.ErrViewLimitReached
+	}
+	l.Views++
+	r.links[linkID] = l
+	return nil
+}
+
+func (r InMemoryRepo) Revoke(ctx context.Context, noteID, linkID uuid.UUID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l, ok := r.links[linkID]
+	if !ok || l.NoteID != noteID {
+		return sharelink.ErrLinkNotFound
+	}
+	if l.RevokedAt == nil {
+		l.RevokedAt = &at
+		r.links[linkID] = l
+	}
+	return nil
+}
+
+func (r InMemoryRepo) DeleteByCreator(ctx context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, l := range r.links {
+		if l.CreatedBy != userID {
+			continue
+		}
+		delete(r.links, id)
+		transaction.OnRollback(ctx, func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.links[id] = l
+		})
+	}
+	return nil
+}
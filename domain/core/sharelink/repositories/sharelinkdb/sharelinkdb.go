@@ -0,0 +1,163 @@
+package sharelinkdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/sharelink"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/google/uuid"
+)
+
+type DBLink struct {
+	ID           uuid.UUID
+	NoteID       uuid.UUID
+	CreatedBy    uuid.UUID
+	TokenHash    []byte
+	PasswordHash []byte
+	ExpiresAt    sql.NullTime
+	MaxViews     int
+	Views        int
+	RevokedAt    sql.NullTime
+}
+
+type database interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+type LinkRepo struct {
+	db database
+}
+
+func NewLinkRepo(db database) LinkRepo {
+	return LinkRepo{db: db}
+}
+
+// conn returns the transaction carried by ctx, see transaction.Manager.
+func (r LinkRepo) conn(ctx context.Context) transaction.Querier {
+	return transaction.DB(ctx, r.db)
+}
+
+func (r LinkRepo) Create(ctx context.Context, l sharelink.Link) error {
+	insertRow := `
+	INSERT INTO share_links (id, note_id, created_by, token_hash, password_hash, expires_at, max_views)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := r.db.ExecContext(ctx, insertRow, l.ID, l.NoteID, l.CreatedBy, l.TokenHash, l.PasswordHash, l.ExpiresAt, l.MaxViews)
+	if err != nil {
+		return fmt.Errorf("create: [%s]: %w", l.ID, err)
+	}
+	return nil
+}
+
+func (r LinkRepo) QueryByHash(ctx context.Context, tokenHash []byte) (sharelink.Link, error) {
+	queryByHash := `
+	SELECT id, note_id, created_by, token_hash, password_hash, expires_at, max_views, views, revoked_at
+	FROM share_links WHERE token_hash=$1;
+	`
+	row := r.db.QueryRowContext(ctx, queryByHash, tokenHash)
+
+	var dbL DBLink
+	err := row.Scan(&dbL.ID, &dbL.NoteID, &dbL.CreatedBy, &dbL.TokenHash, &dbL.PasswordHash, &dbL.ExpiresAt, &dbL.MaxViews, &dbL.Views, &dbL.RevokedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sharelink.Link{}, sharelink.ErrLinkNotFound
+		}
+		return sharelink.Link{}, fmt.Errorf("queryByHash: %w", err)
+	}
+
+	return dbLinkToLink(dbL), nil
+}
+
+func (r LinkRepo) QueryByNoteID(ctx context.Context, noteID uuid.UUID) ([]sharelink.Link, error) {
+	queryByNoteID := `
+	SELECT id, note_id, created_by, token_hash, password_hash, expires_at, max_views, views, revoked_at
+	FROM share_links WHERE note_id=$1 ORDER BY id;
+	`
+	rows, err := r.db.QueryContext(ctx, queryByNoteID, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("queryByNoteID: [%s]: %w", noteID, err)
+	}
+	defer rows.Close()
+
+	ret := []sharelink.Link{}
+	for rows.Next() {
+		var dbL DBLink
+		err := rows.Scan(&dbL.ID, &dbL.NoteID, &dbL.CreatedBy, &dbL.TokenHash, &dbL.PasswordHash, &dbL.ExpiresAt, &dbL.MaxViews, &dbL.Views, &dbL.RevokedAt)
+		if err != nil {
+			return nil, fmt.Errorf("queryByNoteID: [%s]: scan rows: %w", noteID, err)
+		}
+		ret = append(ret, dbLinkToLink(dbL))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("queryByNoteID: [%s]: %w", noteID, err)
+	}
+
+	return ret, nil
+}
+
+func (r LinkRepo) AddView(ctx context.Context, linkID uuid.UUID) error {
+	// checking the limit in the update itself keeps concurrent views from
+	// exceeding it
+	addView := `
+	UPDATE share_links SET views = views + 1
+	WHERE id = $1 AND (max_views = 0 OR views < max_views)`
+
+	res, err := r.db.ExecContext(ctx, addView, linkID)
+	if err != nil {
+		return fmt.Errorf("addView: [%s]: %w", linkID, err)
+	}
+	if c, _ := res.RowsAffected(); c == 0 {
+		return sharelink.ErrViewLimitReached
+	}
+	return nil
+}
+
+func (r LinkRepo) Revoke(ctx context.Context, noteID, linkID uuid.UUID, at time.Time) error {
+	revoke := `
+	UPDATE share_links SET revoked_at = COALESCE(revoked_at, $1)
+	WHERE id = $2 AND note_id = $3`
+
+	res, err := r.db.ExecContext(ctx, revoke, at, linkID, noteID)
+	if err != nil {
+		return fmt.Errorf("revoke: [%s]: %w", linkID, err)
+	}
+	if c, _ := res.RowsAffected(); c == 0 {
+		return sharelink.ErrLinkNotFound
+	}
+	return nil
+}
+
+func (r LinkRepo) DeleteByCreator(ctx context.Context, userID uuid.UUID) error {
+	deleteRows := `DELETE FROM share_links WHERE created_by = $1`
+
+	_, err := r.conn(ctx).ExecContext(ctx, deleteRows, userID)
+	if err != nil {
+		return fmt.Errorf("deleteByCreator: [%s]: %w", userID, err)
+	}
+	return nil
+}
+
+func dbLinkToLink(dbL DBLink) sharelink.Link {
+	l := sharelink.Link{
+		ID:           dbL.ID,
+		NoteID:       dbL.NoteID,
+		CreatedBy:    dbL.CreatedBy,
+		TokenHash:    dbL.TokenHash,
+		PasswordHash: dbL.PasswordHash,
+		MaxViews:     dbL.MaxViews,
+		Views:        dbL.Views,
+	}
+	if dbL.ExpiresAt.Valid {
+		l.ExpiresAt = &dbL.ExpiresAt.Time
+	}
+	if dbL.RevokedAt.Valid {
+		l.RevokedAt = &dbL.RevokedAt.Time
+	}
+	return l
+}
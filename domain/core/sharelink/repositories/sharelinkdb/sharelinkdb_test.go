@@ -0,0 +1,88 @@
+package sharelinkdb_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/sharelink"
+	"github.com/Keisn1/note-taking-app/domain/core/sharelink/repositories/sharelinkdb"
+	"github.com/google/uuid"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	exitCode := run(m)
+	os.Exit(exitCode)
+}
+
+func TestLinkRepo(t *testing.T) {
+	noteID := uuid.UUID{1}
+	testDB, deleteTable := SetupShareLinksTable(t, []uuid.UUID{noteID})
+	defer deleteTable()
+	r := sharelinkdb.NewLinkRepo(testDB)
+	ctx := context.Background()
+
+	expiresAt := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := sharelink.Link{
+		ID:           uuid.New(),
+		NoteID:       noteID,
+		CreatedBy:    uuid.New(),
+		TokenHash:    []byte("hash"),
+		PasswordHash: []byte("password hash"),
+		ExpiresAt:    &expiresAt,
+		MaxViews:     1,
+	}
+
+	t.Run("Create and query", func(t *testing.T) {
+		assert.NoError(t, r.Create(ctx, l))
+
+		got, err := r.QueryByHash(ctx, l.TokenHash)
+		assert.NoError(t, err)
+		assert.Equal(t, l.ID, got.ID)
+		assert.Equal(t, l.PasswordHash, got.PasswordHash)
+		assert.True(t, expiresAt.Equal(*got.ExpiresAt))
+		assert.Nil(t, got.RevokedAt)
+
+		links, err := r.QueryByNoteID(ctx, noteID)
+		assert.NoError(t, err)
+		assert.Len(t, links, 1)
+
+		_, err = r.QueryByHash(ctx, []byte("unknown"))
+		assert.ErrorIs(t, err, sharelink.ErrLinkNotFound)
+	})
+
+	t.Run("Views are limited", func(t *testing.T) {
+		assert.NoError(t, r.AddView(ctx, l.ID))
+		assert.ErrorIs(t, r.AddView(ctx, l.ID), sharelink.ErrViewLimitReached)
+
+		got, err := r.QueryByHash(ctx, l.TokenHash)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, got.Views)
+	})
+
+	t.Run("Revoke only revokes links of the note", func(t *testing.T) {
+		err := r.Revoke(ctx, uuid.New(), l.ID, time.Now())
+		assert.ErrorIs(t, err, sharelink.ErrLinkNotFound)
+
+		assert.NoError(t, r.Revoke(ctx, noteID, l.ID, time.Now()))
+
+		got, err := r.QueryByHash(ctx, l.TokenHash)
+		assert.NoError(t, err)
+		assert.NotNil(t, got.RevokedAt)
+	})
+
+	t.Run("DeleteByCreator deletes the links of the user", func(t *testing.T) {
+		other := sharelink.Link{ID: uuid.New(), NoteID: noteID, CreatedBy: uuid.New(), TokenHash: []byte("other hash")}
+		assert.NoError(t, r.Create(ctx, other))
+
+		assert.NoError(t, r.DeleteByCreator(ctx, l.CreatedBy))
+
+		_, err := r.QueryByHash(ctx, l.TokenHash)
+		assert.ErrorIs(t, err, sharelink.ErrLinkNotFound)
+		_, err = r.QueryByHash(ctx, other.TokenHash)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,29 @@
+package sharelink
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrLinkNotFound     = errors.New("share link not found")
+	ErrViewLimitReached = errors.New("share link view limit reached")
+)
+
+type Repo interface {
+	Create(ctx context.Context, l Link) error
+	QueryByHash(ctx context.Context, tokenHash []byte) (Link, error)
+	QueryByNoteID(ctx context.Context, noteID uuid.UUID) ([]Link, error)
+	// AddView counts a view of the link if its view limit allows for it.
+	// Otherwise it returns ErrViewLimitReached.
+	AddView(ctx context.Context, linkID uuid.UUID) error
+	// Revoke sets RevokedAt of the link if it belongs to noteID. Otherwise it
+	// returns ErrLinkNotFound.
+	Revoke(ctx context.Context, noteID, linkID uuid.UUID, at time.Time) error
+	// DeleteByCreator deletes the links userID created. It runs in the
+	// transaction carried by ctx, see transaction.Manager.
+	DeleteByCreator(ctx context.Context, userID uuid.UUID) error
+}
@@ -0,0 +1,32 @@
+package sharelink
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Link gives read-only access to a note to anyone holding its token. Only the
+// hash of the token is kept. MaxViews of 0 means the link can be opened any
+// number of times.
+type Link struct {
+	ID           uuid.UUID
+	NoteID       uuid.UUID
+	CreatedBy    uuid.UUID
+	TokenHash    []byte
+	PasswordHash []byte
+	ExpiresAt    *time.Time
+	MaxViews     int
+	Views        int
+	RevokedAt    *time.Time
+}
+
+// NewLink holds the optional restrictions of a link to be created. An empty
+// Password leaves the link unprotected.
+type NewLink struct {
+	ExpiresAt *time.Time
+	MaxViews  int
+	Password  string
+}
+
+func (l Link) HasPassword() bool { return len(l.PasswordHash) > 0 }
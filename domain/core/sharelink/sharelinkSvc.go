@@ -0,0 +1,130 @@
+package sharelink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/foundation/common"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidLink      = errors.New("invalid share link")
+	ErrLinkExpired      = errors.New("share link expired")
+	ErrPasswordRequired = errors.New("share link password required")
+	ErrInvalidPassword  = errors.New("invalid share link password")
+	ErrInvalidMaxViews  = errors.New("invalid share link view limit")
+)
+
+type Service interface {
+	Create(ctx context.Context, n note.Note, userID uuid.UUID, nl NewLink) (Link, string, error)
+	Open(ctx context.Context, token, password string) (note.Note, error)
+	QueryByNoteID(ctx context.Context, noteID uuid.UUID) ([]Link, error)
+	Revoke(ctx context.Context, noteID, linkID uuid.UUID) error
+}
+
+type Svc struct {
+	repo    Repo
+	noteSvc note.Service
+}
+
+func NewSvc(repo Repo, ns note.Service) Service {
+	return Svc{repo: repo, noteSvc: ns}
+}
+
+// Create makes a new link to n on behalf of userID and returns it together
+// with its token. The token can't be recovered later on.
+func (s Svc) Create(ctx context.Context, n note.Note, userID uuid.UUID, nl NewLink) (Link, string, error) {
+	if nl.MaxViews < 0 {
+		return Link{}, "", fmt.Errorf("create: %w", ErrInvalidMaxViews)
+	}
+
+	token, err := common.NewToken()
+	if err != nil {
+		return Link{}, "", fmt.Errorf("create: %w", err)
+	}
+
+	l := Link{
+		ID:        uuid.New(),
+		NoteID:    n.ID,
+		CreatedBy: userID,
+		TokenHash: common.HashToken(token),
+		ExpiresAt: nl.ExpiresAt,
+		MaxViews:  nl.MaxViews,
+	}
+
+	if nl.Password != "" {
+		pwHash, err := bcrypt.GenerateFromPassword([]byte(nl.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return Link{}, "", fmt.Errorf("create: %w: %w", ErrInvalidPassword, err)
+		}
+		l.PasswordHash = pwHash
+	}
+
+	if err := s.repo.Create(ctx, l); err != nil {
+		return Link{}, "", fmt.Errorf("create: %w", err)
+	}
+	return l, token, nil
+}
+
+// Open returns the note token links to and counts the view. password is only
+// checked for links protected by one.
+func (s Svc) Open(ctx context.Context, token, password string) (note.Note, error) {
+	l, err := s.repo.QueryByHash(ctx, common.HashToken(token))
+	if err != nil {
+		if errors.Is(err, ErrLinkNotFound) {
+			return note.Note{}, fmt.Errorf("open: %w", ErrInvalidLink)
+		}
+		return note.Note{}, fmt.Errorf("open: %w", err)
+	}
+
+	if l.RevokedAt != nil {
+		return note.Note{}, fmt.Errorf("open: %w", ErrInvalidLink)
+	}
+
+	if l.ExpiresAt != nil && time.Now().After(*l.ExpiresAt) {
+		return note.Note{}, fmt.Errorf("open: %w", ErrLinkExpired)
+	}
+
+	if l.HasPassword() {
+		if password == "" {
+			return note.Note{}, fmt.Errorf("open: %w", ErrPasswordRequired)
+		}
+		if err := bcrypt.CompareHashAndPassword(l.PasswordHash, []byte(password)); err != nil {
+			return note.Note{}, fmt.Errorf("open: %w", ErrInvalidPassword)
+		}
+	}
+
+	// a link to a trashed note doesn't use up a view
+	n, err := s.noteSvc.QueryByID(ctx, l.NoteID)
+	if err != nil {
+		if errors.Is(err, note.ErrNoteNotFound) {
+			return note.Note{}, fmt.Errorf("open: %w", ErrInvalidLink)
+		}
+		return note.Note{}, fmt.Errorf("open: %w", err)
+	}
+
+	if err := s.repo.AddView(ctx, l.ID); err != nil {
+		return note.Note{}, fmt.Errorf("open: %w", err)
+	}
+	return n, nil
+}
+
+func (s Svc) QueryByNoteID(ctx context.Context, noteID uuid.UUID) ([]Link, error) {
+	links, err := s.repo.QueryByNoteID(ctx, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("queryByNoteID: [%s]: %w", noteID, err)
+	}
+	return links, nil
+}
+
+func (s Svc) Revoke(ctx context.Context, noteID, linkID uuid.UUID) error {
+	if err := s.repo.Revoke(ctx, noteID, linkID, time.Now()); err != nil {
+		return fmt.Errorf("revoke: [%s]: %w", linkID, err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package sharelink_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	noteMemory "github.com/Keisn1/note-taking-app/domain/core/note/repositories/memory"
+	"github.com/Keisn1/note-taking-app/domain/core/sharelink"
+	"github.com/Keisn1/note-taking-app/domain/core/sharelink/repositories/memory"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func setup(n note.Note) sharelink.Service {
+	noteSvc := note.NewNotesService(noteMemory.MustNewRepo([]note.Note{n}), noteMemory.NewShareRepo(nil), noteMemory.NewRevisionRepo(nil), nil, transaction.NewMemoryManager())
+	return sharelink.NewSvc(memory.NewRepo(nil), noteSvc)
+}
+
+func Test_Open(t *testing.T) {
+	ctx := context.Background()
+	n := note.Note{ID: uuid.UUID{1}, Title: note.NewTitle("title"), Content: note.NewContent("content"), UserID: uuid.UUID{2}}
+
+	t.Run("A link opens the note it was created for", func(t *testing.T) {
+		svc := setup(n)
+		l, token, err := svc.Create(ctx, n, n.UserID, sharelink.NewLink{})
+		assert.NoError(t, err)
+		assert.Equal(t, n.ID, l.NoteID)
+		assert.NotEmpty(t, token)
+
+		got, err := svc.Open(ctx, token, "")
+		assert.NoError(t, err)
+		assert.Equal(t, n, got)
+	})
+
+	t.Run("Unknown tokens are invalid", func(t *testing.T) {
+		svc := setup(n)
+		_, err := svc.Open(ctx, "unknown", "")
+		assert.ErrorIs(t, err, sharelink.ErrInvalidLink)
+	})
+
+	t.Run("Expired links can't be opened", func(t *testing.T) {
+		svc := setup(n)
+		expiresAt := time.Now().Add(-time.Minute)
+		_, token, err := svc.Create(ctx, n, n.UserID, sharelink.NewLink{ExpiresAt: &expiresAt})
+		assert.NoError(t, err)
+
+		_, err = svc.Open(ctx, token, "")
+		assert.ErrorIs(t, err, sharelink.ErrLinkExpired)
+	})
+
+	t.Run("Links open at most MaxViews times", func(t *testing.T) {
+		svc := setup(n)
+		_, token, err := svc.Create(ctx, n, n.UserID, sharelink.NewLink{MaxViews: 2})
+		assert.NoError(t, err)
+
+		for range 2 {
+			_, err = svc.Open(ctx, token, "")
+			assert.NoError(t, err)
+		}
+		_, err = svc.Open(ctx, token, "")
+		assert.ErrorIs(t, err, sharelink.ErrViewLimitReached)
+
+		_, _, err = svc.Create(ctx, n, n.UserID, sharelink.NewLink{MaxViews: -1})
+		assert.ErrorIs(t, err, sharelink.ErrInvalidMaxViews)
+	})
+
+	t.Run("Protected links need the password", func(t *testing.T) {
+		svc := setup(n)
+		l, token, err := svc.Create(ctx, n, n.UserID, sharelink.NewLink{Password: "secret", MaxViews: 1})
+		assert.NoError(t, err)
+		assert.True(t, l.HasPassword())
+
+		_, err = svc.Open(ctx, token, "")
+		assert.ErrorIs(t, err, sharelink.ErrPasswordRequired)
+
+		// failed attempts don't count as views
+		_, err = svc.Open(ctx, token, "wrong")
+		assert.ErrorIs(t, err, sharelink.ErrInvalidPassword)
+
+		got, err := svc.Open(ctx, token, "secret")
+		assert.NoError(t, err)
+		assert.Equal(t, n, got)
+	})
+
+	t.Run("Links to trashed notes don't use up views", func(t *testing.T) {
+		noteSvc := note.NewNotesService(noteMemory.MustNewRepo([]note.Note{n}), noteMemory.NewShareRepo(nil), noteMemory.NewRevisionRepo(nil), nil, transaction.NewMemoryManager())
+		svc := sharelink.NewSvc(memory.NewRepo(nil), noteSvc)
+		_, token, err := svc.Create(ctx, n, n.UserID, sharelink.NewLink{MaxViews: 1})
+		assert.NoError(t, err)
+
+		assert.NoError(t, noteSvc.Delete(ctx, n.ID, 0))
+		_, err = svc.Open(ctx, token, "")
+		assert.ErrorIs(t, err, sharelink.ErrInvalidLink)
+
+		_, err = noteSvc.Restore(ctx, n.ID, n.UserID)
+		assert.NoError(t, err)
+		_, err = svc.Open(ctx, token, "")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Revoked links can't be opened", func(t *testing.T) {
+		svc := setup(n)
+		l, token, err := svc.Create(ctx, n, n.UserID, sharelink.NewLink{})
+		assert.NoError(t, err)
+
+		err = svc.Revoke(ctx, uuid.New(), l.ID)
+		assert.ErrorIs(t, err, sharelink.ErrLinkNotFound)
+
+		err = svc.Revoke(ctx, n.ID, l.ID)
+		assert.NoError(t, err)
+
+		_, err = svc.Open(ctx, token, "")
+		assert.ErrorIs(t, err, sharelink.ErrInvalidLink)
+
+		links, err := svc.QueryByNoteID(ctx, n.ID)
+		assert.NoError(t, err)
+		assert.Len(t, links, 1)
+		assert.NotNil(t, links[0].RevokedAt)
+	})
+}
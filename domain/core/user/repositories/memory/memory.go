@@ -2,9 +2,11 @@ package memory
 
 import (
 	"context"
-	"errors"
+	"net/mail"
+	"sort"
 
 	"github.com/Keisn1/note-taking-app/domain/core/user"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
 	"github.com/google/uuid"
 )
 
@@ -21,26 +23,75 @@ func NewRepo(users []user.User) InMemoryRepo {
 }
 
 func (r InMemoryRepo) Update(ctx context.Context, u user.User) error {
+	if r.emailTaken(u) {
+		return user.ErrEmailTaken
+	}
+	r.onRollback(ctx, u.ID)
 	r.users[u.ID] = u
 	return nil
 }
 
 func (r InMemoryRepo) Create(ctx context.Context, u user.User) error {
+	if r.emailTaken(u) {
+		return user.ErrEmailTaken
+	}
+	r.onRollback(ctx, u.ID)
 	r.users[u.ID] = u
 	return nil
 }
 
 func (r InMemoryRepo) Delete(ctx context.Context, userID uuid.UUID) error {
 	if _, ok := r.users[userID]; !ok {
-		return errors.New("user not found")
+		return user.ErrUserNotFound
 	}
+	r.onRollback(ctx, userID)
 	delete(r.users, userID)
 	return nil
 }
 
+// onRollback restores the user as it is now if the transaction of ctx is
+// rolled back.
+func (r InMemoryRepo) onRollback(ctx context.Context, userID uuid.UUID) {
+	old, existed := r.users[userID]
+	transaction.OnRollback(ctx, func() {
+		if existed {
+			r.users[userID] = old
+		} else {
+			delete(r.users, userID)
+		}
+	})
+}
+
 func (r InMemoryRepo) QueryByID(ctx context.Context, userID uuid.UUID) (user.User, error) {
 	if user, ok := r.users[userID]; ok {
 		return user, nil
 	}
-	return user.User{}, errors.New("user not found")
+	return user.User{}, user.ErrUserNotFound
+}
+
+func (r InMemoryRepo) QueryByEmail(ctx context.Context, email mail.Address) (user.User, error) {
+	for _, u := range r.users {
+		if u.Email.String().Address == email.Address {
+			return u, nil
+		}
+	}
+	return user.User{}, user.ErrUserNotFound
+}
+
+func (r InMemoryRepo) QueryAll(ctx context.Context) ([]user.User, error) {
+	users := make([]user.User, 0, len(r.users))
+	for _, u := range r.users {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Email.String().Address < users[j].Email.String().Address })
+	return users, nil
+}
+
+func (r InMemoryRepo) emailTaken(u user.User) bool {
+	for _, other := range r.users {
+		if other.ID != u.ID && other.Email.String().Address == u.Email.String().Address {
+			return true
+		}
+	}
+	return false
 }
@@ -1,23 +1,29 @@
 package userdb_test
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"os"
 	"testing"
+
+	"github.com/Keisn1/note-taking-app/domain/core/user/repositories/userdb"
+	"github.com/Keisn1/note-taking-app/domain/data/schema"
+	"github.com/google/uuid"
+)
+
+const (
+	testDBName   = "test_note_taking_app_users"
+	testUser     = "postgres"
+	testPassword = "password"
 )
 
 func run(m *testing.M) int {
 	var (
-		dropDB   = fmt.Sprintf(`DROP DATABASE IF EXISTS %s;`, os.Getenv("DB_NAME"))
-		createDB = fmt.Sprintf(`CREATE DATABASE %s;`, os.Getenv("DB_NAME"))
+		dropDB   = fmt.Sprintf(`DROP DATABASE IF EXISTS %s;`, testDBName)
+		createDB = fmt.Sprintf(`CREATE DATABASE %s;`, testDBName)
 	)
 
-	dsn := fmt.Sprintf(
-		"host=localhost port=5432 user=%s password=%s sslmode=disable",
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-	)
+	dsn := fmt.Sprintf("host=localhost port=5432 user=%s password=%s sslmode=disable", testUser, testPassword)
 	postgresDB, err := sql.Open("pgx", dsn)
 	if err != nil {
 		panic(err)
@@ -43,3 +49,46 @@ func run(m *testing.M) int {
 
 	return m.Run()
 }
+
+func SetupUsersTable(t *testing.T, users []userdb.DBUser) (*sql.DB, func()) {
+	dsn := fmt.Sprintf("host=localhost port=5432 user=%s password=%s sslmode=disable dbname=%s ", testUser, testPassword, testDBName)
+	testDB, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrator, err := schema.NewMigrator(testDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = migrator.Up(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	insertRow := `INSERT INTO users (id, name, email, password_hash, roles) VALUES ($1, $2, $3, $4, $5)`
+	for _, u := range users {
+		_, err = testDB.Exec(insertRow, u.ID, u.Name, u.Email, u.PasswordHash, u.Roles)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deleteTable := func() {
+		err := migrator.Reset(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		testDB.Close()
+	}
+
+	return testDB, deleteTable
+}
+
+func fixtureUsers() []userdb.DBUser {
+	return []userdb.DBUser{
+		{ID: uuid.UUID{1}, Name: "rob", Email: "rob@example.com", PasswordHash: []byte("robs hash"), Roles: []string{"user"}},
+		{ID: uuid.UUID{2}, Name: "anna", Email: "anna@example.com", PasswordHash: []byte("annas hash"), Roles: []string{"user", "admin"}},
+	}
+}
@@ -0,0 +1,212 @@
+package userdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/mail"
+
+	"github.com/Keisn1/note-taking-app/domain/core/user"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// uniqueViolation is the postgres error code for a violated unique constraint.
+const uniqueViolation = "23505"
+
+type DBUser struct {
+	ID           uuid.UUID
+	Name         string
+	Email        string
+	PasswordHash []byte
+	Roles        []string
+}
+
+type database interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+type UserRepo struct {
+	db database
+}
+
+func NewUserRepo(db database) UserRepo {
+	return UserRepo{db: db}
+}
+
+// conn returns the transaction carried by ctx, see transaction.Manager.
+func (uR UserRepo) conn(ctx context.Context) transaction.Querier {
+	return transaction.DB(ctx, uR.db)
+}
+
+func (uR UserRepo) Create(ctx context.Context, u user.User) error {
+	insertRow := `
+	INSERT INTO users (id, name, email, password_hash, roles, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, now(), now())`
+
+	dbU := userToDBUser(u)
+	_, err := uR.conn(ctx).ExecContext(ctx, insertRow, dbU.ID, dbU.Name, dbU.Email, dbU.PasswordHash, dbU.Roles)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return user.ErrEmailTaken
+		}
+		return fmt.Errorf("create: [%s]: %w", u.ID, err)
+	}
+
+	return nil
+}
+
+func (uR UserRepo) Update(ctx context.Context, u user.User) error {
+	updateRow := `
+	UPDATE users
+	SET name = $1, email = $2, password_hash = $3, roles = $4, updated_at = now() WHERE id=$5`
+
+	dbU := userToDBUser(u)
+	res, err := uR.conn(ctx).ExecContext(ctx, updateRow, dbU.Name, dbU.Email, dbU.PasswordHash, dbU.Roles, dbU.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return user.ErrEmailTaken
+		}
+		return fmt.Errorf("update: [%s]: %w", u.ID, err)
+	}
+	if c, _ := res.RowsAffected(); c == 0 {
+		return user.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (uR UserRepo) Delete(ctx context.Context, userID uuid.UUID) error {
+	deleteRow := `DELETE FROM users WHERE id=$1`
+
+	res, err := uR.conn(ctx).ExecContext(ctx, deleteRow, userID)
+	if err != nil {
+		return fmt.Errorf("delete: [%s]: %w", userID, err)
+	}
+	if c, _ := res.RowsAffected(); c == 0 {
+		return user.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// QueryByID locks the user inside a transaction, so that a concurrent Delete
+// waits for it.
+func (uR UserRepo) QueryByID(ctx context.Context, userID uuid.UUID) (user.User, error) {
+	queryByID := `
+	SELECT id, name, email, password_hash, roles FROM users WHERE id=$1`
+	if _, ok := transaction.FromContext(ctx); ok {
+		queryByID += ` FOR SHARE`
+	}
+	row := uR.conn(ctx).QueryRowContext(ctx, queryByID, userID)
+
+	dbU, err := scanUser(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return user.User{}, user.ErrUserNotFound
+		}
+		return user.User{}, fmt.Errorf("queryByID: [%s]: %w", userID, err)
+	}
+
+	return dbUserToUser(dbU), nil
+}
+
+func (uR UserRepo) QueryByEmail(ctx context.Context, email mail.Address) (user.User, error) {
+	queryByEmail := `
+	SELECT id, name, email, password_hash, roles FROM users WHERE email=$1;
+	`
+	row := uR.conn(ctx).QueryRowContext(ctx, queryByEmail, email.Address)
+
+	dbU, err := scanUser(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return user.User{}, user.ErrUserNotFound
+		}
+		return user.User{}, fmt.Errorf("queryByEmail: [%s]: %w", email.Address, err)
+	}
+
+	return dbUserToUser(dbU), nil
+}
+
+func (uR UserRepo) QueryAll(ctx context.Context) ([]user.User, error) {
+	queryAll := `
+	SELECT id, name, email, password_hash, roles FROM users ORDER BY email;
+	`
+	rows, err := uR.conn(ctx).QueryContext(ctx, queryAll)
+	if err != nil {
+		return nil, fmt.Errorf("queryAll: %w", err)
+	}
+	defer rows.Close()
+
+	var users []user.User
+	for rows.Next() {
+		dbU, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("queryAll: scan rows: %w", err)
+		}
+		users = append(users, dbUserToUser(dbU))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("queryAll: %w", err)
+	}
+
+	return users, nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUser(row scanner) (DBUser, error) {
+	var dbU DBUser
+	// database/sql can't scan postgres arrays on its own
+	roles := pgtype.NewMap().SQLScanner(&dbU.Roles)
+	err := row.Scan(&dbU.ID, &dbU.Name, &dbU.Email, &dbU.PasswordHash, roles)
+	return dbU, err
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
+func userToDBUser(u user.User) DBUser {
+	return DBUser{
+		ID:           u.ID,
+		Name:         u.Name.String(),
+		Email:        u.Email.String().Address,
+		PasswordHash: u.PasswordHash,
+		Roles:        rolesToStrings(u.Roles),
+	}
+}
+
+func dbUserToUser(dbU DBUser) user.User {
+	return user.User{
+		ID:           dbU.ID,
+		Name:         user.NewName(dbU.Name),
+		Email:        user.NewEmail(dbU.Email),
+		PasswordHash: dbU.PasswordHash,
+		Roles:        stringsToRoles(dbU.Roles),
+	}
+}
+
+func rolesToStrings(roles []user.Role) []string {
+	ret := make([]string, 0, len(roles))
+	for _, r := range roles {
+		ret = append(ret, string(r))
+	}
+	return ret
+}
+
+func stringsToRoles(roles []string) []user.Role {
+	ret := make([]user.Role, 0, len(roles))
+	for _, r := range roles {
+		ret = append(ret, user.Role(r))
+	}
+	return ret
+}
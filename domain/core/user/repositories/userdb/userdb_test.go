@@ -1,12 +1,17 @@
 package userdb_test
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
+	"net/mail"
 	"os"
 	"testing"
 
+	"github.com/Keisn1/note-taking-app/domain/core/user"
+	"github.com/Keisn1/note-taking-app/domain/core/user/repositories/userdb"
+	"github.com/google/uuid"
 	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestMain(m *testing.M) {
@@ -14,21 +19,162 @@ func TestMain(m *testing.M) {
 	os.Exit(exitCode)
 }
 
-func Test_Create(t *testing.T) {
-	dsn := fmt.Sprintf(
-		"host=localhost port=5432 user=%s password=%s sslmode=disable dbname=%s",
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_NAME"),
-	)
-	testDB, err := sql.Open("pgx", dsn)
-	if err != nil {
-		panic(err)
-	}
-	defer testDB.Close()
-
-	err = testDB.Ping()
-	if err != nil {
-		panic(err)
-	}
+func TestUserRepo_Create(t *testing.T) {
+	testDB, deleteTable := SetupUsersTable(t, fixtureUsers())
+	defer deleteTable()
+	uR := userdb.NewUserRepo(testDB)
+	ctx := context.Background()
+
+	t.Run("Add a user", func(t *testing.T) {
+		u := user.User{ID: uuid.New(), Name: user.NewName("bob"), Email: user.NewEmail("bob@example.com"), PasswordHash: []byte("hash"), Roles: []user.Role{user.RoleUser}}
+
+		err := uR.Create(ctx, u)
+		assert.NoError(t, err)
+
+		got, err := uR.QueryByID(ctx, u.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, u, got)
+	})
+
+	t.Run("Email already taken", func(t *testing.T) {
+		u := user.User{ID: uuid.New(), Name: user.NewName("robbie"), Email: user.NewEmail("rob@example.com"), PasswordHash: []byte("hash")}
+
+		err := uR.Create(ctx, u)
+		assert.ErrorIs(t, err, user.ErrEmailTaken)
+	})
+
+	t.Run("Forwards error on database error", func(t *testing.T) {
+		uR := userdb.NewUserRepo(&stubSQLDB{})
+		u := user.User{ID: uuid.New(), Name: user.NewName("bob"), Email: user.NewEmail("bob@example.com")}
+
+		err := uR.Create(ctx, u)
+		assert.EqualError(t, err, fmt.Sprintf("create: [%s]: DBError", u.ID))
+	})
+}
+
+func TestUserRepo_Update(t *testing.T) {
+	testDB, deleteTable := SetupUsersTable(t, fixtureUsers())
+	defer deleteTable()
+	uR := userdb.NewUserRepo(testDB)
+	ctx := context.Background()
+
+	t.Run("Update name, email, password hash and roles", func(t *testing.T) {
+		u := user.User{
+			ID:           uuid.UUID{1},
+			Name:         user.NewName("robbie"),
+			Email:        user.NewEmail("robbie@example.com"),
+			PasswordHash: []byte("new hash"),
+			Roles:        []user.Role{user.RoleUser, user.RoleAdmin},
+		}
+
+		err := uR.Update(ctx, u)
+		assert.NoError(t, err)
+
+		got, err := uR.QueryByID(ctx, u.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, u, got)
+	})
+
+	t.Run("Email already taken", func(t *testing.T) {
+		u := user.User{ID: uuid.UUID{1}, Name: user.NewName("rob"), Email: user.NewEmail("anna@example.com"), PasswordHash: []byte("hash")}
+
+		err := uR.Update(ctx, u)
+		assert.ErrorIs(t, err, user.ErrEmailTaken)
+	})
+
+	t.Run("User not present", func(t *testing.T) {
+		u := user.User{ID: uuid.New(), Name: user.NewName("bob"), Email: user.NewEmail("bob@example.com"), PasswordHash: []byte("hash")}
+
+		err := uR.Update(ctx, u)
+		assert.ErrorIs(t, err, user.ErrUserNotFound)
+	})
+}
+
+func TestUserRepo_Delete(t *testing.T) {
+	testDB, deleteTable := SetupUsersTable(t, fixtureUsers())
+	defer deleteTable()
+	uR := userdb.NewUserRepo(testDB)
+	ctx := context.Background()
+
+	t.Run("Able to delete a user", func(t *testing.T) {
+		err := uR.Delete(ctx, uuid.UUID{2})
+		assert.NoError(t, err)
+
+		_, err = uR.QueryByID(ctx, uuid.UUID{2})
+		assert.ErrorIs(t, err, user.ErrUserNotFound)
+	})
+
+	t.Run("Delete non-present user throws error", func(t *testing.T) {
+		err := uR.Delete(ctx, uuid.New())
+		assert.ErrorIs(t, err, user.ErrUserNotFound)
+	})
+}
+
+func TestUserRepo_QueryByID(t *testing.T) {
+	testDB, deleteTable := SetupUsersTable(t, fixtureUsers())
+	defer deleteTable()
+	uR := userdb.NewUserRepo(testDB)
+	ctx := context.Background()
+
+	t.Run("Get user by id", func(t *testing.T) {
+		want := user.User{ID: uuid.UUID{1}, Name: user.NewName("rob"), Email: user.NewEmail("rob@example.com"), PasswordHash: []byte("robs hash"), Roles: []user.Role{user.RoleUser}}
+
+		got, err := uR.QueryByID(ctx, uuid.UUID{1})
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("User not found", func(t *testing.T) {
+		_, err := uR.QueryByID(ctx, uuid.New())
+		assert.EqualError(t, err, user.ErrUserNotFound.Error())
+	})
+}
+
+func TestUserRepo_QueryByEmail(t *testing.T) {
+	testDB, deleteTable := SetupUsersTable(t, fixtureUsers())
+	defer deleteTable()
+	uR := userdb.NewUserRepo(testDB)
+	ctx := context.Background()
+
+	t.Run("Get user by email", func(t *testing.T) {
+		want := user.User{
+			ID:           uuid.UUID{2},
+			Name:         user.NewName("anna"),
+			Email:        user.NewEmail("anna@example.com"),
+			PasswordHash: []byte("annas hash"),
+			Roles:        []user.Role{user.RoleUser, user.RoleAdmin},
+		}
+
+		got, err := uR.QueryByEmail(ctx, mail.Address{Address: "anna@example.com"})
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("User not found", func(t *testing.T) {
+		_, err := uR.QueryByEmail(ctx, mail.Address{Address: "bob@example.com"})
+		assert.ErrorIs(t, err, user.ErrUserNotFound)
+	})
+}
+
+func TestUserRepo_QueryAll(t *testing.T) {
+	testDB, deleteTable := SetupUsersTable(t, fixtureUsers())
+	defer deleteTable()
+	uR := userdb.NewUserRepo(testDB)
+	ctx := context.Background()
+
+	t.Run("Get all users ordered by email", func(t *testing.T) {
+		got, err := uR.QueryAll(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, got, 2)
+		assert.Equal(t, "anna@example.com", got[0].Email.String().Address)
+		assert.Equal(t, "rob@example.com", got[1].Email.String().Address)
+		assert.True(t, got[0].HasRole(user.RoleAdmin))
+		assert.False(t, got[1].HasRole(user.RoleAdmin))
+	})
+
+	t.Run("Forwards error on database error", func(t *testing.T) {
+		uR := userdb.NewUserRepo(&stubSQLDB{})
+		_, err := uR.QueryAll(ctx)
+		assert.EqualError(t, err, "queryAll: DBError")
+	})
 }
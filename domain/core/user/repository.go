@@ -2,12 +2,24 @@ package user
 
 import (
 	"context"
+	"errors"
+	"net/mail"
 
 	"github.com/google/uuid"
 )
 
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrEmailTaken   = errors.New("email already taken")
+)
+
+// Repo stores users. It runs in the transaction carried by the context, see
+// transaction.Manager. Inside a transaction QueryByID keeps the user from
+// being deleted until the transaction ends.
 type Repo interface {
 	QueryByID(ctx context.Context, userID uuid.UUID) (User, error)
+	QueryByEmail(ctx context.Context, email mail.Address) (User, error)
+	QueryAll(ctx context.Context) ([]User, error)
 	Create(ctx context.Context, u User) error
 	Update(ctx context.Context, u User) error
 	Delete(ctx context.Context, userID uuid.UUID) error
@@ -6,11 +6,29 @@ import (
 	"github.com/google/uuid"
 )
 
+type Role string
+
+const (
+	RoleUser  Role = "user"
+	RoleAdmin Role = "admin"
+)
+
 type User struct {
 	ID           uuid.UUID
 	Name         Name
 	Email        Email
 	PasswordHash []byte
+	Roles        []Role
+}
+
+// HasRole reports whether u has been granted role.
+func (u User) HasRole(role Role) bool {
+	for _, r := range u.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
 }
 
 type UpdateUser struct {
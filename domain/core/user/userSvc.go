@@ -4,28 +4,41 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/mail"
 
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 var (
-	ErrInvalidPassword = errors.New("invalid password")
+	ErrInvalidPassword       = errors.New("invalid password")
+	ErrAuthenticationFailure = errors.New("authentication failed")
 )
 
 type Service interface {
 	QueryByID(ctx context.Context, userID uuid.UUID) (User, error)
+	QueryAll(ctx context.Context) ([]User, error)
+	Authenticate(ctx context.Context, email mail.Address, password string) (User, error)
 	Create(ctx context.Context, nu UpdateUser) (User, error)
 	Update(ctx context.Context, u User, uu UpdateUser) (User, error)
 	Delete(ctx context.Context, userID uuid.UUID) error
 }
 
+// Cleanup deletes what other services keep of a deleted user.
+type Cleanup func(ctx context.Context, userID uuid.UUID) error
+
 type Svc struct {
-	repo Repo
+	repo     Repo
+	txm      transaction.Manager
+	cleanups []Cleanup
 }
 
-func NewSvc(repo Repo) Service {
-	return Svc{repo: repo}
+// NewSvc takes the cleanups to run when a user is deleted. They run in the
+// transaction of the deletion, so their repositories have to be managed by
+// txm like repo.
+func NewSvc(repo Repo, txm transaction.Manager, cleanups ...Cleanup) Service {
+	return Svc{repo: repo, txm: txm, cleanups: cleanups}
 }
 
 func (s Svc) Update(ctx context.Context, u User, newU UpdateUser) (User, error) {
@@ -43,20 +56,39 @@ func (s Svc) Update(ctx context.Context, u User, newU UpdateUser) (User, error)
 	}
 
 	if !newU.Password.IsEmpty() {
+		if len(newU.Password.String()) == 0 {
+			return User{}, fmt.Errorf("update: %w", ErrInvalidPassword)
+		}
 		pwHash, err := bcrypt.GenerateFromPassword([]byte(newU.Password.String()), bcrypt.DefaultCost)
 		if err != nil {
-			return User{}, fmt.Errorf("create: %w: %w", ErrInvalidPassword, err)
+			return User{}, fmt.Errorf("update: %w: %w", ErrInvalidPassword, err)
 		}
 		u.PasswordHash = pwHash
 	}
 
-	s.repo.Update(ctx, u)
+	if err := s.repo.Update(ctx, u); err != nil {
+		return User{}, fmt.Errorf("update: %w", err)
+	}
 
 	return u, nil
 }
 
+// Delete deletes the user and runs the cleanups in one transaction. The user
+// is deleted first, which waits for the transactions that looked the user up
+// and may still add data of them.
 func (s Svc) Delete(ctx context.Context, userID uuid.UUID) error {
-	if err := s.repo.Delete(ctx, userID); err != nil {
+	err := s.txm.Run(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, userID); err != nil {
+			return err
+		}
+		for _, cleanup := range s.cleanups {
+			if err := cleanup(ctx, userID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("delete: %w", err)
 	}
 	return nil
@@ -78,9 +110,12 @@ func (s Svc) Create(ctx context.Context, newU UpdateUser) (User, error) {
 		Name:         newU.Name,
 		Email:        newU.Email,
 		PasswordHash: pwHash,
+		Roles:        []Role{RoleUser},
 	}
 
-	s.repo.Create(ctx, u)
+	if err := s.repo.Create(ctx, u); err != nil {
+		return User{}, fmt.Errorf("create: %w", err)
+	}
 	return u, nil
 }
 
@@ -91,3 +126,30 @@ func (s Svc) QueryByID(ctx context.Context, userID uuid.UUID) (User, error) {
 	}
 	return u, nil
 }
+
+func (s Svc) QueryAll(ctx context.Context) ([]User, error) {
+	users, err := s.repo.QueryAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("queryAll: %w", err)
+	}
+	return users, nil
+}
+
+// Authenticate looks up the user by email and checks the password against the
+// stored hash. A missing user and a wrong password both yield
+// ErrAuthenticationFailure so callers can't tell which emails are registered.
+func (s Svc) Authenticate(ctx context.Context, email mail.Address, password string) (User, error) {
+	u, err := s.repo.QueryByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return User{}, fmt.Errorf("authenticate: %w", ErrAuthenticationFailure)
+		}
+		return User{}, fmt.Errorf("authenticate: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword(u.PasswordHash, []byte(password)); err != nil {
+		return User{}, fmt.Errorf("authenticate: %w", ErrAuthenticationFailure)
+	}
+
+	return u, nil
+}
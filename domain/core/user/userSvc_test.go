@@ -2,10 +2,14 @@ package user_test
 
 import (
 	"context"
+	"errors"
+	"net/mail"
+	"strings"
 	"testing"
 
 	"github.com/Keisn1/note-taking-app/domain/core/user"
 	"github.com/Keisn1/note-taking-app/domain/core/user/repositories/memory"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/crypto/bcrypt"
@@ -14,7 +18,7 @@ import (
 func Test_Delete(t *testing.T) {
 	t.Run("Deletion success", func(t *testing.T) {
 		rob := user.User{ID: uuid.UUID{1}, Name: user.NewName("rob"), Email: user.NewEmail("rob@example.com")}
-		svc := user.NewSvc(memory.NewRepo([]user.User{rob}))
+		svc := user.NewSvc(memory.NewRepo([]user.User{rob}), transaction.NewMemoryManager())
 
 		got, err := svc.QueryByID(context.Background(), rob.ID)
 		assert.NoError(t, err)
@@ -29,17 +33,46 @@ func Test_Delete(t *testing.T) {
 	})
 
 	t.Run("Error if userID not present", func(t *testing.T) {
-		svc := user.NewSvc(memory.NewRepo([]user.User{}))
+		svc := user.NewSvc(memory.NewRepo([]user.User{}), transaction.NewMemoryManager())
 
 		err := svc.Delete(context.Background(), uuid.New())
 		assert.Error(t, err)
 		assert.ErrorContains(t, err, "delete")
 	})
+
+	t.Run("Runs the cleanups", func(t *testing.T) {
+		rob := user.User{ID: uuid.UUID{1}, Name: user.NewName("rob"), Email: user.NewEmail("rob@example.com")}
+		var cleaned []uuid.UUID
+		cleanup := func(ctx context.Context, userID uuid.UUID) error {
+			cleaned = append(cleaned, userID)
+			return nil
+		}
+		svc := user.NewSvc(memory.NewRepo([]user.User{rob}), transaction.NewMemoryManager(), cleanup)
+
+		err := svc.Delete(context.Background(), rob.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{rob.ID}, cleaned)
+	})
+
+	t.Run("Keeps the user if a cleanup fails", func(t *testing.T) {
+		rob := user.User{ID: uuid.UUID{1}, Name: user.NewName("rob"), Email: user.NewEmail("rob@example.com")}
+		cleanup := func(ctx context.Context, userID uuid.UUID) error {
+			return errors.New("cleanup failed")
+		}
+		svc := user.NewSvc(memory.NewRepo([]user.User{rob}), transaction.NewMemoryManager(), cleanup)
+
+		err := svc.Delete(context.Background(), rob.ID)
+		assert.EqualError(t, err, "delete: cleanup failed")
+
+		got, err := svc.QueryByID(context.Background(), rob.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, rob, got)
+	})
 }
 
 func Test_Update(t *testing.T) {
 	t.Run("Error if user not present", func(t *testing.T) {
-		svc := user.NewSvc(memory.NewRepo([]user.User{}))
+		svc := user.NewSvc(memory.NewRepo([]user.User{}), transaction.NewMemoryManager())
 		u := user.User{ID: uuid.UUID{1}, Name: user.NewName("rob"), Email: user.NewEmail("rob@example.com")}
 		uu := user.UpdateUser{Name: user.NewName("robbie")}
 
@@ -49,7 +82,7 @@ func Test_Update(t *testing.T) {
 
 	t.Run("Update User Name and Email", func(t *testing.T) {
 		rob := user.User{ID: uuid.UUID{1}, Name: user.NewName("rob"), Email: user.NewEmail("rob@example.com")}
-		svc := user.NewSvc(memory.NewRepo([]user.User{rob}))
+		svc := user.NewSvc(memory.NewRepo([]user.User{rob}), transaction.NewMemoryManager())
 
 		type testCase struct {
 			u        user.User
@@ -83,7 +116,7 @@ func Test_Update(t *testing.T) {
 
 	t.Run("Update Password", func(t *testing.T) {
 		rob := user.User{ID: uuid.UUID{1}, Name: user.NewName("rob"), Email: user.NewEmail("rob@example.com")}
-		svc := user.NewSvc(memory.NewRepo([]user.User{rob}))
+		svc := user.NewSvc(memory.NewRepo([]user.User{rob}), transaction.NewMemoryManager())
 
 		u := rob
 		uu := user.UpdateUser{Password: user.NewPassword("new password")}
@@ -95,10 +128,24 @@ func Test_Update(t *testing.T) {
 		assert.NoError(t, err)
 		assert.NoError(t, bcrypt.CompareHashAndPassword(retrievedUser.PasswordHash, []byte(uu.Password.String())))
 	})
+
+	t.Run("Password checking", func(t *testing.T) {
+		rob := user.User{ID: uuid.UUID{1}, Name: user.NewName("rob"), Email: user.NewEmail("rob@example.com"), PasswordHash: []byte("hash")}
+		svc := user.NewSvc(memory.NewRepo([]user.User{rob}), transaction.NewMemoryManager())
+
+		for _, password := range []string{"", strings.Repeat("72", 37)} {
+			_, err := svc.Update(context.Background(), rob, user.UpdateUser{Password: user.NewPassword(password)})
+			assert.ErrorIs(t, err, user.ErrInvalidPassword)
+		}
+
+		retrievedUser, err := svc.QueryByID(context.Background(), rob.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, rob, retrievedUser)
+	})
 }
 
 func Test_Create(t *testing.T) {
-	svc := user.NewSvc(memory.NewRepo([]user.User{}))
+	svc := user.NewSvc(memory.NewRepo([]user.User{}), transaction.NewMemoryManager())
 
 	t.Run("Happy paths", func(t *testing.T) {
 		type testCase struct {
@@ -138,6 +185,7 @@ func Test_Create(t *testing.T) {
 			assert.NotEqual(t, createdUser.ID, uuid.UUID{})
 			assert.Equal(t, tc.wantUser.Name, createdUser.Name)
 			assert.Equal(t, tc.wantUser.Email, createdUser.Email)
+			assert.Equal(t, []user.Role{user.RoleUser}, createdUser.Roles)
 			assert.NoError(t, bcrypt.CompareHashAndPassword(createdUser.PasswordHash, []byte(tc.newUser.Password.String())))
 
 			retrievedUser, err := svc.QueryByID(context.Background(), createdUser.ID)
@@ -175,7 +223,7 @@ func Test_QueryByID(t *testing.T) {
 			{ID: uuid.UUID{1}, Name: user.NewName("rob"), Email: user.NewEmail("rob@example.com")},
 			{ID: uuid.UUID{2}, Name: user.NewName("anna"), Email: user.NewEmail("anna@example.com")},
 		}
-		svc := user.NewSvc(memory.NewRepo(users))
+		svc := user.NewSvc(memory.NewRepo(users), transaction.NewMemoryManager())
 
 		type testCase struct {
 			name          string
@@ -228,3 +276,41 @@ func Test_QueryByID(t *testing.T) {
 		}
 	})
 }
+
+func Test_QueryAll(t *testing.T) {
+	rob := user.User{ID: uuid.UUID{1}, Name: user.NewName("rob"), Email: user.NewEmail("rob@example.com")}
+	anna := user.User{ID: uuid.UUID{2}, Name: user.NewName("anna"), Email: user.NewEmail("anna@example.com"), Roles: []user.Role{user.RoleAdmin}}
+	svc := user.NewSvc(memory.NewRepo([]user.User{rob, anna}), transaction.NewMemoryManager())
+
+	got, err := svc.QueryAll(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []user.User{anna, rob}, got)
+}
+
+func Test_Authenticate(t *testing.T) {
+	svc := user.NewSvc(memory.NewRepo([]user.User{}), transaction.NewMemoryManager())
+	ctx := context.Background()
+
+	rob, err := svc.Create(ctx, user.UpdateUser{
+		Name:     user.NewName("rob"),
+		Email:    user.NewEmail("rob@example.com"),
+		Password: user.NewPassword("password"),
+	})
+	assert.NoError(t, err)
+
+	t.Run("Correct email and password", func(t *testing.T) {
+		got, err := svc.Authenticate(ctx, mail.Address{Address: "rob@example.com"}, "password")
+		assert.NoError(t, err)
+		assert.Equal(t, rob, got)
+	})
+
+	t.Run("Wrong password", func(t *testing.T) {
+		_, err := svc.Authenticate(ctx, mail.Address{Address: "rob@example.com"}, "wrong password")
+		assert.ErrorIs(t, err, user.ErrAuthenticationFailure)
+	})
+
+	t.Run("Unknown email", func(t *testing.T) {
+		_, err := svc.Authenticate(ctx, mail.Address{Address: "anna@example.com"}, "password")
+		assert.ErrorIs(t, err, user.ErrAuthenticationFailure)
+	})
+}
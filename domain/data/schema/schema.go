@@ -0,0 +1,17 @@
+// Package schema holds the database migrations of the service.
+package schema
+
+import (
+	"database/sql"
+	"embed"
+
+	"github.com/Keisn1/note-taking-app/foundation/migrate"
+)
+
+//go:embed sql/*.sql
+var migrations embed.FS
+
+// NewMigrator returns a migrator for the embedded migrations.
+func NewMigrator(db *sql.DB) (*migrate.Migrator, error) {
+	return migrate.New(db, migrations, "sql")
+}
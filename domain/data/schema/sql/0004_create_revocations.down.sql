@@ -0,0 +1,2 @@
+DROP TABLE user_revocations;
+DROP TABLE revoked_tokens;
@@ -0,0 +1,8 @@
+CREATE TABLE note_shares (
+    note_id UUID NOT NULL REFERENCES notes (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    permission TEXT NOT NULL CHECK (permission IN ('read', 'write', 'owner')),
+    PRIMARY KEY (note_id, user_id)
+);
+
+CREATE INDEX note_shares_user_id_idx ON note_shares (user_id);
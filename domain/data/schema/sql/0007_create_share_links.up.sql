@@ -0,0 +1,14 @@
+CREATE TABLE share_links (
+    id UUID PRIMARY KEY,
+    note_id UUID NOT NULL REFERENCES notes (id) ON DELETE CASCADE,
+    created_by UUID NOT NULL,
+    token_hash BYTEA NOT NULL UNIQUE,
+    password_hash BYTEA,
+    expires_at TIMESTAMPTZ,
+    max_views INTEGER NOT NULL DEFAULT 0 CHECK (max_views >= 0),
+    views INTEGER NOT NULL DEFAULT 0,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX share_links_note_id_idx ON share_links (note_id);
@@ -0,0 +1,4 @@
+DROP INDEX notes_user_id_updated_at_idx;
+DROP INDEX notes_user_id_created_at_idx;
+ALTER TABLE notes DROP COLUMN updated_at;
+ALTER TABLE notes DROP COLUMN created_at;
@@ -0,0 +1,5 @@
+ALTER TABLE notes ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE notes ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX notes_user_id_created_at_idx ON notes (user_id, created_at, id);
+CREATE INDEX notes_user_id_updated_at_idx ON notes (user_id, updated_at, id);
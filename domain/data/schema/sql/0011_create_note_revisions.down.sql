@@ -0,0 +1 @@
+DROP TABLE note_revisions;
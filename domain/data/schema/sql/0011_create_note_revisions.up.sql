@@ -0,0 +1,13 @@
+CREATE TABLE note_revisions (
+    note_id UUID NOT NULL REFERENCES notes (id) ON DELETE CASCADE,
+    number INTEGER NOT NULL,
+    author_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    title TEXT NOT NULL,
+    content TEXT NOT NULL,
+    PRIMARY KEY (note_id, number)
+);
+
+-- the current state of existing notes becomes their first revision
+INSERT INTO note_revisions (note_id, number, author_id, created_at, title, content)
+SELECT id, 1, user_id, updated_at, title, content FROM notes;
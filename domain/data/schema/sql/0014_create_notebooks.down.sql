@@ -0,0 +1,2 @@
+DROP TABLE notebook_notes;
+DROP TABLE notebooks;
@@ -0,0 +1,19 @@
+CREATE TABLE notebooks (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    parent_id UUID REFERENCES notebooks (id),
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX notebooks_user_id_idx ON notebooks (user_id);
+CREATE INDEX notebooks_parent_id_idx ON notebooks (parent_id);
+
+-- a note is in at most one notebook
+CREATE TABLE notebook_notes (
+    note_id UUID PRIMARY KEY REFERENCES notes (id) ON DELETE CASCADE,
+    notebook_id UUID NOT NULL REFERENCES notebooks (id) ON DELETE CASCADE
+);
+
+CREATE INDEX notebook_notes_notebook_id_idx ON notebook_notes (notebook_id);
@@ -0,0 +1,16 @@
+-- note_id has no foreign key: the files of attachments have to be deleted
+-- from the blob store after their note has been purged, see
+-- attachment.Service.PurgeOrphans
+CREATE TABLE attachments (
+    id UUID PRIMARY KEY,
+    note_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    filename TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size BIGINT NOT NULL CHECK (size >= 0),
+    sha256 BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX attachments_note_id_idx ON attachments (note_id);
+CREATE INDEX attachments_user_id_idx ON attachments (user_id);
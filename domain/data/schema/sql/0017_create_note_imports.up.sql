@@ -0,0 +1,12 @@
+-- note_id is checked at commit: an import claims the hash of a note before
+-- inserting it, so that concurrent imports of the same file wait for each
+-- other rather than both creating the note
+CREATE TABLE note_imports (
+    user_id UUID NOT NULL,
+    hash TEXT NOT NULL,
+    note_id UUID NOT NULL REFERENCES notes (id) ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, hash)
+);
+
+CREATE INDEX note_imports_note_id_idx ON note_imports (note_id);
@@ -0,0 +1,63 @@
+package transaction
+
+import (
+	"context"
+	"sync"
+)
+
+type undoKey struct{}
+
+// undoLog holds the changes of a transaction of a MemoryManager.
+type undoLog struct {
+	mu    sync.Mutex
+	undos []func()
+}
+
+// MemoryManager is the Manager of the in-memory repositories. Instead of
+// isolating transactions, which the tests don't need, it undoes their
+// changes on rollback.
+type MemoryManager struct{}
+
+func NewMemoryManager() MemoryManager {
+	return MemoryManager{}
+}
+
+func (m MemoryManager) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(undoKey{}).(*undoLog); ok {
+		return fn(ctx)
+	}
+
+	log := &undoLog{}
+	defer func() {
+		if r := recover(); r != nil {
+			log.rollback()
+			panic(r)
+		}
+	}()
+	if err := fn(context.WithValue(ctx, undoKey{}, log)); err != nil {
+		log.rollback()
+		return err
+	}
+	return nil
+}
+
+// OnRollback registers how to undo a change made by an in-memory repository
+// within the transaction of ctx. Outside of a transaction of a MemoryManager
+// it does nothing.
+func OnRollback(ctx context.Context, undo func()) {
+	log, ok := ctx.Value(undoKey{}).(*undoLog)
+	if !ok {
+		return
+	}
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	log.undos = append(log.undos, undo)
+}
+
+func (log *undoLog) rollback() {
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	for i := len(log.undos) - 1; i >= 0; i-- {
+		log.undos[i]()
+	}
+}
@@ -0,0 +1,66 @@
+package transaction_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryManager_Run(t *testing.T) {
+	txm := transaction.NewMemoryManager()
+
+	t.Run("Keeps the changes if fn succeeds", func(t *testing.T) {
+		var changes []string
+		err := txm.Run(context.Background(), func(ctx context.Context) error {
+			changes = append(changes, "a")
+			transaction.OnRollback(ctx, func() { changes = changes[:0] })
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a"}, changes)
+	})
+
+	t.Run("Undoes the changes in reverse order if fn fails", func(t *testing.T) {
+		var undone []string
+		err := txm.Run(context.Background(), func(ctx context.Context) error {
+			transaction.OnRollback(ctx, func() { undone = append(undone, "first") })
+			transaction.OnRollback(ctx, func() { undone = append(undone, "second") })
+			return errors.New("failed")
+		})
+		assert.EqualError(t, err, "failed")
+		assert.Equal(t, []string{"second", "first"}, undone)
+	})
+
+	t.Run("A nested Run joins the outer transaction", func(t *testing.T) {
+		var undone []string
+		err := txm.Run(context.Background(), func(ctx context.Context) error {
+			err := txm.Run(ctx, func(ctx context.Context) error {
+				transaction.OnRollback(ctx, func() { undone = append(undone, "inner") })
+				return nil
+			})
+			assert.NoError(t, err)
+			assert.Empty(t, undone)
+			return errors.New("failed")
+		})
+		assert.Error(t, err)
+		assert.Equal(t, []string{"inner"}, undone)
+	})
+
+	t.Run("Rolls back on panic", func(t *testing.T) {
+		var undone bool
+		assert.Panics(t, func() {
+			_ = txm.Run(context.Background(), func(ctx context.Context) error {
+				transaction.OnRollback(ctx, func() { undone = true })
+				panic("boom")
+			})
+		})
+		assert.True(t, undone)
+	})
+
+	t.Run("OnRollback does nothing outside of a transaction", func(t *testing.T) {
+		transaction.OnRollback(context.Background(), func() { t.Fatal("undo called") })
+	})
+}
@@ -0,0 +1,79 @@
+// Package transaction runs units of work spanning several repositories. A
+// Manager passes the transaction on in the context, the repositories taking
+// part use it instead of their database.
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+type Manager interface {
+	// Run calls fn with a context carrying a transaction, which is committed
+	// if fn returns nil and rolled back otherwise. Run inside a transaction
+	// joins it, the outermost Run commits.
+	Run(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Beginner is implemented by *sql.DB.
+type Beginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// Querier is implemented by *sql.DB and *sql.Tx.
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+type txKey struct{}
+
+type SQLManager struct {
+	db Beginner
+}
+
+func NewSQLManager(db Beginner) SQLManager {
+	return SQLManager{db: db}
+}
+
+func (m SQLManager) Run(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := FromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	// also rolls back if fn panics, after a commit it is a no-op
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+			err = errors.Join(err, rbErr)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// FromContext returns the transaction of an SQLManager carried by ctx.
+func FromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
+	return tx, ok
+}
+
+// DB returns the transaction carried by ctx, or db outside of a transaction.
+func DB(ctx context.Context, db Querier) Querier {
+	if tx, ok := FromContext(ctx); ok {
+		return tx
+	}
+	return db
+}
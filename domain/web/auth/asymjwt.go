@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// asymJWTSvc signs tokens with the active key of a KeyStore (RS256 or EdDSA)
+// and verifies them with the key named by the kid header.
+type asymJWTSvc struct {
+	ks  *KeyStore
+	cfg claimsConfig
+}
+
+func NewAsymJWTService(ks *KeyStore, opts ...Option) *asymJWTSvc {
+	return &asymJWTSvc{ks: ks, cfg: newClaimsConfig(opts)}
+}
+
+func (j *asymJWTSvc) CreateToken(userID uuid.UUID, roles []string, d time.Duration) (string, error) {
+	kid, key, err := j.ks.SigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	claims := j.cfg.newClaims(userID, roles, d)
+
+	token := jwt.NewWithClaims(signingMethod(key), claims)
+	token.Header["kid"] = kid
+
+	tokenS, err := token.SignedString(key)
+	if err != nil {
+		return "", err
+	}
+
+	return tokenS, nil
+}
+
+func (j *asymJWTSvc) Verify(tokenS string) (Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenS, &Claims{}, j.keyFunc, j.cfg.parserOptions()...)
+	if err != nil {
+		return Claims{}, verifyError(err)
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok {
+		return Claims{}, fmt.Errorf("verify: %w", ErrInvalidToken)
+	}
+
+	return *claims, nil
+}
+
+func (j *asymJWTSvc) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		return nil, errors.New("missing kid header")
+	}
+
+	pub, err := j.ks.PublicKey(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	// the algorithm has to match the key, otherwise a token could pick a
+	// weaker algorithm than the key was made for
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		if token.Method != jwt.SigningMethodRS256 {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+	case ed25519.PublicKey:
+		if token.Method != jwt.SigningMethodEdDSA {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", pub)
+	}
+
+	return pub, nil
+}
@@ -0,0 +1,98 @@
+package auth_test
+
+import (
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/web/auth"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsymJWT(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("Create and verify tokens with RSA and Ed25519 keys", func(t *testing.T) {
+		ks := auth.NewKeyStore()
+		assert.NoError(t, ks.Add("rsa", mustRSAKey(t, 2048)))
+		assert.NoError(t, ks.Add("ed", mustEd25519Key(t)))
+		jwtS := auth.NewAsymJWTService(ks)
+
+		for kid, alg := range map[string]string{"rsa": "RS256", "ed": "EdDSA"} {
+			assert.NoError(t, ks.SetActive(kid))
+
+			tokenS, err := jwtS.CreateToken(userID, nil, time.Minute)
+			assert.NoError(t, err)
+
+			token, _, err := jwt.NewParser().ParseUnverified(tokenS, &auth.Claims{})
+			assert.NoError(t, err)
+			assert.Equal(t, kid, token.Header["kid"])
+			assert.Equal(t, alg, token.Header["alg"])
+
+			claims, err := jwtS.Verify(tokenS)
+			assert.NoError(t, err)
+			assert.Equal(t, userID.String(), claims.Subject)
+		}
+	})
+
+	t.Run("Key rotation", func(t *testing.T) {
+		ks := auth.NewKeyStore()
+		assert.NoError(t, ks.Add("old", mustEd25519Key(t)))
+		jwtS := auth.NewAsymJWTService(ks)
+
+		oldToken, err := jwtS.CreateToken(userID, nil, time.Minute)
+		assert.NoError(t, err)
+
+		assert.NoError(t, ks.Add("new", mustEd25519Key(t)))
+		assert.NoError(t, ks.SetActive("new"))
+
+		newToken, err := jwtS.CreateToken(userID, nil, time.Minute)
+		assert.NoError(t, err)
+
+		// both keys are accepted while rotating
+		_, err = jwtS.Verify(oldToken)
+		assert.NoError(t, err)
+		_, err = jwtS.Verify(newToken)
+		assert.NoError(t, err)
+
+		// retired keys are not
+		assert.NoError(t, ks.Remove("old"))
+		_, err = jwtS.Verify(oldToken)
+		assert.ErrorIs(t, err, auth.ErrKeyNotFound)
+		_, err = jwtS.Verify(newToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Rejects tokens without kid or with a mismatching algorithm", func(t *testing.T) {
+		ks := auth.NewKeyStore()
+		edKey := mustEd25519Key(t)
+		assert.NoError(t, ks.Add("ed", edKey))
+		jwtS := auth.NewAsymJWTService(ks)
+
+		noKid, err := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{"sub": userID.String()}).SignedString(edKey)
+		assert.NoError(t, err)
+		_, err = jwtS.Verify(noKid)
+		assert.ErrorContains(t, err, "missing kid header")
+
+		// HS256 signed with the public key bytes must not pass
+		hmacToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": userID.String()})
+		hmacToken.Header["kid"] = "ed"
+		hmacTokenS, err := hmacToken.SignedString([]byte(edKey.Public().(ed25519.PublicKey)))
+		assert.NoError(t, err)
+		_, err = jwtS.Verify(hmacTokenS)
+		assert.ErrorContains(t, err, "unexpected signing method")
+	})
+
+	t.Run("Rejects expired tokens", func(t *testing.T) {
+		ks := auth.NewKeyStore()
+		assert.NoError(t, ks.Add("ed", mustEd25519Key(t)))
+		jwtS := auth.NewAsymJWTService(ks)
+
+		tokenS, err := jwtS.CreateToken(userID, nil, -time.Minute)
+		assert.NoError(t, err)
+		_, err = jwtS.Verify(tokenS)
+		assert.Error(t, err)
+	})
+}
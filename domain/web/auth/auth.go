@@ -1,24 +1,38 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/domain/core/revocation"
+	"github.com/google/uuid"
 )
 
+// ErrMissingToken is returned when the authorization header doesn't carry a
+// bearer token.
+var ErrMissingToken = errors.New("expected authorization header format: Bearer <token>")
+
+var ErrTokenRevoked = errors.New("token revoked")
+
 type AuthInterface interface {
-	Authenticate(bearerToken string) (Claims, error)
+	Authenticate(ctx context.Context, bearerToken string) (Claims, error)
 }
 
 type Auth struct {
-	jwtSvc JWTService
+	jwtSvc  JWTService
+	revoked revocation.Repo
 }
 
-func NewAuth(jwtS JWTService) Auth {
-	return Auth{jwtSvc: jwtS}
+func NewAuth(jwtS JWTService, revoked revocation.Repo) Auth {
+	return Auth{jwtSvc: jwtS, revoked: revoked}
 }
 
-func (a Auth) Authenticate(bearerToken string) (Claims, error) {
+// Authenticate verifies the bearer token and checks that it hasn't been
+// revoked.
+func (a Auth) Authenticate(ctx context.Context, bearerToken string) (Claims, error) {
 	tokenS, err := getTokenString(bearerToken)
 	if err != nil {
 		return Claims{}, fmt.Errorf("authenticate: %w", err)
@@ -29,13 +43,58 @@ func (a Auth) Authenticate(bearerToken string) (Claims, error) {
 		return Claims{}, fmt.Errorf("authenticate: %w", err)
 	}
 
+	// without a jti a token couldn't be revoked on its own
+	if claims.ID == "" {
+		return Claims{}, fmt.Errorf("authenticate: %w: missing jti", ErrInvalidToken)
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return Claims{}, fmt.Errorf("authenticate: %w: invalid subject: %w", ErrInvalidToken, err)
+	}
+
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+
+	revoked, err := a.revoked.IsRevoked(ctx, claims.ID, userID, issuedAt)
+	if err != nil {
+		return Claims{}, fmt.Errorf("authenticate: %w", err)
+	}
+	if revoked {
+		return Claims{}, fmt.Errorf("authenticate: [%s]: %w", claims.ID, ErrTokenRevoked)
+	}
+
 	return claims, nil
 }
 
+// Revoke denylists the token the claims belong to.
+func (a Auth) Revoke(ctx context.Context, claims Claims) error {
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return fmt.Errorf("revoke: %w", ErrInvalidToken)
+	}
+
+	if err := a.revoked.RevokeToken(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return fmt.Errorf("revoke: %w", err)
+	}
+	return nil
+}
+
+// RevokeAll revokes every token issued to userID before the second of
+// before. iat has second precision, so the tokens issued within that second,
+// e.g. on a login right after, stay valid.
+func (a Auth) RevokeAll(ctx context.Context, userID uuid.UUID, before time.Time) error {
+	if err := a.revoked.RevokeUser(ctx, userID, before.Truncate(time.Second)); err != nil {
+		return fmt.Errorf("revokeAll: %w", err)
+	}
+	return nil
+}
+
 func getTokenString(bearerToken string) (string, error) {
 	parts := strings.Split(bearerToken, " ")
 	if len(parts) != 2 || parts[0] != "Bearer" {
-		return "", errors.New("expected authorization header format: Bearer <token>")
+		return "", ErrMissingToken
 	}
 	return parts[1], nil
 }
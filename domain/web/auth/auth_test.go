@@ -1,17 +1,22 @@
 package auth_test
 
 import (
+	"context"
 	"testing"
+	"time"
 
+	"github.com/Keisn1/note-taking-app/domain/core/revocation/repositories/memory"
 	"github.com/Keisn1/note-taking-app/domain/web/auth"
 	"github.com/Keisn1/note-taking-app/foundation/common"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestAuthentication(t *testing.T) {
 	key := common.MustGenerateRandomKey(32)
 	jwtSvc := auth.MustNewJWTService(key)
-	a := auth.NewAuth(jwtSvc)
+	a := auth.NewAuth(jwtSvc, memory.NewRepo())
 
 	testCases := []struct {
 		name        string
@@ -51,8 +56,71 @@ func TestAuthentication(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			_, err := a.Authenticate(tc.bearerToken())
+			_, err := a.Authenticate(context.Background(), tc.bearerToken())
 			tc.assertion(t, err)
 		})
 	}
 }
+
+func TestRevocation(t *testing.T) {
+	ctx := context.Background()
+	jwtSvc := auth.MustNewJWTService(common.MustGenerateRandomKey(32))
+	userID := uuid.New()
+
+	bearer := func(t *testing.T) (string, auth.Claims) {
+		tokenS, err := jwtSvc.CreateToken(userID, nil, time.Minute)
+		assert.NoError(t, err)
+		claims, err := jwtSvc.Verify(tokenS)
+		assert.NoError(t, err)
+		return "Bearer " + tokenS, claims
+	}
+
+	t.Run("Revoked token is rejected, others stay valid", func(t *testing.T) {
+		a := auth.NewAuth(jwtSvc, memory.NewRepo())
+		revokedToken, claims := bearer(t)
+		otherToken, _ := bearer(t)
+
+		assert.NoError(t, a.Revoke(ctx, claims))
+
+		_, err := a.Authenticate(ctx, revokedToken)
+		assert.ErrorIs(t, err, auth.ErrTokenRevoked)
+		_, err = a.Authenticate(ctx, otherToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("RevokeAll rejects tokens issued before the cutoff", func(t *testing.T) {
+		a := auth.NewAuth(jwtSvc, memory.NewRepo())
+		token, claims := bearer(t)
+
+		assert.NoError(t, a.RevokeAll(ctx, userID, claims.IssuedAt.Add(time.Second)))
+		_, err := a.Authenticate(ctx, token)
+		assert.ErrorIs(t, err, auth.ErrTokenRevoked)
+
+		// tokens of other users are unaffected
+		otherTokenS, err := jwtSvc.CreateToken(uuid.New(), nil, time.Minute)
+		assert.NoError(t, err)
+		_, err = a.Authenticate(ctx, "Bearer "+otherTokenS)
+		assert.NoError(t, err)
+	})
+
+	t.Run("RevokeAll keeps tokens issued in the second of the cutoff", func(t *testing.T) {
+		a := auth.NewAuth(jwtSvc, memory.NewRepo())
+		token, claims := bearer(t)
+
+		assert.NoError(t, a.RevokeAll(ctx, userID, claims.IssuedAt.Add(999*time.Millisecond)))
+		_, err := a.Authenticate(ctx, token)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Tokens without jti are rejected", func(t *testing.T) {
+		key := common.MustGenerateRandomKey(32)
+		a := auth.NewAuth(auth.MustNewJWTService(key), memory.NewRepo())
+
+		claims := jwt.RegisteredClaims{Subject: userID.String(), ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}
+		tokenS, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+		assert.NoError(t, err)
+
+		_, err = a.Authenticate(ctx, "Bearer "+tokenS)
+		assert.ErrorIs(t, err, auth.ErrInvalidToken)
+	})
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidToken     = errors.New("invalid token")
+	ErrTokenExpired     = errors.New("token expired")
+	ErrTokenNotYetValid = errors.New("token not yet valid")
+	ErrInvalidAudience  = errors.New("invalid audience")
+	ErrInvalidIssuer    = errors.New("invalid issuer")
+)
+
+// Option configures the standard claims a JWTService sets on and requires
+// from tokens.
+type Option func(*claimsConfig)
+
+// WithIssuer sets iss on created tokens and rejects tokens of other issuers.
+func WithIssuer(iss string) Option {
+	return func(c *claimsConfig) { c.issuer = iss }
+}
+
+// WithAudience sets aud on created tokens and rejects tokens not meant for aud.
+func WithAudience(aud string) Option {
+	return func(c *claimsConfig) { c.audience = aud }
+}
+
+// WithLeeway allows for clock skew when checking exp, nbf and iat.
+func WithLeeway(leeway time.Duration) Option {
+	return func(c *claimsConfig) { c.leeway = leeway }
+}
+
+type claimsConfig struct {
+	issuer   string
+	audience string
+	leeway   time.Duration
+}
+
+func newClaimsConfig(opts []Option) claimsConfig {
+	var c claimsConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+func (c claimsConfig) newClaims(userID uuid.UUID, roles []string, d time.Duration) *Claims {
+	now := time.Now()
+
+	claims := &Claims{Roles: roles, Scopes: ScopesForRoles(roles)}
+	claims.ID = uuid.New().String()
+	claims.Subject = userID.String()
+	claims.Issuer = c.issuer
+	if c.audience != "" {
+		claims.Audience = jwt.ClaimStrings{c.audience}
+	}
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.NotBefore = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(d))
+	return claims
+}
+
+func (c claimsConfig) parserOptions() []jwt.ParserOption {
+	opts := []jwt.ParserOption{
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(c.leeway),
+	}
+	if c.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(c.issuer))
+	}
+	if c.audience != "" {
+		opts = append(opts, jwt.WithAudience(c.audience))
+	}
+	return opts
+}
+
+// verifyError maps the errors of the jwt package to the errors of this
+// package. The original error is kept for logging.
+func verifyError(err error) error {
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return fmt.Errorf("verify: %w: %w", ErrTokenExpired, err)
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return fmt.Errorf("verify: %w: %w", ErrTokenNotYetValid, err)
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return fmt.Errorf("verify: %w: %w", ErrInvalidAudience, err)
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		return fmt.Errorf("verify: %w: %w", ErrInvalidIssuer, err)
+	default:
+		return fmt.Errorf("verify: %w: %w", ErrInvalidToken, err)
+	}
+}
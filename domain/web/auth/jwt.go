@@ -11,36 +11,37 @@ import (
 
 type Claims struct {
 	jwt.RegisteredClaims
+	Roles  []string `json:"roles,omitempty"`
+	Scopes []string `json:"scopes,omitempty"`
 }
 
 type JWTService interface {
-	CreateToken(userID uuid.UUID, d time.Duration) (string, error)
+	CreateToken(userID uuid.UUID, roles []string, d time.Duration) (string, error)
 	Verify(tokenS string) (Claims, error)
 }
 
 type jwtSvc struct {
 	key []byte
+	cfg claimsConfig
 }
 
-func NewJWTService(key []byte) (*jwtSvc, error) {
+func NewJWTService(key []byte, opts ...Option) (*jwtSvc, error) {
 	if len(key) < 32 {
 		return nil, errors.New("key minLength 32")
 	}
-	return &jwtSvc{key: key}, nil
+	return &jwtSvc{key: key, cfg: newClaimsConfig(opts)}, nil
 }
 
-func MustNewJWTService(key []byte) *jwtSvc {
-	jwtSvc, err := NewJWTService(key)
+func MustNewJWTService(key []byte, opts ...Option) *jwtSvc {
+	jwtSvc, err := NewJWTService(key, opts...)
 	if err != nil {
 		panic(err)
 	}
 	return jwtSvc
 }
 
-func (j *jwtSvc) CreateToken(userID uuid.UUID, d time.Duration) (string, error) {
-	claims := &Claims{}
-	claims.Subject = userID.String()
-	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(d))
+func (j *jwtSvc) CreateToken(userID uuid.UUID, roles []string, d time.Duration) (string, error) {
+	claims := j.cfg.newClaims(userID, roles, d)
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenS, err := token.SignedString(j.key)
@@ -52,14 +53,14 @@ func (j *jwtSvc) CreateToken(userID uuid.UUID, d time.Duration) (string, error)
 }
 
 func (j *jwtSvc) Verify(tokenS string) (Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenS, &Claims{}, j.keyFunc)
+	token, err := jwt.ParseWithClaims(tokenS, &Claims{}, j.keyFunc, j.cfg.parserOptions()...)
 	if err != nil {
-		return Claims{}, fmt.Errorf("verify: %w", err)
+		return Claims{}, verifyError(err)
 	}
 
 	claims, ok := token.Claims.(*Claims)
 	if !ok {
-		return Claims{}, errors.New("verify: invalid token")
+		return Claims{}, fmt.Errorf("verify: %w", ErrInvalidToken)
 	}
 
 	return *claims, nil
@@ -6,6 +6,7 @@ import (
 
 	"github.com/Keisn1/note-taking-app/domain/web/auth"
 	"github.com/Keisn1/note-taking-app/foundation/common"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
@@ -22,7 +23,7 @@ func TestJWT(t *testing.T) {
 	assert.NoError(t, err)
 
 	userID := uuid.New()
-	tokenS, err := jwtS.CreateToken(userID, time.Minute)
+	tokenS, err := jwtS.CreateToken(userID, nil, time.Minute)
 	assert.NoError(t, err)
 	assert.Less(t, 0, len(tokenS))
 
@@ -38,9 +39,126 @@ func TestJWT(t *testing.T) {
 	assert.ErrorContains(t, err, "verify: ")
 
 	// assert that verify doesn't verify expired tokens
-	tokenS, err = jwtS.CreateToken(userID, -1*time.Minute)
+	tokenS, err = jwtS.CreateToken(userID, nil, -1*time.Minute)
 	assert.NoError(t, err, "CreateToken should not return an error")
 
 	_, err = jwtS.Verify(tokenS)
 	assert.Error(t, err, "Verify should return an error for expired token")
 }
+
+func TestJWTClaims(t *testing.T) {
+	key := common.MustGenerateRandomKey(32)
+	userID := uuid.New()
+	jwtS := auth.MustNewJWTService(key, auth.WithIssuer("notes-api"), auth.WithAudience("notes"), auth.WithLeeway(5*time.Second))
+
+	t.Run("CreateToken sets the standard claims", func(t *testing.T) {
+		tokenS, err := jwtS.CreateToken(userID, nil, time.Minute)
+		assert.NoError(t, err)
+
+		claims, err := jwtS.Verify(tokenS)
+		assert.NoError(t, err)
+		assert.Equal(t, "notes-api", claims.Issuer)
+		assert.Equal(t, jwt.ClaimStrings{"notes"}, claims.Audience)
+		assert.NotNil(t, claims.IssuedAt)
+		assert.NotNil(t, claims.NotBefore)
+		_, err = uuid.Parse(claims.ID)
+		assert.NoError(t, err)
+
+		// every token gets its own jti
+		otherS, err := jwtS.CreateToken(userID, nil, time.Minute)
+		assert.NoError(t, err)
+		other, err := jwtS.Verify(otherS)
+		assert.NoError(t, err)
+		assert.NotEqual(t, claims.ID, other.ID)
+	})
+
+	sign := func(claims jwt.Claims) string {
+		tokenS, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+		assert.NoError(t, err)
+		return tokenS
+	}
+	now := time.Now()
+	valid := func() jwt.RegisteredClaims {
+		return jwt.RegisteredClaims{
+			Subject:   userID.String(),
+			Issuer:    "notes-api",
+			Audience:  jwt.ClaimStrings{"notes"},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+		}
+	}
+
+	testCases := []struct {
+		name    string
+		claims  func() jwt.RegisteredClaims
+		wantErr error
+	}{
+		{
+			name:    "valid",
+			claims:  valid,
+			wantErr: nil,
+		},
+		{
+			name:    "missing exp",
+			claims:  func() jwt.RegisteredClaims { c := valid(); c.ExpiresAt = nil; return c },
+			wantErr: auth.ErrInvalidToken,
+		},
+		{
+			name: "expired",
+			claims: func() jwt.RegisteredClaims {
+				c := valid()
+				c.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Minute))
+				return c
+			},
+			wantErr: auth.ErrTokenExpired,
+		},
+		{
+			name: "expired within leeway",
+			claims: func() jwt.RegisteredClaims {
+				c := valid()
+				c.ExpiresAt = jwt.NewNumericDate(now.Add(-2 * time.Second))
+				return c
+			},
+			wantErr: nil,
+		},
+		{
+			name: "not yet valid",
+			claims: func() jwt.RegisteredClaims {
+				c := valid()
+				c.NotBefore = jwt.NewNumericDate(now.Add(time.Minute))
+				return c
+			},
+			wantErr: auth.ErrTokenNotYetValid,
+		},
+		{
+			name: "issued in the future",
+			claims: func() jwt.RegisteredClaims {
+				c := valid()
+				c.IssuedAt = jwt.NewNumericDate(now.Add(time.Minute))
+				return c
+			},
+			wantErr: auth.ErrTokenNotYetValid,
+		},
+		{
+			name:    "wrong audience",
+			claims:  func() jwt.RegisteredClaims { c := valid(); c.Audience = jwt.ClaimStrings{"other"}; return c },
+			wantErr: auth.ErrInvalidAudience,
+		},
+		{
+			name:    "wrong issuer",
+			claims:  func() jwt.RegisteredClaims { c := valid(); c.Issuer = "other"; return c },
+			wantErr: auth.ErrInvalidIssuer,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := jwtS.Verify(sign(tc.claims()))
+			if tc.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrKeyNotFound = errors.New("key not found")
+
+// KeyStore holds the RSA and Ed25519 keys used to sign and verify tokens,
+// identified by their kid. New tokens are signed with the active key, while
+// every key in the store is accepted for verification. Rotating keys means
+// adding a new key, making it active and removing the old key once all tokens
+// signed with it have expired.
+type KeyStore struct {
+	mu        sync.RWMutex
+	keys      map[string]crypto.Signer
+	activeKID string
+}
+
+func NewKeyStore() *KeyStore {
+	return &KeyStore{keys: make(map[string]crypto.Signer)}
+}
+
+// LoadKeyStore reads every <kid>.pem file in dir of fsys. The files have to
+// hold PKCS #8 encoded RSA or Ed25519 private keys.
+func LoadKeyStore(fsys fs.FS, dir string, activeKID string) (*KeyStore, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("loadKeyStore: %w", err)
+	}
+
+	ks := NewKeyStore()
+	for _, e := range entries {
+		if e.IsDir() || path.Ext(e.Name()) != ".pem" {
+			continue
+		}
+
+		data, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("loadKeyStore: %w", err)
+		}
+
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("loadKeyStore: [%s]: no PEM block", e.Name())
+		}
+
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("loadKeyStore: [%s]: %w", e.Name(), err)
+		}
+
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("loadKeyStore: [%s]: unsupported key type %T", e.Name(), key)
+		}
+
+		if err := ks.Add(strings.TrimSuffix(e.Name(), ".pem"), signer); err != nil {
+			return nil, fmt.Errorf("loadKeyStore: %w", err)
+		}
+	}
+
+	if err := ks.SetActive(activeKID); err != nil {
+		return nil, fmt.Errorf("loadKeyStore: %w", err)
+	}
+
+	return ks, nil
+}
+
+// Add stores key under kid. The first key added becomes the active key.
+func (ks *KeyStore) Add(kid string, key crypto.Signer) error {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < 2048 {
+			return fmt.Errorf("add: [%s]: rsa key minLength 2048 bits", kid)
+		}
+	case ed25519.PrivateKey:
+	default:
+		return fmt.Errorf("add: [%s]: unsupported key type %T", kid, key)
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	ks.keys[kid] = key
+	if ks.activeKID == "" {
+		ks.activeKID = kid
+	}
+	return nil
+}
+
+// SetActive selects the key used to sign new tokens.
+func (ks *KeyStore) SetActive(kid string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if _, ok := ks.keys[kid]; !ok {
+		return fmt.Errorf("setActive: [%s]: %w", kid, ErrKeyNotFound)
+	}
+	ks.activeKID = kid
+	return nil
+}
+
+// Remove retires a key. Tokens signed with it no longer verify. The active
+// key can't be removed.
+func (ks *KeyStore) Remove(kid string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if kid == ks.activeKID {
+		return fmt.Errorf("remove: [%s]: key is active", kid)
+	}
+	delete(ks.keys, kid)
+	return nil
+}
+
+// SigningKey returns the active key.
+func (ks *KeyStore) SigningKey() (string, crypto.Signer, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key, ok := ks.keys[ks.activeKID]
+	if !ok {
+		return "", nil, fmt.Errorf("signingKey: %w", ErrKeyNotFound)
+	}
+	return ks.activeKID, key, nil
+}
+
+// PublicKey returns the public part of the key with kid.
+func (ks *KeyStore) PublicKey(kid string) (crypto.PublicKey, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("publicKey: [%s]: %w", kid, ErrKeyNotFound)
+	}
+	return key.Public(), nil
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of all keys in the store, sorted by kid.
+func (ks *KeyStore) JWKS() JWKS {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	jwks := JWKS{Keys: make([]JWK, 0, len(ks.keys))}
+	for kid, key := range ks.keys {
+		jwk := JWK{KeyID: kid, Use: "sig", Algorithm: signingMethod(key).Alg()}
+		switch pub := key.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].KeyID < jwks.Keys[j].KeyID })
+
+	return jwks
+}
+
+func signingMethod(key crypto.Signer) jwt.SigningMethod {
+	if _, ok := key.(*rsa.PrivateKey); ok {
+		return jwt.SigningMethodRS256
+	}
+	return jwt.SigningMethodEdDSA
+}
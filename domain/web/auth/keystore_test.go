@@ -0,0 +1,116 @@
+package auth_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Keisn1/note-taking-app/domain/web/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustRSAKey(t *testing.T, bits int) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	assert.NoError(t, err)
+	return key
+}
+
+func mustEd25519Key(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	return key
+}
+
+func mustPEM(t *testing.T, key any) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func TestKeyStore(t *testing.T) {
+	t.Run("First key added is active", func(t *testing.T) {
+		ks := auth.NewKeyStore()
+		assert.NoError(t, ks.Add("k1", mustEd25519Key(t)))
+		assert.NoError(t, ks.Add("k2", mustEd25519Key(t)))
+
+		kid, _, err := ks.SigningKey()
+		assert.NoError(t, err)
+		assert.Equal(t, "k1", kid)
+
+		assert.NoError(t, ks.SetActive("k2"))
+		kid, _, err = ks.SigningKey()
+		assert.NoError(t, err)
+		assert.Equal(t, "k2", kid)
+	})
+
+	t.Run("Rejects weak rsa keys", func(t *testing.T) {
+		ks := auth.NewKeyStore()
+		err := ks.Add("weak", mustRSAKey(t, 1024))
+		assert.EqualError(t, err, "add: [weak]: rsa key minLength 2048 bits")
+	})
+
+	t.Run("Active key can't be removed", func(t *testing.T) {
+		ks := auth.NewKeyStore()
+		assert.NoError(t, ks.Add("k1", mustEd25519Key(t)))
+		assert.Error(t, ks.Remove("k1"))
+	})
+
+	t.Run("Unknown kid", func(t *testing.T) {
+		ks := auth.NewKeyStore()
+		assert.ErrorIs(t, ks.SetActive("unknown"), auth.ErrKeyNotFound)
+		_, err := ks.PublicKey("unknown")
+		assert.ErrorIs(t, err, auth.ErrKeyNotFound)
+	})
+
+	t.Run("Load keys from PEM files", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"keys/rsa-2024.pem": {Data: mustPEM(t, mustRSAKey(t, 2048))},
+			"keys/ed-2025.pem":  {Data: mustPEM(t, mustEd25519Key(t))},
+			"keys/README":       {Data: []byte("ignored")},
+		}
+
+		ks, err := auth.LoadKeyStore(fsys, "keys", "ed-2025")
+		assert.NoError(t, err)
+
+		kid, _, err := ks.SigningKey()
+		assert.NoError(t, err)
+		assert.Equal(t, "ed-2025", kid)
+
+		_, err = ks.PublicKey("rsa-2024")
+		assert.NoError(t, err)
+	})
+
+	t.Run("JWKS holds the public keys", func(t *testing.T) {
+		ks := auth.NewKeyStore()
+		rsaKey := mustRSAKey(t, 2048)
+		edKey := mustEd25519Key(t)
+		assert.NoError(t, ks.Add("a-rsa", rsaKey))
+		assert.NoError(t, ks.Add("b-ed", edKey))
+
+		jwks := ks.JWKS()
+		assert.Len(t, jwks.Keys, 2)
+
+		rsaJWK := jwks.Keys[0]
+		assert.Equal(t, "a-rsa", rsaJWK.KeyID)
+		assert.Equal(t, "RSA", rsaJWK.KeyType)
+		assert.Equal(t, "RS256", rsaJWK.Algorithm)
+		assert.Equal(t, "sig", rsaJWK.Use)
+		assert.Equal(t, base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()), rsaJWK.N)
+		assert.Equal(t, "AQAB", rsaJWK.E)
+
+		edJWK := jwks.Keys[1]
+		assert.Equal(t, "b-ed", edJWK.KeyID)
+		assert.Equal(t, "OKP", edJWK.KeyType)
+		assert.Equal(t, "Ed25519", edJWK.Curve)
+		assert.Equal(t, "EdDSA", edJWK.Algorithm)
+		assert.Equal(t, base64.RawURLEncoding.EncodeToString(edKey.Public().(ed25519.PublicKey)), edJWK.X)
+	})
+}
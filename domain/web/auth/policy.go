@@ -0,0 +1,84 @@
+package auth
+
+import "sort"
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+const (
+	ScopeNotesRead     = "notes:read"
+	ScopeNotesWrite    = "notes:write"
+	ScopeNotesReadAll  = "notes:read:all"
+	ScopeUsersReadAll  = "users:read:all"
+	ScopeUsersReadSelf = "users:read:self"
+)
+
+// roleScopes grants every role its scopes. Scopes are embedded into tokens on
+// creation, so changes here apply to newly issued tokens only.
+var roleScopes = map[string][]string{
+	RoleUser:  {ScopeNotesRead, ScopeNotesWrite, ScopeUsersReadSelf},
+	RoleAdmin: {ScopeNotesReadAll, ScopeUsersReadAll},
+}
+
+// ScopesForRoles returns the sorted union of the scopes of roles.
+func ScopesForRoles(roles []string) []string {
+	set := make(map[string]struct{})
+	for _, role := range roles {
+		for _, scope := range roleScopes[role] {
+			set[scope] = struct{}{}
+		}
+	}
+	if len(set) == 0 {
+		return nil
+	}
+
+	scopes := make([]string, 0, len(set))
+	for scope := range set {
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+// Rule is the access policy of a route. Claims satisfy the rule if they hold
+// at least one of AnyRole and all of AllScopes. Empty fields don't restrict.
+type Rule struct {
+	AnyRole   []string
+	AllScopes []string
+}
+
+var (
+	RuleAny   = Rule{}
+	RuleAdmin = Rule{AnyRole: []string{RoleAdmin}}
+
+	RuleNotesRead     = Rule{AllScopes: []string{ScopeNotesRead}}
+	RuleNotesWrite    = Rule{AllScopes: []string{ScopeNotesWrite}}
+	RuleUsersReadSelf = Rule{AllScopes: []string{ScopeUsersReadSelf}}
+	RuleNotesReadAll  = Rule{AnyRole: []string{RoleAdmin}, AllScopes: []string{ScopeNotesReadAll}}
+	RuleUsersReadAll  = Rule{AnyRole: []string{RoleAdmin}, AllScopes: []string{ScopeUsersReadAll}}
+)
+
+func (r Rule) Allows(claims Claims) bool {
+	if len(r.AnyRole) > 0 && !containsAny(claims.Roles, r.AnyRole) {
+		return false
+	}
+	for _, scope := range r.AllScopes {
+		if !containsAny(claims.Scopes, []string{scope}) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsAny(have, want []string) bool {
+	for _, w := range want {
+		for _, h := range have {
+			if h == w {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package auth_test
+
+import (
+	"testing"
+
+	"github.com/Keisn1/note-taking-app/domain/web/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopesForRoles(t *testing.T) {
+	assert.Nil(t, auth.ScopesForRoles(nil))
+	assert.Nil(t, auth.ScopesForRoles([]string{"unknown"}))
+	assert.Equal(t, []string{"notes:read", "notes:write", "users:read:self"}, auth.ScopesForRoles([]string{auth.RoleUser}))
+	assert.Equal(t,
+		[]string{"notes:read", "notes:read:all", "notes:write", "users:read:all", "users:read:self"},
+		auth.ScopesForRoles([]string{auth.RoleUser, auth.RoleAdmin}),
+	)
+}
+
+func TestRule(t *testing.T) {
+	user := auth.Claims{Roles: []string{auth.RoleUser}, Scopes: auth.ScopesForRoles([]string{auth.RoleUser})}
+	admin := auth.Claims{
+		Roles:  []string{auth.RoleUser, auth.RoleAdmin},
+		Scopes: auth.ScopesForRoles([]string{auth.RoleUser, auth.RoleAdmin}),
+	}
+
+	testCases := []struct {
+		name   string
+		rule   auth.Rule
+		claims auth.Claims
+		want   bool
+	}{
+		{name: "empty rule allows everyone", rule: auth.RuleAny, claims: auth.Claims{}, want: true},
+		{name: "admin rule denies users", rule: auth.RuleAdmin, claims: user, want: false},
+		{name: "admin rule allows admins", rule: auth.RuleAdmin, claims: admin, want: true},
+		{name: "any of the roles", rule: auth.Rule{AnyRole: []string{"editor", auth.RoleUser}}, claims: user, want: true},
+		{
+			name:   "all scopes present",
+			rule:   auth.Rule{AllScopes: []string{auth.ScopeNotesRead, auth.ScopeNotesWrite}},
+			claims: user,
+			want:   true,
+		},
+		{
+			name:   "one scope missing",
+			rule:   auth.Rule{AllScopes: []string{auth.ScopeNotesRead, auth.ScopeNotesReadAll}},
+			claims: user,
+			want:   false,
+		},
+		{
+			name:   "role and scopes required",
+			rule:   auth.Rule{AnyRole: []string{auth.RoleAdmin}, AllScopes: []string{auth.ScopeUsersReadAll}},
+			claims: auth.Claims{Roles: []string{auth.RoleAdmin}},
+			want:   false,
+		},
+		{name: "users read notes", rule: auth.RuleNotesRead, claims: user, want: true},
+		{name: "users write notes", rule: auth.RuleNotesWrite, claims: user, want: true},
+		{name: "users read themselves", rule: auth.RuleUsersReadSelf, claims: user, want: true},
+		{name: "users don't read all notes", rule: auth.RuleNotesReadAll, claims: user, want: false},
+		{name: "admins read all notes", rule: auth.RuleNotesReadAll, claims: admin, want: true},
+		{name: "admins read all users", rule: auth.RuleUsersReadAll, claims: admin, want: true},
+		{name: "admin role without scope", rule: auth.RuleUsersReadAll, claims: auth.Claims{Roles: []string{auth.RoleAdmin}}, want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.rule.Allows(tc.claims))
+		})
+	}
+}
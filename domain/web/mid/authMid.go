@@ -2,18 +2,23 @@ package mid
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 
 	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/core/notebook"
 	"github.com/Keisn1/note-taking-app/domain/web/auth"
 	"github.com/Keisn1/note-taking-app/foundation"
 	"github.com/Keisn1/note-taking-app/foundation/web"
 	"github.com/google/uuid"
 )
 
-func AuthorizeNote(ns note.Service) web.MidHandler {
+// AuthorizeNote loads the note of the request path into the context if the
+// user holds at least the required permission on it, either as its owner or
+// through a share. It has to run after Authenticate.
+func AuthorizeNote(ns note.Service, required note.Permission) web.MidHandler {
 	m := func(next http.Handler) http.Handler {
 		h := func(w http.ResponseWriter, r *http.Request) {
 			noteID, err := uuid.Parse(r.PathValue("note_id"))
@@ -29,7 +34,14 @@ func AuthorizeNote(ns note.Service) web.MidHandler {
 				return
 			}
 
-			if n.UserID != userID {
+			perm, err := ns.Permission(r.Context(), n, userID)
+			if err != nil {
+				http.Error(w, "", http.StatusForbidden)
+				slog.Error("failed note authorization", "noteID", noteID, "error", err)
+				return
+			}
+
+			if !perm.Allows(required) {
 				http.Error(w, "", http.StatusForbidden)
 				return
 			}
@@ -44,14 +56,67 @@ func AuthorizeNote(ns note.Service) web.MidHandler {
 	return m
 }
 
+// AuthorizeNotebook loads the notebook of the request path into the context
+// if it belongs to the user. Notebooks aren't shared, so only their owner gets
+// through. It has to run after Authenticate.
+func AuthorizeNotebook(nbs notebook.Service) web.MidHandler {
+	m := func(next http.Handler) http.Handler {
+		h := func(w http.ResponseWriter, r *http.Request) {
+			notebookID, err := uuid.Parse(r.PathValue("notebook_id"))
+			if err != nil {
+				http.Error(w, "", http.StatusForbidden)
+				return
+			}
+
+			nb, err := nbs.QueryByID(r.Context(), notebookID)
+			if err != nil {
+				http.Error(w, "", http.StatusForbidden)
+				return
+			}
+
+			if nb.UserID != GetUserID(r.Context()) {
+				http.Error(w, "", http.StatusForbidden)
+				return
+			}
+
+			ctx := setNotebook(r.Context(), nb)
+			r = r.WithContext(ctx)
+
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(h)
+	}
+	return m
+}
+
+// Authorize rejects requests whose claims don't satisfy rule. It has to run
+// after Authenticate.
+func Authorize(rule auth.Rule) web.MidHandler {
+	m := func(next http.Handler) http.Handler {
+		h := func(w http.ResponseWriter, r *http.Request) {
+			claims := GetClaims(r.Context())
+			if !rule.Allows(claims) {
+				http.Error(w, "", http.StatusForbidden)
+				slog.Info("failed authorization", "subject", claims.Subject, "rule", rule)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(h)
+	}
+	return m
+}
+
 func Authenticate(a auth.AuthInterface) web.MidHandler {
 	m := func(next http.Handler) http.Handler {
 		h := func(w http.ResponseWriter, r *http.Request) {
 			bearerToken := r.Header.Get("Authorization")
-			claims, err := a.Authenticate(bearerToken)
+			claims, err := a.Authenticate(r.Context(), bearerToken)
 			if err != nil {
-				http.Error(w, "failed authentication", http.StatusForbidden)
-				slog.Info("failed authentication")
+				w.Header().Set("WWW-Authenticate", wwwAuthenticate(err))
+				http.Error(w, "failed authentication: "+authFailureReason(err), http.StatusUnauthorized)
+				slog.Info("failed authentication", "error", err)
 				return
 			}
 
@@ -67,6 +132,35 @@ func Authenticate(a auth.AuthInterface) web.MidHandler {
 	return m
 }
 
+// authFailureReason tells the client why its token was rejected.
+func authFailureReason(err error) string {
+	switch {
+	case errors.Is(err, auth.ErrMissingToken):
+		return "missing bearer token"
+	case errors.Is(err, auth.ErrTokenExpired):
+		return "token expired"
+	case errors.Is(err, auth.ErrTokenNotYetValid):
+		return "token not yet valid"
+	case errors.Is(err, auth.ErrInvalidAudience):
+		return "invalid audience"
+	case errors.Is(err, auth.ErrInvalidIssuer):
+		return "invalid issuer"
+	case errors.Is(err, auth.ErrTokenRevoked):
+		return "token revoked"
+	default:
+		return "invalid token"
+	}
+}
+
+// wwwAuthenticate builds the challenge of RFC 6750. A request without a token
+// gets no error code.
+func wwwAuthenticate(err error) string {
+	if errors.Is(err, auth.ErrMissingToken) {
+		return "Bearer"
+	}
+	return fmt.Sprintf(`Bearer error="invalid_token", error_description=%q`, authFailureReason(err))
+}
+
 func setUserID(ctx context.Context, userID uuid.UUID) context.Context {
 	return context.WithValue(ctx, foundation.UserIDKey, userID)
 }
@@ -92,6 +186,18 @@ func GetNote(ctx context.Context) note.Note {
 	return n
 }
 
+func setNotebook(ctx context.Context, nb notebook.Notebook) context.Context {
+	return context.WithValue(ctx, foundation.NotebookKey, nb)
+}
+
+func GetNotebook(ctx context.Context) notebook.Notebook {
+	nb, ok := ctx.Value(foundation.NotebookKey).(notebook.Notebook)
+	if !ok {
+		return notebook.Notebook{}
+	}
+	return nb
+}
+
 func setClaims(ctx context.Context, claims auth.Claims) context.Context {
 	return context.WithValue(ctx, foundation.ClaimsKey, claims)
 }
@@ -10,10 +10,15 @@ import (
 	"time"
 
 	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/core/notebook"
+	nbMemory "github.com/Keisn1/note-taking-app/domain/core/notebook/repositories/memory"
+	"github.com/Keisn1/note-taking-app/domain/core/revocation/repositories/memory"
+	"github.com/Keisn1/note-taking-app/domain/data/transaction"
 	"github.com/Keisn1/note-taking-app/domain/web/auth"
 	"github.com/Keisn1/note-taking-app/domain/web/mid"
 	"github.com/Keisn1/note-taking-app/foundation"
 	"github.com/Keisn1/note-taking-app/foundation/common"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
@@ -22,8 +27,12 @@ func Test_Authorize(t *testing.T) {
 	userID := uuid.New()
 	noteID := uuid.New()
 	n := note.Note{ID: noteID, Title: note.NewTitle(""), Content: note.NewContent(""), UserID: userID}
-	sns := &StubNoteService{notes: map[uuid.UUID]note.Note{noteID: n}}
-	midAuthorize := mid.AuthorizeNote(sns)
+	reader, writer := uuid.New(), uuid.New()
+	sns := &StubNoteService{
+		notes:  map[uuid.UUID]note.Note{noteID: n},
+		shares: map[uuid.UUID]note.Permission{reader: note.PermissionRead, writer: note.PermissionWrite},
+	}
+	midAuthorize := mid.AuthorizeNote(sns, note.PermissionRead)
 
 	t.Run("Authorize success, retreived note set in context of request", func(t *testing.T) {
 		wantNote := n
@@ -61,6 +70,37 @@ func Test_Authorize(t *testing.T) {
 		assert.Equal(t, http.StatusForbidden, rr.Code)
 	})
 
+	t.Run("Authorize checks the permission of users the note is shared with", func(t *testing.T) {
+		testCases := []struct {
+			name       string
+			userID     uuid.UUID
+			required   note.Permission
+			wantStatus int
+		}{
+			{name: "reader can read", userID: reader, required: note.PermissionRead, wantStatus: http.StatusOK},
+			{name: "reader can't write", userID: reader, required: note.PermissionWrite, wantStatus: http.StatusForbidden},
+			{name: "writer can write", userID: writer, required: note.PermissionWrite, wantStatus: http.StatusOK},
+			{name: "writer isn't owner", userID: writer, required: note.PermissionOwner, wantStatus: http.StatusForbidden},
+			{name: "owner is owner", userID: userID, required: note.PermissionOwner, wantStatus: http.StatusOK},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				handler := mid.AuthorizeNote(sns, tc.required)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					assert.Equal(t, n, mid.GetNote(r.Context()))
+				}))
+
+				req := httptest.NewRequest(http.MethodGet, "/notImplemented", nil)
+				req.SetPathValue("note_id", noteID.String())
+				req = req.WithContext(context.WithValue(req.Context(), foundation.UserIDKey, tc.userID))
+				rr := httptest.NewRecorder()
+
+				handler.ServeHTTP(rr, req)
+				assert.Equal(t, tc.wantStatus, rr.Code)
+			})
+		}
+	})
+
 	t.Run("Authorize failure, note not present", func(t *testing.T) {
 		rr := httptest.NewRecorder()
 		req := httptest.NewRequest(http.MethodGet, "/notImplemented", nil)
@@ -77,14 +117,82 @@ func Test_Authorize(t *testing.T) {
 	})
 }
 
+func Test_AuthorizeNotebook(t *testing.T) {
+	userID := uuid.New()
+	nb := notebook.Notebook{ID: uuid.New(), UserID: userID, Name: "work"}
+	midAuthorize := mid.AuthorizeNotebook(notebook.NewSvc(nbMemory.NewRepo([]notebook.Notebook{nb}), nil, transaction.NewMemoryManager()))
+
+	testCases := []struct {
+		name       string
+		notebookID string
+		userID     uuid.UUID
+		wantStatus int
+	}{
+		{name: "owner gets the notebook", notebookID: nb.ID.String(), userID: userID, wantStatus: http.StatusOK},
+		{name: "other users are forbidden", notebookID: nb.ID.String(), userID: uuid.New(), wantStatus: http.StatusForbidden},
+		{name: "missing notebook is forbidden", notebookID: uuid.New().String(), userID: userID, wantStatus: http.StatusForbidden},
+		{name: "invalid id is forbidden", notebookID: "invalid", userID: userID, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := midAuthorize(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, nb, mid.GetNotebook(r.Context()))
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/notImplemented", nil)
+			req.SetPathValue("notebook_id", tc.notebookID)
+			req = req.WithContext(context.WithValue(req.Context(), foundation.UserIDKey, tc.userID))
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+			assert.Equal(t, tc.wantStatus, rr.Code)
+		})
+	}
+}
+
+func Test_AuthorizeRule(t *testing.T) {
+	var logBuf bytes.Buffer
+	log.SetOutput(&logBuf)
+
+	handler := mid.Authorize(auth.RuleAdmin)(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("Test Handler")) }),
+	)
+
+	testCases := []struct {
+		name       string
+		claims     auth.Claims
+		wantStatus int
+	}{
+		{name: "admin passes", claims: auth.Claims{Roles: []string{auth.RoleUser, auth.RoleAdmin}}, wantStatus: http.StatusOK},
+		{name: "user is forbidden", claims: auth.Claims{Roles: []string{auth.RoleUser}}, wantStatus: http.StatusForbidden},
+		{name: "no claims is forbidden", claims: auth.Claims{}, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logBuf.Reset()
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			req = req.WithContext(context.WithValue(req.Context(), foundation.ClaimsKey, tc.claims))
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			if tc.wantStatus == http.StatusForbidden {
+				assert.Contains(t, logBuf.String(), "failed authorization")
+			}
+		})
+	}
+}
+
 func Test_Authenticate(t *testing.T) {
 	var logBuf bytes.Buffer
 	log.SetOutput(&logBuf)
 
 	key := common.MustGenerateRandomKey(32)
-	jwtSvc, err := auth.NewJWTService(key)
+	jwtSvc, err := auth.NewJWTService(key, auth.WithAudience("notes-api"))
 	assert.NoError(t, err)
-	a := auth.NewAuth(jwtSvc)
+	a := auth.NewAuth(jwtSvc, memory.NewRepo())
 
 	midAuthenticate := mid.Authenticate(a)
 	handler := midAuthenticate(http.HandlerFunc(
@@ -100,7 +208,7 @@ func Test_Authenticate(t *testing.T) {
 		{
 			name: "Test authentication success",
 			setupHeader: func(req *http.Request) {
-				tokenS, _ := jwtSvc.CreateToken(userID, time.Minute)
+				tokenS, _ := jwtSvc.CreateToken(userID, nil, time.Minute)
 				req.Header.Set("Authorization", "Bearer "+tokenS)
 			},
 			assertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -112,8 +220,9 @@ func Test_Authenticate(t *testing.T) {
 			name:        "missing authentication header",
 			setupHeader: func(req *http.Request) {},
 			assertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				assert.Equal(t, http.StatusForbidden, recorder.Code)
-				assert.Contains(t, recorder.Body.String(), "failed authentication")
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+				assert.Equal(t, "Bearer", recorder.Header().Get("WWW-Authenticate"))
+				assert.Contains(t, recorder.Body.String(), "failed authentication: missing bearer token")
 				assert.Contains(t, logBuf.String(), "failed authentication")
 			},
 		},
@@ -121,29 +230,76 @@ func Test_Authenticate(t *testing.T) {
 			name:        "invalid authentication header",
 			setupHeader: func(req *http.Request) { req.Header.Set("Authorization", "invalid") },
 			assertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				assert.Equal(t, http.StatusForbidden, recorder.Code)
-				assert.Contains(t, recorder.Body.String(), "failed authentication")
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+				assert.Equal(t, "Bearer", recorder.Header().Get("WWW-Authenticate"))
+				assert.Contains(t, recorder.Body.String(), "failed authentication: missing bearer token")
 				assert.Contains(t, logBuf.String(), "failed authentication")
 			},
 		},
 		{
 			name: "expired token",
 			setupHeader: func(req *http.Request) {
-				tokenS, _ := jwtSvc.CreateToken(userID, -1*time.Minute)
+				tokenS, _ := jwtSvc.CreateToken(userID, nil, -1*time.Minute)
 				req.Header.Set("Authorization", "Bearer "+tokenS)
 			},
 			assertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				assert.Equal(t, http.StatusForbidden, recorder.Code)
-				assert.Contains(t, recorder.Body.String(), "failed authentication")
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+				assert.Equal(t, `Bearer error="invalid_token", error_description="token expired"`, recorder.Header().Get("WWW-Authenticate"))
+				assert.Contains(t, recorder.Body.String(), "failed authentication: token expired")
 				assert.Contains(t, logBuf.String(), "failed authentication")
 			},
 		},
+		{
+			name: "token not yet valid",
+			setupHeader: func(req *http.Request) {
+				claims := jwt.RegisteredClaims{
+					Subject:   userID.String(),
+					NotBefore: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(2 * time.Hour)),
+				}
+				tokenS, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+				req.Header.Set("Authorization", "Bearer "+tokenS)
+			},
+			assertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+				assert.Equal(t, `Bearer error="invalid_token", error_description="token not yet valid"`, recorder.Header().Get("WWW-Authenticate"))
+				assert.Contains(t, recorder.Body.String(), "failed authentication: token not yet valid")
+			},
+		},
+		{
+			name: "wrong audience",
+			setupHeader: func(req *http.Request) {
+				otherSvc := auth.MustNewJWTService(key, auth.WithAudience("other-api"))
+				tokenS, _ := otherSvc.CreateToken(userID, nil, time.Minute)
+				req.Header.Set("Authorization", "Bearer "+tokenS)
+			},
+			assertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+				assert.Equal(t, `Bearer error="invalid_token", error_description="invalid audience"`, recorder.Header().Get("WWW-Authenticate"))
+				assert.Contains(t, recorder.Body.String(), "failed authentication: invalid audience")
+			},
+		},
+		{
+			name: "revoked token",
+			setupHeader: func(req *http.Request) {
+				tokenS, _ := jwtSvc.CreateToken(userID, nil, time.Minute)
+				claims, _ := jwtSvc.Verify(tokenS)
+				assert.NoError(t, a.Revoke(context.Background(), claims))
+				req.Header.Set("Authorization", "Bearer "+tokenS)
+			},
+			assertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+				assert.Equal(t, `Bearer error="invalid_token", error_description="token revoked"`, recorder.Header().Get("WWW-Authenticate"))
+				assert.Contains(t, recorder.Body.String(), "failed authentication: token revoked")
+			},
+		},
 		{
 			name:        "invalid token",
 			setupHeader: func(req *http.Request) { req.Header.Set("Authorization", "Bearer invalid") },
 			assertions: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				assert.Equal(t, http.StatusForbidden, recorder.Code)
-				assert.Contains(t, recorder.Body.String(), "failed authentication")
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+				assert.Equal(t, `Bearer error="invalid_token", error_description="invalid token"`, recorder.Header().Get("WWW-Authenticate"))
+				assert.Contains(t, recorder.Body.String(), "failed authentication: invalid token")
 				assert.Contains(t, logBuf.String(), "failed authentication")
 			},
 		},
@@ -164,15 +320,15 @@ func Test_Authenticate(t *testing.T) {
 		key := common.MustGenerateRandomKey(32)
 		jwtSvc, err := auth.NewJWTService(key)
 		assert.NoError(t, err)
-		a := auth.NewAuth(jwtSvc)
+		a := auth.NewAuth(jwtSvc, memory.NewRepo())
 
 		midAuthenticate := mid.Authenticate(a)
 
 		wantUserID := uuid.New()
-		tokenS, err := jwtSvc.CreateToken(wantUserID, time.Minute)
+		tokenS, err := jwtSvc.CreateToken(wantUserID, nil, time.Minute)
 		assert.NoError(t, err)
 
-		wantClaims, err := a.Authenticate("Bearer " + tokenS)
+		wantClaims, err := a.Authenticate(context.Background(), "Bearer "+tokenS)
 		assert.NoError(t, err)
 
 		handler := midAuthenticate(http.HandlerFunc(
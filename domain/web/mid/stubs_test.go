@@ -2,21 +2,92 @@ package mid_test
 
 import (
 	"context"
+	"time"
 
 	"github.com/Keisn1/note-taking-app/domain/core/note"
 	"github.com/google/uuid"
 )
 
 type StubNoteService struct {
-	notes map[uuid.UUID]note.Note
+	notes  map[uuid.UUID]note.Note
+	shares map[uuid.UUID]note.Permission
 }
 
-func (ns StubNoteService) Delete(noteID uuid.UUID) error                { return nil }
-func (ns StubNoteService) Create(nN note.UpdateNote) (note.Note, error) { return note.Note{}, nil }
-func (ns StubNoteService) Update(n note.Note, newN note.UpdateNote) (note.Note, error) {
+func (ns StubNoteService) Delete(ctx context.Context, noteID uuid.UUID, version int) error {
+	return nil
+}
+func (ns StubNoteService) Create(ctx context.Context, nN note.UpdateNote) (note.Note, error) {
+	return note.Note{}, nil
+}
+func (ns StubNoteService) Update(ctx context.Context, n note.Note, newN note.UpdateNote) (note.Note, error) {
 	return note.Note{}, nil
 }
 func (ns StubNoteService) QueryByID(ctx context.Context, noteID uuid.UUID) (note.Note, error) {
 	return ns.notes[noteID], nil
 }
 func (ns StubNoteService) GetNotesByUserID(userID uuid.UUID) ([]note.Note, error) { return nil, nil }
+func (ns StubNoteService) Import(ctx context.Context, userID uuid.UUID, items []note.ImportItem) ([]note.ImportResult, error) {
+	return nil, nil
+}
+func (ns StubNoteService) Batch(ctx context.Context, userID uuid.UUID, ops []note.BatchOp, mode note.BatchMode) ([]note.BatchResult, error) {
+	return nil, nil
+}
+func (ns StubNoteService) StreamNotesByUserID(ctx context.Context, userID uuid.UUID, fn func(note.Note) error) error {
+	return nil
+}
+func (ns StubNoteService) Query(ctx context.Context, userID uuid.UUID, filter note.QueryFilter, orderBy note.OrderBy, page note.Page) (note.NotesPage, error) {
+	return note.NotesPage{}, nil
+}
+func (ns StubNoteService) QueryAll(ctx context.Context) ([]note.Note, error) { return nil, nil }
+func (ns StubNoteService) Search(ctx context.Context, userID uuid.UUID, query string) ([]note.SearchResult, error) {
+	return nil, nil
+}
+func (ns StubNoteService) Permission(ctx context.Context, n note.Note, userID uuid.UUID) (note.Permission, error) {
+	if n.UserID == userID {
+		return note.PermissionOwner, nil
+	}
+	return ns.shares[userID], nil
+}
+func (ns StubNoteService) Share(ctx context.Context, n note.Note, userID uuid.UUID, perm note.Permission) (note.Share, error) {
+	return note.Share{}, nil
+}
+func (ns StubNoteService) Unshare(ctx context.Context, noteID, userID uuid.UUID) error { return nil }
+func (ns StubNoteService) QueryShares(ctx context.Context, noteID uuid.UUID) ([]note.Share, error) {
+	return nil, nil
+}
+func (ns StubNoteService) QuerySharedWith(ctx context.Context, userID uuid.UUID) ([]note.Note, error) {
+	return nil, nil
+}
+func (ns StubNoteService) QueryTrash(ctx context.Context, userID uuid.UUID) ([]note.Note, error) {
+	return nil, nil
+}
+func (ns StubNoteService) Restore(ctx context.Context, noteID, userID uuid.UUID) (note.Note, error) {
+	return note.Note{}, nil
+}
+func (ns StubNoteService) PurgeTrash(ctx context.Context, retention time.Duration) (int, error) {
+	return 0, nil
+}
+func (ns StubNoteService) QueryRevisions(ctx context.Context, noteID uuid.UUID) ([]note.Revision, error) {
+	return nil, nil
+}
+func (ns StubNoteService) QueryRevision(ctx context.Context, noteID uuid.UUID, number int) (note.Revision, error) {
+	return note.Revision{}, nil
+}
+func (ns StubNoteService) DiffRevisions(ctx context.Context, noteID uuid.UUID, from, to int) (string, error) {
+	return "", nil
+}
+func (ns StubNoteService) RestoreRevision(ctx context.Context, n note.Note, number int, authorID uuid.UUID) (note.Note, error) {
+	return note.Note{}, nil
+}
+func (ns StubNoteService) AddTags(ctx context.Context, n note.Note, tags note.Tags) (note.Note, error) {
+	return note.Note{}, nil
+}
+func (ns StubNoteService) RemoveTags(ctx context.Context, n note.Note, tags note.Tags) (note.Note, error) {
+	return note.Note{}, nil
+}
+func (ns StubNoteService) RenameTag(ctx context.Context, userID uuid.UUID, from, to string) (int, error) {
+	return 0, nil
+}
+func (ns StubNoteService) QueryTags(ctx context.Context, userID uuid.UUID) ([]note.TagCount, error) {
+	return nil, nil
+}
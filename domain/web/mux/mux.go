@@ -2,13 +2,31 @@ package mux
 
 import (
 	"net/http"
+	"time"
 
+	"github.com/Keisn1/note-taking-app/domain/core/attachment"
+	"github.com/Keisn1/note-taking-app/domain/core/note"
+	"github.com/Keisn1/note-taking-app/domain/core/notebook"
+	"github.com/Keisn1/note-taking-app/domain/core/session"
+	"github.com/Keisn1/note-taking-app/domain/core/sharelink"
+	"github.com/Keisn1/note-taking-app/domain/core/user"
 	"github.com/Keisn1/note-taking-app/domain/web/auth"
 	"github.com/Keisn1/note-taking-app/foundation/web"
 )
 
 type Config struct {
-	Auth auth.Auth
+	Auth        auth.Auth
+	JWTSvc      auth.JWTService
+	KeyStore    *auth.KeyStore
+	TokenTTL    time.Duration
+	NoteSvc     note.Service
+	UserSvc     user.Service
+	SessionSvc  session.Service
+	LinkSvc     sharelink.Service
+	NotebookSvc notebook.Service
+
+	AttachmentSvc     attachment.Service
+	MaxAttachmentSize int64
 }
 
 type RouteAdder func(api *web.App, cfg Config)
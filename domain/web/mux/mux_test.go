@@ -5,13 +5,16 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/Keisn1/note-taking-app/domain/core/revocation/repositories/memory"
 	"github.com/Keisn1/note-taking-app/domain/web/auth"
 	"github.com/Keisn1/note-taking-app/domain/web/mid"
 	"github.com/Keisn1/note-taking-app/domain/web/mux"
 	"github.com/Keisn1/note-taking-app/foundation/common"
 	"github.com/Keisn1/note-taking-app/foundation/web"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -47,7 +50,7 @@ func TestServer(t *testing.T) {
 
 	t.Run("Example with authentication", func(t *testing.T) {
 		key := common.MustGenerateRandomKey(32)
-		cfg := mux.Config{Auth: auth.NewAuth(auth.MustNewJWTService(key))}
+		cfg := mux.Config{Auth: auth.NewAuth(auth.MustNewJWTService(key), memory.NewRepo())}
 
 		testRoutes := func(api *web.App, cfg mux.Config) {
 			authen := mid.Authenticate(cfg.Auth)
@@ -64,16 +67,31 @@ func TestServer(t *testing.T) {
 		}{
 			{
 				setupHeader: func(r *http.Request) {},
-				statusCode:  http.StatusForbidden,
+				statusCode:  http.StatusUnauthorized,
 				want:        "failed authentication",
 			},
 			{
+				// tokens without exp are rejected
 				setupHeader: func(r *http.Request) {
 					token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{})
 					tokenS, err := token.SignedString(key)
 					assert.NoError(t, err)
 					r.Header.Set("Authorization", "Bearer "+tokenS)
 				},
+				statusCode: http.StatusUnauthorized,
+				want:       "failed authentication: invalid token",
+			},
+			{
+				setupHeader: func(r *http.Request) {
+					token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+						"sub": uuid.New().String(),
+						"jti": uuid.New().String(),
+						"exp": time.Now().Add(time.Minute).Unix(),
+					})
+					tokenS, err := token.SignedString(key)
+					assert.NoError(t, err)
+					r.Header.Set("Authorization", "Bearer "+tokenS)
+				},
 				statusCode: http.StatusOK,
 				want:       "Hello from fetch",
 			},
@@ -1,6 +1,10 @@
 package common
 
-import "crypto/rand"
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
 
 func MustGenerateRandomKey(keyLength int) []byte {
 	key := make([]byte, keyLength)
@@ -10,3 +14,23 @@ func MustGenerateRandomKey(keyLength int) []byte {
 	}
 	return key
 }
+
+// tokenLength is the number of random bytes of a token.
+const tokenLength = 32
+
+// NewToken returns a random URL-safe token, as handed out for refresh tokens
+// and share links.
+func NewToken() (string, error) {
+	b := make([]byte, tokenLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken hashes a token of NewToken for storage. The token carries 256
+// bits of randomness, so a fast unsalted hash is sufficient.
+func HashToken(token string) []byte {
+	h := sha256.Sum256([]byte(token))
+	return h[:]
+}
@@ -0,0 +1,105 @@
+// Package enex reads the notes of Evernote exports. The content of the notes
+// is converted from ENML, the XHTML dialect of Evernote, to Markdown.
+package enex
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// timeLayout is the layout of created and updated.
+const timeLayout = "20060102T150405Z"
+
+var ErrNotENEX = errors.New("not an Evernote export")
+
+type Note struct {
+	Title   string
+	Content string
+	Tags    []string
+	Created time.Time
+	Updated time.Time
+	// Err is set if the note couldn't be read, the other notes of the
+	// export are still returned.
+	Err error
+}
+
+type xmlNote struct {
+	Title   string   `xml:"title"`
+	Content string   `xml:"content"`
+	Created string   `xml:"created"`
+	Updated string   `xml:"updated"`
+	Tags    []string `xml:"tag"`
+}
+
+// Parse reads the notes of an export one by one, so that the attachments
+// they contain are not kept in memory. Attachments are not imported.
+func Parse(r io.Reader) ([]Note, error) {
+	d := xml.NewDecoder(r)
+	// Evernote declares the DTD of its exports, whose entities aren't read
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+
+	var notes []Note
+	root := false
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse: %w", err)
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "en-export":
+			root = true
+		case "note":
+			if !root {
+				return nil, ErrNotENEX
+			}
+			var xn xmlNote
+			if err := d.DecodeElement(&xn, &start); err != nil {
+				return nil, fmt.Errorf("parse: %w", err)
+			}
+			notes = append(notes, xn.note())
+		default:
+			if !root {
+				return nil, ErrNotENEX
+			}
+		}
+	}
+	if !root {
+		return nil, ErrNotENEX
+	}
+	return notes, nil
+}
+
+func (xn xmlNote) note() Note {
+	n := Note{Title: strings.TrimSpace(xn.Title), Tags: xn.Tags}
+	var err error
+	if n.Content, err = ToMarkdown(xn.Content); err != nil {
+		n.Err = err
+		return n
+	}
+	for _, t := range []struct {
+		value string
+		dst   *time.Time
+	}{{xn.Created, &n.Created}, {xn.Updated, &n.Updated}} {
+		if t.value == "" {
+			continue
+		}
+		if *t.dst, err = time.Parse(timeLayout, strings.TrimSpace(t.value)); err != nil {
+			n.Err = fmt.Errorf("invalid timestamp %q", t.value)
+			return n
+		}
+	}
+	return n
+}
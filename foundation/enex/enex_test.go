@@ -0,0 +1,95 @@
+package enex_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/foundation/enex"
+	"github.com/stretchr/testify/assert"
+)
+
+const export = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE en-export SYSTEM "http://xml.evernote.com/pub/evernote-export3.dtd">
+<en-export export-date="20240102T100000Z" application="Evernote" version="10.0">
+  <note>
+    <title>Groceries</title>
+    <created>20240101T120000Z</created>
+    <updated>20240101T130000Z</updated>
+    <tag>home</tag>
+    <tag>shopping</tag>
+    <content><![CDATA[<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<!DOCTYPE en-note SYSTEM "http://xml.evernote.com/pub/enml2.dtd">
+<en-note><div><en-todo checked="true"/>milk</div><div><en-todo/>eggs&nbsp;<b>now</b></div><en-media hash="f00" type="image/png"/></en-note>]]></content>
+    <resource><data encoding="base64">aGVsbG8=</data><mime>image/png</mime></resource>
+  </note>
+  <note>
+    <title>Broken</title>
+    <created>yesterday</created>
+    <content><![CDATA[<en-note>x</en-note>]]></content>
+  </note>
+</en-export>`
+
+func TestParse(t *testing.T) {
+	t.Run("Reads the notes of an export", func(t *testing.T) {
+		notes, err := enex.Parse(strings.NewReader(export))
+		assert.NoError(t, err)
+		assert.Len(t, notes, 2)
+
+		assert.Equal(t, enex.Note{
+			Title:   "Groceries",
+			Content: "[x] milk\n\n[ ] eggs **now**\n",
+			Tags:    []string{"home", "shopping"},
+			Created: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			Updated: time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC),
+		}, notes[0])
+
+		assert.Equal(t, "Broken", notes[1].Title)
+		assert.Error(t, notes[1].Err)
+	})
+
+	t.Run("Rejects other XML", func(t *testing.T) {
+		for _, s := range []string{"<rss><note/></rss>", "", "not xml at all"} {
+			_, err := enex.Parse(strings.NewReader(s))
+			assert.ErrorIs(t, err, enex.ErrNotENEX, s)
+		}
+	})
+}
+
+func TestToMarkdown(t *testing.T) {
+	testCases := []struct {
+		name string
+		enml string
+		want string
+	}{
+		{
+			name: "Divs are paragraphs, text is escaped",
+			enml: `<en-note><div>Hello <b>world </b>&amp; *stars*</div><div><br/></div><div>second<br/>line<br/></div></en-note>`,
+			want: "Hello **world** \\& \\*stars\\*\n\nsecond\\\nline\n",
+		},
+		{
+			name: "Headings and nested lists",
+			enml: `<en-note><h2>Title</h2><ul><li><div>a</div></li><li>b<ul><li>c</li></ul></li></ul><ol start="3"><li>x</li><li>y</li></ol></en-note>`,
+			want: "## Title\n\n- a\n- b\n  - c\n\n3. x\n4. y\n",
+		},
+		{
+			name: "Links, code and quotes",
+			enml: "<en-note><a href=\"https://e.com/a b\">link</a> <code>x`y</code><pre>fn()\n  indented</pre><blockquote><div>q1</div><div>q2</div></blockquote><hr/></en-note>",
+			want: "[link](<https://e.com/a b>) ``x`y``\n\n```\nfn()\n  indented\n```\n\n> q1\n>\n> q2\n\n---\n",
+		},
+		{
+			name: "Tables",
+			enml: `<en-note><table><tr><td>a</td><td>b|c</td></tr><tr><td>1</td></tr></table></en-note>`,
+			want: "| a | b\\|c |\n| --- | --- |\n| 1 |  |\n",
+		},
+		{name: "Empty notes", enml: `<en-note><div><br/></div></en-note>`, want: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := enex.ToMarkdown(tc.enml)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
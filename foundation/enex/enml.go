@@ -0,0 +1,387 @@
+package enex
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Keisn1/note-taking-app/foundation/markdown"
+)
+
+// node is an element of ENML, or a text if name is empty.
+type node struct {
+	name     string
+	attrs    map[string]string
+	text     string
+	children []*node
+}
+
+// ToMarkdown converts ENML to Markdown. Formatting Markdown has no syntax
+// for, like colors, is dropped, and so are attachments and encrypted text.
+func ToMarkdown(enml string) (string, error) {
+	root, err := parseENML(enml)
+	if err != nil {
+		return "", err
+	}
+	blocks := renderBlocks(root.children)
+	if len(blocks) == 0 {
+		return "", nil
+	}
+	return strings.Join(blocks, "\n\n") + "\n", nil
+}
+
+func parseENML(enml string) (*node, error) {
+	d := xml.NewDecoder(strings.NewReader(enml))
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+
+	root := &node{name: "root"}
+	stack := []*node{root}
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return root, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parseENML: %w", err)
+		}
+
+		top := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &node{name: strings.ToLower(t.Name.Local), attrs: make(map[string]string)}
+			for _, a := range t.Attr {
+				n.attrs[strings.ToLower(a.Name.Local)] = a.Value
+			}
+			top.children = append(top.children, n)
+			stack = append(stack, n)
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].name == name {
+					stack = stack[:i]
+					break
+				}
+			}
+		case xml.CharData:
+			top.children = append(top.children, &node{text: string(t)})
+		}
+	}
+}
+
+var blockElements = map[string]bool{
+	"en-note": true, "div": true, "p": true, "center": true, "section": true, "article": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"ul": true, "ol": true, "li": true, "blockquote": true, "pre": true, "hr": true,
+	"table": true, "thead": true, "tbody": true, "tfoot": true, "tr": true,
+	"dl": true, "dt": true, "dd": true,
+}
+
+// droppedElements are left out with their content.
+var droppedElements = map[string]bool{"en-media": true, "en-crypt": true, "script": true, "style": true, "head": true}
+
+// renderBlocks renders the blocks of children. Runs of inline children form a
+// paragraph.
+func renderBlocks(children []*node) []string {
+	var blocks []string
+	var inline strings.Builder
+	flush := func() {
+		if p := paragraph(inline.String()); p != "" {
+			blocks = append(blocks, p)
+		}
+		inline.Reset()
+	}
+
+	for _, n := range children {
+		if n.name == "" || !blockElements[n.name] {
+			inline.WriteString(renderInline(n))
+			continue
+		}
+		flush()
+		blocks = append(blocks, renderBlock(n)...)
+	}
+	flush()
+	return blocks
+}
+
+func renderBlock(n *node) []string {
+	switch n.name {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		text := strings.Join(strings.Fields(renderInlines(n.children)), " ")
+		if text == "" {
+			return nil
+		}
+		level, _ := strconv.Atoi(n.name[1:])
+		return []string{strings.Repeat("#", level) + " " + text}
+	case "hr":
+		return []string{"---"}
+	case "pre":
+		return []string{codeBlock(textOf(n))}
+	case "blockquote":
+		inner := strings.Join(renderBlocks(n.children), "\n\n")
+		if inner == "" {
+			return nil
+		}
+		lines := strings.Split(inner, "\n")
+		for i, l := range lines {
+			lines[i] = strings.TrimRight("> "+l, " ")
+		}
+		return []string{strings.Join(lines, "\n")}
+	case "ul", "ol":
+		if l := renderList(n); l != "" {
+			return []string{l}
+		}
+		return nil
+	case "table":
+		if t := renderTable(n); t != "" {
+			return []string{t}
+		}
+		return nil
+	}
+	return renderBlocks(n.children)
+}
+
+// renderList renders the items of a list, lists directly inside the list
+// are nested into the previous item.
+func renderList(n *node) string {
+	number := 1
+	if start, err := strconv.Atoi(n.attrs["start"]); err == nil && start >= 0 {
+		number = start
+	}
+
+	var items []string
+	for _, c := range n.children {
+		var content string
+		switch c.name {
+		case "li":
+			content = strings.Join(renderBlocks(c.children), "\n")
+		case "ul", "ol":
+			if len(items) > 0 {
+				items[len(items)-1] += "\n" + indent(renderList(c), "  ")
+				continue
+			}
+			content = renderList(c)
+		case "":
+			// whitespace between the items
+			if strings.TrimSpace(c.text) == "" {
+				continue
+			}
+			content = paragraph(renderInline(c))
+		default:
+			content = strings.Join(renderBlock(c), "\n")
+		}
+
+		marker := "- "
+		if n.name == "ol" {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+		pad := strings.Repeat(" ", len(marker))
+		items = append(items, marker+strings.TrimPrefix(indent(content, pad), pad))
+	}
+	return strings.Join(items, "\n")
+}
+
+// indent indents all non-empty lines of s.
+func indent(s, prefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = prefix + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderTable renders a table with its first row as header.
+func renderTable(n *node) string {
+	var rows [][]string
+	var collect func(n *node)
+	collect = func(n *node) {
+		for _, c := range n.children {
+			switch c.name {
+			case "thead", "tbody", "tfoot":
+				collect(c)
+			case "tr":
+				var cells []string
+				for _, td := range c.children {
+					if td.name == "td" || td.name == "th" {
+						// pipes in the text are escaped by markdown.Escape
+						cells = append(cells, strings.Join(strings.Fields(strings.Join(renderBlocks(td.children), " ")), " "))
+					}
+				}
+				rows = append(rows, cells)
+			}
+		}
+	}
+	collect(n)
+
+	cols := 0
+	for _, r := range rows {
+		cols = max(cols, len(r))
+	}
+	if cols == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for i, r := range rows {
+		for len(r) < cols {
+			r = append(r, "")
+		}
+		b.WriteString("| " + strings.Join(r, " | ") + " |\n")
+		if i == 0 {
+			b.WriteString("|" + strings.Repeat(" --- |", cols) + "\n")
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func renderInlines(nodes []*node) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		b.WriteString(renderInline(n))
+	}
+	return b.String()
+}
+
+func renderInline(n *node) string {
+	if n.name == "" {
+		return markdown.Escape(collapseSpace(n.text))
+	}
+	if droppedElements[n.name] {
+		return ""
+	}
+
+	switch n.name {
+	case "br":
+		return "\\\n"
+	case "b", "strong":
+		return wrap(renderInlines(n.children), "**")
+	case "i", "em":
+		return wrap(renderInlines(n.children), "*")
+	case "s", "strike", "del":
+		return wrap(renderInlines(n.children), "~~")
+	case "code", "tt":
+		return codeSpan(collapseSpace(textOf(n)))
+	case "en-todo":
+		if n.attrs["checked"] == "true" {
+			return "[x] "
+		}
+		return "[ ] "
+	case "a":
+		text := renderInlines(n.children)
+		href := strings.TrimSpace(n.attrs["href"])
+		if href == "" || strings.TrimSpace(text) == "" {
+			return text
+		}
+		return "[" + text + "](<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(href) + ">)"
+	case "img":
+		src := n.attrs["src"]
+		if !strings.HasPrefix(src, "http://") && !strings.HasPrefix(src, "https://") {
+			return ""
+		}
+		return "![" + markdown.Escape(collapseSpace(n.attrs["alt"])) + "](<" + src + ">)"
+	}
+	return renderInlines(n.children)
+}
+
+// paragraph trims the lines of inline content, a trailing hard break is
+// dropped.
+func paragraph(s string) string {
+	lines := strings.Split(s, "\n")
+	var kept []string
+	for _, l := range lines {
+		if l = strings.TrimSpace(l); l != "" {
+			kept = append(kept, l)
+		}
+	}
+	p := strings.Join(kept, "\n")
+	for strings.HasSuffix(p, `\`) && !strings.HasSuffix(p, `\\`) {
+		p = strings.TrimSpace(strings.TrimSuffix(p, `\`))
+	}
+	return p
+}
+
+// wrap puts delimiters around s. Spaces at the ends of s are moved outside,
+// where they don't keep the delimiters from being emphasis.
+func wrap(s, delim string) string {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return s
+	}
+	start := s[:strings.Index(s, trimmed)]
+	end := s[len(start)+len(trimmed):]
+	return start + delim + trimmed + delim + end
+}
+
+func codeSpan(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return s
+	}
+	fence := strings.Repeat("`", longestRun(s, '`')+1)
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		return fence + " " + s + " " + fence
+	}
+	return fence + s + fence
+}
+
+func codeBlock(s string) string {
+	s = strings.Trim(s, "\n")
+	fence := strings.Repeat("`", max(3, longestRun(s, '`')+1))
+	return fence + "\n" + s + "\n" + fence
+}
+
+func longestRun(s string, c byte) int {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != c {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+	return longest
+}
+
+// textOf returns the text inside n, line breaks included.
+func textOf(n *node) string {
+	if n.name == "" {
+		return n.text
+	}
+	if n.name == "br" {
+		return "\n"
+	}
+	var b strings.Builder
+	for _, c := range n.children {
+		b.WriteString(textOf(c))
+		if blockElements[c.name] && c.name != "" {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func collapseSpace(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	if space {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
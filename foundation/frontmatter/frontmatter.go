@@ -0,0 +1,110 @@
+// Package frontmatter reads Markdown files with a YAML front matter, as
+// written by Obsidian, Jekyll, Hugo and the export of the notes.
+package frontmatter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrNoEnd = errors.New("the front matter is not closed by ---")
+
+// File is a Markdown file. Only title, format, tags, created_at and
+// updated_at are read from the front matter, the fields of the keys it
+// doesn't hold are left empty.
+type File struct {
+	// Title is nil if the front matter has no title.
+	Title   *string
+	Format  string
+	Tags    []string
+	Created time.Time
+	Updated time.Time
+	Content string
+}
+
+type frontMatter struct {
+	Title   *string   `yaml:"title"`
+	Format  string    `yaml:"format"`
+	Tags    tags      `yaml:"tags"`
+	Created timestamp `yaml:"created_at"`
+	Updated timestamp `yaml:"updated_at"`
+}
+
+// Parse reads a Markdown file with or without front matter. Windows line
+// endings and a byte order mark are removed from the content.
+func Parse(data []byte) (File, error) {
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+	content = strings.TrimPrefix(content, "\ufeff")
+
+	rest, ok := strings.CutPrefix(content, "---\n")
+	if !ok {
+		return File{Content: content}, nil
+	}
+	fm, body, found := cut(rest)
+	if !found {
+		return File{}, ErrNoEnd
+	}
+
+	var m frontMatter
+	if err := yaml.Unmarshal([]byte(fm), &m); err != nil {
+		return File{}, fmt.Errorf("front matter: %w", err)
+	}
+	return File{
+		Title:   m.Title,
+		Format:  m.Format,
+		Tags:    m.Tags,
+		Created: time.Time(m.Created),
+		Updated: time.Time(m.Updated),
+		Content: body,
+	}, nil
+}
+
+// cut splits s after the opening --- into the front matter and the body. YAML
+// allows for ... to end the front matter as well.
+func cut(s string) (fm, body string, found bool) {
+	for _, end := range []string{"---", "..."} {
+		if strings.HasPrefix(s, end+"\n") || s == end {
+			return "", strings.TrimPrefix(s[len(end):], "\n"), true
+		}
+		if i := strings.Index(s, "\n"+end+"\n"); i >= 0 {
+			return s[:i], s[i+len(end)+2:], true
+		}
+		if strings.HasSuffix(s, "\n"+end) {
+			return s[:len(s)-len(end)-1], "", true
+		}
+	}
+	return "", "", false
+}
+
+// tags reads a sequence of tags or a single one.
+type tags []string
+
+func (t *tags) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*t = tags{value.Value}
+		return nil
+	}
+	return value.Decode((*[]string)(t))
+}
+
+// timestamp reads the timestamps of the export and dates, which other tools
+// write.
+type timestamp time.Time
+
+func (ts *timestamp) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: invalid timestamp", value.Line)
+	}
+	t, err := time.Parse("2006-01-02", value.Value)
+	if err != nil {
+		if t, err = time.Parse(time.RFC3339Nano, value.Value); err != nil {
+			return fmt.Errorf("line %d: invalid timestamp %q", value.Line, value.Value)
+		}
+	}
+	*ts = timestamp(t)
+	return nil
+}
@@ -0,0 +1,66 @@
+package frontmatter_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/foundation/frontmatter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("Reads the front matter of the export", func(t *testing.T) {
+		data := "---\nid: 01000000-0000-0000-0000-000000000000\ntitle: \"say \\\"hi\\\": it's # me\"\n" +
+			"format: plain\ntags: [\"go\", \"to-do\"]\ncreated_at: 2024-01-01T10:00:00Z\n" +
+			"updated_at: 2024-01-01T11:00:00.5Z\n---\n# hi\n\n---\n"
+
+		got, err := frontmatter.Parse([]byte(data))
+		assert.NoError(t, err)
+		title := `say "hi": it's # me`
+		assert.Equal(t, frontmatter.File{
+			Title:   &title,
+			Format:  "plain",
+			Tags:    []string{"go", "to-do"},
+			Created: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+			Updated: time.Date(2024, 1, 1, 11, 0, 0, 5e8, time.UTC),
+			Content: "# hi\n\n---\n",
+		}, got)
+	})
+
+	t.Run("Files without front matter are content only", func(t *testing.T) {
+		got, err := frontmatter.Parse([]byte("\ufeff- milk\r\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, frontmatter.File{Content: "- milk\n"}, got)
+	})
+
+	t.Run("Front matter of other tools", func(t *testing.T) {
+		data := "---\ntitle: 'it''s mine' # a comment\naliases: [x]\ntags:\n  - Work\n  - \"to do\"\ncreated_at: 2024-02-01\n...\ncontent"
+
+		got, err := frontmatter.Parse([]byte(data))
+		assert.NoError(t, err)
+		assert.Equal(t, "it's mine", *got.Title)
+		assert.Equal(t, []string{"Work", "to do"}, got.Tags)
+		assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), got.Created)
+		assert.Equal(t, "content", got.Content)
+
+		got, err = frontmatter.Parse([]byte("---\ntags: work\n---\n"))
+		assert.NoError(t, err)
+		assert.Nil(t, got.Title)
+		assert.Equal(t, []string{"work"}, got.Tags)
+	})
+
+	t.Run("Invalid front matter", func(t *testing.T) {
+		_, err := frontmatter.Parse([]byte("---\ntitle: x\ncontent"))
+		assert.ErrorIs(t, err, frontmatter.ErrNoEnd)
+
+		for _, data := range []string{
+			"---\ntags: [\"a\", b\n---\n",
+			"---\ntags: {a: b}\n---\n",
+			"---\ncreated_at: yesterday\n---\n",
+			"---\n- a list\n---\n",
+		} {
+			_, err := frontmatter.Parse([]byte(data))
+			assert.Error(t, err, data)
+		}
+	})
+}
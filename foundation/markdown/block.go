@@ -0,0 +1,495 @@
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type blockKind int
+
+const (
+	paragraph blockKind = iota
+	heading
+	thematicBreak
+	codeBlock
+	htmlBlock
+	blockQuote
+	list
+	listItem
+	table
+)
+
+type block struct {
+	kind blockKind
+	// text is the inline text of paragraphs and headings, the contents of
+	// code blocks and the raw HTML of HTML blocks.
+	text     string
+	level    int
+	info     string
+	children []*block
+
+	ordered bool
+	start   int
+	tight   bool
+
+	align []string
+	rows  [][]string
+}
+
+var (
+	atxHeading      = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	setextUnderline = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	thematicBreakRe = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	fenceOpen       = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \\t]*([^`]*?)[ \\t]*$")
+	listMarkerRe    = regexp.MustCompile(`^( {0,3})([-+*]|\d{1,9}[.)])( +|$)`)
+	htmlBlockStart  = regexp.MustCompile(`^ {0,3}(?:<!--|<(/?)([A-Za-z][A-Za-z0-9-]*)(?:[ \t/>]|$))`)
+	tableDelimiter  = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+)
+
+// htmlBlockTags are the elements whose HTML blocks may interrupt a
+// paragraph.
+var htmlBlockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "details": true, "div": true,
+	"dl": true, "figure": true, "footer": true, "h1": true, "h2": true, "h3": true, "h4": true,
+	"h5": true, "h6": true, "header": true, "hr": true, "li": true, "ol": true, "p": true, "pre": true,
+	"section": true, "summary": true, "table": true, "ul": true, "script": true, "style": true,
+}
+
+// maxNesting is the number of block quotes and lists that may be nested in
+// each other. Every level looks at the lines of the levels below again, the
+// markers of deeper ones are read as text to keep that linear in the size of
+// the document.
+const maxNesting = 32
+
+// parseBlocks splits lines into blocks. loose reports whether blank lines
+// separate any of them, which makes a list item loose. depth is the number of
+// block quotes and lists lines are nested in.
+func parseBlocks(lines []string, depth int) (blocks []*block, loose bool) {
+	blankBefore := false
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if isBlank(line) {
+			blankBefore = len(blocks) > 0
+			i++
+			continue
+		}
+		if blankBefore {
+			loose = true
+			blankBefore = false
+		}
+
+		var bl *block
+		switch {
+		case indentation(line) >= 4:
+			bl, i = parseIndentedCode(lines, i)
+		case fenceOpen.MatchString(line):
+			bl, i = parseFencedCode(lines, i)
+		case atxHeading.MatchString(line):
+			m := atxHeading.FindStringSubmatch(line)
+			bl = &block{kind: heading, level: len(m[1]), text: strings.TrimSpace(m[2])}
+			i++
+		case thematicBreakRe.MatchString(line):
+			bl = &block{kind: thematicBreak}
+			i++
+		case depth < maxNesting && isBlockQuote(line):
+			bl, i = parseBlockQuote(lines, i, depth)
+		case depth < maxNesting && listMarkerRe.MatchString(line):
+			bl, i = parseList(lines, i, depth)
+		case isHTMLBlockStart(line, false):
+			bl, i = parseHTMLBlock(lines, i)
+		case isTableStart(lines, i):
+			bl, i = parseTable(lines, i)
+		default:
+			bl, i = parseParagraph(lines, i)
+		}
+		blocks = append(blocks, bl)
+	}
+	return blocks, loose
+}
+
+func parseIndentedCode(lines []string, i int) (*block, int) {
+	var code []string
+	for ; i < len(lines) && (isBlank(lines[i]) || indentation(lines[i]) >= 4); i++ {
+		code = append(code, trimIndent(lines[i], 4))
+	}
+	for len(code) > 0 && isBlank(code[len(code)-1]) {
+		code = code[:len(code)-1]
+	}
+	return &block{kind: codeBlock, text: strings.Join(code, "\n") + "\n"}, i
+}
+
+func parseFencedCode(lines []string, i int) (*block, int) {
+	m := fenceOpen.FindStringSubmatch(lines[i])
+	indent, fence := len(m[1]), m[2]
+	info, _, _ := strings.Cut(unescapeBackslashes(m[3]), " ")
+
+	var code []string
+	for i++; i < len(lines); i++ {
+		closing := strings.TrimSpace(lines[i])
+		if indentation(lines[i]) < 4 && strings.HasPrefix(closing, fence) && strings.Trim(closing, fence[:1]) == "" {
+			i++
+			break
+		}
+		code = append(code, trimIndent(lines[i], indent))
+	}
+
+	text := strings.Join(code, "\n")
+	if len(code) > 0 {
+		text += "\n"
+	}
+	return &block{kind: codeBlock, text: text, info: info}, i
+}
+
+func isBlockQuote(line string) bool {
+	return indentation(line) < 4 && strings.HasPrefix(strings.TrimLeft(line, " "), ">")
+}
+
+// parseBlockQuote strips the markers of the quote. Lines without a marker
+// continue a paragraph at the end of the quote.
+func parseBlockQuote(lines []string, i, depth int) (*block, int) {
+	var inner []string
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isBlockQuote(line) {
+			line = strings.TrimPrefix(strings.TrimLeft(line, " "), ">")
+			inner = append(inner, strings.TrimPrefix(line, " "))
+			continue
+		}
+		if isBlank(line) || len(inner) == 0 || isBlank(inner[len(inner)-1]) || interruptsParagraph(line) {
+			break
+		}
+		inner = append(inner, line)
+	}
+
+	children, _ := parseBlocks(inner, depth+1)
+	return &block{kind: blockQuote, children: children}, i
+}
+
+type listMarker struct {
+	ordered bool
+	// delim is the bullet character or the character after the number.
+	delim         byte
+	start         int
+	contentIndent int
+	empty         bool
+}
+
+func parseListMarker(line string) (listMarker, bool) {
+	m := listMarkerRe.FindStringSubmatch(line)
+	if m == nil {
+		return listMarker{}, false
+	}
+
+	marker := m[2]
+	lm := listMarker{delim: marker[len(marker)-1], empty: isBlank(line[len(m[0]):])}
+	if len(marker) > 1 || marker[0] >= '0' && marker[0] <= '9' {
+		lm.ordered = true
+		lm.start, _ = strconv.Atoi(marker[:len(marker)-1])
+	}
+
+	spaces := len(m[3])
+	if spaces == 0 || spaces > 4 || isBlank(line[len(m[0]):]) {
+		spaces = 1
+	}
+	lm.contentIndent = len(m[1]) + len(marker) + spaces
+	return lm, true
+}
+
+// parseList collects the items of a list, which have to use the same kind of
+// marker. The lines of an item are the ones indented past its marker.
+func parseList(lines []string, i, depth int) (*block, int) {
+	first, _ := parseListMarker(lines[i])
+	l := &block{kind: list, ordered: first.ordered, start: first.start, tight: true}
+
+	for i < len(lines) {
+		// items separated by blank lines make the list loose
+		next := i
+		for next < len(lines) && isBlank(lines[next]) {
+			next++
+		}
+		if next == len(lines) {
+			break
+		}
+		m, ok := parseListMarker(lines[next])
+		if !ok || m.ordered != first.ordered || m.delim != first.delim || thematicBreakRe.MatchString(lines[next]) {
+			break
+		}
+		if next > i {
+			l.tight = false
+		}
+		i = next
+
+		itemLines := []string{""}
+		if len(lines[i]) > m.contentIndent {
+			itemLines[0] = lines[i][m.contentIndent:]
+		}
+		inFence := fenceOpen.MatchString(itemLines[0])
+	collect:
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			prev := itemLines[len(itemLines)-1]
+			switch {
+			case isBlank(line):
+				itemLines = append(itemLines, "")
+				continue
+			case indentation(line) >= m.contentIndent:
+				line = line[m.contentIndent:]
+			case !isBlank(prev) && !inFence && !interruptsParagraph(line) && !listMarkerRe.MatchString(line):
+				// lazy continuation of a paragraph
+			default:
+				break collect
+			}
+			if fenceOpen.MatchString(line) {
+				inFence = !inFence
+			}
+			itemLines = append(itemLines, line)
+		}
+
+		// trailing blank lines are left to the caller
+		for len(itemLines) > 1 && isBlank(itemLines[len(itemLines)-1]) {
+			itemLines = itemLines[:len(itemLines)-1]
+			i--
+		}
+		children, loose := parseBlocks(itemLines, depth+1)
+		if loose {
+			l.tight = false
+		}
+		l.children = append(l.children, &block{kind: listItem, children: children})
+	}
+	return l, i
+}
+
+func parseHTMLBlock(lines []string, i int) (*block, int) {
+	var raw []string
+	for ; i < len(lines) && !isBlank(lines[i]); i++ {
+		raw = append(raw, lines[i])
+	}
+	return &block{kind: htmlBlock, text: strings.Join(raw, "\n") + "\n"}, i
+}
+
+// isHTMLBlockStart reports whether line starts a block of raw HTML. That is a
+// comment, a block-level element or, unless inParagraph, a line holding
+// nothing but a single tag.
+func isHTMLBlockStart(line string, inParagraph bool) bool {
+	m := htmlBlockStart.FindStringSubmatch(line)
+	if m == nil {
+		return false
+	}
+	if m[2] == "" || htmlBlockTags[strings.ToLower(m[2])] {
+		return true
+	}
+	if inParagraph {
+		return false
+	}
+	rest := strings.TrimLeft(line, " ")
+	loc := rawHTML.FindStringIndex(rest)
+	return loc != nil && isBlank(rest[loc[1]:])
+}
+
+func isTableStart(lines []string, i int) bool {
+	return i+1 < len(lines) &&
+		strings.Contains(lines[i], "|") &&
+		tableDelimiter.MatchString(lines[i+1]) &&
+		len(splitRow(lines[i])) == len(splitRow(lines[i+1]))
+}
+
+func parseTable(lines []string, i int) (*block, int) {
+	header := splitRow(lines[i])
+	t := &block{kind: table, rows: [][]string{header}}
+	for _, cell := range splitRow(lines[i+1]) {
+		switch {
+		case strings.HasPrefix(cell, ":") && strings.HasSuffix(cell, ":"):
+			t.align = append(t.align, "center")
+		case strings.HasPrefix(cell, ":"):
+			t.align = append(t.align, "left")
+		case strings.HasSuffix(cell, ":"):
+			t.align = append(t.align, "right")
+		default:
+			t.align = append(t.align, "")
+		}
+	}
+
+	for i += 2; i < len(lines) && !isBlank(lines[i]) && !interruptsParagraph(lines[i]); i++ {
+		row := splitRow(lines[i])
+		// rows have as many cells as the header
+		row = append(row, make([]string, max(0, len(header)-len(row)))...)
+		t.rows = append(t.rows, row[:len(header)])
+	}
+	return t, i
+}
+
+// splitRow splits a table row at the pipes that are not escaped.
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for j := 0; j < len(line); j++ {
+		switch {
+		case line[j] == '\\' && j+1 < len(line) && line[j+1] == '|':
+			cell.WriteByte('|')
+			j++
+		case line[j] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[j])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// parseParagraph collects lines up to a blank line or the start of a block
+// that may interrupt a paragraph. An underline turns it into a heading.
+func parseParagraph(lines []string, i int) (*block, int) {
+	text := []string{strings.TrimLeft(lines[i], " ")}
+	for i++; i < len(lines); i++ {
+		line := lines[i]
+		if m := setextUnderline.FindStringSubmatch(line); m != nil {
+			level := 1
+			if m[1][0] == '-' {
+				level = 2
+			}
+			return &block{kind: heading, level: level, text: strings.TrimSpace(strings.Join(text, "\n"))}, i + 1
+		}
+		if isBlank(line) || interruptsParagraph(line) {
+			break
+		}
+		text = append(text, strings.TrimLeft(line, " "))
+	}
+	return &block{kind: paragraph, text: strings.TrimRight(strings.Join(text, "\n"), " \t")}, i
+}
+
+// interruptsParagraph reports whether line starts a block even right after
+// the text of a paragraph.
+func interruptsParagraph(line string) bool {
+	if indentation(line) >= 4 {
+		return false
+	}
+	if fenceOpen.MatchString(line) || atxHeading.MatchString(line) || thematicBreakRe.MatchString(line) || isBlockQuote(line) {
+		return true
+	}
+	if isHTMLBlockStart(line, true) {
+		return true
+	}
+	// only lists starting at 1 and with content may interrupt a paragraph
+	if m, ok := parseListMarker(line); ok {
+		return !m.empty && (!m.ordered || m.start == 1)
+	}
+	return false
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// trimIndent removes up to n spaces of indentation.
+func trimIndent(line string, n int) string {
+	return line[min(n, indentation(line)):]
+}
+
+func renderBlocks(b *strings.Builder, blocks []*block, tight bool) {
+	for _, bl := range blocks {
+		switch bl.kind {
+		case paragraph:
+			if tight {
+				renderInline(b, bl.text)
+				continue
+			}
+			b.WriteString("<p>")
+			renderInline(b, bl.text)
+			b.WriteString("</p>\n")
+		case heading:
+			tag := "h" + strconv.Itoa(bl.level)
+			b.WriteString("<" + tag + ">")
+			renderInline(b, bl.text)
+			b.WriteString("</" + tag + ">\n")
+		case thematicBreak:
+			b.WriteString("<hr />\n")
+		case codeBlock:
+			b.WriteString("<pre><code")
+			if bl.info != "" {
+				b.WriteString(` class="language-` + escapeHTML(bl.info) + `"`)
+			}
+			b.WriteString(">" + escapeHTML(bl.text) + "</code></pre>\n")
+		case htmlBlock:
+			b.WriteString(bl.text)
+		case blockQuote:
+			b.WriteString("<blockquote>\n")
+			renderBlocks(b, bl.children, false)
+			b.WriteString("</blockquote>\n")
+		case list:
+			renderList(b, bl)
+		case table:
+			renderTable(b, bl)
+		}
+	}
+}
+
+func renderList(b *strings.Builder, l *block) {
+	tag := "ul"
+	if l.ordered {
+		tag = "ol"
+	}
+	b.WriteString("<" + tag)
+	if l.ordered && l.start != 1 {
+		b.WriteString(` start="` + strconv.Itoa(l.start) + `"`)
+	}
+	b.WriteString(">\n")
+
+	for _, item := range l.children {
+		b.WriteString("<li>")
+		for _, child := range item.children {
+			if child.kind != paragraph || !l.tight {
+				// blocks start on a line of their own
+				if s := b.String(); s[len(s)-1] != '\n' {
+					b.WriteByte('\n')
+				}
+			}
+			renderBlocks(b, []*block{child}, l.tight)
+		}
+		b.WriteString("</li>\n")
+	}
+
+	b.WriteString("</" + tag + ">\n")
+}
+
+func renderTable(b *strings.Builder, t *block) {
+	b.WriteString("<table>\n<thead>\n")
+	for r, row := range t.rows {
+		if r == 1 {
+			b.WriteString("<tbody>\n")
+		}
+		cellTag := "td"
+		if r == 0 {
+			cellTag = "th"
+		}
+		b.WriteString("<tr>\n")
+		for c, cell := range row {
+			b.WriteString("<" + cellTag)
+			if t.align[c] != "" {
+				b.WriteString(` align="` + t.align[c] + `"`)
+			}
+			b.WriteString(">")
+			renderInline(b, cell)
+			b.WriteString("</" + cellTag + ">\n")
+		}
+		b.WriteString("</tr>\n")
+		if r == 0 {
+			b.WriteString("</thead>\n")
+		}
+	}
+	if len(t.rows) > 1 {
+		b.WriteString("</tbody>\n")
+	}
+	b.WriteString("</table>\n")
+}
@@ -0,0 +1,459 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	rawHTML       = regexp.MustCompile("^(?:<[A-Za-z][A-Za-z0-9-]*(?:\\s+[A-Za-z_:][A-Za-z0-9_.:-]*(?:\\s*=\\s*(?:[^\\s\"'=<>`]+|'[^']*'|\"[^\"]*\"))?)*\\s*/?>|</[A-Za-z][A-Za-z0-9-]*\\s*>|<!--[\\s\\S]*?-->)")
+	autolink      = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9+.-]{1,31}:[^\s<>]*)>`)
+	emailAutolink = regexp.MustCompile(`^<([A-Za-z0-9.!#$%&'*+/=?^_{|}~-]+@[A-Za-z0-9](?:[A-Za-z0-9-]{0,61}[A-Za-z0-9])?(?:\.[A-Za-z0-9](?:[A-Za-z0-9-]{0,61}[A-Za-z0-9])?)*)>`)
+	entity        = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+	bareURL       = regexp.MustCompile(`^(?:https?://|www\.)[^\s<]+`)
+	trailingRef   = regexp.MustCompile(`&[A-Za-z0-9]+;$`)
+	tags          = regexp.MustCompile(`<[^>]*>`)
+)
+
+// inline is a piece of rendered HTML or a run of emphasis delimiters. The
+// tags a delimiter run opens or closes are only known once all of them have
+// been found.
+type inline struct {
+	html string
+
+	delim             byte
+	count, origCount  int
+	canOpen, canClose bool
+	open, close       []string
+}
+
+func renderInline(b *strings.Builder, s string) {
+	nodes := parseInline(s)
+	processEmphasis(nodes)
+
+	for _, n := range nodes {
+		if n.delim == 0 {
+			b.WriteString(n.html)
+			continue
+		}
+		for _, tag := range n.close {
+			b.WriteString(tag)
+		}
+		b.WriteString(strings.Repeat(string(n.delim), n.count))
+		for _, tag := range n.open {
+			b.WriteString(tag)
+		}
+	}
+}
+
+func parseInline(s string) []*inline {
+	var nodes []*inline
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, &inline{html: escapeHTML(text.String())})
+			text.Reset()
+		}
+	}
+	raw := func(h string) {
+		flush()
+		nodes = append(nodes, &inline{html: h})
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			text.WriteByte(s[i+1])
+			i += 2
+		case c == '\\' && i+1 < len(s) && s[i+1] == '\n':
+			raw("<br />\n")
+			i += 2
+		case c == '`':
+			n := runLength(s, i)
+			if end := codeSpanEnd(s, i+n, n); end >= 0 {
+				raw("<code>" + escapeHTML(codeSpanContent(s[i+n:end])) + "</code>")
+				i = end + n
+			} else {
+				text.WriteString(s[i : i+n])
+				i += n
+			}
+		case c == '*' || c == '_' || c == '~':
+			n := runLength(s, i)
+			flush()
+			nodes = append(nodes, delimiterRun(s, i, n))
+			i += n
+		case c == '!' && i+1 < len(s) && s[i+1] == '[':
+			if h, end, ok := parseLink(s, i+1, true); ok {
+				raw(h)
+				i = end
+			} else {
+				text.WriteByte(c)
+				i++
+			}
+		case c == '[':
+			if h, end, ok := parseLink(s, i, false); ok {
+				raw(h)
+				i = end
+			} else {
+				text.WriteByte(c)
+				i++
+			}
+		case c == '<':
+			if m := autolink.FindStringSubmatch(s[i:]); m != nil {
+				raw(link(m[1], m[1]))
+				i += len(m[0])
+			} else if m := emailAutolink.FindStringSubmatch(s[i:]); m != nil {
+				raw(link("mailto:"+m[1], m[1]))
+				i += len(m[0])
+			} else if loc := rawHTML.FindStringIndex(s[i:]); loc != nil {
+				raw(s[i : i+loc[1]])
+				i += loc[1]
+			} else {
+				text.WriteByte(c)
+				i++
+			}
+		case c == '&':
+			if loc := entity.FindStringIndex(s[i:]); loc != nil {
+				raw(s[i : i+loc[1]])
+				i += loc[1]
+			} else {
+				text.WriteByte(c)
+				i++
+			}
+		case c == '\n':
+			// two spaces at the end of a line make a hard break
+			t := text.String()
+			trimmed := strings.TrimRight(t, " ")
+			text.Reset()
+			text.WriteString(trimmed)
+			if len(t)-len(trimmed) >= 2 {
+				raw("<br />\n")
+			} else {
+				raw("\n")
+			}
+			i++
+		case (c == 'h' || c == 'w') && (i == 0 || strings.IndexByte(" \t\n(*_~", s[i-1]) >= 0):
+			if url := matchBareURL(s[i:]); url != "" {
+				href := url
+				if strings.HasPrefix(url, "www.") {
+					href = "http://" + url
+				}
+				raw(link(href, url))
+				i += len(url)
+			} else {
+				text.WriteByte(c)
+				i++
+			}
+		default:
+			text.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+	return nodes
+}
+
+// delimiterRun tells from the characters around the run whether it may open
+// or close emphasis, following the flanking rules of CommonMark.
+func delimiterRun(s string, i, n int) *inline {
+	prev, next := ' ', ' '
+	if i > 0 {
+		prev, _ = utf8.DecodeLastRuneInString(s[:i])
+	}
+	if i+n < len(s) {
+		next, _ = utf8.DecodeRuneInString(s[i+n:])
+	}
+
+	left := !unicode.IsSpace(next) && (!isPunct(next) || unicode.IsSpace(prev) || isPunct(prev))
+	right := !unicode.IsSpace(prev) && (!isPunct(prev) || unicode.IsSpace(next) || isPunct(next))
+
+	d := &inline{delim: s[i], count: n, origCount: n}
+	switch s[i] {
+	case '_':
+		// no emphasis inside words like snake_case
+		d.canOpen = left && (!right || isPunct(prev))
+		d.canClose = right && (!left || isPunct(next))
+	case '~':
+		d.canOpen = left && n <= 2
+		d.canClose = right && n <= 2
+	default:
+		d.canOpen, d.canClose = left, right
+	}
+	return d
+}
+
+// processEmphasis matches every closing delimiter run with the nearest run
+// before it that can open, from left to right.
+func processEmphasis(nodes []*inline) {
+	for ci, c := range nodes {
+		if c.delim == 0 || !c.canClose {
+			continue
+		}
+
+		for c.count > 0 {
+			var o *inline
+			oi := ci - 1
+			for ; oi >= 0; oi-- {
+				if canMatch(nodes[oi], c) {
+					o = nodes[oi]
+					break
+				}
+			}
+			if o == nil {
+				break
+			}
+
+			use, open, close := 1, "<em>", "</em>"
+			switch {
+			case c.delim == '~':
+				use, open, close = c.count, "<del>", "</del>"
+			case o.count >= 2 && c.count >= 2:
+				use, open, close = 2, "<strong>", "</strong>"
+			}
+			o.count -= use
+			c.count -= use
+			// later matches enclose the earlier ones
+			o.open = append([]string{open}, o.open...)
+			c.close = append(c.close, close)
+
+			for _, between := range nodes[oi+1 : ci] {
+				between.canOpen, between.canClose = false, false
+			}
+		}
+	}
+}
+
+func canMatch(o, c *inline) bool {
+	if o.delim != c.delim || !o.canOpen || o.count == 0 {
+		return false
+	}
+	if c.delim == '~' {
+		return o.count == c.count
+	}
+	// runs that can both open and close only match if their lengths don't
+	// add up to a multiple of 3
+	if (o.canClose || c.canOpen) && (o.origCount+c.origCount)%3 == 0 {
+		return o.origCount%3 == 0 && c.origCount%3 == 0
+	}
+	return true
+}
+
+// parseLink parses an inline link or image starting at the bracket at i. It
+// returns the HTML and the index after the link.
+func parseLink(s string, i int, image bool) (string, int, bool) {
+	closing := closingBracket(s, i)
+	if closing < 0 || closing+1 >= len(s) || s[closing+1] != '(' {
+		return "", 0, false
+	}
+	dest, title, end, ok := parseDestination(s, closing+2)
+	if !ok {
+		return "", 0, false
+	}
+
+	var label strings.Builder
+	renderInline(&label, s[i+1:closing])
+
+	titleAttr := ""
+	if title != "" {
+		titleAttr = ` title="` + escapeHTML(title) + `"`
+	}
+	if image {
+		alt := tags.ReplaceAllString(label.String(), "")
+		return `<img src="` + escapeURL(dest) + `" alt="` + alt + `"` + titleAttr + " />", end, true
+	}
+	return `<a href="` + escapeURL(dest) + `"` + titleAttr + ">" + label.String() + "</a>", end, true
+}
+
+// closingBracket returns the index of the bracket closing the one at i, or -1.
+func closingBracket(s string, i int) int {
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '`':
+			n := runLength(s, j)
+			if end := codeSpanEnd(s, j+n, n); end >= 0 {
+				j = end + n - 1
+			} else {
+				j += n - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// parseDestination parses the destination and optional title of a link
+// after its opening parenthesis at j.
+func parseDestination(s string, j int) (dest, title string, end int, ok bool) {
+	j = skipSpace(s, j)
+	if j < len(s) && s[j] == '<' {
+		k := strings.IndexAny(s[j+1:], "<>\n")
+		if k < 0 || s[j+1+k] != '>' {
+			return "", "", 0, false
+		}
+		dest = s[j+1 : j+1+k]
+		j += k + 2
+	} else {
+		start, depth := j, 0
+	loop:
+		for ; j < len(s); j++ {
+			switch c := s[j]; {
+			case c == '\\' && j+1 < len(s):
+				j++
+			case c == '(':
+				depth++
+			case c == ')':
+				if depth == 0 {
+					break loop
+				}
+				depth--
+			case c <= ' ':
+				break loop
+			}
+		}
+		dest = s[start:j]
+	}
+
+	if k := skipSpace(s, j); k > j && k < len(s) && strings.IndexByte(`"'(`, s[k]) >= 0 {
+		closer := s[k]
+		if closer == '(' {
+			closer = ')'
+		}
+		l := k + 1
+		for ; l < len(s) && s[l] != closer; l++ {
+			if s[l] == '\\' {
+				l++
+			}
+		}
+		if l >= len(s) {
+			return "", "", 0, false
+		}
+		title = s[k+1 : l]
+		j = l + 1
+	}
+
+	j = skipSpace(s, j)
+	if j >= len(s) || s[j] != ')' {
+		return "", "", 0, false
+	}
+	return html.UnescapeString(unescapeBackslashes(dest)), html.UnescapeString(unescapeBackslashes(title)), j + 1, true
+}
+
+func skipSpace(s string, j int) int {
+	for j < len(s) && (s[j] == ' ' || s[j] == '\t' || s[j] == '\n') {
+		j++
+	}
+	return j
+}
+
+func link(href, text string) string {
+	return `<a href="` + escapeURL(href) + `">` + escapeHTML(text) + "</a>"
+}
+
+// matchBareURL returns the URL at the start of s without the punctuation
+// that most likely ends the sentence around it.
+func matchBareURL(s string) string {
+	url := bareURL.FindString(s)
+	for url != "" {
+		switch last := url[len(url)-1]; {
+		case strings.IndexByte(`?!.,:*_~'"`, last) >= 0:
+			url = url[:len(url)-1]
+		case last == ')' && strings.Count(url, "(") < strings.Count(url, ")"):
+			url = url[:len(url)-1]
+		case last == ';' && trailingRef.MatchString(url):
+			url = trailingRef.ReplaceAllString(url, "")
+		default:
+			if strings.HasSuffix(url, "://") || url == "www." {
+				return ""
+			}
+			return url
+		}
+	}
+	return ""
+}
+
+// codeSpanEnd returns the index of the next run of exactly n backticks at or
+// after i, or -1.
+func codeSpanEnd(s string, i, n int) int {
+	for i < len(s) {
+		j := strings.IndexByte(s[i:], '`')
+		if j < 0 {
+			return -1
+		}
+		i += j
+		run := runLength(s, i)
+		if run == n {
+			return i
+		}
+		i += run
+	}
+	return -1
+}
+
+func codeSpanContent(code string) string {
+	code = strings.ReplaceAll(code, "\n", " ")
+	if len(code) >= 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+		code = code[1 : len(code)-1]
+	}
+	return code
+}
+
+// runLength returns the number of times the character at i repeats.
+func runLength(s string, i int) int {
+	n := 1
+	for i+n < len(s) && s[i+n] == s[i] {
+		n++
+	}
+	return n
+}
+
+// escapeURL percent-encodes the characters that are not allowed in URLs and
+// escapes the result for an attribute value.
+func escapeURL(url string) string {
+	const safe = "-._~:/?#[]@!$&'()*+,;=%"
+	var b strings.Builder
+	for i := 0; i < len(url); i++ {
+		c := url[i]
+		if c < utf8.RuneSelf && (c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte(safe, c) >= 0) {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return escapeHTML(b.String())
+}
+
+func unescapeBackslashes(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func isASCIIPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func isPunct(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
@@ -0,0 +1,74 @@
+// Package markdown renders Markdown to HTML. It covers the commonly used part
+// of CommonMark and the GitHub extensions for tables, strikethrough and bare
+// URLs. Raw HTML is passed through, so the output has to be sanitized before
+// it is shown to anyone but the author.
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Render returns the HTML of the Markdown document src.
+func Render(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\r", "\n")
+	src = strings.ReplaceAll(src, "\x00", "�")
+
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		lines[i] = expandTabs(line)
+	}
+
+	blocks, _ := parseBlocks(lines, 0)
+	var b strings.Builder
+	renderBlocks(&b, blocks, false)
+	return b.String()
+}
+
+var (
+	escaper          = strings.NewReplacer(`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "&", `\&`, "~", `\~`, "|", `\|`)
+	lineStartSyntax  = regexp.MustCompile(`^( *)([#+=-]|\d+[.)])`)
+	leadingIndent    = regexp.MustCompile(`^ {4,}`)
+	escapedLineStart = func(m string) string { return m[:len(m)-1] + `\` + m[len(m)-1:] }
+)
+
+// Escape returns s as Markdown that renders to the text of s, for serving
+// plain text where Markdown is expected.
+func Escape(s string) string {
+	lines := strings.Split(escaper.Replace(s), "\n")
+	for i, line := range lines {
+		// indented lines would become code blocks
+		line = leadingIndent.ReplaceAllString(line, "   ")
+		lines[i] = lineStartSyntax.ReplaceAllStringFunc(line, escapedLineStart)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// expandTabs replaces the tabs of the indentation of line with spaces up to
+// the next tab stop, which are 4 columns apart.
+func expandTabs(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case ' ':
+			b.WriteByte(' ')
+		case '\t':
+			b.WriteString(strings.Repeat(" ", 4-b.Len()%4))
+		default:
+			b.WriteString(line[i:])
+			return b.String()
+		}
+	}
+	return b.String()
+}
+
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+func escapeHTML(s string) string {
+	return htmlEscaper.Replace(s)
+}
@@ -0,0 +1,123 @@
+package markdown_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Keisn1/note-taking-app/foundation/markdown"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "Headings and emphasis",
+			src:  "# Title\n\nsome *em* and **strong** text",
+			want: "<h1>Title</h1>\n<p>some <em>em</em> and <strong>strong</strong> text</p>\n",
+		},
+		{name: "Setext heading", src: "Title\n=====", want: "<h1>Title</h1>\n"},
+		{name: "Thematic break", src: "---", want: "<hr />\n"},
+		{name: "Nested emphasis", src: "*a **b***", want: "<p><em>a <strong>b</strong></em></p>\n"},
+		{name: "Strikethrough", src: "~~gone~~", want: "<p><del>gone</del></p>\n"},
+		{name: "Hard break", src: "line  \nbreak", want: "<p>line<br />\nbreak</p>\n"},
+		{
+			name: "Nested lists",
+			src:  "- a\n- b\n  - c\n- d",
+			want: "<ul>\n<li>a</li>\n<li>b\n<ul>\n<li>c</li>\n</ul>\n</li>\n<li>d</li>\n</ul>\n",
+		},
+		{name: "Ordered list", src: "1. one\n2. two", want: "<ol>\n<li>one</li>\n<li>two</li>\n</ol>\n"},
+		{
+			name: "Block quote with lazy continuation",
+			src:  "> quote\ncontinued",
+			want: "<blockquote>\n<p>quote\ncontinued</p>\n</blockquote>\n",
+		},
+		{
+			name: "Fenced code is escaped",
+			src:  "```go\nfmt.Println(\"<hi>\")\n```",
+			want: "<pre><code class=\"language-go\">fmt.Println(&quot;&lt;hi&gt;&quot;)\n</code></pre>\n",
+		},
+		{name: "Code span", src: "`code <b>`", want: "<p><code>code &lt;b&gt;</code></p>\n"},
+		{
+			name: "Table with alignment",
+			src:  "| a | b |\n|:--|--:|\n| 1 | 2 |",
+			want: "<table>\n<thead>\n<tr>\n<th align=\"left\">a</th>\n<th align=\"right\">b</th>\n</tr>\n</thead>\n" +
+				"<tbody>\n<tr>\n<td align=\"left\">1</td>\n<td align=\"right\">2</td>\n</tr>\n</tbody>\n</table>\n",
+		},
+		{
+			name: "Links and images",
+			src:  "[link](http://example.com \"t\") and ![img](/a.png)",
+			want: "<p><a href=\"http://example.com\" title=\"t\">link</a> and <img src=\"/a.png\" alt=\"img\" /></p>\n",
+		},
+		{
+			name: "Autolinks",
+			src:  "<https://example.com> and www.example.com",
+			want: "<p><a href=\"https://example.com\">https://example.com</a> and <a href=\"http://www.example.com\">www.example.com</a></p>\n",
+		},
+		{
+			// Render leaves raw HTML and unsafe links to the sanitizer
+			name: "Raw HTML is passed through",
+			src:  "<script>alert(1)</script>",
+			want: "<script>alert(1)</script>\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, markdown.Render(tc.src))
+		})
+	}
+}
+
+func TestRender_DeepNesting(t *testing.T) {
+	var indented strings.Builder
+	for i := 0; i < 1000; i++ {
+		indented.WriteString(strings.Repeat("  ", i) + "- a\n")
+	}
+	testCases := []struct {
+		name string
+		src  string
+	}{
+		{name: "Lists on one line", src: strings.Repeat("- ", 20000) + "a"},
+		{name: "Block quotes on one line", src: strings.Repeat(">", 20000) + " a"},
+		{name: "Lists and block quotes", src: strings.Repeat("> - ", 10000) + "a"},
+		{name: "Indented lists", src: indented.String()},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			start := time.Now()
+			got := markdown.Render(tc.src)
+			assert.Less(t, time.Since(start), time.Second)
+			// markers past the limit are text
+			assert.LessOrEqual(t, strings.Count(got, "<ul>")+strings.Count(got, "<blockquote>"), 32)
+		})
+	}
+
+	t.Run("Nesting up to the limit", func(t *testing.T) {
+		got := markdown.Render(strings.Repeat("- ", 33) + "a")
+		want := strings.Repeat("<ul>\n<li>\n", 31) + "<ul>\n<li>- a</li>\n</ul>\n" + strings.Repeat("</li>\n</ul>\n", 31)
+		assert.Equal(t, want, got)
+	})
+}
+
+func TestEscape(t *testing.T) {
+	testCases := []struct {
+		text string
+		want string
+	}{
+		{text: "# not a heading", want: "<p># not a heading</p>\n"},
+		{text: "1. not a list", want: "<p>1. not a list</p>\n"},
+		{text: "a *b* _c_ `d` [e](f)", want: "<p>a *b* _c_ `d` [e](f)</p>\n"},
+		{text: "<b>", want: "<p>&lt;b&gt;</p>\n"},
+		{text: "    not code", want: "<p>not code</p>\n"},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.want, markdown.Render(markdown.Escape(tc.text)), tc.text)
+	}
+}
@@ -0,0 +1,281 @@
+// Package migrate applies versioned SQL migrations read from an fs.FS.
+//
+// Migration files are named <version>_<name>.up.sql and
+// <version>_<name>.down.sql. Applied versions are recorded in the
+// schema_migrations table. Every operation holds a postgres advisory lock so
+// that concurrently starting instances don't apply the same migration twice.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// lockID is the key of the advisory lock taken while migrating.
+const lockID int64 = 7_211_962_053
+
+var fileRe = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New reads all migrations in dir of fsys.
+func New(db *sql.DB, fsys fs.FS, dir string) (*Migrator, error) {
+	migrations, err := Load(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("new: %w", err)
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Load parses the migrations in dir of fsys, sorted by version. Every version
+// needs both an up and a down file.
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("load: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		match := fileRe.FindStringSubmatch(e.Name())
+		if match == nil {
+			return nil, fmt.Errorf("load: invalid migration file name [%s]", e.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		data, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("load: %w", err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("load: version %d used by [%s] and [%s]", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("load: version %d [%s] needs an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Up applies all pending migrations in order.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return fmt.Errorf("up: %w", err)
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			insert := `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`
+			if err := execTx(ctx, conn, mig.Up, insert, mig.Version, mig.Name); err != nil {
+				return fmt.Errorf("up: %d_%s: %w", mig.Version, mig.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Down rolls back the latest applied migration.
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		_, err := m.down(ctx, conn)
+		if err != nil {
+			return fmt.Errorf("down: %w", err)
+		}
+		return nil
+	})
+}
+
+// Reset rolls back all applied migrations.
+func (m *Migrator) Reset(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		for {
+			done, err := m.down(ctx, conn)
+			if err != nil {
+				return fmt.Errorf("reset: %w", err)
+			}
+			if done {
+				return nil
+			}
+		}
+	})
+}
+
+// Version returns the latest applied version, 0 if nothing was applied.
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	var version int
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		row := conn.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`)
+		return row.Scan(&version)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("version: %w", err)
+	}
+	return version, nil
+}
+
+// Status reports for every known migration whether it has been applied.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var ret []Status
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			at, ok := applied[mig.Version]
+			ret = append(ret, Status{Migration: mig, Applied: ok, AppliedAt: at})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("status: %w", err)
+	}
+	return ret, nil
+}
+
+// down rolls back the latest applied migration and reports whether there was
+// nothing left to roll back.
+func (m *Migrator) down(ctx context.Context, conn *sql.Conn) (bool, error) {
+	var version int
+	row := conn.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`)
+	if err := row.Scan(&version); err != nil {
+		return false, err
+	}
+	if version == 0 {
+		return true, nil
+	}
+
+	for _, mig := range m.migrations {
+		if mig.Version != version {
+			continue
+		}
+		del := `DELETE FROM schema_migrations WHERE version = $1`
+		if err := execTx(ctx, conn, mig.Down, del, mig.Version); err != nil {
+			return false, fmt.Errorf("%d_%s: %w", mig.Version, mig.Name, err)
+		}
+		return false, nil
+	}
+
+	return false, fmt.Errorf("applied version %d has no migration file", version)
+}
+
+// withLock runs f on a single connection holding the migration advisory lock.
+// Advisory locks belong to a session, so all statements have to go through
+// the same connection.
+func (m *Migrator) withLock(ctx context.Context, f func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockID); err != nil {
+		return fmt.Errorf("acquire lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockID)
+
+	createTable := `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now())`
+	if _, err := conn.ExecContext(ctx, createTable); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	return f(conn)
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at
+	}
+	return applied, rows.Err()
+}
+
+// execTx runs the migration statement and the bookkeeping statement in one
+// transaction.
+func execTx(ctx context.Context, conn *sql.Conn, stmt string, bookkeeping string, args ...any) (err error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+			err = errors.Join(err, rbErr)
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, stmt); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, bookkeeping, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,59 @@
+package migrate_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/Keisn1/note-taking-app/foundation/migrate"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("Migrations are paired and sorted by version", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"sql/0010_add_index.up.sql":      {Data: []byte("CREATE INDEX")},
+			"sql/0010_add_index.down.sql":    {Data: []byte("DROP INDEX")},
+			"sql/0002_create_notes.up.sql":   {Data: []byte("CREATE TABLE notes")},
+			"sql/0002_create_notes.down.sql": {Data: []byte("DROP TABLE notes")},
+		}
+
+		got, err := migrate.Load(fsys, "sql")
+		assert.NoError(t, err)
+		assert.Equal(t, []migrate.Migration{
+			{Version: 2, Name: "create_notes", Up: "CREATE TABLE notes", Down: "DROP TABLE notes"},
+			{Version: 10, Name: "add_index", Up: "CREATE INDEX", Down: "DROP INDEX"},
+		}, got)
+	})
+
+	testCases := []struct {
+		name    string
+		fsys    fstest.MapFS
+		wantErr string
+	}{
+		{
+			name:    "Missing down file",
+			fsys:    fstest.MapFS{"sql/0001_create_notes.up.sql": {Data: []byte("CREATE TABLE notes")}},
+			wantErr: "load: version 1 [create_notes] needs an up and a down file",
+		},
+		{
+			name: "Version used twice",
+			fsys: fstest.MapFS{
+				"sql/0001_create_notes.up.sql": {Data: []byte("CREATE TABLE notes")},
+				"sql/0001_create_users.up.sql": {Data: []byte("CREATE TABLE users")},
+			},
+			wantErr: "load: version 1 used by [create_notes] and [create_users]",
+		},
+		{
+			name:    "Invalid file name",
+			fsys:    fstest.MapFS{"sql/create_notes.sql": {Data: []byte("CREATE TABLE notes")}},
+			wantErr: "load: invalid migration file name [create_notes.sql]",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := migrate.Load(tc.fsys, "sql")
+			assert.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// Package sanitize cleans untrusted HTML with an allowlist of elements and
+// attributes, so that it can be embedded into a page without running scripts
+// or loading anything but links and images.
+package sanitize
+
+import (
+	"html"
+	"net/url"
+	"strings"
+)
+
+// elements maps the allowed elements to their allowed attributes.
+var elements = map[string][]string{
+	"a": {"href", "title"}, "abbr": {"title"}, "b": nil, "blockquote": nil, "br": nil,
+	"code": {"class"}, "dd": nil, "del": nil, "details": nil, "div": nil, "dl": nil, "dt": nil,
+	"em": nil, "h1": nil, "h2": nil, "h3": nil, "h4": nil, "h5": nil, "h6": nil, "hr": nil,
+	"i": nil, "img": {"src", "alt", "title", "width", "height"}, "ins": nil, "kbd": nil, "li": nil,
+	"mark": nil, "ol": {"start"}, "p": nil, "pre": nil, "q": nil, "s": nil, "small": nil,
+	"span": nil, "strong": nil, "sub": nil, "summary": nil, "sup": nil, "table": nil,
+	"tbody": nil, "td": {"align"}, "th": {"align"}, "thead": nil, "tr": nil, "u": nil, "ul": nil,
+}
+
+// voidElements have no end tag.
+var voidElements = map[string]bool{"br": true, "hr": true, "img": true}
+
+// droppedElements are removed together with their contents, which is never
+// meant to be shown as text.
+var droppedElements = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true, "template": true,
+	"noscript": true, "noembed": true, "noframes": true, "textarea": true, "title": true,
+	"xmp": true, "svg": true, "math": true, "select": true,
+}
+
+// urlSchemes are the allowed schemes of links and images. URLs without a
+// scheme are relative and allowed as well.
+var urlSchemes = map[string]bool{"http": true, "https": true, "mailto": true}
+
+// HTML returns the allowed parts of the HTML fragment s. Disallowed elements
+// are left out but their text is kept, except for droppedElements. Links get
+// rel="nofollow noopener noreferrer". The result has all its elements closed.
+func HTML(s string) string {
+	var b strings.Builder
+	var open []string
+	z := tokenizer{s: s}
+
+	for {
+		t, ok := z.next()
+		if !ok {
+			break
+		}
+
+		switch t.kind {
+		case textToken:
+			b.WriteString(escape(html.UnescapeString(t.data)))
+		case startTagToken:
+			if droppedElements[t.name] {
+				if !t.selfClosing {
+					z.skipTo(t.name)
+				}
+				continue
+			}
+			allowed, ok := elements[t.name]
+			if !ok {
+				continue
+			}
+			writeStartTag(&b, t, allowed)
+			if !voidElements[t.name] {
+				open = append(open, t.name)
+			}
+		case endTagToken:
+			// close everything up to the innermost open element of the name
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != t.name {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					b.WriteString("</" + open[j] + ">")
+				}
+				open = open[:i]
+				break
+			}
+		}
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		b.WriteString("</" + open[i] + ">")
+	}
+	return b.String()
+}
+
+func writeStartTag(b *strings.Builder, t token, allowed []string) {
+	b.WriteString("<" + t.name)
+	for _, name := range allowed {
+		value, ok := t.attrs[name]
+		if !ok {
+			continue
+		}
+		value, ok = cleanAttr(t.name, name, value)
+		if !ok {
+			continue
+		}
+		b.WriteString(" " + name + `="` + escape(value) + `"`)
+	}
+	if t.name == "a" {
+		b.WriteString(` rel="nofollow noopener noreferrer"`)
+	}
+	if voidElements[t.name] {
+		b.WriteString(" /")
+	}
+	b.WriteString(">")
+}
+
+// cleanAttr checks the value of an allowed attribute.
+func cleanAttr(element, name, value string) (string, bool) {
+	switch name {
+	case "href", "src":
+		return value, isSafeURL(value)
+	case "class":
+		// only the language of code blocks
+		return value, strings.HasPrefix(value, "language-") && !strings.ContainsAny(value, " \t\n")
+	case "align":
+		return value, value == "left" || value == "center" || value == "right"
+	case "start", "width", "height":
+		return value, value != "" && strings.Trim(value, "0123456789") == ""
+	}
+	return value, true
+}
+
+func isSafeURL(raw string) bool {
+	// browsers ignore control characters and whitespace in schemes
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, raw)
+
+	u, err := url.Parse(cleaned)
+	if err != nil {
+		return false
+	}
+	if u.Scheme == "" {
+		// a colon before any slash would be read as a scheme by browsers
+		return !strings.Contains(strings.SplitN(strings.SplitN(cleaned, "/", 2)[0], "?", 2)[0], ":")
+	}
+	return urlSchemes[strings.ToLower(u.Scheme)]
+}
+
+var escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&#39;")
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
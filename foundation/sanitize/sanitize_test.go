@@ -0,0 +1,89 @@
+package sanitize_test
+
+import (
+	"testing"
+
+	"github.com/Keisn1/note-taking-app/foundation/sanitize"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTML(t *testing.T) {
+	testCases := []struct {
+		name string
+		html string
+		want string
+	}{
+		{name: "Event handlers are dropped and tags closed", html: `<p onclick="x">hi <b>there</p>`, want: `<p>hi <b>there</b></p>`},
+		{name: "Scripts are dropped with their content", html: `<script>alert(1)</script>ok`, want: `ok`},
+		{name: "Iframes are dropped with their content", html: `<iframe src="x">in</iframe>after`, want: `after`},
+		{name: "Unsafe URLs are dropped", html: `<a href="javascript:alert(1)">x</a>`, want: `<a rel="nofollow noopener noreferrer">x</a>`},
+		{
+			name: "Links get rel",
+			html: `<a href="https://e.com" target="_blank">e</a>`,
+			want: `<a href="https://e.com" rel="nofollow noopener noreferrer">e</a>`,
+		},
+		{name: "Relative image", html: `<img src=x onerror=alert(1)>`, want: `<img src="x" />`},
+		{name: "Only language classes", html: `<code class="language-go evil">x</code>`, want: `<code>x</code>`},
+		{name: "Table alignment", html: `<td align="center" style="x">1</td>`, want: `<td align="center">1</td>`},
+		{name: "Comments are dropped and text escaped", html: `<!-- c -->a &amp; b <3`, want: `a &amp; b &lt;3`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, sanitize.HTML(tc.html))
+		})
+	}
+}
+
+func TestHTML_Adversarial(t *testing.T) {
+	testCases := []struct {
+		name string
+		html string
+		want string
+	}{
+		// schemes hidden by entities
+		{name: "Decimal entities", html: `<a href="&#106;&#97;vascript:alert(1)">x</a>`, want: `<a rel="nofollow noopener noreferrer">x</a>`},
+		{name: "Hex entities", html: `<a href="&#x6A;avascript&#x3A;alert(1)">x</a>`, want: `<a rel="nofollow noopener noreferrer">x</a>`},
+		{name: "Entities without semicolon", html: `<a href="&#0000106avascript:alert(1)">x</a>`, want: `<a rel="nofollow noopener noreferrer">x</a>`},
+		{name: "Named entities", html: `<img src="javascript&colon;alert(1)">`, want: `<img />`},
+		{name: "Encoded tab in scheme", html: `<a href="java&#x09;script:alert(1)">x</a>`, want: `<a rel="nofollow noopener noreferrer">x</a>`},
+		{name: "Newline in scheme", html: "<a href=\"java\nscript:alert(1)\">x</a>", want: `<a rel="nofollow noopener noreferrer">x</a>`},
+		{name: "Upper case scheme", html: `<a href="JaVaScRiPt:alert(1)">x</a>`, want: `<a rel="nofollow noopener noreferrer">x</a>`},
+		{name: "Data URL", html: `<img src="data:text/html;base64,PHNjcmlwdD4=">`, want: `<img />`},
+		{name: "Entities in text stay text", html: `&lt;script&gt;alert(1)&lt;/script&gt;`, want: `&lt;script&gt;alert(1)&lt;/script&gt;`},
+
+		// dropped elements nested in each other
+		{name: "Script in style", html: `<style><script>alert(1)</script></style>ok`, want: `ok`},
+		{name: "Script in script", html: `<script><script>alert(1)</script>a</script>ok`, want: `aok`},
+		{
+			name: "End tag of dropped element in attribute",
+			html: `<noscript><p title="</noscript><img src=x onerror=alert(1)>">`,
+			want: `<img src="x" />&quot;&gt;`,
+		},
+		{name: "Upper case dropped element", html: `<SCRIPT>alert(1)</ScRiPt>ok`, want: `ok`},
+		{name: "Dropped element in allowed one", html: `<p><iframe src="x"><b>in</b></iframe>ok</p>`, want: `<p>ok</p>`},
+
+		// unclosed and malformed tags
+		{name: "Unclosed script", html: `a<script>alert(1)`, want: `a`},
+		{name: "Unclosed tag", html: `<img src=x onerror=alert(1)`, want: `<img src="x" />`},
+		{name: "Unclosed quote", html: `<a href="javascript:alert(1)>x</a>`, want: `<a href="" rel="nofollow noopener noreferrer"></a>`},
+		{name: "Tag in tag name", html: `<scr<script>ipt>alert(1)</script>`, want: `ipt&gt;alert(1)`},
+		{name: "Double opening bracket", html: `<<script>alert(1)</script>`, want: `&lt;`},
+		{name: "Slash before attribute", html: `<img/src=x/onerror=alert(1)>`, want: `<img src="x/onerror=alert(1)" />`},
+		{name: "Stray end tags", html: `</p>a</b><b>b</i>`, want: `a<b>b</b>`},
+		{name: "Duplicate attribute", html: `<a href="https://e.com" href="javascript:alert(1)">x</a>`, want: `<a href="https://e.com" rel="nofollow noopener noreferrer">x</a>`},
+		{name: "Unclosed comment", html: `a<!-- <script>alert(1)</script>`, want: `a`},
+
+		// foreign content
+		{name: "SVG", html: `<svg><a href="javascript:alert(1)">x</a><script>alert(1)</script></svg>ok`, want: `ok`},
+		{name: "SVG onload", html: `<svg/onload=alert(1)>`, want: ``},
+		{name: "MathML", html: `<math><mi xlink:href="javascript:alert(1)">x</mi></math>ok`, want: `ok`},
+		{name: "Style in SVG", html: `<svg><style><img src=x onerror=alert(1)></style></svg>ok`, want: `ok`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, sanitize.HTML(tc.html))
+		})
+	}
+}
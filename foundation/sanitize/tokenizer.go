@@ -0,0 +1,176 @@
+package sanitize
+
+import (
+	"html"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	textToken tokenKind = iota
+	startTagToken
+	endTagToken
+)
+
+type token struct {
+	kind tokenKind
+	// data is the raw text of text tokens.
+	data        string
+	name        string
+	attrs       map[string]string
+	selfClosing bool
+}
+
+// tokenizer splits HTML into text and tags. Comments, doctypes and
+// processing instructions are skipped. A '<' that doesn't start a tag is
+// text.
+type tokenizer struct {
+	s   string
+	pos int
+}
+
+func (z *tokenizer) next() (token, bool) {
+	for z.pos < len(z.s) {
+		if z.s[z.pos] != '<' {
+			end := strings.IndexByte(z.s[z.pos+1:], '<')
+			if end < 0 {
+				end = len(z.s)
+			} else {
+				end += z.pos + 1
+			}
+			t := token{kind: textToken, data: z.s[z.pos:end]}
+			z.pos = end
+			return t, true
+		}
+
+		rest := z.s[z.pos:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			z.skipPast("-->", 4)
+		case strings.HasPrefix(rest, "<!") || strings.HasPrefix(rest, "<?"):
+			z.skipPast(">", 2)
+		case strings.HasPrefix(rest, "</") && len(rest) > 2 && isLetter(rest[2]):
+			z.pos += 2
+			name := z.readName()
+			z.skipPast(">", 0)
+			return token{kind: endTagToken, name: name}, true
+		case len(rest) > 1 && isLetter(rest[1]):
+			z.pos++
+			return z.readStartTag(), true
+		default:
+			z.pos++
+			return token{kind: textToken, data: "<"}, true
+		}
+	}
+	return token{}, false
+}
+
+// skipPast moves behind the next occurrence of end, searching from offset.
+// Without one, the rest of the input is skipped.
+func (z *tokenizer) skipPast(end string, offset int) {
+	i := strings.Index(z.s[z.pos+offset:], end)
+	if i < 0 {
+		z.pos = len(z.s)
+		return
+	}
+	z.pos += offset + i + len(end)
+}
+
+// skipTo moves behind the end tag of name, skipping the contents of an
+// element that is dropped entirely.
+func (z *tokenizer) skipTo(name string) {
+	lower := strings.ToLower(z.s[z.pos:])
+	i := strings.Index(lower, "</"+name)
+	if i < 0 {
+		z.pos = len(z.s)
+		return
+	}
+	z.pos += i + 2 + len(name)
+	z.skipPast(">", 0)
+}
+
+func (z *tokenizer) readStartTag() token {
+	t := token{kind: startTagToken, name: z.readName(), attrs: map[string]string{}}
+	for z.pos < len(z.s) {
+		c := z.s[z.pos]
+		switch {
+		case c == '>':
+			z.pos++
+			return t
+		case c == '/':
+			z.pos++
+			if z.pos < len(z.s) && z.s[z.pos] == '>' {
+				t.selfClosing = true
+			}
+		case isSpace(c):
+			z.pos++
+		default:
+			name, value := z.readAttr()
+			if _, ok := t.attrs[name]; !ok && name != "" {
+				t.attrs[name] = value
+			}
+		}
+	}
+	return t
+}
+
+func (z *tokenizer) readName() string {
+	start := z.pos
+	for z.pos < len(z.s) && !isSpace(z.s[z.pos]) && z.s[z.pos] != '/' && z.s[z.pos] != '>' {
+		z.pos++
+	}
+	return strings.ToLower(z.s[start:z.pos])
+}
+
+func (z *tokenizer) readAttr() (string, string) {
+	start := z.pos
+	for z.pos < len(z.s) && !isSpace(z.s[z.pos]) && !strings.ContainsRune("/>=", rune(z.s[z.pos])) {
+		z.pos++
+	}
+	// a stray character that can't start a name, e.g. a second '='
+	if z.pos == start {
+		z.pos++
+		return "", ""
+	}
+	name := strings.ToLower(z.s[start:z.pos])
+
+	for z.pos < len(z.s) && isSpace(z.s[z.pos]) {
+		z.pos++
+	}
+	if z.pos >= len(z.s) || z.s[z.pos] != '=' {
+		return name, ""
+	}
+	z.pos++
+	for z.pos < len(z.s) && isSpace(z.s[z.pos]) {
+		z.pos++
+	}
+	if z.pos >= len(z.s) {
+		return name, ""
+	}
+
+	if q := z.s[z.pos]; q == '"' || q == '\'' {
+		end := strings.IndexByte(z.s[z.pos+1:], q)
+		if end < 0 {
+			z.pos = len(z.s)
+			return name, ""
+		}
+		value := z.s[z.pos+1 : z.pos+1+end]
+		z.pos += end + 2
+		return name, html.UnescapeString(value)
+	}
+
+	start = z.pos
+	for z.pos < len(z.s) && !isSpace(z.s[z.pos]) && z.s[z.pos] != '>' {
+		z.pos++
+	}
+	return name, html.UnescapeString(z.s[start:z.pos])
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
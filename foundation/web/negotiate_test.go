@@ -0,0 +1,32 @@
+package web_test
+
+import (
+	"testing"
+
+	"github.com/Keisn1/note-taking-app/foundation/web"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiate(t *testing.T) {
+	offers := []string{"application/json", "text/html", "text/markdown"}
+
+	testCases := []struct {
+		accept string
+		want   string
+	}{
+		{accept: "", want: "application/json"},
+		{accept: "*/*", want: "application/json"},
+		{accept: "text/html", want: "text/html"},
+		{accept: "TEXT/Markdown", want: "text/markdown"},
+		{accept: "text/*", want: "text/html"},
+		{accept: "text/html;q=0.5, text/markdown", want: "text/markdown"},
+		{accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", want: "text/html"},
+		{accept: "*/*;q=0.1, application/json;q=0", want: "text/html"},
+		{accept: "image/png", want: ""},
+		{accept: "text/plain, application/*;q=0.2", want: "application/json"},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.want, web.Negotiate(tc.accept, offers...), tc.accept)
+	}
+}